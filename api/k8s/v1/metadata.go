@@ -2,6 +2,9 @@ package v1
 
 const (
 	PodModelLabel = "model"
+	// ParkedPodModelLabel is set (instead of PodModelLabel) on the parked Pods
+	// of a Model's warm pool so that they are not treated as server Pods.
+	ParkedPodModelLabel = "parked-model"
 	// PodHashLabel is a label key used to store the hash of the Pod spec
 	// that was used to create the Pod. This is used to determine if a Pod
 	// needs to be recreated.
@@ -15,6 +18,17 @@ const (
 	ModelPodIPAnnotation   = "model-pod-ip"
 	ModelPodPortAnnotation = "model-pod-port"
 
+	// ModelPodTrafficPercentAnnotation is set by the Model controller on the
+	// up-to-date Pods of a BlueGreen or Canary rollout. Together, the Pods with
+	// the annotation receive the given percentage of the requests to the Model,
+	// the remaining requests are sent to the Pods without the annotation.
+	ModelPodTrafficPercentAnnotation = "model-pod-traffic-percent"
+
+	// ModelPodDrainingAnnotation is set by the Model controller on Pods that are
+	// drained before they are deleted. The value is the RFC 3339 time at which
+	// draining started. Draining Pods do not receive new requests.
+	ModelPodDrainingAnnotation = "model-pod-draining"
+
 	ModelCacheEvictionFinalizer = "kubeai.org/cache-eviction"
 )
 
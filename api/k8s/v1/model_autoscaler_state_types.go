@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelAutoscalerStateStatus is the state of the autoscaler for a Model.
+type ModelAutoscalerStateStatus struct {
+	// ActiveRequestsHistory is the moving-average window of the total number of
+	// active requests for the Model, ordered from oldest to newest.
+	ActiveRequestsHistory []int64 `json:"activeRequestsHistory,omitempty"`
+
+	// ConsecutiveScaleDowns is the number of consecutive autoscaling intervals
+	// in which the autoscaler wanted to scale the Model down.
+	ConsecutiveScaleDowns int32 `json:"consecutiveScaleDowns,omitempty"`
+
+	// DryRunReplicas is the number of replicas that the autoscaler would have
+	// scaled the Model to. Only set when autoscalingDryRun is enabled for the Model.
+	DryRunReplicas *int32 `json:"dryRunReplicas,omitempty"`
+
+	// LastActiveTime is the last time that the Model had active requests.
+	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+
+	// LastCalculationTime is the time of the last autoscaling calculation.
+	LastCalculationTime *metav1.Time `json:"lastCalculationTime,omitempty"`
+
+	// Leader is the identity of the KubeAI replica that last updated the state.
+	Leader string `json:"leader,omitempty"`
+}
+
+// ModelAutoscalerState resources store the state of the autoscaler for the
+// Model with the same name. They are managed by KubeAI and allow a newly
+// elected leader to resume autoscaling where the previous leader stopped.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Consecutive Scale Downs",type=integer,JSONPath=`.status.consecutiveScaleDowns`
+// +kubebuilder:printcolumn:name="Last Calculation",type=date,JSONPath=`.status.lastCalculationTime`
+// +kubebuilder:printcolumn:name="Leader",type=string,JSONPath=`.status.leader`
+type ModelAutoscalerState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ModelAutoscalerStateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelAutoscalerStateList contains a list of ModelAutoscalerStates.
+type ModelAutoscalerStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelAutoscalerState `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelAutoscalerState{}, &ModelAutoscalerStateList{})
+}
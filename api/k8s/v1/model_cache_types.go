@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelCacheSpec defines the model that is loaded into a cache ahead of
+// the Models that are served from it.
+type ModelCacheSpec struct {
+	// URL of the model to be cached, in the same formats as the url of a
+	// Model with a cacheProfile.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self.startsWith(\"hf://\") || self.startsWith(\"s3://\") || self.startsWith(\"gs://\") || self.startsWith(\"oss://\") || self.startsWith(\"az://\") || self.startsWith(\"https://\") || self.startsWith(\"oci://\")", message="url must start with \"hf://\", \"s3://\", \"gs://\", \"oss://\", \"az://\", \"https://\" or \"oci://\" and not be empty."
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="url is immutable."
+	URL string `json:"url"`
+
+	// CacheProfile is the name of the sharedFilesystem cache profile to load
+	// the model into. Models with the same url and cacheProfile are served
+	// from the cached model.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="cacheProfile is immutable."
+	CacheProfile string `json:"cacheProfile"`
+
+	// Env variables of the load Job, e.g. the endpoint of an S3-compatible
+	// object storage.
+	// +optional
+	Env map[string]string `json:"env,omitempty"`
+
+	// RefreshInterval is the interval at which the model is loaded again,
+	// picking up new revisions of urls that are not pinned to a revision.
+	// The model is only loaded once when not set.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// ModelCacheStatus is the state of the cached model.
+type ModelCacheStatus struct {
+	// Loaded is true once the model was loaded into the cache.
+	// It stays true while the model is refreshed.
+	Loaded bool `json:"loaded"`
+	// Revision is the resolved revision of the cached model.
+	Revision string `json:"revision,omitempty"`
+	// ManifestSHA256 is the SHA256 digest of the manifest of the cached files.
+	ManifestSHA256 string `json:"manifestSHA256,omitempty"`
+	// SizeBytes is the size of the model on disk as reported by the load Job.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// LoadedTime is the last time that the model was loaded or refreshed.
+	LoadedTime *metav1.Time `json:"loadedTime,omitempty"`
+	// Progress of the load Job while the model is loaded into the cache.
+	Progress *ModelCacheLoadProgress `json:"progress,omitempty"`
+	// Models are the names of the Models that are served from the cached model.
+	Models []string `json:"models,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ModelCacheConditionLoaded is true when the model is loaded into the cache.
+	ModelCacheConditionLoaded = "Loaded"
+)
+
+const (
+	ModelCacheReasonRefreshing    = "Refreshing"
+	ModelCacheReasonRefreshFailed = "RefreshFailed"
+)
+
+// ModelCache resources load a model into a sharedFilesystem cache profile
+// independently of any Model, e.g. to pre-warm the cache for Models that
+// are deployed later. Models with the same url and cacheProfile are served
+// from the cached model instead of loading their own copy.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Profile",type=string,JSONPath=`.spec.cacheProfile`
+// +kubebuilder:printcolumn:name="Loaded",type=boolean,JSONPath=`.status.loaded`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.sizeBytes`
+type ModelCache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelCacheSpec   `json:"spec,omitempty"`
+	Status ModelCacheStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelCacheList contains a list of ModelCaches.
+type ModelCacheList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelCache `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelCache{}, &ModelCacheList{})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelEngineSpec describes how the server Pods of the Models that use the engine are built.
+type ModelEngineSpec struct {
+	// Images of the engine, keyed by image name.
+	// The image is selected by the imageName of the ResourceProfile of a Model,
+	// the "default" image is used when no image with that name exists.
+	// +kubebuilder:validation:XValidation:rule="'default' in self", message="a \"default\" image is required."
+	Images map[string]string `json:"images"`
+
+	// Container is the template of the server container.
+	// The following placeholders (Go templates) are replaced in the command,
+	// args and env values:
+	//
+	// {{ .ModelPath }}: The Huggingface repo (i.e. "org/model") for "hf://" urls
+	// or the local directory of the model for "pvc://" urls and Models with a cacheProfile.
+	// {{ .ModelRevision }}: The pinned revision of "hf://" urls (i.e. "hf://org/model@<revision>"),
+	// empty if the url is not pinned or the Model has a cacheProfile.
+	// {{ .ModelURL }}: The url of the Model.
+	// {{ .ServedModelName }}: The name of the Model.
+	// {{ .Port }}: The port of the engine.
+	//
+	// The args of the Model are appended to the args of the template.
+	// +kubebuilder:validation:Required
+	Container ModelEngineContainer `json:"container"`
+
+	// Port that the engine serves the OpenAI API on.
+	// +kubebuilder:default=8000
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	Port int32 `json:"port,omitempty"`
+
+	// URLSchemes are the schemes of the Model urls that the engine supports (i.e. "hf", "pvc").
+	// Models with a cacheProfile are always supported, the engine loads them from a local directory.
+	// +kubebuilder:validation:MinItems=1
+	URLSchemes []string `json:"urlSchemes"`
+
+	// Features are the features that Models using the engine can have.
+	// +kubebuilder:validation:MinItems=1
+	Features []ModelFeature `json:"features"`
+
+	// HealthPath is the HTTP path of the health endpoint of the engine.
+	// It is used for the startup, readiness and liveness probes.
+	// +kubebuilder:default="/health"
+	// +kubebuilder:validation:Optional
+	HealthPath string `json:"healthPath,omitempty"`
+
+	// Probes overrides the default timing of the probes of the engine.
+	// Models can override these settings in turn.
+	// +kubebuilder:validation:Optional
+	Probes ModelProbes `json:"probes,omitempty"`
+
+	// BashAvailable declares that the images of the engine contain bash,
+	// which is required to track the startup progress of the engine
+	// (see probes.startupStallTimeoutSeconds). Without it, the startup
+	// probe of the engine is kept.
+	// +kubebuilder:validation:Optional
+	BashAvailable bool `json:"bashAvailable,omitempty"`
+
+	// AdapterProtocol is the API that is used to load LoRA adapters into the engine.
+	// None disables adapters for the engine.
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Optional
+	AdapterProtocol AdapterProtocol `json:"adapterProtocol,omitempty"`
+}
+
+// ModelEngineContainer is the template of the server container of an engine.
+type ModelEngineContainer struct {
+	// Command overrides the entrypoint of the image.
+	// +kubebuilder:validation:Optional
+	Command []string `json:"command,omitempty"`
+	// Args of the engine.
+	// +kubebuilder:validation:Optional
+	Args []string `json:"args,omitempty"`
+	// Env of the engine. The env of the Model is appended.
+	// +kubebuilder:validation:Optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=None;VLLM
+type AdapterProtocol string
+
+const (
+	AdapterProtocolNone AdapterProtocol = "None"
+	// AdapterProtocolVLLM uses the dynamic LoRA API of vLLM.
+	AdapterProtocolVLLM AdapterProtocol = "VLLM"
+)
+
+// ModelEngine is an inference engine that Models can reference by name
+// (in .spec.engine) in addition to the engines that are built into KubeAI.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Port",type=integer,JSONPath=`.spec.port`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ModelEngine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelEngineSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelEngineList contains a list of ModelEngines.
+type ModelEngineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelEngine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelEngine{}, &ModelEngineList{})
+}
@@ -18,18 +18,36 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Use "+NOTE: ..." comments to add notes to the code that wont show up in public API reference or Custom Resource Definition.
 
 // ModelSpec defines the desired state of Model.
-// +kubebuilder:validation:XValidation:rule="!has(self.cacheProfile) || self.url.startsWith(\"hf://\") || self.url.startsWith(\"s3://\") || self.url.startsWith(\"gs://\") || self.url.startsWith(\"oss://\")", message="cacheProfile is only supported with urls of format \"hf://...\", \"s3://...\", \"gs://...\", or \"oss://...\" at the moment."
-// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"gs://\") || has(self.cacheProfile)", message="urls of format \"gs://...\" only supported when using a cacheProfile"
-// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"oss://\") || has(self.cacheProfile)", message="urls of format \"oss://...\" only supported when using a cacheProfile"
+// +kubebuilder:validation:XValidation:rule="!has(self.cacheProfile) || self.url.startsWith(\"hf://\") || self.url.startsWith(\"s3://\") || self.url.startsWith(\"gs://\") || self.url.startsWith(\"oss://\") || self.url.startsWith(\"az://\") || self.url.startsWith(\"https://\") || self.url.startsWith(\"oci://\")", message="cacheProfile is only supported with urls of format \"hf://...\", \"s3://...\", \"gs://...\", \"oss://...\", \"az://...\", \"https://...\", or \"oci://...\" at the moment."
+// +kubebuilder:validation:XValidation:rule="has(self.cacheProfile) || !(self.url.startsWith(\"s3://\") || self.url.startsWith(\"gs://\") || self.url.startsWith(\"oss://\")) || self.engine == \"VLLM\" || self.engine.matches(\"^[a-z0-9-]+$\")", message="urls of format \"s3://...\", \"gs://...\" and \"oss://...\" are streamed without a cacheProfile, which is only supported by the VLLM engine and ModelEngines."
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"gs://\") || !self.url.contains(\"#\") || has(self.cacheProfile)", message="urls of format \"gs://...#<generation>\" only supported when using a cacheProfile"
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"oss://\") || has(self.cacheProfile) || (has(self.env) && \"OSS_ENDPOINT\" in self.env)", message="streaming urls of format \"oss://...\" requires the OSS_ENDPOINT env var (e.g. \"oss-cn-hangzhou.aliyuncs.com\")."
+// +kubebuilder:validation:XValidation:rule="!has(self.streaming) || (!has(self.cacheProfile) && (self.url.startsWith(\"s3://\") || self.url.startsWith(\"gs://\") || self.url.startsWith(\"oss://\")))", message="streaming is only supported for urls of format \"s3://...\", \"gs://...\" or \"oss://...\" without a cacheProfile."
+// +kubebuilder:validation:XValidation:rule="!has(self.streaming) || self.engine == \"VLLM\"", message="streaming is only supported by the VLLM engine, configure the streaming of ModelEngines with args."
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"az://\") || has(self.cacheProfile)", message="urls of format \"az://...\" only supported when using a cacheProfile"
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"https://\") || has(self.cacheProfile)", message="urls of format \"https://...\" only supported when using a cacheProfile"
 // +kubebuilder:validation:XValidation:rule="!has(self.maxReplicas) || self.minReplicas <= self.maxReplicas", message="minReplicas should be less than or equal to maxReplicas."
-// +kubebuilder:validation:XValidation:rule="!has(self.adapters) || self.engine == \"VLLM\"", message="adapters only supported with VLLM engine."
+// +kubebuilder:validation:XValidation:rule="!has(self.adapters) || self.engine == \"VLLM\" || self.engine.matches(\"^[a-z0-9-]+$\")", message="adapters only supported with VLLM engine or ModelEngines."
+// +kubebuilder:validation:XValidation:rule="!(self.engine in [\"SGLang\", \"TGI\", \"LlamaCPP\"]) || self.url.startsWith(\"hf://\") || self.url.startsWith(\"pvc://\") || self.url.startsWith(\"oci://\") || has(self.cacheProfile)", message="SGLang, TGI and LlamaCPP engines only support urls of format \"hf://...\", \"pvc://...\" or \"oci://...\" unless a cacheProfile is used."
+// +kubebuilder:validation:XValidation:rule="!(self.engine in [\"SGLang\", \"LlamaCPP\"]) || self.features.all(f, f == \"TextGeneration\" || f == \"TextEmbedding\")", message="SGLang and LlamaCPP engines only support TextGeneration and TextEmbedding features."
+// +kubebuilder:validation:XValidation:rule="self.engine != \"TGI\" || self.features.all(f, f == \"TextGeneration\")", message="TGI engine only supports the TextGeneration feature."
+// +kubebuilder:validation:XValidation:rule="!has(self.workload) || !has(self.workload.kind) || self.workload.kind == \"Pod\" || !has(self.rollout) || !has(self.rollout.strategy) || self.rollout.strategy in [\"RollingUpdate\", \"Recreate\"]", message="Deployment and LeaderWorkerSet workloads only support the RollingUpdate and Recreate rollout strategies."
+// +kubebuilder:validation:XValidation:rule="!has(self.workload) || !has(self.workload.kind) || self.workload.kind == \"Pod\" || !has(self.warmPool) || !has(self.warmPool.replicas) || self.warmPool.replicas == 0", message="warmPool is only supported with the Pod workload."
+// +kubebuilder:validation:XValidation:rule="!has(self.workload) || !has(self.workload.leaderWorkerSet) || !has(self.workload.leaderWorkerSet.size) || self.workload.leaderWorkerSet.size == 1 || self.engine == \"VLLM\"", message="LeaderWorkerSet groups with more than one Pod are only supported with the VLLM engine."
+// +kubebuilder:validation:XValidation:rule="!has(self.idleTimeoutSeconds) || self.minReplicas == 0", message="idleTimeoutSeconds requires minReplicas to be 0."
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.cacheProfile) || self.url == oldSelf.url", message="url is immutable when using cacheProfile."
+// +kubebuilder:validation:XValidation:rule="!self.url.matches(\"^hf://[^?]*@\") || has(self.cacheProfile) || !(self.engine in [\"OLlama\", \"FasterWhisper\", \"LlamaCPP\"])", message="revisions of \"hf://\" urls are not supported by the OLlama, FasterWhisper and LlamaCPP engines unless a cacheProfile is used."
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"s3://\") || !self.url.contains(\"versionId=\") || has(self.cacheProfile)", message="urls of format \"s3://...?versionId=...\" only supported when using a cacheProfile"
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith(\"oci://\") || self.engine != \"OLlama\"", message="urls of format \"oci://...\" are not supported by the OLlama engine."
 // +NOTE: The self.files.all() check is considered "costly" by the Kubernetes API server and will be rejected if the number of files (and length of .path) are not restricted. These restrictions are applied in field-based validations below.
 // +kubebuilder:validation:XValidation:rule="!has(self.files) || self.files.size() <= 1 || !self.files.exists(f, self.files.filter(other, other.path == f.path).size() > 1)", message="All file paths must be unique."
 // +TODO: Limits on total file size should be less than limit of total ConfigMap (1MiB) data, this fails in version 1.29 (for exceeding "cost"): "!has(self.files) || self.files.map(f, size(f.content)).sum() <= 500000"
@@ -37,31 +55,51 @@ type ModelSpec struct {
 	// URL of the model to be served.
 	// Currently the following formats are supported:
 	//
-	// For VLLM, FasterWhisper, Infinity engines:
+	// For VLLM, FasterWhisper, Infinity, SGLang, TGI, LlamaCPP engines:
 	//
 	// "hf://<repo>/<model>"
 	// "pvc://<pvcName>"
 	// "pvc://<pvcName>/<pvcSubpath>"
-	// "gs://<bucket>/<path>" (only with cacheProfile)
-	// "oss://<bucket>/<path>" (only with cacheProfile)
-	// "s3://<bucket>/<path>" (only with cacheProfile)
+	// "gs://<bucket>/<path>" (streamed by VLLM without cacheProfile)
+	// "oss://<bucket>/<path>" (streamed by VLLM without cacheProfile, requires the OSS_ENDPOINT env var)
+	// "s3://<bucket>/<path>" (streamed by VLLM without cacheProfile)
+	// "az://<container>/<path>" (only with cacheProfile)
+	// "https://<host>/<path>" (only with cacheProfile, a single file or a .tar, .tar.gz or .tgz archive)
+	// "oci://<registry>/<repository>:<tag>"
+	// "oci://<registry>/<repository>@<digest>"
+	//
+	// For the LlamaCPP engine, a GGUF file can be selected with the "model" query parameter:
+	//
+	// "hf://<repo>/<model>?model=<file>.gguf"
 	//
 	// For OLlama engine:
 	//
 	// "ollama://<model>"
 	//
+	// Immutable revisions can be pinned (s3 and gs only for single files):
+	//
+	// "hf://<repo>/<model>@<revision>"
+	// "s3://<bucket>/<path>?versionId=<versionId>" (only with cacheProfile)
+	// "gs://<bucket>/<path>#<generation>" (only with cacheProfile)
+	// "https://<host>/<path>#sha256=<checksum>" (only with cacheProfile)
+	//
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:XValidation:rule="self.startsWith(\"hf://\") || self.startsWith(\"pvc://\") || self.startsWith(\"ollama://\") || self.startsWith(\"s3://\") || self.startsWith(\"gs://\") || self.startsWith(\"oss://\")", message="url must start with \"hf://\", \"pvc://\", \"ollama://\", \"s3://\", \"gs://\", or \"oss://\" and not be empty."
+	// +kubebuilder:validation:XValidation:rule="self.startsWith(\"hf://\") || self.startsWith(\"pvc://\") || self.startsWith(\"ollama://\") || self.startsWith(\"s3://\") || self.startsWith(\"gs://\") || self.startsWith(\"oss://\") || self.startsWith(\"az://\") || self.startsWith(\"https://\") || self.startsWith(\"oci://\")", message="url must start with \"hf://\", \"pvc://\", \"ollama://\", \"s3://\", \"gs://\", \"oss://\", \"az://\", \"https://\", or \"oci://\" and not be empty."
 	URL string `json:"url"`
 
 	Adapters []Adapter `json:"adapters,omitempty"`
 
 	// Features that the model supports.
 	// Dictates the APIs that are available for the model.
+	// +NOTE: MaxItems bounds the cost of the engine specific feature validation rules.
+	// +kubebuilder:validation:MaxItems=10
 	Features []ModelFeature `json:"features"`
 
 	// Engine to be used for the server process.
-	// +kubebuilder:validation:Enum=OLlama;VLLM;FasterWhisper;Infinity
+	// One of the built-in engines (OLlama, VLLM, FasterWhisper, Infinity, SGLang, TGI, LlamaCPP)
+	// or the name of a ModelEngine.
+	// +kubebuilder:validation:Pattern=`^(OLlama|VLLM|FasterWhisper|Infinity|SGLang|TGI|LlamaCPP|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	// +kubebuilder:validation:MaxLength=63
 	// +kubebuilder:validation:Required
 	Engine string `json:"engine"`
 
@@ -76,6 +114,12 @@ type ModelSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="cacheProfile is immutable."
 	CacheProfile string `json:"cacheProfile,omitempty"`
 
+	// Streaming configures how the weights of "s3://", "gs://" and "oss://"
+	// urls are streamed from object storage into the model server when no
+	// cacheProfile is used. Only supported by the VLLM engine.
+	// +kubebuilder:validation:Optional
+	Streaming *ModelStreaming `json:"streaming,omitempty"`
+
 	// Image to be used for the server process.
 	// Will be set from ResourceProfile + Engine if not specified.
 	Image string `json:"image,omitempty"`
@@ -91,7 +135,7 @@ type ModelSpec struct {
 
 	// Replicas is the number of Pod replicas that should be actively
 	// serving the model. KubeAI will manage this field unless AutoscalingDisabled
-	// is set to true.
+	// or AutoscalingDryRun is set to true.
 	Replicas *int32 `json:"replicas,omitempty"`
 
 	// MinReplicas is the minimum number of Pod replicas that the model can scale down to.
@@ -109,6 +153,11 @@ type ModelSpec struct {
 	// for the Model. When disabled, metrics will not be collected on server Pods.
 	AutoscalingDisabled bool `json:"autoscalingDisabled,omitempty"`
 
+	// AutoscalingDryRun makes the autoscaler record its scaling decisions
+	// (as Events on the Model and in its ModelAutoscalerState) without applying them.
+	// Replicas are left unchanged, including scale-from-zero.
+	AutoscalingDryRun bool `json:"autoscalingDryRun,omitempty"`
+
 	// TargetRequests is average number of active requests that the autoscaler
 	// will try to maintain on model server Pods.
 	// +kubebuilder:validation:Minimum=1
@@ -120,6 +169,20 @@ type ModelSpec struct {
 	// +kubebuilder:default=30
 	ScaleDownDelaySeconds *int64 `json:"scaleDownDelaySeconds"`
 
+	// IdleTimeoutSeconds is the time after which a Model that has not
+	// received any requests is scaled to zero replicas. Unlike regular
+	// scale-downs, this does not wait for the average number of active
+	// requests to reach zero or for ScaleDownDelaySeconds.
+	// Requires MinReplicas to be 0.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	IdleTimeoutSeconds *int64 `json:"idleTimeoutSeconds,omitempty"`
+
+	// WarmPool keeps parked Pods for the Model to reduce the latency of
+	// scaling up (especially from zero).
+	// +kubebuilder:validation:Optional
+	WarmPool *WarmPool `json:"warmPool,omitempty"`
+
 	// Owner of the model. Used solely to populate the owner field in the
 	// OpenAI /v1/models endpoint.
 	// DEPRECATED.
@@ -140,6 +203,39 @@ type ModelSpec struct {
 	// This is useful for implementing priority and preemption for models.
 	// +kubebuilder:validation:Optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate customizes the Pods that are created for the Model.
+	// It is merged onto the Pods generated by KubeAI (after the system-wide
+	// modelServerPods.jsonPatches are applied), changes result in a rollout.
+	// +kubebuilder:validation:Optional
+	PodTemplate *ModelPodTemplate `json:"podTemplate,omitempty"`
+
+	// Capacity configures how the Model shares the capacity of its ResourceProfile
+	// with other Models when a capacity budget is configured for the ResourceProfile
+	// in the system config.
+	// +kubebuilder:default={}
+	Capacity ModelCapacity `json:"capacity,omitempty"`
+
+	// Rollout configures how the Pods of the Model are replaced when the
+	// Pod spec of the Model changes.
+	// +kubebuilder:default={}
+	Rollout ModelRollout `json:"rollout,omitempty"`
+
+	// Disruption configures how the Pods of the Model are protected against
+	// voluntary disruptions (i.e. Node drains) and how they are drained before
+	// they are deleted by the controller.
+	// +kubebuilder:default={}
+	Disruption ModelDisruption `json:"disruption,omitempty"`
+
+	// Probes overrides the probes of the model server container.
+	// Unset fields fall back to the probes configured for the engine in the
+	// system config (modelServers.<engine>.probes) and then to the engine defaults.
+	// +kubebuilder:validation:Optional
+	Probes *ModelProbes `json:"probes,omitempty"`
+
+	// Workload configures which object manages the Pods of the Model.
+	// +kubebuilder:default={}
+	Workload ModelWorkload `json:"workload,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=TextGeneration;TextEmbedding;SpeechToText
@@ -148,7 +244,7 @@ type ModelFeature string
 const (
 	ModelFeatureTextGeneration = "TextGeneration"
 	ModelFeatureTextEmbedding  = "TextEmbedding"
-	// TODO (samos123): Add validation that Speech to Text only supports Faster Whisper.
+	// SpeechToText is only supported by the FasterWhisper engine (validated by the Model webhook).
 	ModelFeatureSpeechToText = "SpeechToText"
 )
 
@@ -157,15 +253,30 @@ const (
 	VLLMEngine          = "VLLM"
 	FasterWhisperEngine = "FasterWhisper"
 	InfinityEngine      = "Infinity"
+	SGLangEngine        = "SGLang"
+	TGIEngine           = "TGI"
+	LlamaCPPEngine      = "LlamaCPP"
 )
 
+type ModelStreaming struct {
+	// Concurrency is the number of concurrent reads from object storage.
+	// Defaults to the default of the engine.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+	// MemoryLimit limits the CPU memory that buffers the streamed weights.
+	// Defaults to the default of the engine.
+	// +kubebuilder:validation:Optional
+	MemoryLimit *resource.Quantity `json:"memoryLimit,omitempty"`
+}
+
 type Adapter struct {
 	// Name must be a lowercase string with no spaces.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Pattern=^[a-z0-9-]+$
 	// +kubebuilder:validation:MaxLength=63
 	Name string `json:"name"`
-	// +kubebuilder:validation:XValidation:rule="self.startsWith(\"hf://\") || self.startsWith(\"s3://\") || self.startsWith(\"gs://\") || self.startsWith(\"oss://\")", message="adapter url must start with \"hf://\", \"s3://\", \"gs://\", or \"oss://\"."
+	// +kubebuilder:validation:XValidation:rule="self.startsWith(\"hf://\") || self.startsWith(\"s3://\") || self.startsWith(\"gs://\") || self.startsWith(\"oss://\") || self.startsWith(\"az://\") || self.startsWith(\"https://\") || self.startsWith(\"oci://\")", message="adapter url must start with \"hf://\", \"s3://\", \"gs://\", \"oss://\", \"az://\", \"https://\", or \"oci://\"."
 	URL string `json:"url"`
 }
 
@@ -207,6 +318,287 @@ type PrefixHash struct {
 	PrefixCharLength int `json:"prefixCharLength,omitempty"`
 }
 
+// ModelCapacity configures how replicas are allocated to a Model when the
+// capacity of its ResourceProfile is limited.
+// Models with a higher priority (see PriorityClassName) are allocated replicas
+// before Models with a lower priority. Models with the same priority share
+// the remaining capacity according to their weights.
+type ModelCapacity struct {
+	// Weight is the relative share of capacity that the Model receives when
+	// competing with other Models of the same priority.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Weight int32 `json:"weight,omitempty"`
+	// GuaranteedReplicas is the number of replicas (when desired by the autoscaler)
+	// that are allocated to the Model before capacity is shared by priority and weight.
+	// MinReplicas are always allocated.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	GuaranteedReplicas int32 `json:"guaranteedReplicas,omitempty"`
+}
+
+// WarmPool configures parked Pods for a Model.
+// A parked Pod is scheduled with the same constraints and image as a server Pod
+// (and mounts the same cache volumes and runs the same model loaders), but it
+// does not request any resources (i.e. GPUs) and does not start the engine.
+// Parked Pods keep Nodes provisioned, images pulled and model files loaded so
+// that new server Pods only pay for engine start-up. New server Pods prefer the Nodes of parked Pods.
+type WarmPool struct {
+	// Replicas is the number of parked Pods to keep.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas"`
+}
+
+// ModelRollout configures the rollout strategy of a Model.
+type ModelRollout struct {
+	// Strategy is the strategy used to replace out-of-date Pods.
+	// RollingUpdate replaces Pods gradually while keeping the Model available.
+	// Recreate deletes all out-of-date Pods before creating up-to-date Pods,
+	// which avoids requesting additional resources (i.e. GPUs) at the cost of downtime.
+	// BlueGreen creates a full set of up-to-date Pods, switches all traffic to them
+	// once they are ready and deletes the out-of-date Pods after an analysis period.
+	// Canary creates a few up-to-date Pods that receive a fraction of the traffic
+	// during an analysis period before the remaining Pods are rolled out.
+	// BlueGreen and Canary rollouts are rolled back automatically when the
+	// up-to-date Pods fail to become ready or exceed the error rate threshold.
+	// +kubebuilder:default=RollingUpdate
+	// +kubebuilder:validation:Optional
+	Strategy ModelRolloutStrategy `json:"strategy,omitempty"`
+
+	// RollingUpdate configures the RollingUpdate strategy.
+	// +kubebuilder:validation:Optional
+	RollingUpdate ModelRolloutRollingUpdate `json:"rollingUpdate,omitempty"`
+
+	// Canary configures the Canary strategy.
+	// +kubebuilder:default={}
+	// +kubebuilder:validation:Optional
+	Canary ModelRolloutCanary `json:"canary,omitempty"`
+
+	// BlueGreen configures the BlueGreen strategy.
+	// +kubebuilder:default={}
+	// +kubebuilder:validation:Optional
+	BlueGreen ModelRolloutBlueGreen `json:"blueGreen,omitempty"`
+
+	// ProgressDeadlineSeconds is the time that up-to-date Pods have to become
+	// ready during a BlueGreen or Canary rollout before the rollout is rolled back.
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	ProgressDeadlineSeconds int64 `json:"progressDeadlineSeconds,omitempty"`
+
+	// MaxErrorRatePercent is the maximum percentage of failed requests
+	// (5xx responses and connection errors) that up-to-date Pods may serve during
+	// the analysis period of a BlueGreen or Canary rollout. The rollout is rolled back
+	// when the threshold is exceeded after at least 10 requests were served.
+	// Up-to-date Pods are only promoted once at least 10 of their responses were
+	// reported. Set to 100 to disable the error rate analysis.
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Optional
+	MaxErrorRatePercent *int32 `json:"maxErrorRatePercent,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=RollingUpdate;Recreate;BlueGreen;Canary
+type ModelRolloutStrategy string
+
+const (
+	RollingUpdateRolloutStrategy ModelRolloutStrategy = "RollingUpdate"
+	RecreateRolloutStrategy      ModelRolloutStrategy = "Recreate"
+	BlueGreenRolloutStrategy     ModelRolloutStrategy = "BlueGreen"
+	CanaryRolloutStrategy        ModelRolloutStrategy = "Canary"
+)
+
+type ModelRolloutRollingUpdate struct {
+	// MaxSurge is the number of Pods that can be created above the desired
+	// number of replicas during a rollout.
+	// Defaults to the surge of the system config (modelRollouts.surge).
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	MaxSurge *int32 `json:"maxSurge,omitempty"`
+	// MaxUnavailable is the number of ready Pods that can be deleted below the
+	// desired number of replicas during a rollout.
+	// If both MaxSurge and MaxUnavailable are 0, MaxUnavailable is treated as 1.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+type ModelRolloutCanary struct {
+	// Replicas is the number of canary Pods that are created before the
+	// remaining Pods are rolled out.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// TrafficPercent is the percentage of requests that is sent to the canary Pods.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Optional
+	TrafficPercent int32 `json:"trafficPercent,omitempty"`
+	// AnalysisSeconds is the time that the ready canary Pods receive traffic
+	// before the remaining Pods are rolled out.
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	AnalysisSeconds int64 `json:"analysisSeconds,omitempty"`
+}
+
+type ModelRolloutBlueGreen struct {
+	// AnalysisSeconds is the time that all traffic is sent to the up-to-date
+	// Pods while the out-of-date Pods are kept to allow for a rollback.
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	AnalysisSeconds int64 `json:"analysisSeconds,omitempty"`
+}
+
+// ModelPodTemplate customizes the Pods of a Model.
+type ModelPodTemplate struct {
+	// Metadata contains labels and annotations that are added to the Pods.
+	// +kubebuilder:validation:Optional
+	Metadata ModelPodTemplateMetadata `json:"metadata,omitempty"`
+
+	// Spec is a partial Pod spec that is merged onto the generated Pod spec
+	// using a strategic merge patch (i.e. containers are merged by name).
+	// Use it to add volumes, volume mounts, sidecars and init containers or to
+	// set probe timings, node selectors and tolerations.
+	// The model server container is named "server". It can be modified but
+	// not removed and its ports can not be changed.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:validation:Optional
+	Spec *runtime.RawExtension `json:"spec,omitempty"`
+}
+
+// ModelPodTemplateMetadata contains labels and annotations for the Pods of a Model.
+type ModelPodTemplateMetadata struct {
+	// Labels to add to the Pods.
+	// +kubebuilder:validation:XValidation:rule="!self.exists(k, k in ['model', 'parked-model', 'pod-hash'] || k.startsWith('adapter.kubeai.org/'))", message="labels used by KubeAI can not be overridden."
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations to add to the Pods.
+	// +kubebuilder:validation:XValidation:rule="!self.exists(k, k in ['model-pod-ip', 'model-pod-port', 'model-pod-traffic-percent', 'model-pod-draining'])", message="annotations used by KubeAI can not be overridden."
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ModelDisruption configures the PodDisruptionBudget and the graceful drain of
+// the Pods of a Model.
+type ModelDisruption struct {
+	// PodDisruptionBudgetDisabled stops the controller from managing a
+	// PodDisruptionBudget for the Pods of the Model.
+	// +kubebuilder:validation:Optional
+	PodDisruptionBudgetDisabled bool `json:"podDisruptionBudgetDisabled,omitempty"`
+
+	// MinAvailable is the number (or percentage) of Pods that must stay
+	// available when Pods are evicted.
+	// Defaults to allowing a single Pod to be unavailable at a time.
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:Optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// DrainTimeoutSeconds is the maximum time that a ready Pod is drained
+	// before it is deleted by the controller. A draining Pod does not receive
+	// new requests and is deleted as soon as its in-flight requests are finished.
+	// Set to 0 to delete Pods without draining them.
+	// +kubebuilder:default=120
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	DrainTimeoutSeconds *int32 `json:"drainTimeoutSeconds,omitempty"`
+}
+
+// ModelWorkload configures which object manages the Pods of a Model.
+type ModelWorkload struct {
+	// Kind of the object that manages the Pods of the Model.
+	// Pod: KubeAI creates and rolls out the Pods itself.
+	// Deployment: KubeAI manages a Deployment named "model-<name>" that creates the Pods.
+	// LeaderWorkerSet: KubeAI manages a LeaderWorkerSet named "model-<name>" that
+	// creates a group of Pods per replica (requires LeaderWorkerSet to be installed).
+	// Only the leader Pods of a group serve requests.
+	// The replicas of the Model are applied to the workload, Deployment and
+	// LeaderWorkerSet only support the RollingUpdate and Recreate rollout strategies.
+	// +kubebuilder:default=Pod
+	// +kubebuilder:validation:Optional
+	Kind ModelWorkloadKind `json:"kind,omitempty"`
+
+	// LeaderWorkerSet configures the LeaderWorkerSet workload.
+	// +kubebuilder:default={}
+	// +kubebuilder:validation:Optional
+	LeaderWorkerSet ModelWorkloadLeaderWorkerSet `json:"leaderWorkerSet,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Pod;Deployment;LeaderWorkerSet
+type ModelWorkloadKind string
+
+const (
+	PodWorkloadKind             ModelWorkloadKind = "Pod"
+	DeploymentWorkloadKind      ModelWorkloadKind = "Deployment"
+	LeaderWorkerSetWorkloadKind ModelWorkloadKind = "LeaderWorkerSet"
+)
+
+type ModelWorkloadLeaderWorkerSet struct {
+	// Size is the number of Pods in a group (the leader and its workers).
+	// Groups with more than one Pod run a multi-host Ray cluster and are only
+	// supported with the VLLM engine, set --tensor-parallel-size and
+	// --pipeline-parallel-size in the args of the Model to span the group.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Size int32 `json:"size,omitempty"`
+}
+
+// ModelProbes configures the probes of the model server container.
+type ModelProbes struct {
+	// Startup configures the startup probe. The maximum startup time of the
+	// model server is FailureThreshold * PeriodSeconds.
+	// +kubebuilder:validation:Optional
+	Startup *ProbeSettings `json:"startup,omitempty"`
+
+	// Readiness configures the readiness probe.
+	// +kubebuilder:validation:Optional
+	Readiness *ProbeSettings `json:"readiness,omitempty"`
+
+	// Liveness configures the liveness probe.
+	// +kubebuilder:validation:Optional
+	Liveness *ProbeSettings `json:"liveness,omitempty"`
+
+	// StartupStallTimeoutSeconds enables tracking the progress of loading the model
+	// while the model server starts: the server container is restarted when no
+	// progress (bytes downloaded or read by the server processes) is observed
+	// for this duration, instead of only after the maximum startup time.
+	// Only supported by engines with an HTTP health endpoint (not OLlama) that
+	// declare that their images contain bash (bashAvailable), the startup probe
+	// of other engines is kept. Set to 0 to disable.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	StartupStallTimeoutSeconds *int32 `json:"startupStallTimeoutSeconds,omitempty"`
+}
+
+// ProbeSettings overrides the timing of a probe.
+type ProbeSettings struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// SuccessThreshold must be 1 for startup and liveness probes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+}
+
 // File represents a file to be mounted in the model pod.
 type File struct {
 	// Path where the file should be mounted in the pod.
@@ -225,19 +617,188 @@ type File struct {
 
 // ModelStatus defines the observed state of Model.
 type ModelStatus struct {
+	// ObservedGeneration is the most recent generation of the Model
+	// that was reconciled by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is a summary of the conditions of the Model.
+	Phase ModelPhase `json:"phase,omitempty"`
+
 	Replicas ModelStatusReplicas `json:"replicas,omitempty"`
 	Cache    *ModelStatusCache   `json:"cache,omitempty"`
+
+	// Rollout is the state of the latest rollout of the Model.
+	Rollout *ModelStatusRollout `json:"rollout,omitempty"`
+
+	// Conditions describe the current state of the Model.
+	// Known condition types are "Ready", "Progressing", "Degraded",
+	// "Schedulable", "CacheLoaded" and "AdaptersLoaded".
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ModelPhase is a summary of the lifecycle of a Model.
+// +kubebuilder:validation:Enum=Pending;Loading;Progressing;Ready;ScaledToZero;Degraded;Failed
+type ModelPhase string
+
+const (
+	// ModelPhasePending means that the Model is waiting for replicas to be
+	// scheduled or started.
+	ModelPhasePending ModelPhase = "Pending"
+	// ModelPhaseLoading means that the Model is being loaded into the cache.
+	ModelPhaseLoading ModelPhase = "Loading"
+	// ModelPhaseProgressing means that the Model is being scaled or rolled out.
+	ModelPhaseProgressing ModelPhase = "Progressing"
+	// ModelPhaseReady means that all desired replicas are ready.
+	ModelPhaseReady ModelPhase = "Ready"
+	// ModelPhaseScaledToZero means that the Model has no replicas.
+	// It is scaled up when it receives requests.
+	ModelPhaseScaledToZero ModelPhase = "ScaledToZero"
+	// ModelPhaseDegraded means that replicas of the Model are failing.
+	ModelPhaseDegraded ModelPhase = "Degraded"
+	// ModelPhaseFailed means that the Model can not be served without
+	// changes, for example because of an invalid configuration.
+	ModelPhaseFailed ModelPhase = "Failed"
+)
+
+// Model condition types.
+const (
+	// ModelConditionReady is true when all desired replicas are ready.
+	ModelConditionReady = "Ready"
+	// ModelConditionProgressing is true while replicas are being created,
+	// deleted or replaced with an up-to-date version.
+	ModelConditionProgressing = "Progressing"
+	// ModelConditionDegraded is true when replicas are failing, for example
+	// because of image pull errors, crash loops or out-of-memory kills.
+	ModelConditionDegraded = "Degraded"
+	// ModelConditionSchedulable is false when replicas can not be scheduled,
+	// for example because of insufficient GPUs.
+	ModelConditionSchedulable = "Schedulable"
+	// ModelConditionCacheLoaded is true when the Model is loaded into the cache.
+	// Only set when a cache profile is configured.
+	ModelConditionCacheLoaded = "CacheLoaded"
+	// ModelConditionAdaptersLoaded is true when all adapters are loaded into
+	// the replicas. Only set when adapters are configured.
+	ModelConditionAdaptersLoaded = "AdaptersLoaded"
+)
+
+// Model condition reasons.
+const (
+	ModelReasonAllReplicasReady     = "AllReplicasReady"
+	ModelReasonReplicasNotReady     = "ReplicasNotReady"
+	ModelReasonScaledToZero         = "ScaledToZero"
+	ModelReasonRollingOut           = "RollingOut"
+	ModelReasonScalingUp            = "ScalingUp"
+	ModelReasonScalingDown          = "ScalingDown"
+	ModelReasonComplete             = "Complete"
+	ModelReasonAsExpected           = "AsExpected"
+	ModelReasonInvalidConfiguration = "InvalidConfiguration"
+	ModelReasonImagePullBackOff     = "ImagePullBackOff"
+	ModelReasonCrashLoopBackOff     = "CrashLoopBackOff"
+	ModelReasonOOMKilled            = "OOMKilled"
+	ModelReasonContainerConfigError = "CreateContainerConfigError"
+	ModelReasonScheduled            = "Scheduled"
+	ModelReasonUnschedulable        = "Unschedulable"
+	ModelReasonInsufficientGPU      = "InsufficientGPU"
+	ModelReasonLoading              = "Loading"
+	ModelReasonLoaded               = "Loaded"
+	ModelReasonLoadFailed           = "LoadFailed"
+	ModelReasonEvicting             = "Evicting"
+	ModelReasonEvicted              = "Evicted"
+	ModelReasonRolledBack           = "RolledBack"
+)
+
 type ModelStatusReplicas struct {
 	All   int32 `json:"all"`
 	Ready int32 `json:"ready"`
+	// Parked is the number of parked Pods in the warm pool.
+	Parked int32 `json:"parked,omitempty"`
 }
 
 type ModelStatusCache struct {
 	Loaded bool `json:"loaded"`
+	// ModelCache is the name of the ModelCache that the Model is served
+	// from, empty if the Model loaded its own copy of the model.
+	ModelCache string `json:"modelCache,omitempty"`
+	// Entry is the entry of a sharedFilesystem cache that the model is
+	// loaded into. Models with the same url share an entry.
+	Entry string `json:"entry,omitempty"`
+	// Revision is the resolved revision of the cached model, i.e. the commit
+	// of a Huggingface repo or the pinned revision of the url.
+	Revision string `json:"revision,omitempty"`
+	// ManifestSHA256 is the SHA256 digest of the manifest of the cached files.
+	// The files are verified against the manifest when the model is loaded again.
+	ManifestSHA256 string `json:"manifestSHA256,omitempty"`
+	// Files is the manifest of the cached files. Omitted for models with too
+	// many files to be reported by the load Job.
+	Files []ModelCachedFile `json:"files,omitempty"`
+	// Progress of the load Job while the model is loaded into the cache.
+	Progress *ModelCacheLoadProgress `json:"progress,omitempty"`
+}
+
+type ModelCacheLoadProgress struct {
+	// DownloadedBytes is the number of bytes that were downloaded so far,
+	// including the bytes of an interrupted download that is resumed.
+	DownloadedBytes int64 `json:"downloadedBytes"`
+	// TotalBytes is the download size of the model, 0 if it is unknown.
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+	// EstimatedCompletionTime is estimated from the current download rate.
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+	// Restarts is the number of times the loader was restarted after a failure.
+	Restarts int32 `json:"restarts,omitempty"`
 }
 
+type ModelCachedFile struct {
+	// Path of the file relative to the model directory.
+	Path string `json:"path"`
+	// SHA256 digest of the file.
+	SHA256 string `json:"sha256"`
+}
+
+type ModelStatusRollout struct {
+	// Strategy is the strategy used for the rollout.
+	Strategy ModelRolloutStrategy `json:"strategy"`
+	// TargetHash is the hash of the Pod spec that is being rolled out.
+	TargetHash string `json:"targetHash"`
+	// Phase is the current phase of the rollout.
+	Phase ModelRolloutPhase `json:"phase"`
+	// UpdatedReplicas is the number of Pods with the target Pod spec.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// ReadyUpdatedReplicas is the number of ready Pods with the target Pod spec.
+	ReadyUpdatedReplicas int32 `json:"readyUpdatedReplicas"`
+	// TrafficPercent is the percentage of requests that is sent to the Pods
+	// with the target Pod spec. Only set for BlueGreen and Canary rollouts.
+	TrafficPercent int32 `json:"trafficPercent,omitempty"`
+	// StartTime is the time the rollout started.
+	StartTime metav1.Time `json:"startTime"`
+	// AnalysisStartTime is the time the analysis period of a BlueGreen or
+	// Canary rollout started.
+	AnalysisStartTime *metav1.Time `json:"analysisStartTime,omitempty"`
+	// Message describes the state of the rollout, for example the reason of a rollback.
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Progressing;Analyzing;Promoting;Succeeded;RolledBack
+type ModelRolloutPhase string
+
+const (
+	// ModelRolloutPhaseProgressing means that up-to-date Pods are being created.
+	ModelRolloutPhaseProgressing ModelRolloutPhase = "Progressing"
+	// ModelRolloutPhaseAnalyzing means that the ready up-to-date Pods receive
+	// traffic and are being analyzed before the rollout continues.
+	ModelRolloutPhaseAnalyzing ModelRolloutPhase = "Analyzing"
+	// ModelRolloutPhasePromoting means that the canary Pods passed the analysis
+	// and the remaining Pods are being rolled out.
+	ModelRolloutPhasePromoting ModelRolloutPhase = "Promoting"
+	// ModelRolloutPhaseSucceeded means that all Pods are up to date.
+	ModelRolloutPhaseSucceeded ModelRolloutPhase = "Succeeded"
+	// ModelRolloutPhaseRolledBack means that the up-to-date Pods were deleted
+	// because they failed. The rollout is retried when the Model is updated.
+	ModelRolloutPhaseRolledBack ModelRolloutPhase = "RolledBack"
+)
+
 // NOTE: Model name length should be limited to allow for the model name to be used in
 // the names of the resources created by the controller.
 
@@ -245,6 +806,11 @@ type ModelStatusCache struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas.all
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.spec.replicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.replicas.ready`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 40", message="name must not exceed 40 characters."
 type Model struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NodeModelCacheSpec identifies the Node and the nodeLocal cache profile
+// of a NodeModelCache.
+type NodeModelCacheSpec struct {
+	// NodeName is the name of the Node that the models are cached on.
+	NodeName string `json:"nodeName"`
+	// CacheProfile is the name of the nodeLocal cache profile.
+	CacheProfile string `json:"cacheProfile"`
+}
+
+// NodeModelCacheStatus is the state of the models cached on a Node.
+type NodeModelCacheStatus struct {
+	// DiskCapacityBytes is the capacity of the filesystem of the cache
+	// as last reported by a load Job.
+	DiskCapacityBytes int64 `json:"diskCapacityBytes,omitempty"`
+	// DiskUsedBytes is the used space of the filesystem of the cache
+	// as last reported by a load Job, minus the models evicted since.
+	DiskUsedBytes int64 `json:"diskUsedBytes,omitempty"`
+	// Models are the models that are cached on the Node.
+	Models []NodeCachedModel `json:"models,omitempty"`
+}
+
+type NodeCachedModel struct {
+	// Name of the Model.
+	Name string `json:"name"`
+	// UID of the Model.
+	UID types.UID `json:"uid"`
+	// Phase of the model on the Node.
+	Phase NodeCachedModelPhase `json:"phase"`
+	// SizeBytes is the size of the model on disk.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// LastUsedTime is the last time that a model server Pod of the Model
+	// was observed on the Node. Used to evict the least recently used models.
+	LastUsedTime *metav1.Time `json:"lastUsedTime,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Loading;Loaded;Failed;Evicting;Evicted
+type NodeCachedModelPhase string
+
+const (
+	NodeCachedModelLoading  NodeCachedModelPhase = "Loading"
+	NodeCachedModelLoaded   NodeCachedModelPhase = "Loaded"
+	NodeCachedModelFailed   NodeCachedModelPhase = "Failed"
+	NodeCachedModelEvicting NodeCachedModelPhase = "Evicting"
+	// NodeCachedModelEvicted models were evicted to free disk space. They
+	// are not prefetched again but loaded when a model server Pod of the
+	// Model is scheduled onto the Node.
+	NodeCachedModelEvicted NodeCachedModelPhase = "Evicted"
+)
+
+// NodeModelCache resources track the models that are cached on a Node by a
+// nodeLocal cache profile. They are managed by KubeAI.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+// +kubebuilder:printcolumn:name="Profile",type=string,JSONPath=`.spec.cacheProfile`
+// +kubebuilder:printcolumn:name="Used",type=integer,JSONPath=`.status.diskUsedBytes`
+// +kubebuilder:printcolumn:name="Capacity",type=integer,JSONPath=`.status.diskCapacityBytes`
+type NodeModelCache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeModelCacheSpec   `json:"spec,omitempty"`
+	Status NodeModelCacheStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeModelCacheList contains a list of NodeModelCaches.
+type NodeModelCacheList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeModelCache `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeModelCache{}, &NodeModelCacheList{})
+}
+
+// CachedModel returns the cache entry of the given Model, nil if the Model
+// is not cached on the Node.
+func (c *NodeModelCache) CachedModel(name string, uid types.UID) *NodeCachedModel {
+	for i := range c.Status.Models {
+		if c.Status.Models[i].Name == name && c.Status.Models[i].UID == uid {
+			return &c.Status.Models[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SharedModelCacheStatus is the usage of a sharedFilesystem cache profile.
+type SharedModelCacheStatus struct {
+	// CapacityBytes is the configured capacity of the cache profile,
+	// zero if no capacity is configured.
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// UsedBytes is the total size of the models in the cache, including
+	// the models of ModelCaches.
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+	// Models are the models that are loaded into the cache or were evicted from it.
+	Models []SharedCachedModel `json:"models,omitempty"`
+}
+
+type SharedCachedModel struct {
+	// Name of the Model.
+	Name string `json:"name"`
+	// UID of the Model.
+	UID types.UID `json:"uid"`
+	// Phase of the model in the cache.
+	Phase SharedCachedModelPhase `json:"phase"`
+	// SizeBytes is the size of the model on disk as reported by the load Job.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// LoadedTime is the time that the model was loaded into the cache.
+	LoadedTime *metav1.Time `json:"loadedTime,omitempty"`
+	// LastRequestTime is the last time that the proxy observed active
+	// requests for the Model. Used to evict the least recently served models.
+	LastRequestTime *metav1.Time `json:"lastRequestTime,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Loaded;Evicting;Evicted
+type SharedCachedModelPhase string
+
+const (
+	SharedCachedModelLoaded   SharedCachedModelPhase = "Loaded"
+	SharedCachedModelEvicting SharedCachedModelPhase = "Evicting"
+	// SharedCachedModelEvicted models were evicted to free space. They are
+	// loaded again when the Model is scaled up.
+	SharedCachedModelEvicted SharedCachedModelPhase = "Evicted"
+)
+
+// SharedModelCache resources track the usage of a sharedFilesystem cache
+// profile. They have the name of the cache profile and are managed by KubeAI.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Used",type=integer,JSONPath=`.status.usedBytes`
+// +kubebuilder:printcolumn:name="Capacity",type=integer,JSONPath=`.status.capacityBytes`
+type SharedModelCache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status SharedModelCacheStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SharedModelCacheList contains a list of SharedModelCaches.
+type SharedModelCacheList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedModelCache `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedModelCache{}, &SharedModelCacheList{})
+}
@@ -72,6 +72,17 @@ Create the name of the service account to use for model pods
 {{- end }}
 {{- end }}
 
+{{/*
+Create the name of the service account to use for model load Jobs
+*/}}
+{{- define "modelLoader.serviceAccountName" -}}
+{{- if .Values.modelLoaderServiceAccount.create }}
+{{- default (printf "%s-model-loader" (include "kubeai.fullname" .)) .Values.modelLoaderServiceAccount.name }}
+{{- else }}
+{{- default "default" .Values.modelLoaderServiceAccount.name }}
+{{- end }}
+{{- end }}
+
 {{/*
 Create the name of the alibaba secret to use
 */}}
@@ -108,6 +119,24 @@ Create the name of the aws secret to use
 {{- end }}
 {{- end }}
 
+{{/*
+Create the name of the azure secret to use
+*/}}
+{{- define "kubeai.azureSecretName" -}}
+{{- if .Values.secrets.azure.create -}}
+{{- if .Values.secrets.azure.name -}}
+{{- .Values.secrets.azure.name -}}
+{{- else }}
+{{- (include "kubeai.fullname" .)}}-azure
+{{- end}}
+{{- else }}
+{{- if not .Values.secrets.azure.name -}}
+{{ fail "if secrets.azure.create is false, secrets.azure.name is required" }}
+{{- end }}
+{{- .Values.secrets.azure.name }}
+{{- end }}
+{{- end }}
+
 {{/*
 Create the name of the gcp secret to use
 */}}
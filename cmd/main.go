@@ -18,6 +18,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/substratusai/kubeai/internal/manager"
@@ -26,6 +27,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == simulateAutoscalingCommand {
+		if err := runSimulateAutoscaling(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Flag parsing can cause a panic if done inside of command.Run() and called in a goroutine (as in tests).
 	// So we parse flags here.
 	opts := zap.Options{
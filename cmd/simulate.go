@@ -0,0 +1,231 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/manager"
+	"github.com/substratusai/kubeai/internal/modelautoscaler"
+	"sigs.k8s.io/yaml"
+)
+
+const simulateAutoscalingCommand = "simulate-autoscaling"
+
+// runSimulateAutoscaling replays a recorded time series of active requests
+// through the autoscaling algorithm and writes the outcome to out.
+func runSimulateAutoscaling(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet(simulateAutoscalingCommand, flag.ContinueOnError)
+	var (
+		modelPath      = fs.String("model", "", "Path to a Model manifest (YAML). Required.")
+		inputPath      = fs.String("input", "", "Path to the recorded active requests. Required.")
+		inputFormat    = fs.String("input-format", "", `Format of the input: "csv" or "prometheus" (range query JSON response). Defaults to "prometheus" for .json files and "csv" otherwise.`)
+		modelLabel     = fs.String("prometheus-model-label", "request_model", "Label of the Prometheus series that contains the model name.")
+		seriesModel    = fs.String("series-model", "", "Name of the model in the input. Defaults to the name of the Model.")
+		configPath     = fs.String("config", "", "Path to a KubeAI system config file, used for the autoscaling settings and resource profiles.")
+		interval       = fs.Duration("interval", 0, "Autoscaling interval. Overrides the system config (default 10s).")
+		timeWindow     = fs.Duration("time-window", 0, "Autoscaling time window. Overrides the system config (default 10m).")
+		startupDelay   = fs.Duration("startup-delay", 0, "Time it takes for a new replica to become ready.")
+		gpusPerReplica = fs.Float64("gpus-per-replica", -1, "GPUs used by each replica. Derived from the resource profile in the system config if not set.")
+		concurrency    = fs.Int64("concurrency-per-replica", 0, "Requests that a ready replica processes at once, used to estimate queueing. Defaults to the targetRequests of the Model.")
+		output         = fs.String("output", "text", `Output format: "text", "csv" or "json".`)
+		verbose        = fs.Bool("v", false, "Log every autoscaling decision.")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *modelPath == "" || *inputPath == "" {
+		fs.Usage()
+		return errors.New("-model and -input are required")
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	model, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.ModelAutoscaling{
+		Interval:   config.Duration{Duration: 10 * time.Second},
+		TimeWindow: config.Duration{Duration: 10 * time.Minute},
+	}
+	var profiles map[string]config.ResourceProfile
+	if *configPath != "" {
+		sysCfg, err := manager.LoadConfigFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("loading config file: %w", err)
+		}
+		if sysCfg.ModelAutoscaling.Interval.Duration != 0 {
+			cfg.Interval = sysCfg.ModelAutoscaling.Interval
+		}
+		if sysCfg.ModelAutoscaling.TimeWindow.Duration != 0 {
+			cfg.TimeWindow = sysCfg.ModelAutoscaling.TimeWindow
+		}
+		profiles = sysCfg.ResourceProfiles
+	}
+	if *interval != 0 {
+		cfg.Interval.Duration = *interval
+	}
+	if *timeWindow != 0 {
+		cfg.TimeWindow.Duration = *timeWindow
+	}
+
+	opts := modelautoscaler.SimulationOptions{
+		StartupDelay:          *startupDelay,
+		GPUsPerReplica:        *gpusPerReplica,
+		ConcurrencyPerReplica: *concurrency,
+	}
+	if opts.GPUsPerReplica < 0 {
+		opts.GPUsPerReplica = 0
+		if profiles != nil {
+			gpus, err := modelautoscaler.GPUsPerReplica(model, profiles)
+			if err != nil {
+				return fmt.Errorf("determining GPUs per replica: %w", err)
+			}
+			opts.GPUsPerReplica = gpus
+		}
+	}
+
+	samplesByModel, err := readSamples(*inputPath, *inputFormat, *modelLabel)
+	if err != nil {
+		return err
+	}
+	name := *seriesModel
+	if name == "" {
+		name = model.Name
+	}
+	samples, ok := samplesByModel[name]
+	if !ok {
+		return fmt.Errorf("no samples found for model %q", name)
+	}
+
+	result, err := modelautoscaler.Simulate(cfg, model, samples, opts)
+	if err != nil {
+		return fmt.Errorf("simulating: %w", err)
+	}
+
+	switch *output {
+	case "text":
+		return writeSimulationText(out, result)
+	case "csv":
+		return writeSimulationCSV(out, result)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	default:
+		return fmt.Errorf("unsupported output format: %q", *output)
+	}
+}
+
+func readModel(path string) (*kubeaiv1.Model, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading model: %w", err)
+	}
+	model := &kubeaiv1.Model{}
+	if err := yaml.UnmarshalStrict(contents, model); err != nil {
+		return nil, fmt.Errorf("parsing model: %w", err)
+	}
+	return model, nil
+}
+
+func readSamples(path, format, modelLabel string) (map[string][]modelautoscaler.Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening input: %w", err)
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = "csv"
+		if filepath.Ext(path) == ".json" {
+			format = "prometheus"
+		}
+	}
+	switch format {
+	case "csv":
+		return modelautoscaler.ParseSamplesCSV(f)
+	case "prometheus":
+		return modelautoscaler.ParsePrometheusMatrix(f, modelLabel)
+	default:
+		return nil, fmt.Errorf("unsupported input format: %q", format)
+	}
+}
+
+// writeSimulationText writes a summary and the steps in which the
+// number of replicas changed.
+func writeSimulationText(out io.Writer, result *modelautoscaler.SimulationResult) error {
+	fmt.Fprintf(out, "Scale ups:              %d\n", result.ScaleUps)
+	fmt.Fprintf(out, "Scale downs:            %d\n", result.ScaleDowns)
+	fmt.Fprintf(out, "Replica-hours:          %.2f\n", result.ReplicaHours)
+	fmt.Fprintf(out, "GPU-hours:              %.2f\n", result.GPUHours)
+	fmt.Fprintf(out, "Queued request-seconds: %.0f\n", result.QueuedRequestSeconds)
+	fmt.Fprintf(out, "Max queued requests:    %d\n", result.MaxQueuedRequests)
+	fmt.Fprintf(out, "Time with queueing:     %s\n\n", result.QueueingDuration)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTIVE\tAVERAGE\tDESIRED\tREPLICAS\tREADY\tQUEUED")
+	for i, s := range result.Steps {
+		if i > 0 {
+			prev := result.Steps[i-1]
+			if s.Replicas == prev.Replicas && s.ReadyReplicas == prev.ReadyReplicas {
+				continue
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%d\t%d\t%d\t%d\n", s.Time.Format(time.RFC3339),
+			s.ActiveRequests, s.AverageActiveRequests, s.DesiredReplicas, s.Replicas, s.ReadyReplicas, s.QueuedRequests)
+	}
+	return w.Flush()
+}
+
+func writeSimulationCSV(out io.Writer, result *modelautoscaler.SimulationResult) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"timestamp", "active_requests", "average_active_requests", "desired_replicas", "replicas", "ready_replicas", "queued_requests"}); err != nil {
+		return err
+	}
+	for _, s := range result.Steps {
+		if err := w.Write([]string{
+			s.Time.Format(time.RFC3339),
+			strconv.FormatInt(s.ActiveRequests, 10),
+			strconv.FormatFloat(s.AverageActiveRequests, 'f', -1, 64),
+			strconv.Itoa(int(s.DesiredReplicas)),
+			strconv.Itoa(int(s.Replicas)),
+			strconv.Itoa(int(s.ReadyReplicas)),
+			strconv.FormatInt(s.QueuedRequests, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -5,34 +5,222 @@ set -euxo pipefail
 src=$1
 dest=$2
 
+# The "model" query parameter (e.g. "hf://org/repo?model=model-q4_k_m.gguf")
+# selects a single file to download from the source.
+file=""
+# "https://" urls are downloaded as-is (including their query) and can be
+# pinned with a checksum: "https://host/path/model.gguf#sha256=<checksum>".
+checksum=""
+if [[ $src == "https://"* ]]; then
+    if [[ $src == *"#sha256="* ]]; then
+        checksum=${src##*#sha256=}
+    fi
+    src=${src%%#*}
+fi
+# An immutable revision can be pinned: "hf://org/repo@<revision>",
+# "s3://bucket/path/file?versionId=<id>" or "gs://bucket/path/file#<generation>".
+# OCI artifacts are pinned by digest as part of the reference
+# ("oci://registry/repo@sha256:<digest>").
+revision=""
+if [[ $src != "https://"* && $src == *"?"* ]]; then
+    query=${src#*\?}
+    src=${src%%\?*}
+    for param in ${query//&/ }; do
+        if [[ $param == model=* ]]; then
+            file=${param#model=}
+        elif [[ $param == versionId=* ]]; then
+            revision=${param#versionId=}
+        fi
+    done
+fi
+case $src in
+    "hf://"*"@"*)
+        revision=${src##*@}
+        src=${src%@*}
+        ;;
+    "gs://"*"#"*)
+        revision=${src##*#}
+        src=${src%%#*}
+        ;;
+esac
+
 # If dest is a local directory, download the model to that directory.
 # Otherwise, download to a temporary directory and upload from there.
 dest_type=""
 if [[ $dest == *"://"* ]]; then
     dir=$(mktemp -d)
     dest_type="url"
+elif [[ -n "${KUBEAI_REFRESH:-}" ]]; then
+    # Refreshes load the model again next to dest (KUBEAI_REFRESH is a unique
+    # id of the refresh) and swap it in once it is loaded (see swap_dir).
+    # Interrupted refreshes are resumed.
+    dir=${dest}_${KUBEAI_REFRESH}
+    dest_type="dir"
+    mkdir -p $dir
 else
     dir=$dest
     dest_type="dir"
     mkdir -p $dir
+    # Verify a previously loaded copy, loading the model again if the files
+    # were corrupted or changed.
+    if [[ -f $dir/.kubeai-manifest.json ]]; then
+        if manifest verify $dir; then
+            manifest report $dir
+            exit 0
+        fi
+        echo "Cached files do not match the manifest, loading the model again"
+        rm -rf $dir
+        mkdir -p $dir
+    fi
+fi
+
+# Report the progress of the download in the logs (see progress.py).
+# Files that were downloaded before the loader was interrupted are not
+# downloaded again.
+if [[ $dest_type == "dir" && -n "${KUBEAI_JOB_NAME:-}" ]]; then
+    total=$(TERMINATION_LOG=/dev/null size "$1" 2>/dev/null | tail -n1 || true)
+    if [[ ! $total =~ ^[0-9]+$ ]]; then
+        total=0
+    fi
+    progress $dir $total &
+    progress_pid=$!
+    trap '{ set +x; } 2>/dev/null; kill $progress_pid 2>/dev/null || true' EXIT
 fi
 
+# The number of files (or parts of files) that are downloaded in parallel.
+parallelism=${LOAD_PARALLELISM:-}
+
+# Merges the image pull secrets (dockerconfigjson) mounted by KubeAI into a
+# single registry config for oras.
+oci_registry_config() {
+    local config
+    config=$(mktemp)
+    python3 -c 'import glob, json, sys; auths = {}; [auths.update(json.load(open(f)).get("auths", {})) for f in sorted(glob.glob(sys.argv[1] + "/*.json"))]; print(json.dumps({"auths": auths}))' \
+        "${OCI_CREDENTIALS_DIR:-/nonexistent}" > $config
+    echo $config
+}
+
+# Atomically points dest (a symlink) to the directory of a refresh. Servers
+# that were started before keep the files of the previous load, which are
+# kept until the next refresh. Loads of older versions are removed.
+swap_dir() {
+    local name prev
+    name=$(basename $dir)
+    prev=$(readlink $dest || true)
+    if [[ -d $dest && ! -L $dest ]]; then
+        # The directory of the first load is moved aside once (not atomic).
+        prev=$(basename $dest)_0
+        mv $dest $(dirname $dest)/$prev
+    fi
+    python3 -c 'import os, sys; os.symlink(sys.argv[1], sys.argv[2] + ".swap"); os.replace(sys.argv[2] + ".swap", sys.argv[2])' \
+        $name $dest
+    for old in ${dest}_*; do
+        if [[ $(basename $old) != $name && $(basename $old) != $prev ]]; then
+            rm -rf $old
+        fi
+    done
+}
+
+# Converts "az://container/path" to the blob url of the storage account,
+# authorized with the SAS token if set.
+azure_blob_url() {
+    if [[ -z "${AZURE_STORAGE_ACCOUNT:-}" ]]; then
+        echo "AZURE_STORAGE_ACCOUNT is required for az:// urls" >&2
+        exit 1
+    fi
+    local url="https://$AZURE_STORAGE_ACCOUNT.blob.core.windows.net/${1#az://}"
+    if [[ -n "${AZURE_STORAGE_SAS_TOKEN:-}" ]]; then
+        url="$url?${AZURE_STORAGE_SAS_TOKEN#\?}"
+    fi
+    echo "$url"
+}
+
 # Download
 case $src in
     "hf://"*)
         repo=${src#hf://}
-        huggingface-cli download --local-dir $dir $repo
+        huggingface-cli download --local-dir $dir ${revision:+--revision $revision} ${parallelism:+--max-workers $parallelism} $repo $file
+        # Resolve branches and tags to the commit that was downloaded. The
+        # commit is the first line of the metadata that huggingface-cli
+        # records for every downloaded file (no request to the Hub needed).
+        commit=$(find $dir/.cache/huggingface/download -name '*.metadata' -exec head -n1 {} \; -quit 2>/dev/null || true)
+        if [[ $commit =~ ^[0-9a-f]{40}$ ]]; then
+            revision=$commit
+        fi
         rm -rf $dir/.cache
         ;;
     "s3://"*)
-        aws s3 sync $src $dir
+        if [[ -n $parallelism ]]; then
+            aws configure set default.s3.max_concurrent_requests $parallelism
+        fi
+        if [[ -n $revision ]]; then
+            path=${src#s3://}
+            aws s3api get-object --bucket ${path%%/*} --key ${path#*/} --version-id $revision $dir/$(basename $path) > /dev/null
+        else
+            aws s3 sync $src $dir
+        fi
         ;;
     "gs://"*)
         gcloud auth activate-service-account --key-file $GOOGLE_APPLICATION_CREDENTIALS
-        gcloud storage rsync $src $dir
+        if [[ -n $parallelism ]]; then
+            export CLOUDSDK_STORAGE_THREAD_COUNT=$parallelism
+        fi
+        if [[ -n $revision ]]; then
+            gcloud storage cp "$src#$revision" $dir/
+        else
+            gcloud storage rsync $src $dir
+        fi
         ;;
     "oss://"*)
-        ossutil sync $src $dir
+        if [[ -n $revision ]]; then
+            echo "Revisions are not supported for oss:// urls"
+            exit 1
+        fi
+        ossutil sync ${parallelism:+--jobs $parallelism} $src $dir
+        ;;
+    "az://"*)
+        if [[ -n $revision ]]; then
+            echo "Revisions are not supported for az:// urls"
+            exit 1
+        fi
+        if [[ -n $parallelism ]]; then
+            export AZCOPY_CONCURRENCY_VALUE=$parallelism
+        fi
+        # Do not trace the SAS token.
+        set +x
+        url=$(azure_blob_url "${src%/}/*")
+        azcopy copy "$url" $dir --recursive --overwrite=ifSourceNewer
+        set -x
+        ;;
+    "https://"*)
+        name=$(basename ${src%%\?*})
+        # Download next to the model to resume interrupted downloads.
+        download=$dir/.kubeai-download
+        mkdir -p $download
+        curl -fL --retry 3 -C - -o $download/$name "$src"
+        sum=$(sha256sum $download/$name | cut -d' ' -f1)
+        if [[ -n $checksum && $sum != $checksum ]]; then
+            rm -rf $download
+            echo "Checksum of $name ($sum) does not match $checksum"
+            exit 1
+        fi
+        revision="sha256:$sum"
+        case $name in
+            *.tar|*.tar.gz|*.tgz)
+                tar -xf $download/$name -C $dir
+                ;;
+            *)
+                mv $download/$name $dir/
+                ;;
+        esac
+        rm -rf $download
+        ;;
+    "oci://"*)
+        ref=${src#oci://}
+        registry_config=$(oci_registry_config)
+        oras pull --registry-config $registry_config ${parallelism:+--concurrency $parallelism} -o $dir $ref
+        # Record the digest of the artifact that was pulled.
+        revision=$(oras resolve --registry-config $registry_config $ref)
         ;;
     *)
         echo "Unsupported source url: $src"
@@ -40,6 +228,14 @@ case $src in
         ;;
 esac
 
+# Record the manifest of the loaded files.
+if [[ $dest_type == "dir" ]]; then
+    manifest write $dir "$revision"
+    if [[ $dir != $dest ]]; then
+        swap_dir
+    fi
+fi
+
 # Upload
 if [[ $dest_type == "url" ]]; then
     case $dest in
@@ -57,6 +253,12 @@ if [[ $dest_type == "url" ]]; then
         "oss://"*)
             ossutil sync $dir $dest
             ;;
+        "az://"*)
+            set +x
+            url=$(azure_blob_url $dest)
+            azcopy copy "$dir/*" "$url" --recursive
+            set -x
+            ;;
         *)
             echo "Unsupported destination url: $dest"
             exit 1
@@ -0,0 +1,114 @@
+#!/usr/bin/env python3
+
+# Records and verifies the content manifest of a loaded model.
+#
+#   manifest write <dir> <revision>  Writes the manifest (file list + SHA256)
+#                                    and reports it.
+#   manifest report <dir>            Reports an existing manifest.
+#   manifest verify <dir> [sha256]   Exits with 3 if the files do not match
+#                                    the manifest or the manifest does not
+#                                    match the expected digest, 0 if there is
+#                                    no manifest.
+#
+# Reports are written to the termination log to be read by KubeAI.
+
+import hashlib
+import json
+import os
+import sys
+
+MANIFEST = ".kubeai-manifest.json"
+TERMINATION_LOG = "/dev/termination-log"
+# Kubernetes truncates termination messages to 4096 bytes.
+MAX_REPORT_BYTES = 4000
+EXIT_MISMATCH = 3
+
+
+def sha256(path):
+    h = hashlib.sha256()
+    with open(path, "rb") as f:
+        for chunk in iter(lambda: f.read(1 << 20), b""):
+            h.update(chunk)
+    return h.hexdigest()
+
+
+def list_files(d):
+    files = []
+    for root, dirs, names in os.walk(d):
+        dirs.sort()
+        for name in sorted(names):
+            path = os.path.join(root, name)
+            rel = os.path.relpath(path, d)
+            if rel != MANIFEST:
+                files.append(rel)
+    return files
+
+
+def write(d, revision):
+    files = [{"path": rel, "sha256": sha256(os.path.join(d, rel))} for rel in list_files(d)]
+    with open(os.path.join(d, MANIFEST), "w") as f:
+        f.write(json.dumps({"revision": revision, "files": files}, sort_keys=True))
+    report(d)
+
+
+def report(d):
+    with open(os.path.join(d, MANIFEST)) as f:
+        manifest = f.read()
+    parsed = json.loads(manifest)
+    files = parsed["files"]
+    result = {
+        "sizeBytes": sum(os.path.getsize(os.path.join(d, f["path"])) for f in files),
+        "revision": parsed["revision"],
+        "manifestSHA256": hashlib.sha256(manifest.encode()).hexdigest(),
+        "files": files,
+    }
+    msg = json.dumps(result)
+    if len(msg) > MAX_REPORT_BYTES:
+        del result["files"]
+        msg = json.dumps(result)
+    print(msg)
+    if os.access(TERMINATION_LOG, os.W_OK):
+        with open(TERMINATION_LOG, "w") as f:
+            f.write(msg)
+
+
+def verify(d, expected_sha256=""):
+    path = os.path.join(d, MANIFEST)
+    if not os.path.exists(path):
+        return 0
+    with open(path) as f:
+        raw = f.read()
+    # The manifest itself is checked against the digest that was reported
+    # when the model was loaded.
+    if expected_sha256 and hashlib.sha256(raw.encode()).hexdigest() != expected_sha256:
+        print(f"Digest of the manifest in {d} does not match {expected_sha256}")
+        return EXIT_MISMATCH
+    manifest = json.loads(raw)
+    expected = {f["path"]: f["sha256"] for f in manifest["files"]}
+    actual = list_files(d)
+    if sorted(expected) != sorted(actual):
+        print(f"Files in {d} do not match the manifest")
+        return EXIT_MISMATCH
+    for rel, digest in expected.items():
+        if sha256(os.path.join(d, rel)) != digest:
+            print(f"Digest of {rel} does not match the manifest")
+            return EXIT_MISMATCH
+    print(f"Verified {len(expected)} files in {d}")
+    return 0
+
+
+def main():
+    if len(sys.argv) >= 3 and sys.argv[1] == "write":
+        write(sys.argv[2], sys.argv[3] if len(sys.argv) > 3 else "")
+        return 0
+    if len(sys.argv) == 3 and sys.argv[1] == "report":
+        report(sys.argv[2])
+        return 0
+    if len(sys.argv) in (3, 4) and sys.argv[1] == "verify":
+        return verify(sys.argv[2], sys.argv[3] if len(sys.argv) > 3 else "")
+    print(f"Usage: {sys.argv[0]} write <dir> [revision] | report <dir> | verify <dir> [sha256]")
+    return 1
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,54 @@
+#!/usr/bin/env python3
+
+# Reports the progress of a download of the model loader.
+#
+#   progress <dir> <totalBytes>
+#
+# Runs until it is killed. The progress is printed to the logs of the loader
+# as "kubeai-load-progress <json>" lines that are read by KubeAI, the loader
+# does not need access to the Kubernetes API.
+
+import datetime
+import json
+import os
+import sys
+import time
+
+PREFIX = "kubeai-load-progress"
+INTERVAL_SECONDS = int(os.environ.get("PROGRESS_INTERVAL_SECONDS", "15"))
+
+
+def dir_size(d):
+    size = 0
+    for root, _, names in os.walk(d):
+        for name in names:
+            try:
+                size += os.path.getsize(os.path.join(root, name))
+            except OSError:
+                # Files are renamed when their download completes.
+                pass
+    return size
+
+
+def main():
+    if len(sys.argv) != 3:
+        print(f"Usage: {sys.argv[0]} <dir> <totalBytes>")
+        return 1
+    d, total = sys.argv[1], int(sys.argv[2] or 0)
+
+    prev_size, prev_time = dir_size(d), time.time()
+    while True:
+        time.sleep(INTERVAL_SECONDS)
+        size, now = dir_size(d), time.time()
+        progress = {
+            "downloadedBytes": size,
+            "totalBytes": total,
+            "bytesPerSecond": max(0, int((size - prev_size) / (now - prev_time))),
+            "updateTime": datetime.datetime.now(datetime.timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ"),
+        }
+        prev_size, prev_time = size, now
+        print(f"{PREFIX} {json.dumps(progress)}", flush=True)
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,99 @@
+#!/bin/bash
+
+# Measures the download size of a model in bytes. The size is printed and
+# written to the termination log of the container to be read by KubeAI.
+
+set -euxo pipefail
+
+src=$1
+
+# The "model" query parameter selects a single file (see load.sh).
+file=""
+if [[ $src == "https://"* ]]; then
+    src=${src%%#*}
+elif [[ $src == *"?"* ]]; then
+    query=${src#*\?}
+    src=${src%%\?*}
+    for param in ${query//&/ }; do
+        if [[ $param == model=* ]]; then
+            file=${param#model=}
+        fi
+    done
+fi
+
+# Pinned revisions (see load.sh). The size of S3 objects is measured for
+# the latest version.
+revision=""
+case $src in
+    "hf://"*"@"*)
+        revision=${src##*@}
+        src=${src%@*}
+        ;;
+    "gs://"*"#"*)
+        src=${src%%#*}
+        ;;
+esac
+
+# Merges the image pull secrets (dockerconfigjson) mounted by KubeAI into a
+# single registry config for oras.
+oci_registry_config() {
+    local config
+    config=$(mktemp)
+    python3 -c 'import glob, json, sys; auths = {}; [auths.update(json.load(open(f)).get("auths", {})) for f in sorted(glob.glob(sys.argv[1] + "/*.json"))]; print(json.dumps({"auths": auths}))' \
+        "${OCI_CREDENTIALS_DIR:-/nonexistent}" > $config
+    echo $config
+}
+
+case $src in
+    "hf://"*)
+        repo=${src#hf://}
+        # Do not trace the token.
+        set +x
+        auth=()
+        if [[ -n "${HF_TOKEN:-}" ]]; then
+            auth=(-H "Authorization: Bearer $HF_TOKEN")
+        fi
+        size=$(curl -sfL "${auth[@]}" "https://huggingface.co/api/models/$repo${revision:+/revision/$revision}?blobs=true" | \
+            FILE="$file" python3 -c 'import json, os, sys; f = os.environ["FILE"]; print(sum(s.get("size", 0) for s in json.load(sys.stdin)["siblings"] if not f or s["rfilename"] == f))')
+        set -x
+        ;;
+    "s3://"*)
+        size=$(aws s3 ls --recursive --summarize $src | awk '/Total Size:/ {print $3}')
+        ;;
+    "gs://"*)
+        gcloud auth activate-service-account --key-file $GOOGLE_APPLICATION_CREDENTIALS
+        size=$(gcloud storage du --summarize $src | awk '{print $1}')
+        ;;
+    "oss://"*)
+        size=$(ossutil du $src | awk -F: '/total object sum size/ {gsub(/ /, "", $2); print $2}')
+        ;;
+    "az://"*)
+        if [[ -z "${AZURE_STORAGE_ACCOUNT:-}" ]]; then
+            echo "AZURE_STORAGE_ACCOUNT is required for az:// urls"
+            exit 1
+        fi
+        # Do not trace the SAS token.
+        set +x
+        url="https://$AZURE_STORAGE_ACCOUNT.blob.core.windows.net/${src#az://}"
+        if [[ -n "${AZURE_STORAGE_SAS_TOKEN:-}" ]]; then
+            url="$url?${AZURE_STORAGE_SAS_TOKEN#\?}"
+        fi
+        size=$(azcopy list "$url" --machine-readable --running-tally | awk -F': ' '/Total file size/ {print $2}')
+        set -x
+        ;;
+    "https://"*)
+        # Archives are measured by their download size.
+        size=$(curl -sfIL "$src" | awk 'tolower($1) == "content-length:" {gsub(/\r/, "", $2); size = $2} END {print size}')
+        ;;
+    "oci://"*)
+        size=$(oras manifest fetch --registry-config $(oci_registry_config) ${src#oci://} | \
+            python3 -c 'import json, sys; print(sum(l["size"] for l in json.load(sys.stdin).get("layers", [])))')
+        ;;
+    *)
+        echo "Unsupported source url: $src"
+        exit 1
+        ;;
+esac
+
+echo "$size"
+echo -n "$size" > ${TERMINATION_LOG:-/dev/termination-log}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type System struct {
@@ -17,9 +19,9 @@ type System struct {
 
 	ModelLoading ModelLoading `json:"modelLoading" validate:"required"`
 
-	ResourceProfiles map[string]ResourceProfile `json:"resourceProfiles" validate:"required"`
+	ResourceProfiles map[string]ResourceProfile `json:"resourceProfiles" validate:"required,dive"`
 
-	CacheProfiles map[string]CacheProfile `json:"cacheProfiles"`
+	CacheProfiles map[string]CacheProfile `json:"cacheProfiles" validate:"dive"`
 
 	Messaging Messaging `json:"messaging"`
 
@@ -33,17 +35,20 @@ type System struct {
 
 	ModelAutoscaling ModelAutoscaling `json:"modelAutoscaling" validate:"required"`
 
+	LoadReporting LoadReporting `json:"loadReporting"`
+
 	ModelServerPods ModelServerPods `json:"modelServerPods,omitempty"`
 
 	ModelRollouts ModelRollouts `json:"modelRollouts"`
 
+	ModelController ModelController `json:"modelController"`
+
+	ModelWebhook ModelWebhook `json:"modelWebhook"`
+
 	LeaderElection LeaderElection `json:"leaderElection"`
 
 	// AllowPodAddressOverride will allow the pod address to be overridden by the Model objects. Useful for development purposes.
 	AllowPodAddressOverride bool `json:"allowPodAddressOverride"`
-
-	// FixedSelfMetricAddrs is a list of fixed addresses to be used when scraping metrics for autoscaling. Useful for development purposes.
-	FixedSelfMetricAddrs []string `json:"fixedSelfMetricAddrs,omitempty"`
 }
 
 func (s *System) DefaultAndValidate() error {
@@ -67,6 +72,13 @@ func (s *System) DefaultAndValidate() error {
 		s.ModelAutoscaling.TimeWindow.Duration = 10 * time.Minute
 	}
 
+	if s.LoadReporting.Interval.Duration == 0 {
+		s.LoadReporting.Interval.Duration = s.ModelAutoscaling.Interval.Duration
+	}
+	if s.LoadReporting.StaleAfter.Duration == 0 {
+		s.LoadReporting.StaleAfter.Duration = 3 * s.LoadReporting.Interval.Duration
+	}
+
 	if s.LeaderElection.LeaseDuration.Duration == 0 {
 		s.LeaderElection.LeaseDuration.Duration = 15 * time.Second
 	}
@@ -77,9 +89,28 @@ func (s *System) DefaultAndValidate() error {
 		s.LeaderElection.RetryPeriod.Duration = 2 * time.Second
 	}
 
+	if s.ModelController.MaxConcurrentReconciles == 0 {
+		s.ModelController.MaxConcurrentReconciles = 1
+	}
+
+	if s.ModelWebhook.Port == 0 {
+		s.ModelWebhook.Port = 9443
+	}
+	if s.ModelWebhook.CertDir == "" {
+		s.ModelWebhook.CertDir = "/tmp/k8s-webhook-server/serving-certs"
+	}
+
 	if s.CacheProfiles == nil {
 		s.CacheProfiles = map[string]CacheProfile{}
 	}
+	for _, p := range s.CacheProfiles {
+		if p.SharedFilesystem != nil && p.SharedFilesystem.EvictionThresholdPercent == 0 {
+			p.SharedFilesystem.EvictionThresholdPercent = 80
+		}
+		if p.NodeLocal != nil && p.NodeLocal.EvictionThresholdPercent == 0 {
+			p.NodeLocal.EvictionThresholdPercent = 80
+		}
+	}
 
 	return validator.New(validator.WithRequiredStructEnabled()).Struct(s)
 }
@@ -116,6 +147,31 @@ type ModelRollouts struct {
 	Surge int32 `json:"surge"`
 }
 
+type ModelController struct {
+	// MaxConcurrentReconciles is the number of Models that are reconciled in parallel.
+	// Defaults to 1.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles" validate:"min=1"`
+}
+
+type ModelWebhook struct {
+	// Enabled starts the admission webhook server that validates Models
+	// against the system config and defaults their fields.
+	// The webhook configurations and the serving certificate are managed
+	// by the Helm chart.
+	Enabled bool `json:"enabled"`
+	// Port of the webhook server.
+	// Defaults to 9443.
+	Port int `json:"port"`
+	// CertDir is the directory that contains the serving certificate
+	// of the webhook server (tls.crt and tls.key).
+	// Defaults to "/tmp/k8s-webhook-server/serving-certs".
+	CertDir string `json:"certDir"`
+	// DefaultImages sets the image of new Models that do not specify an
+	// image to the image of their engine. This pins the image of the Model,
+	// changes to the images in modelServers are not rolled out to it.
+	DefaultImages bool `json:"defaultImages"`
+}
+
 type ModelAutoscaling struct {
 	// Interval is the time between each autoscaling check.
 	// Defaults to 10 seconds.
@@ -124,12 +180,12 @@ type ModelAutoscaling struct {
 	// calculating the average number of requests.
 	// Defaults to 10 minutes.
 	TimeWindow Duration `json:"timeWindow" validate:"required"`
-	// StateConfigMapName is the name of the ConfigMap that will be used
-	// to store the state of the autoscaler. This ConfigMap ensures that
-	// the autoscaler can recover from crashes and restarts without losing
-	// its state.
-	// Required.
-	StateConfigMapName string `json:"stateConfigMapName" validate:"required"`
+	// StateConfigMapName is the name of the ConfigMap that was used by
+	// previous versions to store the state of the autoscaler. The state is
+	// now stored in ModelAutoscalerState objects, the ConfigMap is only read
+	// to migrate the state of Models that do not have a ModelAutoscalerState yet.
+	// Deprecated.
+	StateConfigMapName string `json:"stateConfigMapName"`
 }
 
 // RequiredConsecutiveScaleDowns returns the number of consecutive scale down
@@ -145,9 +201,27 @@ func (a *ModelAutoscaling) AverageWindowCount() int {
 	return int(math.Ceil(float64(a.TimeWindow.Duration) / float64(a.Interval.Duration)))
 }
 
+// LoadReporting configures how KubeAI replicas report the number of
+// active requests they are handling to the leader, which uses the
+// aggregated load to autoscale Models.
+type LoadReporting struct {
+	// Interval is the time between reports sent by each replica.
+	// Defaults to the autoscaling interval.
+	Interval Duration `json:"interval"`
+	// StaleAfter is the duration after which the leader discards a report
+	// from a replica that has stopped reporting (i.e. was deleted).
+	// Defaults to 3x the interval.
+	StaleAfter Duration `json:"staleAfter"`
+	// AuthenticateReplicas only accepts reports that are sent from the Pod of
+	// the reporting replica, which has to run as the ServiceAccount of the
+	// leader. Requires KubeAI to run in a Pod with the name of its host name.
+	AuthenticateReplicas bool `json:"authenticateReplicas"`
+}
+
 type SecretNames struct {
 	Alibaba     string `json:"alibaba" required:"true"`
 	AWS         string `json:"aws" required:"true"`
+	Azure       string `json:"azure" required:"true"`
 	GCP         string `json:"gcp" required:"true"`
 	Huggingface string `json:"huggingface" required:"true"`
 }
@@ -197,10 +271,30 @@ type ResourceProfile struct {
 	Tolerations      []corev1.Toleration `json:"tolerations,omitempty"`
 	SchedulerName	 string              `json:"schedulerName,omitempty"`
 	RuntimeClassName *string             `json:"runtimeClassName,omitempty"`
+	// Capacity is the budget of this profile that is shared by all Models.
+	// If not set, Models using this profile scale independently.
+	Capacity *ResourceProfileCapacity `json:"capacity,omitempty"`
 }
 
+// ResourceProfileCapacity is the total capacity of a ResourceProfile, measured in
+// units of the profile. A Model with resourceProfile "<name>:2" consumes 2 units
+// per replica.
+type ResourceProfileCapacity struct {
+	// Units is a fixed number of units that are available.
+	// Takes precedence over DiscoverFromNodes. Required if DiscoverFromNodes
+	// is not set.
+	Units *int32 `json:"units,omitempty" validate:"required_without=DiscoverFromNodes"`
+	// DiscoverFromNodes calculates the number of available units from the
+	// allocatable resources of schedulable Nodes that match the NodeSelector
+	// of the profile.
+	DiscoverFromNodes bool `json:"discoverFromNodes,omitempty"`
+}
+
+// CacheProfile configures exactly one type of cache.
 type CacheProfile struct {
-	SharedFilesystem *CacheSharedFilesystem `json:"sharedFilesystem,omitempty"`
+	SharedFilesystem *CacheSharedFilesystem `json:"sharedFilesystem,omitempty" validate:"required_without_all=DedicatedVolume NodeLocal,excluded_with=DedicatedVolume NodeLocal"`
+	DedicatedVolume  *CacheDedicatedVolume  `json:"dedicatedVolume,omitempty" validate:"required_without_all=SharedFilesystem NodeLocal,excluded_with=SharedFilesystem NodeLocal"`
+	NodeLocal        *CacheNodeLocal        `json:"nodeLocal,omitempty" validate:"required_without_all=SharedFilesystem DedicatedVolume,excluded_with=SharedFilesystem DedicatedVolume"`
 }
 
 type CacheSharedFilesystem struct {
@@ -209,6 +303,81 @@ type CacheSharedFilesystem struct {
 	// PersistentVolumeName is the name of the PersistentVolume to use for the shared filesystem.
 	// This is usually used if you have an existing filesystem that you want to use.
 	PersistentVolumeName string `json:"persistentVolumeName,omitempty" validate:"required_without=StorageClassName"`
+	// Capacity is the space that models may use on the filesystem. When the
+	// total size of the cached models reaches EvictionThresholdPercent of the
+	// capacity, the least recently served Models that are scaled to zero are
+	// evicted. Models are never evicted if unset.
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// EvictionThresholdPercent is the percentage of the capacity above which
+	// models are evicted. Defaults to 80.
+	EvictionThresholdPercent int `json:"evictionThresholdPercent,omitempty" validate:"min=0,max=100"`
+}
+
+// CacheDedicatedVolume provisions one PVC per Model. The PVC is owned by the
+// Model and deleted with it.
+type CacheDedicatedVolume struct {
+	// StorageClassName is the name of the StorageClass of the PVC that the
+	// model is loaded into.
+	StorageClassName string `json:"storageClassName" validate:"required"`
+	// Size of the PVC. Defaults to the download size of the model plus 10%,
+	// measured by a Job before the PVC is created.
+	Size *resource.Quantity `json:"size,omitempty"`
+	// ReadOnlyMany, if set, clones the loaded PVC into a ReadOnlyMany PVC
+	// that is mounted by the model server Pods (i.e. for Google Hyperdisk ML).
+	ReadOnlyMany *CacheReadOnlyMany `json:"readOnlyMany,omitempty"`
+}
+
+// HasNodeLocalCacheProfiles returns true if any cache profile caches models
+// on the Nodes.
+func (s *System) HasNodeLocalCacheProfiles() bool {
+	for _, p := range s.CacheProfiles {
+		if p.NodeLocal != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSharedFilesystemCacheProfiles returns true if any cache profile caches
+// models on a shared filesystem.
+func (s *System) HasSharedFilesystemCacheProfiles() bool {
+	for _, p := range s.CacheProfiles {
+		if p.SharedFilesystem != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheNodeLocal caches models in a directory on the Nodes. Models are
+// prefetched onto all selected Nodes and the model server Pods prefer Nodes
+// that have the model cached.
+type CacheNodeLocal struct {
+	// HostPath is the directory on the Nodes that models are cached in,
+	// i.e. the mount point of a local SSD.
+	HostPath string `json:"hostPath" validate:"required,startswith=/"`
+	// NodeSelector selects the Nodes that models are cached on. Model server
+	// Pods are only scheduled onto these Nodes.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are added to the model server Pods and the Jobs that load
+	// models onto the Nodes.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// EvictionThresholdPercent is the disk usage of the filesystem of the
+	// cache above which the least recently used models that are not in use
+	// on a Node are evicted. No models are prefetched above the threshold.
+	// Defaults to 80.
+	EvictionThresholdPercent int `json:"evictionThresholdPercent,omitempty" validate:"min=0,max=100"`
+}
+
+type CacheReadOnlyMany struct {
+	// StorageClassName is the name of the StorageClass of the ReadOnlyMany PVC.
+	// Defaults to the StorageClass of the loaded PVC.
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// VolumeSnapshotClassName, if set, creates a VolumeSnapshot of the loaded
+	// PVC and restores the ReadOnlyMany PVC from it. Otherwise the loaded PVC
+	// is cloned directly, which requires support for volume cloning by the
+	// CSI driver.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 }
 
 type MessageStream struct {
@@ -224,14 +393,44 @@ type ModelServers struct {
 	VLLM          ModelServer `json:"VLLM"`
 	FasterWhisper ModelServer `json:"FasterWhisper"`
 	Infinity      ModelServer `json:"Infinity"`
+	SGLang        ModelServer `json:"SGLang"`
+	TGI           ModelServer `json:"TGI"`
+	LlamaCPP      ModelServer `json:"LlamaCPP"`
 }
 
 type ModelServer struct {
 	Images map[string]string `json:"images"`
+	// Probes overrides the default probes of the engine.
+	// Models can override these settings in turn.
+	Probes kubeaiv1.ModelProbes `json:"probes"`
+	// BashAvailable declares that the images of the engine contain bash,
+	// which is required to track the startup progress of the engine
+	// (see probes.startupStallTimeoutSeconds).
+	BashAvailable bool `json:"bashAvailable,omitempty"`
 }
 
 type ModelLoading struct {
 	Image string `json:"image" validate:"required"`
+	// Verify adds an init container to the model server Pods of Models with
+	// a sharedFilesystem or dedicatedVolume cache profile that verifies the
+	// cached files against the manifest recorded when the model was loaded.
+	// Models that do not match are loaded again.
+	Verify bool `json:"verify,omitempty"`
+	// Parallelism is the number of files (or parts of files) that are
+	// downloaded in parallel by the model loader. Defaults to the default of
+	// the download tool of the source.
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// BackoffLimit is the number of retries of a load Job before it is
+	// marked as failed. Interrupted downloads are resumed. Defaults to the
+	// default of Kubernetes Jobs (6).
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ServiceAccountName is the service account of load Jobs.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// ImageVolumes mounts the artifacts of "oci://" Models without a cache
+	// profile as image volumes instead of pulling them with the model loader.
+	// Requires Kubernetes 1.31+ with the ImageVolume feature gate enabled,
+	// ignored on older clusters.
+	ImageVolumes bool `json:"imageVolumes,omitempty"`
 }
 
 type JSONPatch struct {
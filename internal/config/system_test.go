@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/substratusai/kubeai/internal/config"
+	"k8s.io/utils/ptr"
 )
 
 func TestAutoscalingConfig(t *testing.T) {
@@ -55,3 +56,40 @@ func TestAutoscalingConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceProfileCapacityValidation(t *testing.T) {
+	cases := map[string]struct {
+		capacity *config.ResourceProfileCapacity
+		wantErr  bool
+	}{
+		"no capacity": {},
+		"units": {
+			capacity: &config.ResourceProfileCapacity{Units: ptr.To[int32](4)},
+		},
+		"discover from nodes": {
+			capacity: &config.ResourceProfileCapacity{DiscoverFromNodes: true},
+		},
+		"empty": {
+			capacity: &config.ResourceProfileCapacity{},
+			wantErr:  true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			sys := config.System{
+				SecretNames:  config.SecretNames{Huggingface: "huggingface"},
+				ModelServers: config.ModelServers{VLLM: config.ModelServer{Images: map[string]string{"default": "vllm"}}},
+				ModelLoading: config.ModelLoading{Image: "model-loader"},
+				ResourceProfiles: map[string]config.ResourceProfile{
+					"gpu": {Capacity: c.capacity},
+				},
+			}
+			err := sys.DefaultAndValidate()
+			if c.wantErr {
+				require.ErrorContains(t, err, "Units")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -13,3 +13,12 @@ func IsJobCompleted(job *batchv1.Job) bool {
 	}
 	return false
 }
+
+func IsJobFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -6,16 +6,16 @@ import (
 	"sync/atomic"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
-	"k8s.io/client-go/util/flowcontrol"
 )
 
+// NewElection returns the leader election of KubeAI. The controller manager
+// elects the leader with the Lock of the election, so that the controllers,
+// the autoscaler and the aggregator of load reports run on the same replica.
 func NewElection(clientset kubernetes.Interface, id, namespace string,
-	leaseDuration time.Duration,
-	renewDeadline time.Duration,
 	retryPeriod time.Duration,
 ) *Election {
 	lock := &resourcelock.LeaseLock{
@@ -29,58 +29,80 @@ func NewElection(clientset kubernetes.Interface, id, namespace string,
 		},
 	}
 
-	isLeader := &atomic.Bool{}
-
-	config := leaderelection.LeaderElectionConfig{
-		Lock: lock,
-		// TODO: Set to true after ensuring autoscaling is done before cancel:
-		ReleaseOnCancel: false,
-		LeaseDuration:   leaseDuration,
-		RenewDeadline:   renewDeadline,
-		RetryPeriod:     retryPeriod,
-		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(ctx context.Context) {
-				log.Printf("%q started leading", id)
-				isLeader.Store(true)
-			},
-			OnStoppedLeading: func() {
-				log.Printf("%q stopped leading", id)
-				isLeader.Store(false)
-			},
-			OnNewLeader: func(identity string) {
-				if identity == id {
-					return
-				}
-				log.Printf("New leader elected: %s", identity)
-			},
-		},
-	}
+	leaderID := &atomic.Value{}
+	leaderID.Store("")
 
 	return &Election{
-		IsLeader: isLeader,
-		config:   config,
-		ID:       id,
+		IsLeader:    &atomic.Bool{},
+		ID:          id,
+		lock:        lock,
+		retryPeriod: retryPeriod,
+		leaderID:    leaderID,
 	}
 }
 
 type Election struct {
-	config   leaderelection.LeaderElectionConfig
-	IsLeader *atomic.Bool
-	ID       string
+	IsLeader    *atomic.Bool
+	ID          string
+	lock        resourcelock.Interface
+	retryPeriod time.Duration
+	leaderID    *atomic.Value
+}
+
+// Lock returns the lock that the controller manager elects the leader with
+// (see ctrl.Options.LeaderElectionResourceLockInterface).
+func (le *Election) Lock() resourcelock.Interface {
+	return le.lock
+}
+
+// Leader returns the identity of the most recently observed leader.
+// Returns an empty string if no leader has been observed yet.
+func (le *Election) Leader() string {
+	return le.leaderID.Load().(string)
+}
+
+// Lead marks this replica as the leader until the context is cancelled.
+// It is run by the controller manager once this replica was elected.
+func (le *Election) Lead(ctx context.Context) error {
+	log.Printf("%q started leading", le.ID)
+	le.leaderID.Store(le.ID)
+	le.IsLeader.Store(true)
+	<-ctx.Done()
+	le.IsLeader.Store(false)
+	log.Printf("%q stopped leading", le.ID)
+	return nil
 }
 
+// Start observes the leader that holds the lock until the context is
+// cancelled.
 func (le *Election) Start(ctx context.Context) error {
-	backoff := flowcontrol.NewBackOff(1*time.Second, 15*time.Second)
-	const backoffID = "kubeai-leader-election"
+	ticker := time.NewTicker(le.retryPeriod)
+	defer ticker.Stop()
 	for {
-		leaderelection.RunOrDie(ctx, le.config)
-		backoff.Next(backoffID, backoff.Clock.Now())
-		delay := backoff.Get(backoffID)
-		log.Printf("Leader election stopped on %q, retrying in %s", le.ID, delay)
+		if !le.IsLeader.Load() {
+			le.observe(ctx)
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(delay):
+		case <-ticker.C:
+		}
+	}
+}
+
+func (le *Election) observe(ctx context.Context) {
+	record, _, err := le.lock.Get(ctx)
+	if err != nil {
+		if !apierrors.IsNotFound(err) && ctx.Err() == nil {
+			log.Printf("Getting leader election record: %v", err)
 		}
+		return
+	}
+	if record.HolderIdentity == "" || record.HolderIdentity == le.Leader() {
+		return
+	}
+	le.leaderID.Store(record.HolderIdentity)
+	if record.HolderIdentity != le.ID {
+		log.Printf("New leader elected: %s", record.HolderIdentity)
 	}
 }
@@ -11,7 +11,7 @@ import (
 	"go.opentelemetry.io/otel/metric"
 )
 
-func (g *group) chwblGetAddr(key string, loadFactor float64, adapter string) (endpoint, bool) {
+func (g *group) chwblGetAddr(key string, loadFactor float64, adapter string, filter func(endpoint) bool) (endpoint, bool) {
 	if len(g.chwblHashes) == 0 {
 		return endpoint{}, false
 	}
@@ -41,9 +41,12 @@ func (g *group) chwblGetAddr(key string, loadFactor float64, adapter string) (en
 		}
 
 		var adapterMatches bool
-		if adapter == "" {
+		switch {
+		case filter != nil && !filter(ep):
+			// Excluded because the endpoint is draining or by the traffic split.
+		case adapter == "":
 			adapterMatches = true
-		} else {
+		default:
 			_, adapterMatches = ep.adapters[adapter]
 		}
 
@@ -54,7 +57,7 @@ func (g *group) chwblGetAddr(key string, loadFactor float64, adapter string) (en
 				defaultEndpoint = &ep
 				defaultEndpointName = name
 			}
-			if chwblLoadOK(ep.inFlight.Load(), g.totalInFlight.Load(), len(g.endpoints), loadFactor) {
+			if chwblLoadOK(ep.load(), g.totalLoad(), len(g.endpoints), loadFactor) {
 				metrics.InferenceRequestsHashLookupIterations.Record(context.Background(), int64(n+1))
 				metrics.InferenceRequestsHashLookupFinal.Add(context.Background(), 1, metric.WithAttributeSet(attribute.NewSet(
 					metrics.AttrEndpoint.String(name),
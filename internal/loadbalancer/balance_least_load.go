@@ -1,17 +1,20 @@
 package loadbalancer
 
-func (g *group) getAddrLeastLoad(adapter string) (endpoint, bool) {
+func (g *group) getAddrLeastLoad(adapter string, filter func(endpoint) bool) (endpoint, bool) {
 	var bestEp endpoint
 	var found bool
 	var minInFlight int
 	for _, ep := range g.endpoints {
+		if filter != nil && !filter(ep) {
+			continue
+		}
 		if adapter != "" {
 			// Skip endpoints that don't have the requested adapter.
 			if _, ok := ep.adapters[adapter]; !ok {
 				continue
 			}
 		}
-		inFlight := int(ep.inFlight.Load())
+		inFlight := int(ep.load())
 		if !found || inFlight < minInFlight {
 			bestEp = ep
 			found = true
@@ -3,17 +3,21 @@ package loadbalancer
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"sync"
 	"sync/atomic"
 
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/apiutils"
+	"github.com/substratusai/kubeai/internal/loadreport"
 )
 
 func newEndpointGroup(lb v1.LoadBalancing) *group {
 	g := &group{
 		endpoints:         make(map[string]endpoint),
 		totalInFlight:     &atomic.Int64{},
+		totalRemote:       &atomic.Int64{},
+		totalQueued:       &atomic.Int64{},
 		chwblReplication:  lb.PrefixHash.Replication,
 		chwblHashes:       map[uint64]string{},
 		chwblSortedHashes: []uint64{},
@@ -28,6 +32,11 @@ type group struct {
 	endpoints map[string]endpoint
 
 	totalInFlight *atomic.Int64
+	// totalRemote is the number of requests that the other KubeAI replicas
+	// have in flight to the endpoints, see endpoint.remoteInFlight.
+	totalRemote *atomic.Int64
+	// totalQueued is the number of requests waiting for an endpoint.
+	totalQueued *atomic.Int64
 
 	// the number of times an endpoint is replicated on the hash ring
 	chwblReplication int
@@ -44,8 +53,23 @@ type endpoint struct {
 	address string
 
 	inFlight *atomic.Int64
+	// remoteInFlight is the number of requests that the other KubeAI
+	// replicas have in flight to the endpoint as of their last load report.
+	remoteInFlight *atomic.Int64
 
 	adapters map[string]struct{}
+
+	// trafficPercent is set for the up-to-date Pods of a BlueGreen or Canary
+	// rollout, see v1.ModelPodTrafficPercentAnnotation.
+	trafficPercent *int32
+
+	// draining is true if the Pod of the endpoint is drained before it
+	// is deleted. Draining endpoints do not receive new requests.
+	draining bool
+
+	// responses and errors count the responses received from the endpoint.
+	responses *atomic.Int64
+	errors    *atomic.Int64
 }
 
 // getBestAddr returns the best "IP:Port". It blocks until there are available endpoints
@@ -65,13 +89,25 @@ func (g *group) getBestAddr(ctx context.Context, req *apiutils.Request, awaitCha
 
 	var ep endpoint
 	var found bool
-	switch req.LoadBalancing.Strategy {
-	case v1.PrefixHashStrategy:
-		ep, found = g.chwblGetAddr(req.Adapter+req.Prefix, float64(req.LoadBalancing.PrefixHash.MeanLoadPercentage)/100, req.Adapter)
-	case v1.LeastLoadStrategy:
-		ep, found = g.getAddrLeastLoad(req.Adapter)
-	default:
-		return "", func() {}, fmt.Errorf("unknown load balancing strategy: %v", req.LoadBalancing.Strategy)
+	// Fall back to all endpoints that are not draining if none of the
+	// selected side of the traffic split can serve the request.
+	filters := []func(endpoint) bool{notDraining}
+	if split := g.trafficSplitFilter(); split != nil {
+		filters = append([]func(endpoint) bool{split}, filters...)
+	}
+	for _, filter := range filters {
+		switch req.LoadBalancing.Strategy {
+		case v1.PrefixHashStrategy:
+			ep, found = g.chwblGetAddr(req.Adapter+req.Prefix, float64(req.LoadBalancing.PrefixHash.MeanLoadPercentage)/100, req.Adapter, filter)
+		case v1.LeastLoadStrategy:
+			ep, found = g.getAddrLeastLoad(req.Adapter, filter)
+		default:
+			g.mtx.RUnlock()
+			return "", func() {}, fmt.Errorf("unknown load balancing strategy: %v", req.LoadBalancing.Strategy)
+		}
+		if found {
+			break
+		}
 	}
 
 	if !found {
@@ -87,6 +123,55 @@ func (g *group) getBestAddr(ctx context.Context, req *apiutils.Request, awaitCha
 	return ep.address, decFunc, nil
 }
 
+// trafficSplitFilter returns a filter that restricts the endpoints that are
+// not draining to either the endpoints with a traffic percentage or the
+// endpoints without one, chosen at random according to the percentage. It returns nil if there is no traffic split.
+// Must be called with the read lock held.
+func (g *group) trafficSplitFilter() func(endpoint) bool {
+	var percent int32
+	var split, other bool
+	for _, ep := range g.endpoints {
+		if ep.draining {
+			continue
+		}
+		if ep.trafficPercent != nil {
+			split = true
+			percent = *ep.trafficPercent
+		} else {
+			other = true
+		}
+	}
+	if !split || !other {
+		return nil
+	}
+	toSplit := rand.Int32N(100) < percent
+	return func(ep endpoint) bool {
+		return !ep.draining && (ep.trafficPercent != nil) == toSplit
+	}
+}
+
+// notDraining excludes the endpoints of Pods that are drained before they
+// are deleted, see v1.ModelPodDrainingAnnotation.
+func notDraining(ep endpoint) bool {
+	return !ep.draining
+}
+
+// recordResponse counts a response from the endpoint with the given address.
+func (g *group) recordResponse(addr string, failed bool) {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	for _, ep := range g.endpoints {
+		if ep.address != addr {
+			continue
+		}
+		ep.responses.Add(1)
+		if failed {
+			ep.errors.Add(1)
+		}
+		return
+	}
+}
+
 func (g *group) awaitEndpoints() chan struct{} {
 	g.bmtx.RLock()
 	defer g.bmtx.RUnlock()
@@ -105,17 +190,52 @@ func (g *group) getAllAddrs() []string {
 	return hosts
 }
 
+// load returns the number of queued and in-flight requests for the group.
+func (g *group) load() loadreport.ModelLoad {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	queued := g.totalQueued.Load()
+	load := loadreport.ModelLoad{
+		Active:    queued + g.totalInFlight.Load(),
+		Queued:    queued,
+		Endpoints: map[string]int64{},
+	}
+	for _, ep := range g.endpoints {
+		if n := ep.inFlight.Load(); n > 0 {
+			load.Endpoints[ep.address] = n
+		}
+		if n := ep.responses.Load(); n > 0 {
+			if load.Responses == nil {
+				load.Responses = map[string]loadreport.EndpointResponses{}
+			}
+			load.Responses[ep.address] = loadreport.EndpointResponses{Total: n, Errors: ep.errors.Load()}
+		}
+		if ep.draining {
+			load.Draining = append(load.Draining, ep.address)
+		}
+	}
+	return load
+}
+
 func (g *group) reconcileEndpoints(observed map[string]endpoint) {
 	g.mtx.Lock()
 	for name, observedEp := range observed {
 		if currentEp, ok := g.endpoints[name]; ok {
 			currentEp.adapters = observedEp.adapters
+			currentEp.trafficPercent = observedEp.trafficPercent
+			currentEp.draining = observedEp.draining
 			g.endpoints[name] = currentEp
 		} else {
 			g.endpoints[name] = endpoint{
-				inFlight: &atomic.Int64{},
-				address:  observedEp.address,
-				adapters: observedEp.adapters,
+				inFlight:       &atomic.Int64{},
+				remoteInFlight: &atomic.Int64{},
+				address:        observedEp.address,
+				adapters:       observedEp.adapters,
+				trafficPercent: observedEp.trafficPercent,
+				draining:       observedEp.draining,
+				responses:      &atomic.Int64{},
+				errors:         &atomic.Int64{},
 			}
 			g.chwblAddEndpoint(name)
 		}
@@ -144,6 +264,32 @@ func (g *group) broadcastEndpoints() {
 	g.bcast = make(chan struct{})
 }
 
+// load returns the number of requests that all KubeAI replicas have in
+// flight to the endpoint.
+func (ep endpoint) load() int64 {
+	return ep.inFlight.Load() + ep.remoteInFlight.Load()
+}
+
+// totalLoad returns the number of requests that all KubeAI replicas have in
+// flight to the endpoints of the group.
+func (g *group) totalLoad() int64 {
+	return g.totalInFlight.Load() + g.totalRemote.Load()
+}
+
+// setRemoteInFlight records the number of requests that the other KubeAI
+// replicas have in flight by endpoint address.
+func (g *group) setRemoteInFlight(byAddr map[string]int64) {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	var total int64
+	for _, ep := range g.endpoints {
+		n := byAddr[ep.address]
+		ep.remoteInFlight.Store(n)
+		total += n
+	}
+	g.totalRemote.Store(total)
+}
+
 func (g *group) addInFlight(endpointInFlight *atomic.Int64, add int64) int64 {
 	g.totalInFlight.Add(add)
 	return endpointInFlight.Add(add)
@@ -127,3 +127,27 @@ func TestAbortOnCtxCancel(t *testing.T) {
 
 	doneWg.Wait()
 }
+
+func TestRemoteInFlight(t *testing.T) {
+	group := newEndpointGroup(v1.LoadBalancing{PrefixHash: v1.PrefixHash{Replication: 100}})
+	group.reconcileEndpoints(map[string]endpoint{
+		"pod1": {address: "10.0.0.1:8000"},
+		"pod2": {address: "10.0.0.2:8000"},
+	})
+
+	// The endpoint that is busy with the requests of other replicas is
+	// avoided although this replica has no requests in flight to it.
+	group.setRemoteInFlight(map[string]int64{"10.0.0.1:8000": 5})
+	require.Equal(t, int64(5), group.totalLoad())
+	for i := 0; i < 3; i++ {
+		addr, done, err := group.getBestAddr(context.Background(), &apiutils.Request{
+			LoadBalancing: v1.LoadBalancing{Strategy: v1.LeastLoadStrategy},
+		}, false)
+		require.NoError(t, err)
+		defer done()
+		require.Equal(t, "10.0.0.2:8000", addr)
+	}
+
+	group.setRemoteInFlight(nil)
+	require.Equal(t, int64(3), group.totalLoad())
+}
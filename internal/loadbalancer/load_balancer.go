@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/apiutils"
 	"github.com/substratusai/kubeai/internal/k8sutils"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -37,9 +39,6 @@ type LoadBalancer struct {
 	// map[<model-name>]endpointGroup
 	groups map[string]*group
 
-	selfIPsMtx sync.RWMutex
-	selfIPs    []string
-
 	ExcludePods map[string]struct{}
 }
 
@@ -61,27 +60,6 @@ func (r *LoadBalancer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
-	const (
-		selfLabelKey = "app.kubernetes.io/name"
-		selfLabelVal = "kubeai"
-	)
-	if labels[selfLabelKey] == selfLabelVal {
-		var podList corev1.PodList
-		if err := r.List(ctx, &podList, client.InNamespace(pod.Namespace), client.MatchingLabels{selfLabelKey: selfLabelVal}); err != nil {
-			return ctrl.Result{}, fmt.Errorf("listing matching pods: %w", err)
-		}
-		var selfIPs []string
-		for _, p := range podList.Items {
-			if k8sutils.PodIsReady(&p) {
-				selfIPs = append(selfIPs, p.Status.PodIP)
-			}
-		}
-		r.selfIPsMtx.Lock()
-		r.selfIPs = selfIPs
-		r.selfIPsMtx.Unlock()
-		return ctrl.Result{}, nil
-	}
-
 	modelName, ok := labels[v1.PodModelLabel]
 	if !ok {
 		return ctrl.Result{}, nil
@@ -121,8 +99,10 @@ func (r *LoadBalancer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Re
 		}
 
 		observedEndpoints[pod.Namespace+"/"+pod.Name] = endpoint{
-			address:  ip + ":" + port,
-			adapters: getEndpointAdapters(pod),
+			address:        ip + ":" + port,
+			adapters:       getEndpointAdapters(pod),
+			trafficPercent: getEndpointTrafficPercent(pod),
+			draining:       getPodAnnotation(pod, v1.ModelPodDrainingAnnotation) != "",
 		}
 	}
 
@@ -151,6 +131,19 @@ func getEndpointAdapters(pod corev1.Pod) map[string]struct{} {
 	return adapters
 }
 
+func getEndpointTrafficPercent(pod corev1.Pod) *int32 {
+	val := getPodAnnotation(pod, v1.ModelPodTrafficPercentAnnotation)
+	if val == "" {
+		return nil
+	}
+	percent, err := strconv.ParseInt(val, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: Invalid traffic percent annotation %q for pod %s: %v", val, pod.Name, err)
+		return nil
+	}
+	return ptr.To(int32(percent))
+}
+
 func getPodAnnotation(pod corev1.Pod, key string) string {
 	if ann := pod.GetAnnotations(); ann != nil {
 		return ann[key]
@@ -179,17 +172,25 @@ func (r *LoadBalancer) getEndpointGroup(modelName string) (*group, bool) {
 	return g, ok
 }
 
-func (r *LoadBalancer) GetSelfIPs() []string {
-	r.selfIPsMtx.RLock()
-	defer r.selfIPsMtx.RUnlock()
-	return r.selfIPs
-}
-
 // AwaitBestAddress returns the "IP:Port" with the lowest number of in-flight requests. It will block until an endpoint
 // becomes available or the context times out. It returns a function that should be called when the
 // request is complete to decrement the in-flight count.
 func (r *LoadBalancer) AwaitBestAddress(ctx context.Context, req *apiutils.Request) (string, func(), error) {
-	return r.getOrCreateEndpointGroup(req.Model, req.LoadBalancing).getBestAddr(ctx, req, false)
+	g := r.getOrCreateEndpointGroup(req.Model, req.LoadBalancing)
+	g.totalQueued.Add(1)
+	defer g.totalQueued.Add(-1)
+	return g.getBestAddr(ctx, req, false)
+}
+
+// RecordResponse counts a response received from the given address. Failed
+// responses are 5xx responses and requests that did not receive a response.
+// The counts are reported to the leader to analyze BlueGreen and Canary rollouts.
+func (r *LoadBalancer) RecordResponse(model, addr string, failed bool) {
+	grp, ok := r.getEndpointGroup(model)
+	if !ok {
+		return
+	}
+	grp.recordResponse(addr, failed)
 }
 
 // GetAllHosts retrieves the list of all hosts for a given model.
@@ -200,3 +201,40 @@ func (r *LoadBalancer) GetAllAddresses(model string) []string {
 	}
 	return grp.getAllAddrs()
 }
+
+// LocalLoad returns the load that this replica is currently handling for each model.
+// Models without any queued or in-flight requests or received responses are omitted.
+func (r *LoadBalancer) LocalLoad() map[string]loadreport.ModelLoad {
+	r.endpointsMtx.Lock()
+	groups := make(map[string]*group, len(r.groups))
+	for name, g := range r.groups {
+		groups[name] = g
+	}
+	r.endpointsMtx.Unlock()
+
+	result := map[string]loadreport.ModelLoad{}
+	for name, g := range groups {
+		load := g.load()
+		if load.Active == 0 && len(load.Responses) == 0 && len(load.Draining) == 0 {
+			continue
+		}
+		result[name] = load
+	}
+	return result
+}
+
+// SetRemoteInFlight records the number of requests that the other KubeAI
+// replicas have in flight to each endpoint, grouped by model. Endpoints are
+// selected by the total load across all replicas.
+func (r *LoadBalancer) SetRemoteInFlight(inFlight map[string]map[string]int64) {
+	r.endpointsMtx.Lock()
+	groups := make(map[string]*group, len(r.groups))
+	for name, g := range r.groups {
+		groups[name] = g
+	}
+	r.endpointsMtx.Unlock()
+
+	for name, g := range groups {
+		g.setRemoteInFlight(inFlight[name])
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/apiutils"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"github.com/substratusai/kubeai/internal/metrics/metricstest"
 )
 
@@ -524,3 +525,185 @@ func percentDifference(a, b float64) float64 {
 	avg := (a + b) / 2.0
 	return (diff / avg) * 100.0
 }
+
+func TestLocalLoad(t *testing.T) {
+	metricstest.Init(t)
+
+	const myModel = "my-model"
+	lb := v1.LoadBalancing{Strategy: v1.LeastLoadStrategy}
+	manager := &LoadBalancer{
+		groups: map[string]*group{},
+	}
+	manager.getOrCreateEndpointGroup(myModel, lb).reconcileEndpoints(map[string]endpoint{
+		"pod1": {address: "10.0.0.1:8000"},
+	})
+	manager.getOrCreateEndpointGroup("idle-model", lb)
+
+	_, done1, err := manager.AwaitBestAddress(context.Background(), &apiutils.Request{Model: myModel, LoadBalancing: lb})
+	require.NoError(t, err)
+	_, done2, err := manager.AwaitBestAddress(context.Background(), &apiutils.Request{Model: myModel, LoadBalancing: lb})
+	require.NoError(t, err)
+
+	// Queue a request for a model without endpoints.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _, err := manager.AwaitBestAddress(ctx, &apiutils.Request{Model: "scaling-from-zero", LoadBalancing: lb})
+		assert.ErrorIs(t, err, context.Canceled)
+	}()
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, int64(1), manager.LocalLoad()["scaling-from-zero"].Queued)
+	}, time.Second, time.Millisecond)
+
+	load := manager.LocalLoad()
+	require.Len(t, load, 2, "idle models should be omitted")
+	require.Equal(t, int64(2), load[myModel].Active)
+	require.Equal(t, int64(0), load[myModel].Queued)
+	require.Equal(t, map[string]int64{"10.0.0.1:8000": 2}, load[myModel].Endpoints)
+	require.Equal(t, int64(1), load["scaling-from-zero"].Active)
+
+	cancel()
+	wg.Wait()
+	done1()
+	done2()
+	require.Empty(t, manager.LocalLoad())
+}
+
+func TestTrafficSplit(t *testing.T) {
+	const (
+		myModel    = "my-model"
+		myAdapter  = "my-adapter"
+		stableAddr = "10.0.0.1:8000"
+		canaryAddr = "10.0.0.2:8000"
+	)
+
+	for _, strategy := range []v1.LoadBalancingStrategy{v1.LeastLoadStrategy, v1.PrefixHashStrategy} {
+		t.Run(string(strategy), func(t *testing.T) {
+			metricstest.Init(t)
+
+			manager := &LoadBalancer{
+				groups: map[string]*group{},
+			}
+			lb := v1.LoadBalancing{
+				Strategy: strategy,
+				PrefixHash: v1.PrefixHash{
+					MeanLoadPercentage: 125,
+					Replication:        256,
+				},
+			}
+			grp := manager.getOrCreateEndpointGroup(myModel, lb)
+			reconcile := func(canaryPercent int32) {
+				grp.reconcileEndpoints(map[string]endpoint{
+					"stable": {address: stableAddr, adapters: map[string]struct{}{myAdapter: {}}},
+					"canary": {address: canaryAddr, trafficPercent: &canaryPercent},
+				})
+			}
+			countCanary := func(adapter string) int {
+				var n int
+				for i := 0; i < 1000; i++ {
+					addr, done, err := manager.AwaitBestAddress(context.Background(), &apiutils.Request{
+						Model:         myModel,
+						Adapter:       adapter,
+						Prefix:        fmt.Sprint(i),
+						LoadBalancing: lb,
+					})
+					require.NoError(t, err)
+					done()
+					if addr == canaryAddr {
+						n++
+					}
+				}
+				return n
+			}
+
+			reconcile(20)
+			require.InDelta(t, 200, countCanary(""), 60)
+
+			reconcile(0)
+			require.Equal(t, 0, countCanary(""))
+
+			reconcile(100)
+			require.Equal(t, 1000, countCanary(""))
+			// Falls back to the stable endpoint if the canary can not serve the request.
+			require.Equal(t, 0, countCanary(myAdapter))
+		})
+	}
+}
+
+func TestDraining(t *testing.T) {
+	const (
+		myModel      = "my-model"
+		servingAddr  = "10.0.0.1:8000"
+		drainingAddr = "10.0.0.2:8000"
+	)
+
+	for _, strategy := range []v1.LoadBalancingStrategy{v1.LeastLoadStrategy, v1.PrefixHashStrategy} {
+		t.Run(string(strategy), func(t *testing.T) {
+			metricstest.Init(t)
+
+			manager := &LoadBalancer{
+				groups: map[string]*group{},
+			}
+			lb := v1.LoadBalancing{
+				Strategy: strategy,
+				PrefixHash: v1.PrefixHash{
+					MeanLoadPercentage: 125,
+					Replication:        256,
+				},
+			}
+			grp := manager.getOrCreateEndpointGroup(myModel, lb)
+			grp.reconcileEndpoints(map[string]endpoint{
+				"serving":  {address: servingAddr},
+				"draining": {address: drainingAddr, draining: true},
+			})
+
+			for i := 0; i < 100; i++ {
+				addr, done, err := manager.AwaitBestAddress(context.Background(), &apiutils.Request{
+					Model:         myModel,
+					Prefix:        fmt.Sprint(i),
+					LoadBalancing: lb,
+				})
+				require.NoError(t, err)
+				done()
+				require.Equal(t, servingAddr, addr, "Draining endpoints should not receive new requests")
+			}
+			require.Equal(t, []string{drainingAddr}, manager.LocalLoad()[myModel].Draining)
+
+			// Requests wait for an endpoint that is not draining.
+			grp.reconcileEndpoints(map[string]endpoint{
+				"draining": {address: drainingAddr, draining: true},
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			_, _, err := manager.AwaitBestAddress(ctx, &apiutils.Request{Model: myModel, LoadBalancing: lb})
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+}
+
+func TestRecordResponse(t *testing.T) {
+	metricstest.Init(t)
+
+	const myModel = "my-model"
+	lb := v1.LoadBalancing{Strategy: v1.LeastLoadStrategy}
+	manager := &LoadBalancer{
+		groups: map[string]*group{},
+	}
+	manager.getOrCreateEndpointGroup(myModel, lb).reconcileEndpoints(map[string]endpoint{
+		"pod1": {address: "10.0.0.1:8000"},
+		"pod2": {address: "10.0.0.2:8000"},
+	})
+
+	manager.RecordResponse(myModel, "10.0.0.1:8000", false)
+	manager.RecordResponse(myModel, "10.0.0.1:8000", true)
+	manager.RecordResponse(myModel, "10.0.0.3:8000", true)
+	manager.RecordResponse("unknown-model", "10.0.0.1:8000", true)
+
+	load := manager.LocalLoad()
+	require.Len(t, load, 1, "models with responses should be reported")
+	require.Equal(t, map[string]loadreport.EndpointResponses{
+		"10.0.0.1:8000": {Total: 2, Errors: 1},
+	}, load[myModel].Responses)
+}
@@ -0,0 +1,191 @@
+package loadreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+func NewAggregator(staleAfter time.Duration) *Aggregator {
+	return &Aggregator{
+		staleAfter: staleAfter,
+		reports:    map[string]receivedReport{},
+		now:        time.Now,
+	}
+}
+
+// Aggregator collects the latest Report from every KubeAI replica.
+// It runs on all replicas but only the leader receives reports.
+// Reports that have not been refreshed within the stale duration are
+// ignored, which allows replicas to come and go without leaving
+// behind phantom load.
+type Aggregator struct {
+	staleAfter time.Duration
+
+	// Authenticator verifies that reports are sent by KubeAI replicas.
+	// Reports are accepted from any client if nil.
+	Authenticator Authenticator
+
+	mtx sync.RWMutex
+	// map[<replica>]receivedReport
+	reports map[string]receivedReport
+
+	now func() time.Time
+}
+
+// Authenticator verifies the identity of the sender of a load report.
+type Authenticator interface {
+	// Authenticate returns an error if the report of the given replica was
+	// not sent from the given "IP:Port" of that replica.
+	Authenticate(ctx context.Context, replica, remoteAddr string) error
+}
+
+type receivedReport struct {
+	Report
+	receivedAt time.Time
+}
+
+// Add records the given report, replacing any previous report from the same replica.
+func (a *Aggregator) Add(r Report) {
+	a.mtx.Lock()
+	a.reports[r.Replica] = receivedReport{Report: r, receivedAt: a.now()}
+	a.mtx.Unlock()
+}
+
+// ServeHTTP accepts load reports that are POSTed by other replicas.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var report Report
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, fmt.Sprintf("decoding report: %v", err), http.StatusBadRequest)
+		return
+	}
+	if report.Replica == "" {
+		http.Error(w, "replica must be set", http.StatusBadRequest)
+		return
+	}
+	if a.Authenticator != nil {
+		if err := a.Authenticator.Authenticate(r.Context(), report.Replica, r.RemoteAddr); err != nil {
+			log.Printf("Rejecting load report of replica %q from %s: %v", report.Replica, r.RemoteAddr, err)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	}
+	a.Add(report)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(Feedback{InFlight: a.InFlightByEndpoint(report.Replica)}); err != nil {
+		log.Printf("Failed to encode load report feedback: %v", err)
+	}
+}
+
+// Replicas returns the number of replicas with a fresh report.
+func (a *Aggregator) Replicas() int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.pruneStale()
+	return len(a.reports)
+}
+
+// ActiveRequestsByModel returns the number of active requests reported by
+// each replica, grouped by Model.
+func (a *Aggregator) ActiveRequestsByModel() map[string][]int64 {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.pruneStale()
+
+	result := map[string][]int64{}
+	for _, r := range a.reports {
+		for model, load := range r.Models {
+			result[model] = append(result[model], load.Active)
+		}
+	}
+	return result
+}
+
+// InFlightByEndpoint returns the total number of in-flight requests sent to
+// each endpoint of every Model across all replicas except the given one,
+// grouped by Model.
+func (a *Aggregator) InFlightByEndpoint(exceptReplica string) map[string]map[string]int64 {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.pruneStale()
+
+	result := map[string]map[string]int64{}
+	for replica, r := range a.reports {
+		if replica == exceptReplica {
+			continue
+		}
+		for model, load := range r.Models {
+			for addr, n := range load.Endpoints {
+				if result[model] == nil {
+					result[model] = map[string]int64{}
+				}
+				result[model][addr] += n
+			}
+		}
+	}
+	return result
+}
+
+// ResponsesByEndpoint returns the total number of responses received from
+// each endpoint of the given Model across all replicas.
+func (a *Aggregator) ResponsesByEndpoint(model string) map[string]EndpointResponses {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.pruneStale()
+
+	result := map[string]EndpointResponses{}
+	for _, r := range a.reports {
+		for addr, resp := range r.Models[model].Responses {
+			total := result[addr]
+			total.Total += resp.Total
+			total.Errors += resp.Errors
+			result[addr] = total
+		}
+	}
+	return result
+}
+
+// EndpointDrained returns true if no replica has in-flight requests to the
+// given endpoint of the Model and every replica that handles the Model
+// reported the endpoint as draining. Without reports of the Model, the
+// endpoint is not known to be drained.
+func (a *Aggregator) EndpointDrained(model, addr string) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.pruneStale()
+
+	var reported bool
+	for _, r := range a.reports {
+		load, ok := r.Models[model]
+		if !ok {
+			continue
+		}
+		if load.Endpoints[addr] > 0 || !slices.Contains(load.Draining, addr) {
+			return false
+		}
+		reported = true
+	}
+	return reported
+}
+
+// pruneStale removes reports that have not been refreshed in time.
+// Must be called with the mutex held.
+func (a *Aggregator) pruneStale() {
+	now := a.now()
+	for replica, r := range a.reports {
+		if now.Sub(r.receivedAt) > a.staleAfter {
+			log.Printf("Dropping stale load report from replica %q, last received %s", replica, r.receivedAt)
+			delete(a.reports, replica)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package loadreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregator(t *testing.T) {
+	now := time.Now()
+	agg := NewAggregator(time.Minute)
+	agg.now = func() time.Time { return now }
+
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {
+			Active:    1,
+			Endpoints: map[string]int64{"10.0.0.1:8000": 1},
+			Responses: map[string]EndpointResponses{"10.0.0.1:8000": {Total: 10, Errors: 1}},
+		},
+		"m2": {Active: 5, Queued: 5},
+	}})
+	agg.Add(Report{Replica: "b", Models: map[string]ModelLoad{
+		"m1": {
+			Active:    3,
+			Endpoints: map[string]int64{"10.0.0.1:8000": 2, "10.0.0.2:8000": 1},
+			Responses: map[string]EndpointResponses{"10.0.0.1:8000": {Total: 5}, "10.0.0.2:8000": {Total: 2, Errors: 2}},
+		},
+	}})
+
+	require.Equal(t, 2, agg.Replicas())
+	active := agg.ActiveRequestsByModel()
+	require.ElementsMatch(t, []int64{1, 3}, active["m1"])
+	require.ElementsMatch(t, []int64{5}, active["m2"])
+	require.Equal(t, map[string]map[string]int64{
+		"m1": {"10.0.0.1:8000": 3, "10.0.0.2:8000": 1},
+	}, agg.InFlightByEndpoint(""))
+	require.Equal(t, map[string]map[string]int64{
+		"m1": {"10.0.0.1:8000": 2, "10.0.0.2:8000": 1},
+	}, agg.InFlightByEndpoint("a"))
+	require.Equal(t, map[string]EndpointResponses{
+		"10.0.0.1:8000": {Total: 15, Errors: 1},
+		"10.0.0.2:8000": {Total: 2, Errors: 2},
+	}, agg.ResponsesByEndpoint("m1"))
+
+	// A newer report replaces the previous one from the same replica.
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {Active: 2},
+	}})
+	active = agg.ActiveRequestsByModel()
+	require.ElementsMatch(t, []int64{2, 3}, active["m1"])
+	require.Empty(t, active["m2"])
+
+	// Replica "b" stops reporting.
+	now = now.Add(45 * time.Second)
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {Active: 2},
+	}})
+	now = now.Add(30 * time.Second)
+	require.Equal(t, 1, agg.Replicas())
+	require.Equal(t, []int64{2}, agg.ActiveRequestsByModel()["m1"])
+}
+
+func TestAggregatorEndpointDrained(t *testing.T) {
+	agg := NewAggregator(time.Minute)
+	require.False(t, agg.EndpointDrained("m1", "10.0.0.1:8000"), "Endpoints without reports should not be drained")
+
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {Endpoints: map[string]int64{"10.0.0.1:8000": 1}, Draining: []string{"10.0.0.1:8000"}},
+	}})
+	agg.Add(Report{Replica: "b", Models: map[string]ModelLoad{
+		"m1": {Draining: []string{"10.0.0.1:8000"}},
+	}})
+	agg.Add(Report{Replica: "c", Models: map[string]ModelLoad{
+		"m2": {Active: 1},
+	}})
+	require.False(t, agg.EndpointDrained("m2", "10.0.0.3:8000"), "Endpoints that are not reported as draining should not be drained")
+	require.False(t, agg.EndpointDrained("m1", "10.0.0.1:8000"), "In-flight requests should block draining")
+
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {Active: 1, Endpoints: map[string]int64{"10.0.0.2:8000": 1}},
+	}})
+	require.False(t, agg.EndpointDrained("m1", "10.0.0.1:8000"), "Endpoint should be draining on all replicas")
+
+	agg.Add(Report{Replica: "a", Models: map[string]ModelLoad{
+		"m1": {Active: 1, Endpoints: map[string]int64{"10.0.0.2:8000": 1}, Draining: []string{"10.0.0.1:8000"}},
+	}})
+	require.True(t, agg.EndpointDrained("m1", "10.0.0.1:8000"))
+}
+
+func TestAggregatorServeHTTP(t *testing.T) {
+	agg := NewAggregator(time.Minute)
+
+	cases := []struct {
+		name      string
+		method    string
+		body      string
+		expStatus int
+	}{
+		{
+			name:      "valid report",
+			method:    http.MethodPost,
+			body:      `{"replica": "a", "models": {"m1": {"active": 4}}}`,
+			expStatus: http.StatusOK,
+		},
+		{
+			name:      "missing replica",
+			method:    http.MethodPost,
+			body:      `{"models": {"m1": {"active": 4}}}`,
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "invalid json",
+			method:    http.MethodPost,
+			body:      `{`,
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "wrong method",
+			method:    http.MethodGet,
+			expStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			agg.ServeHTTP(w, httptest.NewRequest(c.method, Path, bytes.NewBufferString(c.body)))
+			require.Equal(t, c.expStatus, w.Code)
+		})
+	}
+
+	require.Equal(t, []int64{4}, agg.ActiveRequestsByModel()["m1"])
+}
+
+type testAuthenticator map[string]string
+
+func (a testAuthenticator) Authenticate(_ context.Context, replica, remoteAddr string) error {
+	if a[replica] != remoteAddr {
+		return errors.New("unknown replica")
+	}
+	return nil
+}
+
+func TestAggregatorServeHTTPFeedback(t *testing.T) {
+	agg := NewAggregator(time.Minute)
+	agg.Authenticator = testAuthenticator{"a": "10.0.0.10:1234", "b": "10.0.0.11:1234"}
+
+	post := func(remoteAddr, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, Path, bytes.NewBufferString(body))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		agg.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, post("10.0.0.10:1234", `{"replica": "a", "models": {"m1": {"active": 2, "endpoints": {"10.0.0.1:8000": 2}}}}`).Code)
+
+	// Every replica receives the in-flight requests of the other replicas.
+	w := post("10.0.0.11:1234", `{"replica": "b", "models": {"m1": {"active": 1, "endpoints": {"10.0.0.1:8000": 1}}}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var feedback Feedback
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&feedback))
+	require.Equal(t, map[string]map[string]int64{"m1": {"10.0.0.1:8000": 2}}, feedback.InFlight)
+
+	// Reports from other clients are rejected.
+	require.Equal(t, http.StatusForbidden, post("10.0.0.99:1234", `{"replica": "a", "models": {"m1": {"active": 100}}}`).Code)
+	require.ElementsMatch(t, []int64{2, 1}, agg.ActiveRequestsByModel()["m1"])
+}
@@ -0,0 +1,49 @@
+package loadreport
+
+import "time"
+
+// Path is the HTTP path that the leader serves for receiving load reports
+// from other KubeAI replicas.
+const Path = "/internal/load-reports"
+
+// Report is sent periodically by every KubeAI replica to the leader.
+type Report struct {
+	// Replica is the identity of the reporting replica (the Pod name).
+	Replica string `json:"replica"`
+	// Timestamp is the time the report was generated on the replica.
+	Timestamp time.Time `json:"timestamp"`
+	// Models maps Model names to the load observed by the replica.
+	Models map[string]ModelLoad `json:"models"`
+}
+
+// ModelLoad is the load a single replica is handling for a given Model.
+type ModelLoad struct {
+	// Active is the number of requests that are being handled,
+	// including requests that are still queued.
+	Active int64 `json:"active"`
+	// Queued is the number of requests that are waiting for an endpoint.
+	Queued int64 `json:"queued"`
+	// Endpoints maps endpoint addresses to the number of in-flight
+	// requests the replica has sent to that endpoint.
+	Endpoints map[string]int64 `json:"endpoints,omitempty"`
+	// Responses maps endpoint addresses to the responses the replica
+	// has received from that endpoint since the endpoint became ready.
+	Responses map[string]EndpointResponses `json:"responses,omitempty"`
+	// Draining lists the addresses of the endpoints that the replica
+	// no longer sends new requests to.
+	Draining []string `json:"draining,omitempty"`
+}
+
+// EndpointResponses counts the responses received from an endpoint.
+type EndpointResponses struct {
+	Total int64 `json:"total"`
+	// Errors is the number of 5xx responses and failed connections.
+	Errors int64 `json:"errors"`
+}
+
+// Feedback is returned by the leader in response to a Report.
+type Feedback struct {
+	// InFlight maps Model names and endpoint addresses to the number of
+	// in-flight requests that all other replicas have sent to the endpoint.
+	InFlight map[string]map[string]int64 `json:"inFlight,omitempty"`
+}
@@ -0,0 +1,186 @@
+package loadreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/substratusai/kubeai/internal/leader"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LoadSource provides the load that is currently being handled by this replica.
+type LoadSource interface {
+	LocalLoad() map[string]ModelLoad
+	// SetRemoteInFlight receives the number of in-flight requests that the
+	// other replicas have sent to each endpoint, grouped by Model.
+	SetRemoteInFlight(inFlight map[string]map[string]int64)
+}
+
+// LeaderResolver finds where load reports should be sent.
+type LeaderResolver interface {
+	// LeaderAddr returns the "IP:Port" of the leader or self=true if this
+	// replica is the leader.
+	LeaderAddr(ctx context.Context) (addr string, self bool, err error)
+}
+
+func NewReporter(
+	replica string,
+	source LoadSource,
+	resolver LeaderResolver,
+	local *Aggregator,
+	interval time.Duration,
+) *Reporter {
+	return &Reporter{
+		replica:    replica,
+		source:     source,
+		resolver:   resolver,
+		local:      local,
+		interval:   interval,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Reporter periodically sends the load of this replica to the leader.
+type Reporter struct {
+	replica  string
+	source   LoadSource
+	resolver LeaderResolver
+	// local is the Aggregator of this replica, used when this replica is the leader.
+	local    *Aggregator
+	interval time.Duration
+
+	HTTPClient *http.Client
+}
+
+func (r *Reporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.report(ctx); err != nil {
+			log.Printf("Failed to report load to leader: %v", err)
+		}
+	}
+}
+
+func (r *Reporter) report(ctx context.Context) error {
+	report := Report{
+		Replica:   r.replica,
+		Timestamp: time.Now(),
+		Models:    r.source.LocalLoad(),
+	}
+
+	addr, self, err := r.resolver.LeaderAddr(ctx)
+	if err != nil {
+		return fmt.Errorf("resolving leader: %w", err)
+	}
+	if self {
+		r.local.Add(report)
+		r.source.SetRemoteInFlight(r.local.InFlightByEndpoint(r.replica))
+		return nil
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshalling report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s%s", addr, Path), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending report to %s: %w", addr, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		// Leaders of previous versions do not return feedback.
+		return nil
+	default:
+		return fmt.Errorf("sending report to %s: unexpected status code: %d", addr, resp.StatusCode)
+	}
+	var feedback Feedback
+	if err := json.NewDecoder(resp.Body).Decode(&feedback); err != nil {
+		return fmt.Errorf("decoding feedback from %s: %w", addr, err)
+	}
+	r.source.SetRemoteInFlight(feedback.InFlight)
+	return nil
+}
+
+// PodLeaderResolver resolves the leader by looking up the Pod that is named
+// after the identity of the current leader.
+type PodLeaderResolver struct {
+	Client    client.Reader
+	Namespace string
+	Election  *leader.Election
+	// Port is the port that the leader serves load reports on.
+	Port int
+}
+
+func (r *PodLeaderResolver) LeaderAddr(ctx context.Context) (string, bool, error) {
+	if r.Election.IsLeader.Load() {
+		return "", true, nil
+	}
+	id := r.Election.Leader()
+	if id == "" {
+		return "", false, errors.New("leader not yet observed")
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: id}, pod); err != nil {
+		return "", false, fmt.Errorf("getting leader pod %q: %w", id, err)
+	}
+	if pod.Status.PodIP == "" {
+		return "", false, fmt.Errorf("leader pod %q has no IP", id)
+	}
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(r.Port)), false, nil
+}
+
+// PodAuthenticator authenticates load reports by looking up the Pod that is
+// named after the reporting replica: it must have the IP that the report was
+// sent from and run as the same ServiceAccount as this replica.
+type PodAuthenticator struct {
+	Client    client.Reader
+	Namespace string
+	// Self is the name of the Pod of this replica.
+	Self string
+}
+
+func (a *PodAuthenticator) Authenticate(ctx context.Context, replica, remoteAddr string) error {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return fmt.Errorf("parsing remote address: %w", err)
+	}
+	self := &corev1.Pod{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Namespace: a.Namespace, Name: a.Self}, self); err != nil {
+		return fmt.Errorf("getting own pod %q: %w", a.Self, err)
+	}
+	pod := &corev1.Pod{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Namespace: a.Namespace, Name: replica}, pod); err != nil {
+		return fmt.Errorf("getting replica pod %q: %w", replica, err)
+	}
+	if pod.Status.PodIP == "" || pod.Status.PodIP != host {
+		return fmt.Errorf("replica pod %q has IP %q", replica, pod.Status.PodIP)
+	}
+	if pod.Spec.ServiceAccountName != self.Spec.ServiceAccountName {
+		return fmt.Errorf("replica pod %q runs as ServiceAccount %q", replica, pod.Spec.ServiceAccountName)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package loadreport
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testLoadSource struct {
+	local  map[string]ModelLoad
+	remote map[string]map[string]int64
+}
+
+func (s *testLoadSource) LocalLoad() map[string]ModelLoad { return s.local }
+
+func (s *testLoadSource) SetRemoteInFlight(inFlight map[string]map[string]int64) {
+	s.remote = inFlight
+}
+
+type testLeaderResolver struct {
+	addr string
+	self bool
+}
+
+func (r testLeaderResolver) LeaderAddr(context.Context) (string, bool, error) {
+	return r.addr, r.self, nil
+}
+
+func TestReporter(t *testing.T) {
+	load := &testLoadSource{local: map[string]ModelLoad{
+		"m1": {Active: 3, Queued: 1, Endpoints: map[string]int64{"10.0.0.1:8000": 3}},
+	}}
+	other := Report{Replica: "other", Models: map[string]ModelLoad{
+		"m1": {Active: 1, Endpoints: map[string]int64{"10.0.0.1:8000": 1}},
+	}}
+
+	t.Run("leader is self", func(t *testing.T) {
+		local := NewAggregator(time.Minute)
+		local.Add(other)
+		r := NewReporter("self", load, testLeaderResolver{self: true}, local, time.Second)
+		require.NoError(t, r.report(context.Background()))
+		require.ElementsMatch(t, []int64{1, 3}, local.ActiveRequestsByModel()["m1"])
+		require.Equal(t, map[string]map[string]int64{"m1": {"10.0.0.1:8000": 1}}, load.remote)
+	})
+
+	t.Run("leader is remote", func(t *testing.T) {
+		local := NewAggregator(time.Minute)
+		remote := NewAggregator(time.Minute)
+		remote.Add(other)
+		srv := httptest.NewServer(remote)
+		defer srv.Close()
+
+		r := NewReporter("follower", load, testLeaderResolver{addr: strings.TrimPrefix(srv.URL, "http://")}, local, time.Second)
+		require.NoError(t, r.report(context.Background()))
+		require.ElementsMatch(t, []int64{1, 3}, remote.ActiveRequestsByModel()["m1"])
+		require.Equal(t, 0, local.Replicas())
+		require.Equal(t, map[string]map[string]int64{"m1": {"10.0.0.1:8000": 1}}, load.remote)
+	})
+}
@@ -23,18 +23,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/leader"
 	"github.com/substratusai/kubeai/internal/loadbalancer"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"github.com/substratusai/kubeai/internal/messenger"
 	"github.com/substratusai/kubeai/internal/modelautoscaler"
 	"github.com/substratusai/kubeai/internal/modelclient"
@@ -121,9 +123,11 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 	//	tlsOpts = append(tlsOpts, disableHTTP2)
 	//}
 
-	//webhookServer := webhook.NewServer(webhook.Options{
-	//	TLSOpts: tlsOpts,
-	//})
+	webhookServer := webhook.NewServer(webhook.Options{
+		Port:    cfg.ModelWebhook.Port,
+		CertDir: cfg.ModelWebhook.CertDir,
+		//TLSOpts: tlsOpts,
+	})
 
 	// Metrics endpoint is enabled in 'config/default/kustomization.yaml'. The Metrics options configure the server.
 	// More info:
@@ -137,18 +141,31 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		SecureServing: false,
 	}
 
+	clientset, err := kubernetes.NewForConfig(k8sCfg)
+	if err != nil {
+		return fmt.Errorf("unable to create clientset: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("unable to get hostname: %w", err)
+	}
+	leaderElection := leader.NewElection(clientset, hostname, namespace,
+		cfg.LeaderElection.RetryPeriod.Duration,
+	)
+
 	mgr, err := ctrl.NewManager(k8sCfg, ctrl.Options{
-		Scheme:  Scheme,
-		Metrics: metricsServerOptions,
-		//WebhookServer:          webhookServer,
+		Scheme:                 Scheme,
+		Metrics:                metricsServerOptions,
+		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: cfg.HealthAddress,
-		// TODO: Consolidate controller and autoscaler leader election.
-		LeaderElection:          true,
-		LeaderElectionID:        "cc6bca10.substratus.ai",
-		LeaderElectionNamespace: namespace,
-		LeaseDuration:           ptr.To(cfg.LeaderElection.LeaseDuration.Duration),
-		RenewDeadline:           ptr.To(cfg.LeaderElection.RenewDeadline.Duration),
-		RetryPeriod:             ptr.To(cfg.LeaderElection.RetryPeriod.Duration),
+		// The controllers run on the leader of the KubeAI leader election,
+		// which aggregates the load reports that they act on.
+		LeaderElection:                      true,
+		LeaderElectionResourceLockInterface: leaderElection.Lock(),
+		LeaseDuration:                       ptr.To(cfg.LeaderElection.LeaseDuration.Duration),
+		RenewDeadline:                       ptr.To(cfg.LeaderElection.RenewDeadline.Duration),
+		RetryPeriod:                         ptr.To(cfg.LeaderElection.RetryPeriod.Duration),
 		Cache: cache.Options{
 			Scheme: Scheme, //mgr.GetScheme(),
 			DefaultNamespaces: map[string]cache.Config{
@@ -173,11 +190,6 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		return fmt.Errorf("unable to start manager: %w", err)
 	}
 
-	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
-	if err != nil {
-		return fmt.Errorf("unable to create clientset: %w", err)
-	}
-
 	podRESTClient, err := apiutil.RESTClientForGVK(schema.GroupVersionKind{
 		Group:   "",
 		Version: "v1",
@@ -194,26 +206,26 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		return fmt.Errorf("unable to create client: %w", err)
 	}
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		return fmt.Errorf("unable to get hostname: %w", err)
-	}
-	leaderElection := leader.NewElection(clientset, hostname, namespace,
-		cfg.LeaderElection.LeaseDuration.Duration,
-		cfg.LeaderElection.RenewDeadline.Duration,
-		cfg.LeaderElection.RetryPeriod.Duration,
-	)
-
 	loadBalancer, err := loadbalancer.New(mgr)
 	if err != nil {
 		return fmt.Errorf("unable to setup model resolver: %w", err)
 	}
 
+	loadReports := loadreport.NewAggregator(cfg.LoadReporting.StaleAfter.Duration)
+	if cfg.LoadReporting.AuthenticateReplicas {
+		loadReports.Authenticator = &loadreport.PodAuthenticator{
+			Client:    mgr.GetClient(),
+			Namespace: namespace,
+			Self:      hostname,
+		}
+	}
+
 	modelReconciler := &modelcontroller.ModelReconciler{
 		Client:                  mgr.GetClient(),
 		RESTConfig:              mgr.GetConfig(),
 		PodRESTClient:           podRESTClient,
 		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("kubeai-model-controller"),
 		Namespace:               namespace,
 		AllowPodAddressOverride: cfg.AllowPodAddressOverride,
 		SecretNames:             cfg.SecretNames,
@@ -223,6 +235,8 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		ModelServerPods:         cfg.ModelServerPods,
 		ModelLoaders:            cfg.ModelLoading,
 		ModelRollouts:           cfg.ModelRollouts,
+		LoadReports:             loadReports,
+		MaxConcurrentReconciles: cfg.ModelController.MaxConcurrentReconciles,
 		VLLMClient: &vllmclient.Client{
 			HTTPClient: &http.Client{Timeout: 10 * time.Second},
 		},
@@ -230,7 +244,49 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 	if err = modelReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create Model controller: %w", err)
 	}
+	if cfg.HasNodeLocalCacheProfiles() {
+		nodeCacheReconciler := &modelcontroller.NodeCacheReconciler{
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			Namespace:       namespace,
+			ModelReconciler: modelReconciler,
+		}
+		if err := nodeCacheReconciler.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create node cache controller: %w", err)
+		}
+	}
+	if cfg.HasSharedFilesystemCacheProfiles() {
+		sharedCacheReconciler := &modelcontroller.SharedCacheReconciler{
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			Namespace:       namespace,
+			ModelReconciler: modelReconciler,
+		}
+		if err := sharedCacheReconciler.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create shared cache controller: %w", err)
+		}
+		modelCacheReconciler := &modelcontroller.ModelCacheReconciler{
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			ModelReconciler: modelReconciler,
+		}
+		if err := modelCacheReconciler.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create model cache controller: %w", err)
+		}
+	}
+	if cfg.ModelWebhook.Enabled {
+		modelWebhook := &modelcontroller.ModelWebhook{
+			Reconciler:    modelReconciler,
+			DefaultImages: cfg.ModelWebhook.DefaultImages,
+		}
+		if err := modelWebhook.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create Model webhook: %w", err)
+		}
+	}
 	// +kubebuilder:scaffold:builder
+	if err := mgr.Add(manager.RunnableFunc(leaderElection.Lead)); err != nil {
+		return fmt.Errorf("unable to add leader: %w", err)
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up health check: %w", err)
@@ -238,6 +294,11 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up ready check: %w", err)
 	}
+	if cfg.ModelWebhook.Enabled {
+		if err := mgr.AddReadyzCheck("webhook", webhookServer.StartedChecker()); err != nil {
+			return fmt.Errorf("unable to set up webhook ready check: %w", err)
+		}
+	}
 
 	modelClient := modelclient.NewModelClient(mgr.GetClient(), namespace)
 
@@ -246,20 +307,30 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		return fmt.Errorf("unable to parse metrics port: %w", err)
 	}
 
-	modelAutoscaler, err := modelautoscaler.New(
-		ctx,
+	loadReporter := loadreport.NewReporter(
+		hostname,
+		loadBalancer,
+		&loadreport.PodLeaderResolver{
+			Client:    k8sClient,
+			Namespace: namespace,
+			Election:  leaderElection,
+			Port:      metricsPort,
+		},
+		loadReports,
+		cfg.LoadReporting.Interval.Duration,
+	)
+
+	modelAutoscaler := modelautoscaler.New(
 		k8sClient,
 		leaderElection,
 		modelClient,
-		loadBalancer,
+		loadReports,
+		mgr.GetEventRecorderFor("kubeai-autoscaler"),
 		cfg.ModelAutoscaling,
-		metricsPort,
-		types.NamespacedName{Name: cfg.ModelAutoscaling.StateConfigMapName, Namespace: namespace},
-		cfg.FixedSelfMetricAddrs,
+		cfg.ResourceProfiles,
+		namespace,
 	)
-	if err != nil {
-		return fmt.Errorf("unable to create model autoscaler: %w", err)
-	}
+	modelClient.SetCapacityChecker(modelAutoscaler)
 
 	modelProxy := modelproxy.NewHandler(modelClient, loadBalancer, 3, nil)
 	openaiHandler := openaiserver.NewHandler(mgr.GetClient(), modelProxy)
@@ -277,6 +348,7 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		Handler: metricsMux,
 	}
 	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsMux.Handle(loadreport.Path, loadReports)
 
 	httpClient := &http.Client{}
 
@@ -309,6 +381,15 @@ func Run(ctx context.Context, k8sCfg *rest.Config, cfg config.System) error {
 		modelAutoscaler.Start(ctx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer func() {
+			Log.Info("load reporter stopped")
+			wg.Done()
+		}()
+		loadReporter.Start(ctx)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer func() {
@@ -72,11 +72,12 @@ func NewMessenger(
 
 type ModelClient interface {
 	LookupModel(ctx context.Context, model, adapter string, selectors []string) (*v1.Model, error)
-	ScaleAtLeastOneReplica(ctx context.Context, model string) error
+	ScaleAtLeastOneReplica(ctx context.Context, model string) (bool, error)
 }
 
 type LoadBalancer interface {
 	AwaitBestAddress(ctx context.Context, req *apiutils.Request) (string, func(), error)
+	RecordResponse(model, addr string, failed bool)
 }
 
 func (m *Messenger) Start(ctx context.Context) error {
@@ -227,6 +228,9 @@ func (m *Messenger) handleRequest(ctx context.Context, msg *pubsub.Message) {
 	url := fmt.Sprintf("http://%s%s", host, mr.path)
 	log.Printf("Sending request to backend for message %s: %s", msg.LoggableID, url)
 	respPayload, respCode, err := m.sendBackendRequest(ctx, url, mr.Body)
+	if ctx.Err() == nil {
+		m.loadBalancer.RecordResponse(mr.Model, host, err != nil || respCode >= http.StatusInternalServerError)
+	}
 	if err != nil {
 		m.sendResponse(mr, m.jsonError("error sending request to backend: %v", err), http.StatusBadGateway)
 		return
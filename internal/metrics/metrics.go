@@ -2,7 +2,6 @@ package metrics
 
 import (
 	"fmt"
-	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -24,6 +23,8 @@ var (
 	InferenceRequestsHashLookupFinal                metric.Int64Counter
 	InferenceRequestsHashLookupDefaultMetricName    = "kubeai.inference.requests.hash.lookup.default"
 	InferenceRequestsHashLookupDefault              metric.Int64Counter
+	InferenceRequestsColdStartDurationMetricName    = "kubeai.inference.requests.cold_start.duration"
+	InferenceRequestsColdStartDuration              metric.Float64Histogram
 )
 
 // Attributes:
@@ -75,13 +76,14 @@ func Init(meter metric.Meter) error {
 		return fmt.Errorf("%s: %w", InferenceRequestsHashLookupDefaultMetricName, err)
 	}
 
-	return nil
-}
-
-func OtelNameToPromName(name string) string {
-	return strings.ReplaceAll(name, ".", "_")
-}
+	InferenceRequestsColdStartDuration, err = meter.Float64Histogram(InferenceRequestsColdStartDurationMetricName,
+		metric.WithDescription("The time that requests waited for a model with no ready replicas to become available"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", InferenceRequestsColdStartDurationMetricName, err)
+	}
 
-func OtelAttrToPromLabel(k attribute.Key) string {
-	return OtelNameToPromName(string(k))
+	return nil
 }
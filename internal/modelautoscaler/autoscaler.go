@@ -2,70 +2,43 @@ package modelautoscaler
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"math"
 	"sync"
 	"time"
 
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/config"
 	"github.com/substratusai/kubeai/internal/leader"
-	"github.com/substratusai/kubeai/internal/loadbalancer"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"github.com/substratusai/kubeai/internal/modelclient"
-	"github.com/substratusai/kubeai/internal/movingaverage"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func New(
-	ctx context.Context,
 	k8sClient client.Client,
 	leaderElection *leader.Election,
 	modelClient *modelclient.ModelClient,
-	resolver *loadbalancer.LoadBalancer,
+	loadReports *loadreport.Aggregator,
+	eventRecorder record.EventRecorder,
 	cfg config.ModelAutoscaling,
-	metricsPort int,
-	stateConfigMapRef types.NamespacedName,
-	fixedSelfMetricAddrs []string,
-) (*Autoscaler, error) {
-	a := &Autoscaler{
-		k8sClient:            k8sClient,
-		leaderElection:       leaderElection,
-		modelClient:          modelClient,
-		resolver:             resolver,
-		movingAvgByModel:     map[string]*movingaverage.Simple{},
-		cfg:                  cfg,
-		metricsPort:          metricsPort,
-		stateConfigMapRef:    stateConfigMapRef,
-		fixedSelfMetricAddrs: fixedSelfMetricAddrs,
+	resourceProfiles map[string]config.ResourceProfile,
+	namespace string,
+) *Autoscaler {
+	return &Autoscaler{
+		k8sClient:         k8sClient,
+		namespace:         namespace,
+		leaderElection:    leaderElection,
+		modelClient:       modelClient,
+		loadReports:       loadReports,
+		eventRecorder:     eventRecorder,
+		cfg:               cfg,
+		resourceProfiles:  resourceProfiles,
+		capacityDecisions: map[string]string{},
 	}
-
-	// Load preloaded moving averages from the last known state.
-	//
-	// NOTE: There is an edge case where this might load an empty state:
-	// 1. KubeAI running with active requests.
-	// 2. KubeAI saves request states.
-	// 3. KubeAI is restarted.
-	// 4. Leader election occurs and KubeAI saves request states before any requests come in (empty state).
-	// 5. KubeAI restarted again (before requests come in).
-	// 6. New KubeAI instance starts and loads the empty state.
-	// 7. Leader election occurs and KubeAI scales down Model replicas.
-	//
-	lastModelState, err := a.loadLastTotalModelState(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("loading last state of models: %w", err)
-	}
-	log.Printf("Loaded last state of models: %d total, last calculated on %s", len(lastModelState.Models), lastModelState.LastCalculationTime)
-	for m, s := range lastModelState.Models {
-		// Preload moving averages with the last known state.
-		// If the last known state was 5.5, the preloaded moving average
-		// would look like [5.5, 5.5, 5.5, ...].
-		preloaded := newPrefilledFloat64Slice(a.cfg.AverageWindowCount(), s.AverageActiveRequests)
-		a.movingAvgByModel[m] = movingaverage.NewSimple(preloaded)
-		log.Printf("Preloaded moving average for model %q with %v", m, preloaded)
-	}
-
-	return a, nil
 }
 
 // Autoscaler is responsible for making continuous adjustments to
@@ -74,21 +47,33 @@ func New(
 type Autoscaler struct {
 	k8sClient client.Client
 
-	stateConfigMapRef types.NamespacedName
+	// namespace is where the ModelAutoscalerState objects are stored.
+	namespace string
 
 	leaderElection *leader.Election
 
 	modelClient *modelclient.ModelClient
-	resolver    *loadbalancer.LoadBalancer
+	// loadReports holds the latest load reported by every KubeAI replica.
+	loadReports *loadreport.Aggregator
+
+	eventRecorder record.EventRecorder
 
 	cfg config.ModelAutoscaling
 
-	metricsPort int
+	// resourceProfiles are used to limit the replicas of Models
+	// when a ResourceProfile has a capacity budget.
+	resourceProfiles map[string]config.ResourceProfile
 
-	movingAvgByModelMtx sync.Mutex
-	movingAvgByModel    map[string]*movingaverage.Simple
+	capacityDecisionsMtx sync.Mutex
+	// map[<model-name>]<last-event-reason>
+	capacityDecisions map[string]string
 
-	fixedSelfMetricAddrs []string
+	// states is only accessed from the Start loop. It is (re)loaded
+	// from the API server whenever this replica becomes the leader
+	// so that autoscaling resumes where the previous leader stopped.
+	// map[<model-name>]*modelScaleState
+	states       map[string]*modelScaleState
+	statesLoaded bool
 }
 
 func (a *Autoscaler) Start(ctx context.Context) {
@@ -100,12 +85,20 @@ func (a *Autoscaler) Start(ctx context.Context) {
 		}
 		if !a.leaderElection.IsLeader.Load() {
 			log.Println("Not leader, doing nothing")
+			// Another replica might update the state while this replica is not the leader.
+			a.statesLoaded = false
 			continue
 		}
 
 		log.Println("Is leader, autoscaling")
 
-		// TODO: Remove hardcoded Service lookup by name "lingo".
+		if !a.statesLoaded {
+			if err := a.loadStates(ctx); err != nil {
+				log.Printf("Failed to load autoscaler state: %v", err)
+				continue
+			}
+			a.statesLoaded = true
+		}
 
 		models, err := a.modelClient.ListAllModels(ctx)
 		if err != nil {
@@ -113,76 +106,150 @@ func (a *Autoscaler) Start(ctx context.Context) {
 			continue
 		}
 
-		nextModelState := newTotalModelState()
-
-		var selfAddrs []string
-		if len(a.fixedSelfMetricAddrs) > 0 {
-			selfAddrs = a.fixedSelfMetricAddrs
-		} else {
-			selfIPs := a.resolver.GetSelfIPs()
-			for _, ip := range selfIPs {
-				selfAddrs = append(selfAddrs, fmt.Sprintf("%s:%d", ip, a.metricsPort))
-			}
-		}
-		if len(selfAddrs) == 0 {
-			log.Println("Unable to resolve KubeAI addresses, skipping")
-			continue
-		}
-
-		log.Printf("Aggregating metrics from KubeAI addresses %v", selfAddrs)
-		agg := newMetricsAggregation()
-		if err := aggregateAllMetrics(agg, selfAddrs, "/metrics"); err != nil {
-			log.Printf("Failed to aggregate metrics: %v", err)
+		replicas := a.loadReports.Replicas()
+		if replicas == 0 {
+			log.Println("No load reports received from KubeAI replicas, skipping")
 			continue
 		}
+		log.Printf("Aggregating load reports from %d KubeAI replicas", replicas)
+		activeRequestsByModel := a.loadReports.ActiveRequestsByModel()
 
+		now := time.Now()
+		desired := map[string]int32{}
 		for _, m := range models {
 			if m.Spec.AutoscalingDisabled {
 				log.Printf("Model %q has autoscaling disabled, skipping", m.Name)
 				continue
 			}
 
-			activeRequests := agg.activeRequestsByModel[m.Name]
+			activeRequests := activeRequestsByModel[m.Name]
 			var activeRequestSum int64
 			for _, req := range activeRequests {
 				activeRequestSum += req
 			}
 
-			avg := a.getMovingAvgActiveReqPerModel(m.Name)
-			avg.Next(float64(activeRequestSum))
-			avgActiveRequests := avg.Calculate()
-			normalized := avgActiveRequests / float64(*m.Spec.TargetRequests)
-			ceil := math.Ceil(normalized)
-			log.Printf("Calculated target replicas for model %q: ceil(%v/%v) = %v, current requests: sum(%v) = %v, history: %v",
-				m.Name, avgActiveRequests, *m.Spec.TargetRequests, ceil, activeRequests, activeRequestSum, avg.History())
-			a.modelClient.Scale(ctx, &m, int32(ceil), a.cfg.RequiredConsecutiveScaleDowns(*m.Spec.ScaleDownDelaySeconds))
-
-			nextModelState.Models[m.Name] = modelState{
-				AverageActiveRequests: avgActiveRequests,
+			s := a.getState(m.Name)
+			log.Printf("Active requests for model %q: sum(%v) = %v", m.Name, activeRequests, activeRequestSum)
+			desired[m.Name] = s.desiredReplicas(&m, activeRequestSum, now)
+		}
+
+		if err := a.applyCapacityBudgets(ctx, models, desired); err != nil {
+			log.Printf("Failed to apply capacity budgets: %v", err)
+			continue
+		}
+
+		for i := range models {
+			m := &models[i]
+			replicas, ok := desired[m.Name]
+			if !ok {
+				continue
+			}
+			s := a.getState(m.Name)
+			requiredConsecutiveScaleDowns := s.requiredConsecutiveScaleDowns(a.cfg, m)
+			if m.Spec.AutoscalingDryRun {
+				a.recordDryRun(m, s, replicas, requiredConsecutiveScaleDowns)
+			} else {
+				s.dryRunReplicas = nil
+				if err := a.modelClient.Scale(ctx, m, replicas, requiredConsecutiveScaleDowns, &s.consecutiveScaleDowns); err != nil {
+					log.Printf("Failed to scale model %q: %v", m.Name, err)
+				}
+			}
+
+			if err := a.saveState(ctx, m, s, now); err != nil {
+				log.Printf("Failed to save autoscaler state for model %q: %v", m.Name, err)
+				if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+					// Another replica updated the state, continue from the persisted state.
+					if err := a.refreshState(ctx, m.Name); err != nil {
+						log.Printf("Failed to refresh autoscaler state for model %q: %v", m.Name, err)
+						a.statesLoaded = false
+					}
+				}
+			}
+		}
+
+		// Forget about deleted Models, their state objects are garbage collected.
+		existing := make(map[string]struct{}, len(models))
+		for _, m := range models {
+			existing[m.Name] = struct{}{}
+		}
+		for name := range a.states {
+			if _, ok := existing[name]; !ok {
+				delete(a.states, name)
 			}
 		}
+	}
+}
+
+// recordDryRun records the scaling decision for a Model in dry-run mode
+// without applying it.
+func (a *Autoscaler) recordDryRun(m *kubeaiv1.Model, s *modelScaleState, replicas int32, requiredConsecutiveScaleDowns int) {
+	existing := s.currentReplicas(m)
+	next := modelclient.NextReplicas(m, existing, replicas, requiredConsecutiveScaleDowns, &s.consecutiveScaleDowns)
+	if next != existing {
+		log.Printf("Dry run: would scale model %q from %d to %d replicas", m.Name, existing, next)
+		a.eventRecorder.Eventf(m, corev1.EventTypeNormal, eventReasonDryRunScale,
+			"Autoscaler would scale from %d to %d replicas (dry run)", existing, next)
+	}
+	s.dryRunReplicas = &next
+}
 
-		if err := a.saveTotalModelState(ctx, nextModelState); err != nil {
-			log.Printf("Failed to save model state: %v", err)
+// desiredReplicas runs one iteration of the autoscaling algorithm for the Model
+// and returns the desired number of replicas, within the replica bounds of the Model.
+func (s *modelScaleState) desiredReplicas(m *kubeaiv1.Model, activeRequests int64, now time.Time) int32 {
+	lastActive := s.observeActivity(m, activeRequests, now)
+
+	if m.Spec.IdleTimeoutSeconds != nil && now.Sub(lastActive) >= time.Duration(*m.Spec.IdleTimeoutSeconds)*time.Second {
+		// Forget the request history so that the Model is not scaled
+		// back up by the moving average.
+		s.avg.Reset()
+		s.idle = true
+		if s.currentReplicas(m) > 0 {
+			log.Printf("Model %q has been idle since %s (idle timeout: %ds), scaling to zero",
+				m.Name, lastActive.Format(time.RFC3339), *m.Spec.IdleTimeoutSeconds)
 		}
+		return 0
 	}
+	s.idle = false
+
+	s.avg.Next(float64(activeRequests))
+	avgActiveRequests := s.avg.Calculate()
+	normalized := avgActiveRequests / float64(*m.Spec.TargetRequests)
+	ceil := math.Ceil(normalized)
+	log.Printf("Calculated target replicas for model %q: ceil(%v/%v) = %v, history: %v",
+		m.Name, avgActiveRequests, *m.Spec.TargetRequests, ceil, s.avg.History())
+	return modelclient.EnforceReplicaBounds(int32(ceil), m)
 }
 
-func (a *Autoscaler) getMovingAvgActiveReqPerModel(model string) *movingaverage.Simple {
-	a.movingAvgByModelMtx.Lock()
-	avg, ok := a.movingAvgByModel[model]
-	if !ok {
-		avg = movingaverage.NewSimple(make([]float64, a.cfg.AverageWindowCount()))
-		a.movingAvgByModel[model] = avg
+// requiredConsecutiveScaleDowns returns the number of consecutive scale down
+// decisions that are required before the Model is scaled down.
+func (s *modelScaleState) requiredConsecutiveScaleDowns(cfg config.ModelAutoscaling, m *kubeaiv1.Model) int {
+	if s.idle {
+		// The idle timeout already accounts for the scale down delay.
+		return 0
+	}
+	return cfg.RequiredConsecutiveScaleDowns(*m.Spec.ScaleDownDelaySeconds)
+}
+
+// currentReplicas returns the number of replicas of the Model as seen by
+// the autoscaler. In dry-run mode, this is the last recorded decision.
+func (s *modelScaleState) currentReplicas(m *kubeaiv1.Model) int32 {
+	if m.Spec.AutoscalingDryRun && s.dryRunReplicas != nil {
+		return *s.dryRunReplicas
+	}
+	if m.Spec.Replicas != nil {
+		return *m.Spec.Replicas
 	}
-	a.movingAvgByModelMtx.Unlock()
-	return avg
+	return 0
 }
 
-func newPrefilledFloat64Slice(length int, value float64) []float64 {
-	s := make([]float64, length)
-	for i := range s {
-		s[i] = value
+// observeActivity records whether the Model is currently active and
+// returns the last time that it was active.
+func (s *modelScaleState) observeActivity(m *kubeaiv1.Model, activeRequests int64, now time.Time) time.Time {
+	// A Model that was scaled to zero after being idle, but has replicas now
+	// was scaled from zero by a request that has not been reported yet.
+	scaledFromZero := s.idle && s.currentReplicas(m) > 0
+	if s.lastActive.IsZero() || activeRequests > 0 || scaledFromZero {
+		s.lastActive = now
 	}
-	return s
+	return s.lastActive
 }
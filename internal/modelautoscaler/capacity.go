@@ -0,0 +1,330 @@
+package modelautoscaler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	eventReasonCapacityLimited   = "CapacityLimited"
+	eventReasonCapacityAvailable = "CapacityAvailable"
+	eventReasonDryRunScale       = "DryRunScale"
+)
+
+// capacityDemand is the number of replicas that a Model would like to have
+// in a ResourceProfile with a limited capacity.
+type capacityDemand struct {
+	model string
+	// units consumed per replica.
+	units      int32
+	desired    int32
+	min        int32
+	guaranteed int32
+	priority   int32
+	weight     int32
+}
+
+// allocateCapacity distributes the budget (in units) across the demands and
+// returns the number of replicas allocated to each demand (same order as demands).
+//
+// Allocation happens in phases:
+//  1. MinReplicas are always allocated, even if they exceed the budget.
+//  2. Guaranteed replicas are allocated, highest priority first.
+//  3. The remaining budget is allocated to the highest priority Models first,
+//     Models of equal priority share it according to their weights.
+func allocateCapacity(budget int32, demands []capacityDemand) []int32 {
+	alloc := make([]int32, len(demands))
+	remaining := budget
+
+	order := make([]int, len(demands))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		di, dj := demands[order[i]], demands[order[j]]
+		if di.priority != dj.priority {
+			return di.priority > dj.priority
+		}
+		return di.model < dj.model
+	})
+
+	for _, i := range order {
+		d := demands[i]
+		alloc[i] = min(d.min, d.desired)
+		remaining -= alloc[i] * d.units
+	}
+
+	for _, i := range order {
+		d := demands[i]
+		for alloc[i] < min(d.guaranteed, d.desired) && d.units <= remaining {
+			alloc[i]++
+			remaining -= d.units
+		}
+	}
+
+	for start := 0; start < len(order); {
+		// Find all demands in the same priority tier.
+		end := start
+		for end < len(order) && demands[order[end]].priority == demands[order[start]].priority {
+			end++
+		}
+		tier := order[start:end]
+		for {
+			best := -1
+			for _, i := range tier {
+				d := demands[i]
+				if alloc[i] >= d.desired || d.units > remaining {
+					continue
+				}
+				// Pick the demand with the lowest allocation relative to its weight.
+				if best == -1 || int64(alloc[i])*int64(demands[best].weight) < int64(alloc[best])*int64(d.weight) {
+					best = i
+				}
+			}
+			if best == -1 {
+				break
+			}
+			alloc[best]++
+			remaining -= demands[best].units
+		}
+		start = end
+	}
+
+	return alloc
+}
+
+// applyCapacityBudgets limits the desired replicas of Models whose ResourceProfile
+// has a capacity budget. desired is updated in place.
+func (a *Autoscaler) applyCapacityBudgets(ctx context.Context, models []kubeaiv1.Model, desired map[string]int32) error {
+	type profileDemands struct {
+		// fixed is the number of units used by Models that are not autoscaled.
+		fixed   int32
+		demands []capacityDemand
+	}
+	byProfile := map[string]*profileDemands{}
+	modelsByName := map[string]*kubeaiv1.Model{}
+	priorities := map[string]int32{}
+
+	for i := range models {
+		m := &models[i]
+		profileName, units, err := parseResourceProfile(m.Spec.ResourceProfile)
+		if err != nil {
+			log.Printf("Model %q: %v, skipping capacity allocation", m.Name, err)
+			continue
+		}
+		profile, ok := a.resourceProfiles[profileName]
+		if !ok || profile.Capacity == nil {
+			continue
+		}
+		pd, ok := byProfile[profileName]
+		if !ok {
+			pd = &profileDemands{}
+			byProfile[profileName] = pd
+		}
+
+		want, autoscaled := desired[m.Name]
+		if !autoscaled {
+			if m.Spec.Replicas != nil {
+				pd.fixed += *m.Spec.Replicas * units
+			}
+			continue
+		}
+
+		priority, ok := priorities[m.Spec.PriorityClassName]
+		if !ok {
+			priority, err = a.lookupPriority(ctx, m.Spec.PriorityClassName)
+			if err != nil {
+				return fmt.Errorf("looking up priority of model %q: %w", m.Name, err)
+			}
+			priorities[m.Spec.PriorityClassName] = priority
+		}
+		weight := m.Spec.Capacity.Weight
+		if weight < 1 {
+			weight = 1
+		}
+		modelsByName[m.Name] = m
+		pd.demands = append(pd.demands, capacityDemand{
+			model:      m.Name,
+			units:      units,
+			desired:    want,
+			min:        m.Spec.MinReplicas,
+			guaranteed: m.Spec.Capacity.GuaranteedReplicas,
+			priority:   priority,
+			weight:     weight,
+		})
+	}
+
+	for profileName, pd := range byProfile {
+		budget, err := a.capacityBudget(ctx, a.resourceProfiles[profileName])
+		if err != nil {
+			return fmt.Errorf("calculating capacity budget for resource profile %q: %w", profileName, err)
+		}
+
+		alloc := allocateCapacity(budget-pd.fixed, pd.demands)
+
+		var summary []string
+		for i, d := range pd.demands {
+			summary = append(summary, fmt.Sprintf("%s=%dx%d (priority %d, weight %d)", d.model, alloc[i], d.units, d.priority, d.weight))
+		}
+		for i, d := range pd.demands {
+			desired[d.model] = alloc[i]
+			var reason, msg string
+			if alloc[i] < d.desired {
+				reason = eventReasonCapacityLimited
+				msg = fmt.Sprintf("Allocated %d of %d desired replicas: ResourceProfile %q capacity of %d units is exhausted (%d units used by Models that are not autoscaled), allocations: %s",
+					alloc[i], d.desired, profileName, budget, pd.fixed, strings.Join(summary, ", "))
+			} else {
+				reason = eventReasonCapacityAvailable
+				msg = fmt.Sprintf("Allocated all %d desired replicas from ResourceProfile %q", d.desired, profileName)
+			}
+			a.recordCapacityDecision(modelsByName[d.model], reason, msg)
+		}
+	}
+
+	return nil
+}
+
+// HasCapacityForReplica returns whether one more replica of the Model fits into
+// the capacity budget of its ResourceProfile next to the current replicas of
+// all Models of the profile. It is used to scale Models from zero, the
+// allocation by priority is left to the next autoscaling interval.
+func (a *Autoscaler) HasCapacityForReplica(ctx context.Context, model *kubeaiv1.Model) (bool, error) {
+	profileName, units, err := parseResourceProfile(model.Spec.ResourceProfile)
+	if err != nil {
+		return false, err
+	}
+	profile, ok := a.resourceProfiles[profileName]
+	if !ok || profile.Capacity == nil {
+		return true, nil
+	}
+	budget, err := a.capacityBudget(ctx, profile)
+	if err != nil {
+		return false, fmt.Errorf("calculating capacity budget for resource profile %q: %w", profileName, err)
+	}
+
+	models, err := a.modelClient.ListAllModels(ctx)
+	if err != nil {
+		return false, err
+	}
+	var used int32
+	for _, m := range models {
+		name, mUnits, err := parseResourceProfile(m.Spec.ResourceProfile)
+		if err != nil || name != profileName || m.Name == model.Name {
+			continue
+		}
+		if m.Spec.Replicas != nil {
+			used += *m.Spec.Replicas * mUnits
+		}
+	}
+	return used+units <= budget, nil
+}
+
+// recordCapacityDecision emits an event on the Model when the capacity
+// decision for the Model changes.
+func (a *Autoscaler) recordCapacityDecision(m *kubeaiv1.Model, reason, msg string) {
+	a.capacityDecisionsMtx.Lock()
+	last, ok := a.capacityDecisions[m.Name]
+	a.capacityDecisions[m.Name] = reason
+	a.capacityDecisionsMtx.Unlock()
+
+	if last == reason || (!ok && reason == eventReasonCapacityAvailable) {
+		return
+	}
+	log.Printf("Model %q: %s", m.Name, msg)
+	if a.eventRecorder != nil {
+		a.eventRecorder.Event(m, corev1.EventTypeNormal, reason, msg)
+	}
+}
+
+func (a *Autoscaler) lookupPriority(ctx context.Context, priorityClassName string) (int32, error) {
+	if priorityClassName == "" {
+		return 0, nil
+	}
+	pc := &schedulingv1.PriorityClass{}
+	if err := a.k8sClient.Get(ctx, types.NamespacedName{Name: priorityClassName}, pc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return pc.Value, nil
+}
+
+// capacityBudget returns the number of units available in the given profile.
+func (a *Autoscaler) capacityBudget(ctx context.Context, profile config.ResourceProfile) (int32, error) {
+	if profile.Capacity.Units != nil {
+		return *profile.Capacity.Units, nil
+	}
+	if !profile.Capacity.DiscoverFromNodes {
+		return 0, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := a.k8sClient.List(ctx, nodes); err != nil {
+		return 0, fmt.Errorf("listing nodes: %w", err)
+	}
+	selector := labels.SelectorFromSet(profile.NodeSelector)
+	var total int32
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		total += nodeUnits(node, profile)
+	}
+	return total, nil
+}
+
+// nodeUnits returns the number of units of the given profile that fit
+// into the allocatable resources of the Node.
+func nodeUnits(node corev1.Node, profile config.ResourceProfile) int32 {
+	perUnit := profile.Limits
+	if len(perUnit) == 0 {
+		perUnit = profile.Requests
+	}
+	if len(perUnit) == 0 {
+		return 0
+	}
+	units := int64(-1)
+	for name, q := range perUnit {
+		if q.IsZero() {
+			continue
+		}
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			return 0
+		}
+		n := allocatable.MilliValue() / q.MilliValue()
+		if units == -1 || n < units {
+			units = n
+		}
+	}
+	if units < 0 {
+		return 0
+	}
+	return int32(units)
+}
+
+// parseResourceProfile parses "<name>:<multiple>".
+func parseResourceProfile(s string) (string, int32, error) {
+	name, multipleStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid resource profile: %q", s)
+	}
+	multiple, err := strconv.Atoi(multipleStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid resource profile multiple: %q: %w", multipleStr, err)
+	}
+	return name, int32(multiple), nil
+}
@@ -0,0 +1,177 @@
+package modelautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/modelclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAllocateCapacity(t *testing.T) {
+	cases := map[string]struct {
+		budget   int32
+		demands  []capacityDemand
+		expAlloc []int32
+	}{
+		"enough capacity": {
+			budget: 10,
+			demands: []capacityDemand{
+				{model: "a", units: 1, desired: 3, weight: 1},
+				{model: "b", units: 2, desired: 3, weight: 1},
+			},
+			expAlloc: []int32{3, 3},
+		},
+		"min replicas exceed budget": {
+			budget: 2,
+			demands: []capacityDemand{
+				{model: "a", units: 1, desired: 3, min: 3, weight: 1},
+				{model: "b", units: 1, desired: 3, weight: 1},
+			},
+			expAlloc: []int32{3, 0},
+		},
+		"higher priority first": {
+			budget: 4,
+			demands: []capacityDemand{
+				{model: "low", units: 1, desired: 4, weight: 1},
+				{model: "high", units: 1, desired: 3, priority: 100, weight: 1},
+			},
+			expAlloc: []int32{1, 3},
+		},
+		"guaranteed replicas before higher priority": {
+			budget: 4,
+			demands: []capacityDemand{
+				{model: "low", units: 1, desired: 4, guaranteed: 2, weight: 1},
+				{model: "high", units: 1, desired: 4, priority: 100, weight: 1},
+			},
+			expAlloc: []int32{2, 2},
+		},
+		"weighted share within priority": {
+			budget: 6,
+			demands: []capacityDemand{
+				{model: "a", units: 1, desired: 10, weight: 2},
+				{model: "b", units: 1, desired: 10, weight: 1},
+			},
+			expAlloc: []int32{4, 2},
+		},
+		"leftover goes to models that still fit": {
+			budget: 5,
+			demands: []capacityDemand{
+				{model: "a", units: 4, desired: 2, weight: 1},
+				{model: "b", units: 1, desired: 1, weight: 1},
+			},
+			expAlloc: []int32{1, 1},
+		},
+		"negative budget": {
+			budget: -2,
+			demands: []capacityDemand{
+				{model: "a", units: 1, desired: 2, min: 1, weight: 1},
+			},
+			expAlloc: []int32{1},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expAlloc, allocateCapacity(c.budget, c.demands))
+		})
+	}
+}
+
+func TestNodeUnits(t *testing.T) {
+	node := corev1.Node{
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				"nvidia.com/gpu":      resource.MustParse("8"),
+				corev1.ResourceCPU:    resource.MustParse("30"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		profile  config.ResourceProfile
+		expUnits int32
+	}{
+		"limits": {
+			profile: config.ResourceProfile{
+				Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+			},
+			expUnits: 8,
+		},
+		"requests when no limits": {
+			profile: config.ResourceProfile{
+				Requests: corev1.ResourceList{
+					"nvidia.com/gpu":   resource.MustParse("1"),
+					corev1.ResourceCPU: resource.MustParse("6"),
+				},
+			},
+			expUnits: 5,
+		},
+		"missing resource": {
+			profile: config.ResourceProfile{
+				Limits: corev1.ResourceList{"google.com/tpu": resource.MustParse("1")},
+			},
+			expUnits: 0,
+		},
+		"no resources": {
+			profile:  config.ResourceProfile{},
+			expUnits: 0,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expUnits, nodeUnits(node, c.profile))
+		})
+	}
+}
+
+func TestHasCapacityForReplica(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubeaiv1.AddToScheme(scheme))
+	model := func(name, profile string, replicas int32) *kubeaiv1.Model {
+		return &kubeaiv1.Model{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       kubeaiv1.ModelSpec{ResourceProfile: profile, Replicas: ptr.To(replicas)},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		model("a", "gpu:2", 1),
+		model("b", "gpu:1", 1),
+		model("c", "other:4", 3),
+	).Build()
+	a := &Autoscaler{
+		k8sClient:   k8sClient,
+		modelClient: modelclient.NewModelClient(k8sClient, "default"),
+		resourceProfiles: map[string]config.ResourceProfile{
+			"gpu":       {Capacity: &config.ResourceProfileCapacity{Units: ptr.To[int32](4)}},
+			"unlimited": {},
+		},
+	}
+
+	cases := map[string]struct {
+		model   *kubeaiv1.Model
+		expFits bool
+	}{
+		"fits into the remaining budget":                   {model: model("new", "gpu:1", 0), expFits: true},
+		"exceeds the remaining budget":                     {model: model("new", "gpu:2", 0), expFits: false},
+		"profile without capacity":                         {model: model("new", "unlimited:8", 0), expFits: true},
+		"the replicas of the model itself are not counted": {model: model("a", "gpu:2", 1), expFits: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			fits, err := a.HasCapacityForReplica(context.Background(), c.model)
+			require.NoError(t, err)
+			require.Equal(t, c.expFits, fits)
+		})
+	}
+}
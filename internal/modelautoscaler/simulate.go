@@ -0,0 +1,347 @@
+package modelautoscaler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/modelclient"
+	"github.com/substratusai/kubeai/internal/movingaverage"
+	"k8s.io/utils/ptr"
+)
+
+// Sample is the total number of active requests for a Model at a point in time.
+type Sample struct {
+	Time           time.Time
+	ActiveRequests int64
+}
+
+// SimulationOptions configure the parts of a simulation that are
+// not covered by the autoscaling algorithm itself.
+type SimulationOptions struct {
+	// StartupDelay is the time it takes for a new replica to become ready.
+	StartupDelay time.Duration
+	// GPUsPerReplica is used to calculate GPU-hours.
+	GPUsPerReplica float64
+	// ConcurrencyPerReplica is the number of requests that a ready replica
+	// processes at once, additional requests are considered queued.
+	// Defaults to the TargetRequests of the Model.
+	ConcurrencyPerReplica int64
+}
+
+// SimulationStep is the outcome of a single autoscaling interval.
+type SimulationStep struct {
+	Time                  time.Time `json:"time"`
+	ActiveRequests        int64     `json:"activeRequests"`
+	AverageActiveRequests float64   `json:"averageActiveRequests"`
+	// DesiredReplicas is the result of the algorithm before the scale down delay.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// Replicas is the number of replicas that the Model is scaled to.
+	Replicas      int32 `json:"replicas"`
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// QueuedRequests is the estimated number of requests that are waiting
+	// for a ready replica.
+	QueuedRequests int64 `json:"queuedRequests"`
+}
+
+// SimulationResult is the outcome of replaying a time series of active
+// requests through the autoscaling algorithm.
+type SimulationResult struct {
+	Steps        []SimulationStep `json:"steps"`
+	ScaleUps     int              `json:"scaleUps"`
+	ScaleDowns   int              `json:"scaleDowns"`
+	ReplicaHours float64          `json:"replicaHours"`
+	GPUHours     float64          `json:"gpuHours"`
+	// QueuedRequestSeconds is the estimated sum of the time that requests
+	// spent waiting for a ready replica.
+	QueuedRequestSeconds float64 `json:"queuedRequestSeconds"`
+	MaxQueuedRequests    int64   `json:"maxQueuedRequests"`
+	// QueueingDuration is the total time during which requests were queued.
+	QueueingDuration time.Duration `json:"queueingDuration"`
+}
+
+// Simulate replays the given samples through the autoscaling algorithm that
+// is used for live Models, including replica bounds, the scale down delay,
+// the idle timeout and scale-from-zero on incoming requests.
+// Samples are evaluated at every autoscaling interval, the most recent sample
+// at that time is used. Capacity budgets of ResourceProfiles are not simulated.
+func Simulate(cfg config.ModelAutoscaling, model *kubeaiv1.Model, samples []Sample, opts SimulationOptions) (*SimulationResult, error) {
+	if cfg.Interval.Duration <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if cfg.AverageWindowCount() < 1 {
+		return nil, errors.New("time window must be at least one interval")
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("no samples")
+	}
+	samples = sortedSamples(samples)
+
+	m := model.DeepCopy()
+	// Apply the defaults of the Model CRD.
+	if m.Spec.TargetRequests == nil {
+		m.Spec.TargetRequests = ptr.To[int32](100)
+	}
+	if m.Spec.ScaleDownDelaySeconds == nil {
+		m.Spec.ScaleDownDelaySeconds = ptr.To[int64](30)
+	}
+	if m.Spec.Replicas == nil {
+		m.Spec.Replicas = ptr.To(m.Spec.MinReplicas)
+	}
+	// The simulation decides on replicas like a live Model would.
+	m.Spec.AutoscalingDryRun = false
+
+	concurrency := opts.ConcurrencyPerReplica
+	if concurrency <= 0 {
+		concurrency = int64(*m.Spec.TargetRequests)
+	}
+
+	s := &modelScaleState{
+		avg: movingaverage.NewSimple(make([]float64, cfg.AverageWindowCount())),
+	}
+	// readyAt holds the time at which each replica becomes ready, oldest first.
+	readyAt := make([]time.Time, *m.Spec.Replicas)
+	for i := range readyAt {
+		readyAt[i] = samples[0].Time
+	}
+
+	result := &SimulationResult{}
+	interval := cfg.Interval.Duration
+	sampleIdx := 0
+	for now := samples[0].Time; !now.After(samples[len(samples)-1].Time); now = now.Add(interval) {
+		for sampleIdx+1 < len(samples) && !samples[sampleIdx+1].Time.After(now) {
+			sampleIdx++
+		}
+		active := samples[sampleIdx].ActiveRequests
+
+		step := SimulationStep{Time: now, ActiveRequests: active}
+
+		// Requests scale Models from zero without waiting for the autoscaler.
+		if !m.Spec.AutoscalingDisabled && active > 0 && *m.Spec.Replicas == 0 {
+			readyAt = scaleReplicas(readyAt, 1, now.Add(opts.StartupDelay))
+			m.Spec.Replicas = ptr.To[int32](1)
+			result.ScaleUps++
+		}
+
+		if !m.Spec.AutoscalingDisabled {
+			existing := *m.Spec.Replicas
+			step.DesiredReplicas = s.desiredReplicas(m, active, now)
+			step.AverageActiveRequests = s.avg.Calculate()
+			next := modelclient.NextReplicas(m, existing, step.DesiredReplicas, s.requiredConsecutiveScaleDowns(cfg, m), &s.consecutiveScaleDowns)
+			if next > existing {
+				result.ScaleUps++
+			} else if next < existing {
+				result.ScaleDowns++
+			}
+			readyAt = scaleReplicas(readyAt, next, now.Add(opts.StartupDelay))
+			m.Spec.Replicas = ptr.To(next)
+		} else {
+			step.DesiredReplicas = *m.Spec.Replicas
+		}
+
+		step.Replicas = *m.Spec.Replicas
+		for _, t := range readyAt {
+			if !t.After(now) {
+				step.ReadyReplicas++
+			}
+		}
+		step.QueuedRequests = max(0, active-int64(step.ReadyReplicas)*concurrency)
+
+		hours := interval.Hours()
+		result.ReplicaHours += float64(step.Replicas) * hours
+		result.GPUHours += float64(step.Replicas) * opts.GPUsPerReplica * hours
+		result.QueuedRequestSeconds += float64(step.QueuedRequests) * interval.Seconds()
+		result.MaxQueuedRequests = max(result.MaxQueuedRequests, step.QueuedRequests)
+		if step.QueuedRequests > 0 {
+			result.QueueingDuration += interval
+		}
+
+		result.Steps = append(result.Steps, step)
+	}
+
+	return result, nil
+}
+
+// scaleReplicas adds replicas that become ready at the given time or removes
+// replicas (the ones that became ready last first) to match the given number of replicas.
+func scaleReplicas(readyAt []time.Time, replicas int32, ready time.Time) []time.Time {
+	for int32(len(readyAt)) < replicas {
+		readyAt = append(readyAt, ready)
+	}
+	return readyAt[:replicas]
+}
+
+func sortedSamples(samples []Sample) []Sample {
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+	return sorted
+}
+
+// ParseSamplesCSV parses samples from CSV with a header row and the columns
+// "timestamp", "model" and "active_requests". Timestamps are either RFC 3339
+// or Unix seconds. Rows of the same Model with the same timestamp (i.e. from
+// different KubeAI replicas) are summed up. Samples are returned by Model.
+func ParseSamplesCSV(r io.Reader) (map[string][]Sample, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"timestamp", "model", "active_requests"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	totals := map[string]map[time.Time]int64{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading record: %w", err)
+		}
+		ts, err := parseTimestamp(record[columns["timestamp"]])
+		if err != nil {
+			return nil, err
+		}
+		active, err := strconv.ParseFloat(strings.TrimSpace(record[columns["active_requests"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing active requests: %w", err)
+		}
+		addSample(totals, strings.TrimSpace(record[columns["model"]]), ts, active)
+	}
+
+	return samplesByModel(totals), nil
+}
+
+// ParsePrometheusMatrix parses samples from the JSON response of a Prometheus
+// range query (/api/v1/query_range). The Model is read from the given label.
+// Series of the same Model (i.e. from different KubeAI replicas) are summed up.
+// Samples are returned by Model.
+func ParsePrometheusMatrix(r io.Reader, modelLabel string) (map[string][]Sample, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Metric map[string]string `json:"metric"`
+				Values [][2]any          `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decoding prometheus response: %w", err)
+	}
+	if resp.Status != "" && resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus response status: %q", resp.Status)
+	}
+	if resp.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unsupported prometheus result type %q, expected \"matrix\"", resp.Data.ResultType)
+	}
+
+	totals := map[string]map[time.Time]int64{}
+	for _, series := range resp.Data.Result {
+		model, ok := series.Metric[modelLabel]
+		if !ok {
+			return nil, fmt.Errorf("series without label %q: %v", modelLabel, series.Metric)
+		}
+		for _, v := range series.Values {
+			unix, ok := v[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("unexpected timestamp: %v", v[0])
+			}
+			valueStr, ok := v[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected value: %v", v[1])
+			}
+			value, err := strconv.ParseFloat(valueStr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing value: %w", err)
+			}
+			addSample(totals, model, unixTime(unix), value)
+		}
+	}
+
+	return samplesByModel(totals), nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if unix, err := strconv.ParseFloat(s, 64); err == nil {
+		return unixTime(unix), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing timestamp %q: %w", s, err)
+	}
+	return t, nil
+}
+
+func unixTime(unix float64) time.Time {
+	return time.UnixMilli(int64(unix * 1000)).UTC()
+}
+
+func addSample(totals map[string]map[time.Time]int64, model string, t time.Time, active float64) {
+	if totals[model] == nil {
+		totals[model] = map[time.Time]int64{}
+	}
+	totals[model][t] += int64(math.Round(active))
+}
+
+func samplesByModel(totals map[string]map[time.Time]int64) map[string][]Sample {
+	result := make(map[string][]Sample, len(totals))
+	for model, byTime := range totals {
+		samples := make([]Sample, 0, len(byTime))
+		for t, active := range byTime {
+			samples = append(samples, Sample{Time: t, ActiveRequests: active})
+		}
+		result[model] = sortedSamples(samples)
+	}
+	return result
+}
+
+// GPUsPerReplica returns the number of accelerators (GPUs or TPUs) used by
+// a replica of the Model according to its ResourceProfile.
+func GPUsPerReplica(model *kubeaiv1.Model, profiles map[string]config.ResourceProfile) (float64, error) {
+	if model.Spec.ResourceProfile == "" {
+		return 0, nil
+	}
+	name, multiple, err := parseResourceProfile(model.Spec.ResourceProfile)
+	if err != nil {
+		return 0, err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return 0, fmt.Errorf("resource profile not found: %q", name)
+	}
+	resources := profile.Limits
+	if len(resources) == 0 {
+		resources = profile.Requests
+	}
+	var gpus float64
+	for resourceName, q := range resources {
+		if strings.HasSuffix(string(resourceName), "/gpu") || strings.HasSuffix(string(resourceName), "/tpu") {
+			gpus += q.AsApproximateFloat64()
+		}
+	}
+	return gpus * float64(multiple), nil
+}
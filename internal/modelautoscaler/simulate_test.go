@@ -0,0 +1,173 @@
+package modelautoscaler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestSimulate(t *testing.T) {
+	cfg := config.ModelAutoscaling{
+		Interval:   config.Duration{Duration: 10 * time.Second},
+		TimeWindow: config.Duration{Duration: 30 * time.Second},
+	}
+	model := &kubeaiv1.Model{}
+	model.Spec.MaxReplicas = ptr.To[int32](10)
+	model.Spec.TargetRequests = ptr.To[int32](10)
+	model.Spec.ScaleDownDelaySeconds = ptr.To[int64](20)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start.Add(60 * time.Second), ActiveRequests: 0},
+		{Time: start, ActiveRequests: 25},
+		{Time: start.Add(150 * time.Second), ActiveRequests: 0},
+	}
+
+	result, err := Simulate(cfg, model, samples, SimulationOptions{
+		StartupDelay:   20 * time.Second,
+		GPUsPerReplica: 2,
+	})
+	require.NoError(t, err)
+
+	var replicas, ready []int32
+	var queued []int64
+	for _, s := range result.Steps {
+		replicas = append(replicas, s.Replicas)
+		ready = append(ready, s.ReadyReplicas)
+		queued = append(queued, s.QueuedRequests)
+	}
+	// Scaled from zero by the first request, scaled down after 2 consecutive scale downs.
+	require.Equal(t, []int32{1, 2, 3, 3, 3, 3, 3, 3, 0, 0, 0, 0, 0, 0, 0, 0}, replicas)
+	require.Equal(t, []int32{0, 0, 1, 2, 3, 3, 3, 3, 0, 0, 0, 0, 0, 0, 0, 0}, ready)
+	require.Equal(t, []int64{25, 25, 15, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, queued)
+
+	require.Equal(t, 3, result.ScaleUps)
+	require.Equal(t, 1, result.ScaleDowns)
+	require.InDelta(t, 210.0/3600, result.ReplicaHours, 1e-9)
+	require.InDelta(t, 2*210.0/3600, result.GPUHours, 1e-9)
+	require.InDelta(t, 700, result.QueuedRequestSeconds, 1e-9)
+	require.Equal(t, int64(25), result.MaxQueuedRequests)
+	require.Equal(t, 40*time.Second, result.QueueingDuration)
+
+	// The given Model is not modified.
+	require.Nil(t, model.Spec.Replicas)
+}
+
+func TestSimulateIdleTimeout(t *testing.T) {
+	cfg := config.ModelAutoscaling{
+		Interval:   config.Duration{Duration: 10 * time.Second},
+		TimeWindow: config.Duration{Duration: 60 * time.Second},
+	}
+	model := &kubeaiv1.Model{}
+	model.Spec.TargetRequests = ptr.To[int32](10)
+	model.Spec.ScaleDownDelaySeconds = ptr.To[int64](300)
+	model.Spec.IdleTimeoutSeconds = ptr.To[int64](30)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, err := Simulate(cfg, model, []Sample{
+		{Time: start, ActiveRequests: 5},
+		{Time: start.Add(10 * time.Second), ActiveRequests: 0},
+		{Time: start.Add(60 * time.Second), ActiveRequests: 0},
+	}, SimulationOptions{})
+	require.NoError(t, err)
+
+	var replicas []int32
+	for _, s := range result.Steps {
+		replicas = append(replicas, s.Replicas)
+	}
+	// Idle since 0s, scaled to zero at 30s without waiting for the scale down delay.
+	require.Equal(t, []int32{1, 1, 1, 0, 0, 0, 0}, replicas)
+}
+
+func TestParseSamplesCSV(t *testing.T) {
+	in := `timestamp,model,active_requests
+2024-01-01T00:00:00Z,a,1
+2024-01-01T00:00:00Z,a,2
+1704067210,a,4
+1704067200,b,3
+`
+	samples, err := ParseSamplesCSV(strings.NewReader(in))
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, map[string][]Sample{
+		"a": {
+			{Time: start, ActiveRequests: 3},
+			{Time: start.Add(10 * time.Second), ActiveRequests: 4},
+		},
+		"b": {
+			{Time: start, ActiveRequests: 3},
+		},
+	}, samples)
+
+	_, err = ParseSamplesCSV(strings.NewReader("time,model,active_requests\n"))
+	require.ErrorContains(t, err, `missing column "timestamp"`)
+}
+
+func TestParsePrometheusMatrix(t *testing.T) {
+	in := `{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {"metric": {"request_model": "a", "pod": "kubeai-0"}, "values": [[1704067200, "1"], [1704067210, "2"]]},
+      {"metric": {"request_model": "a", "pod": "kubeai-1"}, "values": [[1704067200, "3"]]},
+      {"metric": {"request_model": "b"}, "values": [[1704067200.5, "0.6"]]}
+    ]
+  }
+}`
+	samples, err := ParsePrometheusMatrix(strings.NewReader(in), "request_model")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, map[string][]Sample{
+		"a": {
+			{Time: start, ActiveRequests: 4},
+			{Time: start.Add(10 * time.Second), ActiveRequests: 2},
+		},
+		"b": {
+			{Time: start.Add(500 * time.Millisecond), ActiveRequests: 1},
+		},
+	}, samples)
+
+	_, err = ParsePrometheusMatrix(strings.NewReader(in), "model")
+	require.ErrorContains(t, err, `series without label "model"`)
+}
+
+func TestGPUsPerReplica(t *testing.T) {
+	profiles := map[string]config.ResourceProfile{
+		"nvidia-gpu-l4": {
+			Limits: corev1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("1"),
+				"cpu":            resource.MustParse("4"),
+			},
+		},
+		"cpu": {
+			Requests: corev1.ResourceList{
+				"cpu": resource.MustParse("1"),
+			},
+		},
+	}
+
+	model := &kubeaiv1.Model{}
+	model.Spec.ResourceProfile = "nvidia-gpu-l4:2"
+	gpus, err := GPUsPerReplica(model, profiles)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, gpus)
+
+	model.Spec.ResourceProfile = "cpu:4"
+	gpus, err = GPUsPerReplica(model, profiles)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, gpus)
+
+	model.Spec.ResourceProfile = "missing:1"
+	_, err = GPUsPerReplica(model, profiles)
+	require.ErrorContains(t, err, "resource profile not found")
+}
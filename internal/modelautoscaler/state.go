@@ -5,61 +5,206 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/movingaverage"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func newTotalModelState() totalModelState {
-	return totalModelState{
-		Models:              make(map[string]modelState),
-		LastCalculationTime: time.Now(),
+// modelScaleState is the in-memory autoscaling state of a Model.
+// It is persisted in a ModelAutoscalerState object with the same name as the Model.
+type modelScaleState struct {
+	avg                   *movingaverage.Simple
+	consecutiveScaleDowns int32
+	lastActive            time.Time
+	// idle is true if the Model was scaled to zero after the idle timeout
+	// in the last autoscaling interval. It is not persisted.
+	idle bool
+	// dryRunReplicas is the last recorded decision for a Model in dry-run mode.
+	dryRunReplicas *int32
+
+	// obj is the last observed state object. Its resourceVersion is used
+	// for optimistic concurrency when saving the state.
+	obj *kubeaiv1.ModelAutoscalerState
+}
+
+// loadStates replaces the in-memory state of all Models with the persisted state.
+func (a *Autoscaler) loadStates(ctx context.Context) error {
+	list := &kubeaiv1.ModelAutoscalerStateList{}
+	if err := a.k8sClient.List(ctx, list, client.InNamespace(a.namespace)); err != nil {
+		return fmt.Errorf("listing model autoscaler states: %w", err)
+	}
+
+	states := map[string]*modelScaleState{}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		states[obj.Name] = a.stateFromObject(obj)
 	}
+	log.Printf("Loaded autoscaler state of %d models", len(states))
+
+	if err := a.migrateLegacyState(ctx, states); err != nil {
+		log.Printf("Failed to migrate legacy autoscaler state: %v", err)
+	}
+
+	a.states = states
+	return nil
 }
 
-type totalModelState struct {
-	Models              map[string]modelState `json:"models"`
-	LastCalculationTime time.Time             `json:"lastCalculationTime"`
+// refreshState replaces the in-memory state of the given Model with the
+// persisted state, i.e. after another replica updated it.
+func (a *Autoscaler) refreshState(ctx context.Context, model string) error {
+	obj := &kubeaiv1.ModelAutoscalerState{}
+	if err := a.k8sClient.Get(ctx, types.NamespacedName{Namespace: a.namespace, Name: model}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			delete(a.states, model)
+			return nil
+		}
+		return fmt.Errorf("getting model autoscaler state: %w", err)
+	}
+	a.states[model] = a.stateFromObject(obj)
+	return nil
 }
 
-type modelState struct {
-	AverageActiveRequests float64 `json:"averageActiveRequests"`
+func (a *Autoscaler) stateFromObject(obj *kubeaiv1.ModelAutoscalerState) *modelScaleState {
+	s := &modelScaleState{
+		avg:                   movingaverage.NewSimple(a.resizeHistory(obj.Status.ActiveRequestsHistory)),
+		consecutiveScaleDowns: obj.Status.ConsecutiveScaleDowns,
+		dryRunReplicas:        obj.Status.DryRunReplicas,
+		obj:                   obj,
+	}
+	if obj.Status.LastActiveTime != nil {
+		s.lastActive = obj.Status.LastActiveTime.Time
+	}
+	return s
 }
 
-func (a *Autoscaler) loadLastTotalModelState(ctx context.Context) (totalModelState, error) {
-	cm := &corev1.ConfigMap{}
-	if err := a.k8sClient.Get(ctx, a.stateConfigMapRef, cm); err != nil {
-		return totalModelState{}, fmt.Errorf("get ConfigMap %q: %w", a.stateConfigMapRef, err)
+// resizeHistory fits the persisted history into the current averaging window,
+// keeping the newest measurements.
+func (a *Autoscaler) resizeHistory(history []int64) []float64 {
+	result := make([]float64, a.cfg.AverageWindowCount())
+	offset := len(result) - len(history)
+	for i, v := range history {
+		if i+offset >= 0 {
+			result[i+offset] = float64(v)
+		}
 	}
-	const key = "models"
-	jsonState, ok := cm.Data[key]
+	return result
+}
+
+// getState returns the in-memory state of the given Model, initializing it if needed.
+func (a *Autoscaler) getState(model string) *modelScaleState {
+	s, ok := a.states[model]
 	if !ok {
-		log.Printf("Autoscaler state ConfigMap %q has no key %q, state not loaded", key, a.stateConfigMapRef)
-		return totalModelState{}, nil
+		s = &modelScaleState{
+			avg: movingaverage.NewSimple(make([]float64, a.cfg.AverageWindowCount())),
+		}
+		a.states[model] = s
+	}
+	return s
+}
+
+// saveState persists the in-memory state of the given Model. It fails on
+// conflicting writes (i.e. by another replica that believes it is the leader).
+func (a *Autoscaler) saveState(ctx context.Context, m *kubeaiv1.Model, s *modelScaleState, now time.Time) error {
+	if s.obj == nil {
+		obj := &kubeaiv1.ModelAutoscalerState{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.Namespace,
+				Name:      m.Name,
+			},
+		}
+		if err := controllerutil.SetOwnerReference(m, obj, a.k8sClient.Scheme()); err != nil {
+			return fmt.Errorf("setting owner reference: %w", err)
+		}
+		if err := a.k8sClient.Create(ctx, obj); err != nil {
+			return fmt.Errorf("creating model autoscaler state: %w", err)
+		}
+		s.obj = obj
 	}
-	tms := totalModelState{}
-	if err := json.Unmarshal([]byte(jsonState), &tms); err != nil {
-		return totalModelState{}, fmt.Errorf("unmarshalling state: %w", err)
+
+	obj := s.obj.DeepCopy()
+	history := s.avg.History()
+	obj.Status.ActiveRequestsHistory = make([]int64, len(history))
+	for i, v := range history {
+		obj.Status.ActiveRequestsHistory[i] = int64(math.Round(v))
 	}
-	return tms, nil
+	obj.Status.ConsecutiveScaleDowns = s.consecutiveScaleDowns
+	obj.Status.LastActiveTime = nil
+	if !s.lastActive.IsZero() {
+		obj.Status.LastActiveTime = &metav1.Time{Time: s.lastActive}
+	}
+	obj.Status.DryRunReplicas = s.dryRunReplicas
+	obj.Status.LastCalculationTime = &metav1.Time{Time: now}
+	obj.Status.Leader = a.leaderElection.ID
+
+	if err := a.k8sClient.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("updating model autoscaler state: %w", err)
+	}
+	s.obj = obj
+	return nil
+}
+
+type legacyTotalModelState struct {
+	Models map[string]struct {
+		AverageActiveRequests float64 `json:"averageActiveRequests"`
+	} `json:"models"`
 }
 
-func (a *Autoscaler) saveTotalModelState(ctx context.Context, state totalModelState) error {
-	jsonState, err := json.Marshal(state)
-	if err != nil {
-		return fmt.Errorf("marshalling state: %w", err)
+// migrateLegacyState preloads the state of Models that do not have a
+// ModelAutoscalerState yet from the ConfigMap used by previous versions.
+func (a *Autoscaler) migrateLegacyState(ctx context.Context, states map[string]*modelScaleState) error {
+	if a.cfg.StateConfigMapName == "" {
+		return nil
 	}
-	patch := fmt.Sprintf(`{"data":{"models":%q}}`, string(jsonState))
-	if err := a.k8sClient.Patch(ctx, &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: a.stateConfigMapRef.Namespace,
-			Name:      a.stateConfigMapRef.Name,
-		},
-	}, client.RawPatch(types.StrategicMergePatchType, []byte(patch))); err != nil {
-		return fmt.Errorf("patching ConfigMap %q: %w", a.stateConfigMapRef, err)
+	ref := types.NamespacedName{Namespace: a.namespace, Name: a.cfg.StateConfigMapName}
+	cm := &corev1.ConfigMap{}
+	if err := a.k8sClient.Get(ctx, ref, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get ConfigMap %q: %w", ref, err)
+	}
+	jsonState, ok := cm.Data["models"]
+	if !ok {
+		return nil
+	}
+	var legacy legacyTotalModelState
+	if err := json.Unmarshal([]byte(jsonState), &legacy); err != nil {
+		return fmt.Errorf("unmarshalling state: %w", err)
+	}
+	for m, ls := range legacy.Models {
+		if _, ok := states[m]; ok {
+			continue
+		}
+		// Preload moving averages with the last known average. The history
+		// is persisted as whole numbers of requests, so the average is
+		// spread across the window: if the last known average was 5.5, the
+		// preloaded moving average would look like [5, 6, 5, 6, ...].
+		preloaded := newPrefilledHistory(a.cfg.AverageWindowCount(), ls.AverageActiveRequests)
+		states[m] = &modelScaleState{avg: movingaverage.NewSimple(preloaded)}
+		log.Printf("Migrated legacy autoscaler state for model %q: %v", m, preloaded)
 	}
 	return nil
 }
+
+// newPrefilledHistory returns a history of whole numbers with the given
+// length and (rounded) average.
+func newPrefilledHistory(length int, avg float64) []float64 {
+	s := make([]float64, length)
+	total := int64(math.Round(avg * float64(length)))
+	var sum int64
+	for i := range s {
+		// Spread the remainder evenly across the window.
+		next := total * int64(i+1) / int64(length)
+		s[i] = float64(next - sum)
+		sum = next
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package modelautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/movingaverage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewPrefilledHistory(t *testing.T) {
+	cases := map[string]struct {
+		length int
+		avg    float64
+		exp    []float64
+	}{
+		"whole":      {length: 4, avg: 3, exp: []float64{3, 3, 3, 3}},
+		"fractional": {length: 4, avg: 5.5, exp: []float64{5, 6, 5, 6}},
+		"small":      {length: 4, avg: 0.25, exp: []float64{0, 0, 0, 1}},
+		"zero":       {length: 3, avg: 0, exp: []float64{0, 0, 0}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			history := newPrefilledHistory(c.length, c.avg)
+			require.Equal(t, c.exp, history)
+			require.Equal(t, c.avg, movingaverage.NewSimple(history).Calculate())
+		})
+	}
+}
+
+func TestRefreshState(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubeaiv1.AddToScheme(scheme))
+	persisted := &kubeaiv1.ModelAutoscalerState{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "m1"},
+		Status: kubeaiv1.ModelAutoscalerStateStatus{
+			ActiveRequestsHistory: []int64{1, 2, 3, 4},
+			ConsecutiveScaleDowns: 2,
+		},
+	}
+	a := &Autoscaler{
+		k8sClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(persisted).Build(),
+		namespace: "default",
+		cfg: config.ModelAutoscaling{
+			Interval:   config.Duration{Duration: time.Second},
+			TimeWindow: config.Duration{Duration: 4 * time.Second},
+		},
+		states: map[string]*modelScaleState{},
+	}
+
+	// Stale in-memory states are replaced by the persisted ones.
+	a.getState("m1").avg.Next(100)
+	a.getState("deleted").avg.Next(100)
+	require.NoError(t, a.refreshState(context.Background(), "m1"))
+	require.NoError(t, a.refreshState(context.Background(), "deleted"))
+
+	require.Equal(t, []float64{1, 2, 3, 4}, a.states["m1"].avg.History())
+	require.Equal(t, int32(2), a.states["m1"].consecutiveScaleDowns)
+	require.NotNil(t, a.states["m1"].obj)
+	require.NotContains(t, a.states, "deleted")
+}
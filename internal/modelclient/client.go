@@ -3,7 +3,6 @@ package modelclient
 import (
 	"context"
 	"fmt"
-	"sync"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -13,14 +12,25 @@ import (
 )
 
 type ModelClient struct {
-	client                   client.Client
-	namespace                string
-	consecutiveScaleDownsMtx sync.RWMutex
-	consecutiveScaleDowns    map[string]int
+	client    client.Client
+	namespace string
+	capacity  CapacityChecker
+}
+
+// CapacityChecker checks whether a Model can be scaled up by one replica
+// without exceeding the capacity budget of its ResourceProfile.
+type CapacityChecker interface {
+	HasCapacityForReplica(ctx context.Context, model *kubeaiv1.Model) (bool, error)
 }
 
 func NewModelClient(client client.Client, namespace string) *ModelClient {
-	return &ModelClient{client: client, namespace: namespace, consecutiveScaleDowns: map[string]int{}}
+	return &ModelClient{client: client, namespace: namespace}
+}
+
+// SetCapacityChecker sets the checker that prevents scaling Models from zero
+// beyond the capacity budget of their ResourceProfile.
+func (c *ModelClient) SetCapacityChecker(capacity CapacityChecker) {
+	c.capacity = capacity
 }
 
 // LookupModel checks if a model exists and matches the given label selectors.
@@ -11,14 +11,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (c *ModelClient) ScaleAtLeastOneReplica(ctx context.Context, model string) error {
+// ScaleAtLeastOneReplica scales the model to one replica if it is scaled to zero.
+// It returns true if the model has no ready replicas (the request is a cold start).
+// The model is not scaled if the replica does not fit into the capacity budget
+// of its ResourceProfile. The request then stays queued and the autoscaler
+// allocates the budget by priority (scaling other models down if needed).
+func (c *ModelClient) ScaleAtLeastOneReplica(ctx context.Context, model string) (bool, error) {
 	obj := &kubeaiv1.Model{}
 	if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: model}, obj); err != nil {
-		return fmt.Errorf("get scale: %w", err)
+		return false, fmt.Errorf("get scale: %w", err)
 	}
 
-	if obj.Spec.AutoscalingDisabled {
-		return nil
+	coldStart := obj.Status.Replicas.Ready == 0
+
+	if obj.Spec.AutoscalingDisabled || obj.Spec.AutoscalingDryRun {
+		return coldStart, nil
 	}
 
 	replicas := int32(0)
@@ -27,55 +34,46 @@ func (c *ModelClient) ScaleAtLeastOneReplica(ctx context.Context, model string)
 	}
 
 	if replicas == 0 && !obj.Spec.AutoscalingDisabled {
+		if c.capacity != nil {
+			ok, err := c.capacity.HasCapacityForReplica(ctx, obj)
+			if err != nil {
+				return coldStart, fmt.Errorf("check capacity: %w", err)
+			}
+			if !ok {
+				log.Printf("model %s: not scaling from zero, the capacity budget of its resource profile is exhausted", model)
+				return coldStart, nil
+			}
+		}
 		scale := &autoscalingv1.Scale{
 			Spec: autoscalingv1.ScaleSpec{Replicas: 1},
 		}
 		if err := c.client.SubResource("scale").Update(ctx, obj, client.WithSubResourceBody(scale)); err != nil {
-			return fmt.Errorf("update scale: %w", err)
+			return coldStart, fmt.Errorf("update scale: %w", err)
 		}
 	}
 
-	return nil
+	return coldStart, nil
 }
 
 // Scale scales the model to the desired number of replicas, enforcing the min and max replica bounds.
+// Scale downs only happen after requiredConsecutiveScaleDowns consecutive calls that would scale down.
+// consecutiveScaleDowns tracks the number of these calls and is updated in place.
 // Model should have .Spec defined before calling Scale().
-func (c *ModelClient) Scale(ctx context.Context, model *kubeaiv1.Model, replicas int32, requiredConsecutiveScaleDowns int) error {
-	//obj := &kubeaiv1.Model{}
-	//if err := s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: model}, obj); err != nil {
-	//	return fmt.Errorf("get scale: %w", err)
-	//}
-
-	replicas = enforceReplicaBounds(replicas, model)
-
+func (c *ModelClient) Scale(ctx context.Context, model *kubeaiv1.Model, replicas int32, requiredConsecutiveScaleDowns int, consecutiveScaleDowns *int32) error {
 	var existingReplicas int32 = 0
 	if model.Spec.Replicas != nil {
 		existingReplicas = *model.Spec.Replicas
 	}
 
-	if existingReplicas > replicas {
-		// Scale down
-		c.consecutiveScaleDownsMtx.RLock()
-		consec := c.consecutiveScaleDowns[model.Name]
-		c.consecutiveScaleDownsMtx.RUnlock()
-		if consec < requiredConsecutiveScaleDowns {
-			log.Printf("model %s has %d consecutive scale downs (< %d), not scaling down yet", model.Name, consec, requiredConsecutiveScaleDowns)
-			c.consecutiveScaleDownsMtx.Lock()
-			c.consecutiveScaleDowns[model.Name]++
-			c.consecutiveScaleDownsMtx.Unlock()
-			return nil
-		}
-	} else {
-		// Scale up or constant scale.
-		c.consecutiveScaleDownsMtx.Lock()
-		c.consecutiveScaleDowns[model.Name] = 0
-		c.consecutiveScaleDownsMtx.Unlock()
+	next := NextReplicas(model, existingReplicas, replicas, requiredConsecutiveScaleDowns, consecutiveScaleDowns)
+	if next == existingReplicas && EnforceReplicaBounds(replicas, model) < existingReplicas {
+		log.Printf("model %s has %d/%d consecutive scale downs, not scaling down yet", model.Name, *consecutiveScaleDowns, requiredConsecutiveScaleDowns)
 	}
 
-	if existingReplicas != replicas {
-		log.Printf("scaling model %s from %d to %d replicas", model.Name, existingReplicas, replicas)
+	if existingReplicas != next {
+		log.Printf("scaling model %s from %d to %d replicas", model.Name, existingReplicas, next)
 		scale := &autoscalingv1.Scale{
-			Spec: autoscalingv1.ScaleSpec{Replicas: replicas},
+			Spec: autoscalingv1.ScaleSpec{Replicas: next},
 		}
 		if err := c.client.SubResource("scale").Update(ctx, model, client.WithSubResourceBody(scale)); err != nil {
 			return fmt.Errorf("update scale: %w", err)
@@ -85,7 +83,31 @@ func (c *ModelClient) Scale(ctx context.Context, model *kubeaiv1.Model, replicas
 	return nil
 }
 
-func enforceReplicaBounds(replicas int32, model *kubeaiv1.Model) int32 {
+// NextReplicas returns the number of replicas that a model with existingReplicas
+// should be scaled to when the desired number of replicas is given. It enforces
+// the min and max replica bounds and delays scale downs until there were
+// requiredConsecutiveScaleDowns consecutive calls that would scale down.
+// consecutiveScaleDowns tracks the number of these calls and is updated in place.
+func NextReplicas(model *kubeaiv1.Model, existingReplicas, replicas int32, requiredConsecutiveScaleDowns int, consecutiveScaleDowns *int32) int32 {
+	replicas = EnforceReplicaBounds(replicas, model)
+
+	if existingReplicas > replicas {
+		// Scale down
+		if int(*consecutiveScaleDowns) < requiredConsecutiveScaleDowns {
+			*consecutiveScaleDowns++
+			return existingReplicas
+		}
+	} else {
+		// Scale up or constant scale.
+		*consecutiveScaleDowns = 0
+	}
+
+	return replicas
+}
+
+// EnforceReplicaBounds returns the given number of replicas clamped
+// to the min and max replicas of the Model.
+func EnforceReplicaBounds(replicas int32, model *kubeaiv1.Model) int32 {
 	max := model.Spec.MaxReplicas
 	min := model.Spec.MinReplicas
 	if max != nil {
@@ -21,12 +21,16 @@ const (
 // of the adapter URL.
 // At request-time, the endpoint resolver will inspect these labels to determine which adapters
 // are loaded in the pod.
-func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.Pod, adapters []v1.Adapter) error {
+func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.Pod, model *v1.Model, protocol v1.AdapterProtocol) error {
+	if protocol != v1.AdapterProtocolVLLM {
+		return nil
+	}
+	adapters := model.Spec.Adapters
+
 	type reconcileParam struct {
 		pod         *corev1.Pod
 		toEnsure    []v1.Adapter
 		toRemoveIDs []string
-		engine      string
 	}
 	var reconcileList []reconcileParam
 
@@ -35,13 +39,8 @@ func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.
 			pod: pod,
 		}
 
-		if pod.Labels == nil {
-			continue
-		}
-		switch pod.Labels[appKubernetesIOName] {
-		case strings.ToLower(v1.VLLMEngine):
-			param.engine = v1.VLLMEngine
-		default:
+		// Skip Pods of a previous engine of the Model.
+		if pod.Labels == nil || pod.Labels[appKubernetesIOName] != strings.ToLower(model.Spec.Engine) {
 			continue
 		}
 
@@ -73,19 +72,16 @@ func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.
 			if err := r.execAdapterLoad(ctx, param.pod, adapter); err != nil {
 				return fmt.Errorf("exec adapter load for pod %q: %w", param.pod.Namespace+"/"+param.pod.Name, err)
 			}
-			switch param.engine {
-			case v1.VLLMEngine:
-				if err := r.VLLMClient.LoadLoraAdapter(ctx, addr, vllmclient.LoadAdapterRequest{
-					LoraName: adapter.Name,
-					LoraPath: adapterDir(adapter),
-					Options: vllmclient.LoadAdapterRequestOptions{
-						// It is possible that the adapter is already loaded, but updating the Pod labels
-						// failed. In this case, we ignore the error and continue.
-						IgnoreAlreadyLoaded: true,
-					},
-				}); err != nil {
-					return fmt.Errorf("load vllm adapter %q: %w", adapter.Name, err)
-				}
+			if err := r.VLLMClient.LoadLoraAdapter(ctx, addr, vllmclient.LoadAdapterRequest{
+				LoraName: adapter.Name,
+				LoraPath: adapterDir(adapter),
+				Options: vllmclient.LoadAdapterRequestOptions{
+					// It is possible that the adapter is already loaded, but updating the Pod labels
+					// failed. In this case, we ignore the error and continue.
+					IgnoreAlreadyLoaded: true,
+				},
+			}); err != nil {
+				return fmt.Errorf("load vllm adapter %q: %w", adapter.Name, err)
 			}
 			if err := r.updatePodAddLabel(ctx, param.pod, v1.PodAdapterLabel(adapter.Name), k8sutils.StringHash(adapter.URL)); err != nil {
 				return fmt.Errorf("update pod labels for pod %q: %w", param.pod.Namespace+"/"+param.pod.Name, err)
@@ -95,18 +91,15 @@ func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.
 			if err := r.execAdapterUnload(ctx, param.pod, adapterID); err != nil {
 				return fmt.Errorf("exec adapter unload for pod %q: %w", param.pod.Namespace+"/"+param.pod.Name, err)
 			}
-			switch param.engine {
-			case v1.VLLMEngine:
-				if err := r.VLLMClient.UnloadLoraAdapter(ctx, addr, vllmclient.UnloadAdapterRequest{
-					LoraName: adapterID,
-					Options: vllmclient.UnloadAdapterRequestOptions{
-						// It is possible that the adapter is already unloaded, but updating the Pod labels
-						// failed. In this case, we ignore the error and continue.
-						IgnoreNotFound: true,
-					},
-				}); err != nil {
-					return fmt.Errorf("unload vllm adapter %q: %w", adapterID, err)
-				}
+			if err := r.VLLMClient.UnloadLoraAdapter(ctx, addr, vllmclient.UnloadAdapterRequest{
+				LoraName: adapterID,
+				Options: vllmclient.UnloadAdapterRequestOptions{
+					// It is possible that the adapter is already unloaded, but updating the Pod labels
+					// failed. In this case, we ignore the error and continue.
+					IgnoreNotFound: true,
+				},
+			}); err != nil {
+				return fmt.Errorf("unload vllm adapter %q: %w", adapterID, err)
 			}
 			if err := r.updatePodRemoveLabel(ctx, param.pod, v1.PodAdapterLabel(adapterID)); err != nil {
 				return fmt.Errorf("update pod labels for pod %q: %w", param.pod.Namespace+"/"+param.pod.Name, err)
@@ -117,6 +110,19 @@ func (r *ModelReconciler) reconcileAdapters(ctx context.Context, pods []*corev1.
 	return nil
 }
 
+// adapterProtocol returns the protocol used to load adapters into the
+// server Pods of the Model.
+func adapterProtocol(model *v1.Model, cfg ModelConfig) v1.AdapterProtocol {
+	switch {
+	case cfg.Engine != nil:
+		return cfg.Engine.Spec.AdapterProtocol
+	case model.Spec.Engine == v1.VLLMEngine:
+		return v1.AdapterProtocolVLLM
+	default:
+		return v1.AdapterProtocolNone
+	}
+}
+
 func getPodModelServerAddr(pod *corev1.Pod) string {
 	// Example:
 	//
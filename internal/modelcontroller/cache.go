@@ -4,34 +4,74 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
 	"github.com/substratusai/kubeai/internal/k8sutils"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type PVCModelAnnotationValue struct {
 	UID       string    `json:"uid"`
 	Timestamp time.Time `json:"timestamp"`
+	// SizeBytes is the size of the model as reported by the load Job.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Evicted is set when the model was evicted from a shared cache to free
+	// space. It is loaded again when the Model is scaled up.
+	Evicted bool `json:"evicted,omitempty"`
+	// Revision is the resolved revision of the model as reported by the load Job.
+	Revision string `json:"revision,omitempty"`
+	// ManifestSHA256 is the digest of the manifest of the model files.
+	ManifestSHA256 string `json:"manifestSHA256,omitempty"`
+	// Entry is the cache entry that the model was loaded into. The model of
+	// an entry is shared by all Models whose annotations reference it and
+	// evicted with the last of them. Empty for models that were loaded into
+	// a directory of their own.
+	Entry string `json:"entry,omitempty"`
 }
 
+// cacheLoadReport is the termination message of a load Job.
+type cacheLoadReport struct {
+	SizeBytes         int64                      `json:"sizeBytes"`
+	DiskCapacityBytes int64                      `json:"diskCapacityBytes,omitempty"`
+	DiskUsedBytes     int64                      `json:"diskUsedBytes,omitempty"`
+	Revision          string                     `json:"revision,omitempty"`
+	ManifestSHA256    string                     `json:"manifestSHA256,omitempty"`
+	Files             []kubeaiv1.ModelCachedFile `json:"files,omitempty"`
+}
+
+// cacheVerifyExitCode is the exit code of the model-verifier init container
+// when the cached files do not match the manifest recorded by the load Job.
+const cacheVerifyExitCode = 3
+
 func (r *ModelReconciler) reconcileCache(ctx context.Context, model *kubeaiv1.Model, cfg ModelConfig) (ctrl.Result, error) {
 	if model.Status.Cache == nil {
 		model.Status.Cache = &kubeaiv1.ModelStatusCache{}
 	}
 
+	if cfg.CacheProfile.NodeLocal != nil {
+		return ctrl.Result{}, r.reconcileNodeLocalCache(ctx, model)
+	}
+
 	modelDeleted := model.DeletionTimestamp != nil
 
 	pvc := &corev1.PersistentVolumeClaim{}
@@ -52,15 +92,27 @@ func (r *ModelReconciler) reconcileCache(ctx context.Context, model *kubeaiv1.Mo
 	// Create PVC if not exists.
 	if !pvcExists {
 		if !modelDeleted {
-			pvc = r.cachePVCForModel(model, cfg)
-			// TODO: Set controller reference on PVC for 1:1 Model to PVC situations
-			// such as Google Hyperdisk ML.
-			//if err := controllerutil.SetControllerReference(model, pvc, r.Scheme); err != nil {
-			//	return ctrl.Result{}, fmt.Errorf("setting controller reference on pvc: %w", err)
-			//}
+			size, err := r.cachePVCSize(ctx, model, cfg)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			pvc, err = r.cachePVCForModel(model, cfg, size)
+			if err != nil {
+				setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonInvalidConfiguration, err.Error())
+				return ctrl.Result{}, err
+			}
+			if cfg.CacheProfile.DedicatedVolume != nil {
+				// Dedicated volumes are deleted with the Model.
+				if err := ctrl.SetControllerReference(model, pvc, r.Scheme); err != nil {
+					return ctrl.Result{}, fmt.Errorf("setting controller reference on pvc: %w", err)
+				}
+			}
 			if err := r.Create(ctx, pvc); err != nil {
 				return ctrl.Result{}, fmt.Errorf("creating cache PVC: %w", err)
 			}
+			if err := r.deleteCacheJob(ctx, model, sizeCacheJobName(model)); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 	}
 
@@ -72,10 +124,52 @@ func (r *ModelReconciler) reconcileCache(ctx context.Context, model *kubeaiv1.Mo
 			}
 		}
 
+		// Models with the url of a ModelCache are served from its model.
+		if !modelDeleted {
+			mc, err := r.modelCacheForModel(ctx, model)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if served, err := reconcileModelCacheOfModel(model, mc); served {
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Wait for the SharedCacheReconciler to finish evicting the model
+		// before it is loaded again.
+		if !modelDeleted {
+			evictJob := &batchv1.Job{}
+			if err := r.Client.Get(ctx, types.NamespacedName{
+				Namespace: model.Namespace,
+				Name:      evictCacheJobName(model),
+			}, evictJob); err == nil && evictJob.DeletionTimestamp == nil {
+				model.Status.Cache.Loaded = false
+				setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonEvicting,
+					fmt.Sprintf("Evicting the model from the cache with Job %s", evictJob.Name))
+				return ctrl.Result{}, errReturnEarly
+			} else if err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("getting cache eviction job: %w", err)
+			}
+		}
 	}
 	// NOTE: .Spec.CacheProfile and .Spec.URL are immutable, so we don't need to check if they
 	// have changed in order to evict a stale cache.
 
+	pvcModelAnn, err := parsePVCModelAnnotation(pvc, model.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("parsing pvc model annotation: %w", err)
+	}
+
+	// Models of sharedFilesystem caches are loaded into the cache entry of
+	// their url, unless they were loaded into a directory of their own.
+	var entry string
+	if cfg.CacheProfile.SharedFilesystem != nil {
+		if pvcModelAnn.UID != string(model.UID) || pvcModelAnn.Entry != "" || pvcModelAnn.Evicted {
+			entry = cacheEntryName(cfg.Source.url)
+		}
+		model.Status.Cache.Entry = entry
+	}
+
 	loadJob := &batchv1.Job{}
 	var jobExists bool
 	if err := r.Client.Get(ctx, types.NamespacedName{
@@ -91,38 +185,87 @@ func (r *ModelReconciler) reconcileCache(ctx context.Context, model *kubeaiv1.Mo
 		jobExists = true
 	}
 
-	pvcModelAnn, err := parsePVCModelAnnotation(pvc, model.Name)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("parsing pvc model annotation: %w", err)
+	// Evicted models are loaded again when the Model is scaled up.
+	if pvcModelAnn.UID == string(model.UID) && pvcModelAnn.Evicted && !jobExists && isScaledToZero(model) {
+		model.Status.Cache.Loaded = false
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonEvicted,
+			"The model was evicted from the cache, it is loaded again when the Model is scaled up")
+		return ctrl.Result{}, nil
 	}
 
-	// Run Job to populate PVC if not already downloaded.
-	if pvcModelAnn.UID != string(model.UID) {
-		// Ensure the download job exists.
-		if !jobExists {
-			loadJob = r.loadCacheJobForModel(model, cfg)
-			if err := ctrl.SetControllerReference(model, loadJob, r.Scheme); err != nil {
-				return ctrl.Result{}, fmt.Errorf("setting controller reference on job: %w", err)
-			}
-			if err := r.Create(ctx, loadJob); err != nil {
-				return ctrl.Result{}, fmt.Errorf("creating job: %w", err)
+	needsLoad := pvcModelAnn.UID != string(model.UID) || pvcModelAnn.Evicted
+	if needsLoad && entry != "" {
+		// Skip loading the model if another Model with the same url did.
+		referenced, err := r.referenceCacheEntry(ctx, model, pvc, entry)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if referenced {
+			if pvcModelAnn, err = parsePVCModelAnnotation(pvc, model.Name); err != nil {
+				return ctrl.Result{}, fmt.Errorf("parsing pvc model annotation: %w", err)
 			}
-			return ctrl.Result{}, errReturnEarly
+			model.Status.Cache.Files = nil
+			needsLoad = false
 		}
+	}
 
-		if !k8sutils.IsJobCompleted(loadJob) {
-			return ctrl.Result{}, errReturnEarly
+	// Run Job to populate PVC if not already downloaded.
+	if needsLoad {
+		res, report, err := r.cacheLoadJobReport(ctx, model, loadJob, jobExists, func() *batchv1.Job {
+			return r.loadCacheJobForModel(model, cfg)
+		})
+		if err != nil {
+			return res, err
 		}
-		if err := r.updatePVCModelAnnotation(ctx, pvc, model.Name, PVCModelAnnotationValue{
-			UID:       string(model.UID),
-			Timestamp: time.Now(),
-		}); err != nil {
+		pvcModelAnn = PVCModelAnnotationValue{
+			UID:            string(model.UID),
+			Timestamp:      time.Now(),
+			SizeBytes:      report.SizeBytes,
+			Revision:       report.Revision,
+			ManifestSHA256: report.ManifestSHA256,
+			Entry:          entry,
+		}
+		if err := r.updatePVCModelAnnotation(ctx, pvc, model.Name, pvcModelAnn); err != nil {
 			return ctrl.Result{}, fmt.Errorf("setting pvc model annotation: %w", err)
 		}
+		model.Status.Cache.Files = report.Files
+	}
+	model.Status.Cache.Progress = nil
+	model.Status.Cache.Loaded = pvcModelAnn.UID == string(model.UID) && !pvcModelAnn.Evicted
+	model.Status.Cache.Revision = pvcModelAnn.Revision
+	model.Status.Cache.ManifestSHA256 = pvcModelAnn.ManifestSHA256
+	if model.Status.Cache.Loaded && r.ModelLoaders.Verify {
+		failed, err := r.cacheVerificationFailed(ctx, model, pvcModelAnn.Timestamp)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if failed && pvcModelAnn.Entry != "" {
+			return r.reloadCacheEntry(ctx, model, cfg, pvc, pvcModelAnn.Entry, loadJob, jobExists)
+		}
+		if failed {
+			log.FromContext(ctx).Info("Cached model files do not match the manifest, loading the model again")
+			delete(pvc.Annotations, kubeaiv1.PVCModelAnnotation(model.Name))
+			if err := r.Update(ctx, pvc); err != nil {
+				return ctrl.Result{}, fmt.Errorf("updating PVC, removing cache annotation: %w", err)
+			}
+			model.Status.Cache.Loaded = false
+			setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+				"The cached files do not match the manifest, loading the model again")
+			return ctrl.Result{Requeue: true}, errReturnEarly
+		}
+	}
+	if model.Status.Cache.Loaded {
+		if vol := cfg.CacheProfile.DedicatedVolume; vol != nil && vol.ReadOnlyMany != nil {
+			if err := r.reconcileReadOnlyManyCache(ctx, model, cfg, pvc); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionTrue, kubeaiv1.ModelReasonLoaded, "")
 	}
-	model.Status.Cache.Loaded = pvcModelAnn.UID == string(model.UID)
 
-	if jobExists {
+	// Reloads of a cache entry (see reloadCacheEntry) are not interrupted.
+	reloading := entry != "" && !k8sutils.IsJobCompleted(loadJob) && !k8sutils.IsJobFailed(loadJob)
+	if jobExists && metav1.IsControlledBy(loadJob, model) && !reloading {
 		// Cache loading completed, delete Job to avoid accumulating a mess of completed Jobs.
 		// Use foreground deletion policy to ensure the Pods are deleted as well.
 		if err := r.Delete(ctx, loadJob, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
@@ -133,7 +276,112 @@ func (r *ModelReconciler) reconcileCache(ctx context.Context, model *kubeaiv1.Mo
 	return ctrl.Result{}, nil
 }
 
+// reloadCacheEntry loads the model of a cache entry again after its files did
+// not match the manifest. Model servers that run from the entry keep its files:
+// the model is loaded into a new directory that replaces the directory of the
+// entry once it is loaded (see patchCacheRefreshJob). Then the Models that
+// reference the entry are pointed to the new load.
+func (r *ModelReconciler) reloadCacheEntry(ctx context.Context, model *kubeaiv1.Model, cfg ModelConfig, pvc *corev1.PersistentVolumeClaim, entry string, loadJob *batchv1.Job, jobExists bool) (ctrl.Result, error) {
+	model.Status.Cache.Loaded = false
+	res, report, err := r.cacheLoadJobReport(ctx, model, loadJob, jobExists, func() *batchv1.Job {
+		log.FromContext(ctx).Info("Cached model files do not match the manifest, loading the model again")
+		job := r.loadCacheJobForModel(model, cfg)
+		patchCacheRefreshJob(job, strconv.FormatInt(time.Now().Unix(), 10))
+		return job
+	})
+	if err != nil {
+		return res, err
+	}
+	if err := r.repointCacheEntryRefs(ctx, pvc, entry, report); err != nil {
+		return ctrl.Result{}, err
+	}
+	model.Status.Cache.Files = report.Files
+	if err := r.Delete(ctx, loadJob, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting job: %w", err)
+	}
+	return ctrl.Result{Requeue: true}, errReturnEarly
+}
+
+// cacheLoadJobReport creates the load Job of a Model with newJob if it does
+// not exist and returns its report once it completed. While the Job runs, the
+// CacheLoaded condition reports its progress and errReturnEarly is returned.
+// Models that share the load Job of a cache entry wait for the Model that
+// created it to record the loaded model.
+func (r *ModelReconciler) cacheLoadJobReport(ctx context.Context, model *kubeaiv1.Model, loadJob *batchv1.Job, jobExists bool, newJob func() *batchv1.Job) (ctrl.Result, cacheLoadReport, error) {
+	var report cacheLoadReport
+	if jobExists && loadJob.DeletionTimestamp != nil {
+		// The Job of a previous load has to be gone before the model is loaded again.
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			fmt.Sprintf("Waiting for the previous Job %s to be deleted", loadJob.Name))
+		return ctrl.Result{}, report, errReturnEarly
+	}
+	// Ensure the download job exists.
+	if !jobExists {
+		loadJob = newJob()
+		if err := ctrl.SetControllerReference(model, loadJob, r.Scheme); err != nil {
+			return ctrl.Result{}, report, fmt.Errorf("setting controller reference on job: %w", err)
+		}
+		if err := r.Create(ctx, loadJob); apierrors.IsAlreadyExists(err) {
+			// Another Model with the same url started to load the model of the cache entry.
+			setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+				fmt.Sprintf("Waiting for Job %s to load the model into the cache", loadJob.Name))
+			return ctrl.Result{RequeueAfter: cacheEntryWaitPeriod}, report, errReturnEarly
+		} else if err != nil {
+			return ctrl.Result{}, report, fmt.Errorf("creating job: %w", err)
+		}
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			fmt.Sprintf("Loading the model into the cache with Job %s", loadJob.Name))
+		return ctrl.Result{}, report, errReturnEarly
+	}
+
+	if k8sutils.IsJobFailed(loadJob) {
+		_, failure, err := r.jobContainerFailures(ctx, loadJob, "loader")
+		if err != nil {
+			return ctrl.Result{}, report, err
+		}
+		msg := fmt.Sprintf("Job %s failed to load the model into the cache", loadJob.Name)
+		if failure != "" {
+			msg += ": " + failure
+		}
+		model.Status.Cache.Progress = nil
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoadFailed, msg)
+		return ctrl.Result{}, report, errReturnEarly
+	}
+	if !k8sutils.IsJobCompleted(loadJob) {
+		progress, msg, err := r.cacheLoadJobProgress(ctx, loadJob)
+		if err != nil {
+			return ctrl.Result{}, report, err
+		}
+		model.Status.Cache.Progress = progress
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading, msg)
+		// Read the progress from the logs of the loader again.
+		return ctrl.Result{RequeueAfter: cacheLoadProgressPeriod}, report, errReturnEarly
+	}
+	if !metav1.IsControlledBy(loadJob, model) {
+		// The Model that created the load Job of the cache entry records
+		// the loaded model, which is then referenced.
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			fmt.Sprintf("Waiting for Job %s to load the model into the cache", loadJob.Name))
+		return ctrl.Result{RequeueAfter: cacheEntryWaitPeriod}, report, errReturnEarly
+	}
+	msg, err := r.jobTerminationMessage(ctx, loadJob, "loader")
+	if err != nil {
+		return ctrl.Result{}, report, err
+	}
+	if msg != "" {
+		if err := json.Unmarshal([]byte(msg), &report); err != nil {
+			return ctrl.Result{}, report, fmt.Errorf("parsing report of job %q: %w", loadJob.Name, err)
+		}
+	}
+	return ctrl.Result{}, report, nil
+}
+
 func (r *ModelReconciler) finalizeCache(ctx context.Context, model *kubeaiv1.Model, cfg ModelConfig) error {
+	if cfg.CacheProfile.NodeLocal != nil {
+		// Models are evicted from the Nodes by the NodeCacheReconciler.
+		return nil
+	}
+
 	pvc := &corev1.PersistentVolumeClaim{}
 	var pvcExists bool
 	if err := r.Client.Get(ctx, types.NamespacedName{
@@ -162,6 +410,36 @@ func (r *ModelReconciler) finalizeCache(ctx context.Context, model *kubeaiv1.Mod
 	}
 
 	if controllerutil.ContainsFinalizer(model, kubeaiv1.ModelCacheEvictionFinalizer) {
+		pvcModelAnn, err := parsePVCModelAnnotation(pvc, model.Name)
+		if err != nil {
+			return fmt.Errorf("parsing pvc model annotation: %w", err)
+		}
+		// The model of a cache entry is only evicted with the last Model
+		// that references it.
+		if entry := loadedCacheEntry(model, pvcModelAnn); entry != "" {
+			refs, err := cacheEntryRefs(pvc, entry, model.Name)
+			if err != nil {
+				return err
+			}
+			waiting, err := r.cacheEntryModels(ctx, model.Namespace, entry, model.Name)
+			if err != nil {
+				return err
+			}
+			if len(refs) > 0 || len(waiting) > 0 {
+				if _, ok := pvc.Annotations[kubeaiv1.PVCModelAnnotation(model.Name)]; ok {
+					delete(pvc.Annotations, kubeaiv1.PVCModelAnnotation(model.Name))
+					if err := r.Update(ctx, pvc); err != nil {
+						return fmt.Errorf("updating PVC, removing cache annotation: %w", err)
+					}
+				}
+				controllerutil.RemoveFinalizer(model, kubeaiv1.ModelCacheEvictionFinalizer)
+				if err := r.Update(ctx, model); err != nil {
+					return fmt.Errorf("removing cache deletion finalizer: %w", err)
+				}
+				return r.deleteAllCacheJobsAndPods(ctx, model)
+			}
+		}
+
 		evictJob := &batchv1.Job{}
 		var jobExists bool
 		if err := r.Client.Get(ctx, types.NamespacedName{
@@ -178,7 +456,7 @@ func (r *ModelReconciler) finalizeCache(ctx context.Context, model *kubeaiv1.Mod
 		}
 
 		if !jobExists {
-			job := r.evictCacheJobForModel(model, cfg)
+			job := r.evictCacheJobForModel(model, cfg, loadedCacheDir(model, pvcModelAnn))
 			if err := ctrl.SetControllerReference(model, job, r.Scheme); err != nil {
 				return fmt.Errorf("setting controller reference on cache deletion job: %w", err)
 			}
@@ -218,9 +496,18 @@ func (r *ModelReconciler) finalizeCache(ctx context.Context, model *kubeaiv1.Mod
 
 func (r *ModelReconciler) deleteAllCacheJobsAndPods(ctx context.Context, model *kubeaiv1.Model) error {
 	jobNames := []string{
-		loadCacheJobName(model),
+		sizeCacheJobName(model),
 		evictCacheJobName(model),
 	}
+	loadJob := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: loadCacheJobName(model)}, loadJob); err == nil {
+		// The load Job of a cache entry is only deleted by the Model that created it.
+		if metav1.IsControlledBy(loadJob, model) {
+			jobNames = append(jobNames, loadJob.Name)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("getting cache job: %w", err)
+	}
 
 	for _, jobName := range jobNames {
 		if err := r.Delete(ctx, &batchv1.Job{
@@ -248,6 +535,66 @@ func (r *ModelReconciler) deleteAllCacheJobsAndPods(ctx context.Context, model *
 	return nil
 }
 
+// cacheVerificationFailed returns true if the model-verifier init container
+// of a model server Pod found that the cached files do not match their
+// manifest since the given time.
+func (r *ModelReconciler) cacheVerificationFailed(ctx context.Context, model *kubeaiv1.Model, since time.Time) (bool, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(model.Namespace), client.MatchingLabels{
+		kubeaiv1.PodModelLabel: model.Name,
+	}); err != nil {
+		return false, fmt.Errorf("listing pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != "model-verifier" {
+				continue
+			}
+			for _, term := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if term != nil && term.ExitCode == cacheVerifyExitCode && term.FinishedAt.After(since) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// patchServerCacheVerifier adds an init container to the model server Pod
+// that verifies the cached files against the manifest recorded by the load
+// Job. Models that fail verification are loaded again.
+func (r *ModelReconciler) patchServerCacheVerifier(pod *corev1.Pod, m *kubeaiv1.Model, c ModelConfig) {
+	if !r.ModelLoaders.Verify || m.Spec.CacheProfile == "" || c.CacheProfile.NodeLocal != nil {
+		return
+	}
+	if m.Status.Cache != nil && m.Status.Cache.ModelCache != "" {
+		// ModelCaches are not loaded again on behalf of a Model.
+		return
+	}
+	if servingCachePVCName(m, c) != cachePVCName(m, c) {
+		// ReadOnlyMany clones can not be repaired by loading the model again.
+		return
+	}
+	command := []string{"manifest", "verify", modelCacheDir(m)}
+	if m.Status.Cache != nil && m.Status.Cache.ManifestSHA256 != "" {
+		// Fail if the manifest was replaced since the model was loaded.
+		command = append(command, m.Status.Cache.ManifestSHA256)
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:    "model-verifier",
+		Image:   r.ModelLoaders.Image,
+		Command: command,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: modelCacheDir(m),
+				SubPath:   strings.TrimPrefix(modelCacheDir(m), "/"),
+				ReadOnly:  true,
+			},
+		},
+	})
+}
+
 func parsePVCModelAnnotation(pvc *corev1.PersistentVolumeClaim, modelName string) (PVCModelAnnotationValue, error) {
 	pvcModelStatusJSON := k8sutils.GetAnnotation(pvc, kubeaiv1.PVCModelAnnotation(modelName))
 	if pvcModelStatusJSON == "" {
@@ -272,7 +619,7 @@ func (r *ModelReconciler) updatePVCModelAnnotation(ctx context.Context, pvc *cor
 	return nil
 }
 
-func (r *ModelReconciler) cachePVCForModel(m *kubeaiv1.Model, c ModelConfig) *corev1.PersistentVolumeClaim {
+func (r *ModelReconciler) cachePVCForModel(m *kubeaiv1.Model, c ModelConfig, size resource.Quantity) (*corev1.PersistentVolumeClaim, error) {
 	pvc := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cachePVCName(m, c),
@@ -282,48 +629,457 @@ func (r *ModelReconciler) cachePVCForModel(m *kubeaiv1.Model, c ModelConfig) *co
 	}
 	switch {
 	case c.CacheProfile.SharedFilesystem != nil:
-		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
-		storageClassName := c.CacheProfile.SharedFilesystem.StorageClassName
+		return sharedCachePVC(m.Namespace, m.Spec.CacheProfile, c.CacheProfile.SharedFilesystem, size), nil
+	case c.CacheProfile.DedicatedVolume != nil:
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		storageClassName := c.CacheProfile.DedicatedVolume.StorageClassName
 		pvc.Spec.StorageClassName = &storageClassName
-		pvc.Spec.VolumeName = c.CacheProfile.SharedFilesystem.PersistentVolumeName
-		pvc.Spec.Resources.Requests = corev1.ResourceList{
-			// https://discuss.huggingface.co/t/how-to-get-model-size/11038/7
-			corev1.ResourceStorage: resource.MustParse("10Gi"),
-		}
 	default:
-		panic("unsupported cache profile, this point should not be reached")
+		return nil, fmt.Errorf("cache profile %q does not configure a supported cache", m.Spec.CacheProfile)
+	}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: size,
+	}
+	return &pvc, nil
+}
+
+// cachePVCSize returns the requested size of the cache PVC. The size of
+// dedicated volumes is derived from the download size of the model which is
+// measured with a Job, errReturnEarly is returned until the Job completed.
+func (r *ModelReconciler) cachePVCSize(ctx context.Context, model *kubeaiv1.Model, cfg ModelConfig) (resource.Quantity, error) {
+	vol := cfg.CacheProfile.DedicatedVolume
+	if vol == nil {
+		// The size of shared filesystems is not enforced by most provisioners.
+		// https://discuss.huggingface.co/t/how-to-get-model-size/11038/7
+		return resource.MustParse("10Gi"), nil
+	}
+	if vol.Size != nil {
+		return *vol.Size, nil
+	}
+
+	sizeJob := &batchv1.Job{}
+	if err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: model.Namespace,
+		Name:      sizeCacheJobName(model),
+	}, sizeJob); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return resource.Quantity{}, fmt.Errorf("getting cache size job: %w", err)
+		}
+		sizeJob = r.sizeCacheJobForModel(model, cfg)
+		if err := ctrl.SetControllerReference(model, sizeJob, r.Scheme); err != nil {
+			return resource.Quantity{}, fmt.Errorf("setting controller reference on cache size job: %w", err)
+		}
+		if err := r.Create(ctx, sizeJob); err != nil {
+			return resource.Quantity{}, fmt.Errorf("creating cache size job: %w", err)
+		}
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			fmt.Sprintf("Measuring the download size of the model with Job %s", sizeJob.Name))
+		return resource.Quantity{}, errReturnEarly
+	}
+
+	if k8sutils.IsJobFailed(sizeJob) {
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoadFailed,
+			fmt.Sprintf("Job %s failed to measure the download size of the model", sizeJob.Name))
+		return resource.Quantity{}, errReturnEarly
+	}
+	if !k8sutils.IsJobCompleted(sizeJob) {
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			fmt.Sprintf("Measuring the download size of the model with Job %s", sizeJob.Name))
+		return resource.Quantity{}, errReturnEarly
+	}
+
+	msg, err := r.jobTerminationMessage(ctx, sizeJob, "sizer")
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	if msg == "" {
+		// The Pods of the Job are gone (i.e. garbage collected), measure again.
+		if err := r.deleteCacheJob(ctx, model, sizeJob.Name); err != nil {
+			return resource.Quantity{}, err
+		}
+		return resource.Quantity{}, errReturnEarly
+	}
+	downloadBytes, err := strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+	if err != nil || downloadBytes < 0 {
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoadFailed,
+			fmt.Sprintf("Job %s reported an invalid download size: %q", sizeJob.Name, msg))
+		return resource.Quantity{}, errReturnEarly
+	}
+	return dedicatedVolumeSize(downloadBytes), nil
+}
+
+// dedicatedVolumeSize adds 10% of headroom to the download size of a model
+// and rounds it up to the next GiB.
+func dedicatedVolumeSize(downloadBytes int64) resource.Quantity {
+	const gib = 1 << 30
+	size := downloadBytes + downloadBytes/10
+	gibs := (size + gib - 1) / gib
+	if gibs < 1 {
+		gibs = 1
+	}
+	return *resource.NewQuantity(gibs*gib, resource.BinarySI)
+}
+
+// jobTerminationMessage returns the termination message of the container
+// of a successful Pod of a Job, or "" if there is no such Pod.
+func (r *ModelReconciler) jobTerminationMessage(ctx context.Context, job *batchv1.Job, containerName string) (string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{
+		batchv1.JobNameLabel: job.Name,
+	}); err != nil {
+		return "", fmt.Errorf("listing pods of job %q: %w", job.Name, err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != containerName || status.State.Terminated == nil || status.State.Terminated.ExitCode != 0 {
+				continue
+			}
+			return status.State.Terminated.Message, nil
+		}
+	}
+	return "", nil
+}
+
+// cacheLoadJobProgress returns the progress of a running load Job and the
+// message of the condition that reports it.
+func (r *ModelReconciler) cacheLoadJobProgress(ctx context.Context, job *batchv1.Job) (*kubeaiv1.ModelCacheLoadProgress, string, error) {
+	logs, err := r.cacheLoaderLogs(ctx, job)
+	if err != nil {
+		return nil, "", err
+	}
+	progress, err := parseCacheLoadProgress(job.Name, logs)
+	if err != nil {
+		// The progress is informational, do not block loading.
+		log.FromContext(ctx).Error(err, "Ignoring the progress of the load Job")
+	}
+	failures, failure, err := r.jobContainerFailures(ctx, job, "loader")
+	if err != nil {
+		return nil, "", err
+	}
+	if failures > 0 {
+		if progress == nil {
+			progress = &kubeaiv1.ModelCacheLoadProgress{}
+		}
+		progress.Restarts = failures
+	}
+	return progress, cacheLoadingMessage(job.Name, progress, failure), nil
+}
+
+func cacheLoadingMessage(jobName string, progress *kubeaiv1.ModelCacheLoadProgress, failure string) string {
+	msg := fmt.Sprintf("Loading the model into the cache with Job %s", jobName)
+	if progress == nil {
+		return msg
+	}
+	if progress.TotalBytes > 0 {
+		percent := min(100, progress.DownloadedBytes*100/progress.TotalBytes)
+		msg += fmt.Sprintf(" (%d%% downloaded)", percent)
+	}
+	if progress.Restarts > 0 {
+		msg += fmt.Sprintf(", resumed after %d failures", progress.Restarts)
+		if failure != "" {
+			msg += ", last failure: " + failure
+		}
+	}
+	return msg
+}
+
+const (
+	// cacheLoadProgressLogPrefix prefixes the progress that the model loader
+	// prints to its logs (see progress.py).
+	cacheLoadProgressLogPrefix = "kubeai-load-progress "
+	// cacheLoadProgressPeriod is the interval in which the progress of load
+	// Jobs is read from the logs of the loader.
+	cacheLoadProgressPeriod = 15 * time.Second
+)
+
+// cacheLoaderLogs returns the recent logs of the running loader of a Job.
+// The loader does not need access to the Kubernetes API to report its progress.
+func (r *ModelReconciler) cacheLoaderLogs(ctx context.Context, job *batchv1.Job) (string, error) {
+	if r.PodRESTClient == nil {
+		return "", nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{
+		batchv1.JobNameLabel: job.Name,
+	}); err != nil {
+		return "", fmt.Errorf("listing pods of job %q: %w", job.Name, err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != "loader" || status.State.Running == nil {
+				continue
+			}
+			logs, err := r.PodRESTClient.Get().
+				Namespace(pod.Namespace).
+				Resource("pods").
+				Name(pod.Name).
+				SubResource("log").
+				VersionedParams(&corev1.PodLogOptions{
+					Container:    "loader",
+					SinceSeconds: ptr.To(int64(4 * cacheLoadProgressPeriod / time.Second)),
+				}, runtime.NewParameterCodec(r.Scheme)).
+				DoRaw(ctx)
+			if err != nil {
+				// The progress is informational, do not block loading.
+				log.FromContext(ctx).Info("Unable to read the logs of the loader", "pod", pod.Name, "error", err.Error())
+				return "", nil
+			}
+			return string(logs), nil
+		}
+	}
+	return "", nil
+}
+
+// cacheLoadProgress is printed by the model loader to its logs.
+type cacheLoadProgress struct {
+	DownloadedBytes int64     `json:"downloadedBytes"`
+	TotalBytes      int64     `json:"totalBytes"`
+	BytesPerSecond  int64     `json:"bytesPerSecond"`
+	UpdateTime      time.Time `json:"updateTime"`
+}
+
+// parseCacheLoadProgress returns the last progress in the logs of a loader.
+func parseCacheLoadProgress(jobName, logs string) (*kubeaiv1.ModelCacheLoadProgress, error) {
+	var value string
+	lines := strings.Split(logs, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), cacheLoadProgressLogPrefix); ok {
+			value = v
+			break
+		}
+	}
+	if value == "" {
+		return nil, nil
+	}
+	var reported cacheLoadProgress
+	if err := json.Unmarshal([]byte(value), &reported); err != nil {
+		return nil, fmt.Errorf("parsing progress of job %q: %w", jobName, err)
+	}
+	progress := &kubeaiv1.ModelCacheLoadProgress{
+		DownloadedBytes: reported.DownloadedBytes,
+		TotalBytes:      reported.TotalBytes,
+	}
+	if reported.BytesPerSecond > 0 && reported.TotalBytes > reported.DownloadedBytes {
+		remaining := time.Duration((reported.TotalBytes-reported.DownloadedBytes)/reported.BytesPerSecond) * time.Second
+		progress.EstimatedCompletionTime = ptr.To(metav1.NewTime(reported.UpdateTime.Add(remaining)))
+	}
+	return progress, nil
+}
+
+// jobContainerFailures returns the number of times that the container of
+// the Pods of a Job failed and the last line of the termination message of
+// the most recent failure.
+func (r *ModelReconciler) jobContainerFailures(ctx context.Context, job *batchv1.Job, containerName string) (int32, string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{
+		batchv1.JobNameLabel: job.Name,
+	}); err != nil {
+		return 0, "", fmt.Errorf("listing pods of job %q: %w", job.Name, err)
+	}
+	var failures int32
+	var last *corev1.ContainerStateTerminated
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != containerName {
+				continue
+			}
+			failures += status.RestartCount
+			if term := status.State.Terminated; term != nil && term.ExitCode != 0 {
+				failures++
+			}
+			for _, term := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if term == nil || term.ExitCode == 0 {
+					continue
+				}
+				if last == nil || term.FinishedAt.After(last.FinishedAt.Time) {
+					last = term
+				}
+			}
+		}
+	}
+	if last == nil {
+		return failures, "", nil
+	}
+	return failures, lastLogLine(last.Message), nil
+}
+
+// lastLogLine returns the last line of the logs of a loader (that are used
+// as the termination message on failure), skipping traced commands and
+// progress reports.
+func lastLogLine(logs string) string {
+	lines := strings.Split(logs, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, cacheLoadProgressLogPrefix) {
+			return line
+		}
+	}
+	return ""
+}
+
+func (r *ModelReconciler) deleteCacheJob(ctx context.Context, model *kubeaiv1.Model, jobName string) error {
+	if err := r.Delete(ctx, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: model.Namespace,
+			Name:      jobName,
+		},
+	}, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting job %q: %w", jobName, err)
+		}
+	}
+	return nil
+}
+
+// reconcileReadOnlyManyCache clones the loaded dedicated volume of a Model
+// into a ReadOnlyMany PVC that is mounted by the model server Pods, either
+// directly or through a VolumeSnapshot.
+func (r *ModelReconciler) reconcileReadOnlyManyCache(ctx context.Context, model *kubeaiv1.Model, cfg ModelConfig, loaded *corev1.PersistentVolumeClaim) error {
+	rox := cfg.CacheProfile.DedicatedVolume.ReadOnlyMany
+
+	dataSource := &corev1.TypedLocalObjectReference{
+		Kind: "PersistentVolumeClaim",
+		Name: loaded.Name,
+	}
+	if rox.VolumeSnapshotClassName != "" {
+		snapshot := newVolumeSnapshot()
+		if err := r.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: loaded.Name}, snapshot); err != nil {
+			if meta.IsNoMatchError(err) {
+				setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionTrue, kubeaiv1.ModelReasonInvalidConfiguration,
+					"the VolumeSnapshot API is not installed in the cluster")
+				return errReturnEarly
+			}
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("getting cache volume snapshot: %w", err)
+			}
+			snapshot = volumeSnapshotForPVC(loaded, rox.VolumeSnapshotClassName)
+			if err := ctrl.SetControllerReference(model, snapshot, r.Scheme); err != nil {
+				return fmt.Errorf("setting controller reference on volume snapshot: %w", err)
+			}
+			if err := r.Create(ctx, snapshot); err != nil {
+				return fmt.Errorf("creating cache volume snapshot: %w", err)
+			}
+		}
+		dataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: ptr.To(volumeSnapshotGVK.Group),
+			Kind:     volumeSnapshotGVK.Kind,
+			Name:     snapshot.GetName(),
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: readOnlyManyCachePVCName(model, cfg)}, pvc); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("getting read only cache PVC: %w", err)
+	}
+
+	storageClassName := rox.StorageClassName
+	if storageClassName == "" {
+		storageClassName = cfg.CacheProfile.DedicatedVolume.StorageClassName
+	}
+	pvc = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      readOnlyManyCachePVCName(model, cfg),
+			Namespace: model.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany},
+			StorageClassName: &storageClassName,
+			Resources:        loaded.Spec.Resources,
+			DataSource:       dataSource,
+		},
+	}
+	if err := ctrl.SetControllerReference(model, pvc, r.Scheme); err != nil {
+		return fmt.Errorf("setting controller reference on read only cache pvc: %w", err)
 	}
-	return &pvc
+	if err := r.Create(ctx, pvc); err != nil {
+		return fmt.Errorf("creating read only cache PVC: %w", err)
+	}
+	return nil
 }
 
 func cachePVCName(m *kubeaiv1.Model, c ModelConfig) string {
 	switch {
 	case c.CacheProfile.SharedFilesystem != nil:
 		// One PVC for all models.
-		return fmt.Sprintf("shared-model-cache-%s", m.Spec.CacheProfile)
+		return sharedCachePVCName(m.Spec.CacheProfile)
 	default:
 		// One PVC per model.
 		return fmt.Sprintf("model-cache-%s-%s", m.Name, m.UID[0:7])
 	}
 }
 
-func (r *ModelReconciler) loadCacheJobForModel(m *kubeaiv1.Model, c ModelConfig) *batchv1.Job {
+// sharedCachePVC returns the PVC of a sharedFilesystem cache profile that
+// is shared by all Models and ModelCaches of the profile.
+func sharedCachePVC(namespace, cacheProfile string, profile *config.CacheSharedFilesystem, size resource.Quantity) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sharedCachePVCName(cacheProfile),
+			Namespace: namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: ptr.To(profile.StorageClassName),
+			VolumeName:       profile.PersistentVolumeName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
+
+func sharedCachePVCName(cacheProfile string) string {
+	return fmt.Sprintf("shared-model-cache-%s", cacheProfile)
+}
+
+func readOnlyManyCachePVCName(m *kubeaiv1.Model, c ModelConfig) string {
+	return cachePVCName(m, c) + "-ro"
+}
+
+// servingCachePVCName returns the name of the PVC that is mounted by the
+// model server Pods.
+func servingCachePVCName(m *kubeaiv1.Model, c ModelConfig) string {
+	if vol := c.CacheProfile.DedicatedVolume; vol != nil && vol.ReadOnlyMany != nil {
+		return readOnlyManyCachePVCName(m, c)
+	}
+	return cachePVCName(m, c)
+}
+
+func cacheJobEnv(vars map[string]string) []corev1.EnvVar {
 	var env []corev1.EnvVar
 	var envKeys []string
-	for key := range m.Spec.Env {
+	for key := range vars {
 		envKeys = append(envKeys, key)
 	}
 	sort.Strings(envKeys)
 	for _, key := range envKeys {
 		env = append(env, corev1.EnvVar{
 			Name:  key,
-			Value: m.Spec.Env[key],
+			Value: vars[key],
 		})
 	}
+	return env
+}
+
+// cacheLoaderEnv configures the model loader of a load Job: the number of
+// parallel downloads and the Job that the progress is reported for.
+func (r *ModelReconciler) cacheLoaderEnv(jobName string) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{Name: "KUBEAI_JOB_NAME", Value: jobName},
+	}
+	if r.ModelLoaders.Parallelism > 0 {
+		env = append(env, corev1.EnvVar{Name: "LOAD_PARALLELISM", Value: strconv.Itoa(int(r.ModelLoaders.Parallelism))})
+	}
+	return env
+}
 
+func (r *ModelReconciler) sizeCacheJobForModel(m *kubeaiv1.Model, c ModelConfig) *batchv1.Job {
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      loadCacheJobName(m),
+			Name:      sizeCacheJobName(m),
 			Namespace: m.Namespace,
 		},
 		Spec: batchv1.JobSpec{
@@ -333,15 +1089,76 @@ func (r *ModelReconciler) loadCacheJobForModel(m *kubeaiv1.Model, c ModelConfig)
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
 					RestartPolicy: corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{
+						{
+							Name: "sizer",
+							Env:  cacheJobEnv(m.Spec.Env),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	job.Spec.Template.Spec.Containers[0].Image = r.ModelLoaders.Image
+	job.Spec.Template.Spec.Containers[0].Command = []string{"/bin/size"}
+	job.Spec.Template.Spec.Containers[0].Args = []string{m.Spec.URL}
+	c.Source.modelSourcePodAdditions.applyToPodSpec(&job.Spec.Template.Spec, 0)
+
+	return job
+}
+
+func (r *ModelReconciler) loadCacheJobForModel(m *kubeaiv1.Model, c ModelConfig) *batchv1.Job {
+	job := r.loadCacheJob(loadCacheJobName(m), m.Namespace, m.Spec.URL, m.Spec.Env, modelCacheDir(m), cachePVCName(m, c), c.Source)
+	if m.Status.Cache != nil && m.Status.Cache.Entry != "" {
+		job.Labels = map[string]string{cacheEntryLabel: m.Status.Cache.Entry}
+	}
+	return job
+}
+
+// patchCacheRefreshJob makes a load Job refresh a loaded model: the model is
+// loaded into a new directory next to the cache directory, which is replaced
+// with a symlink to the new directory once the model is loaded. Model servers
+// that were started from the previous load are not affected.
+func patchCacheRefreshJob(job *batchv1.Job, id string) {
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{Name: "KUBEAI_REFRESH", Value: id})
+	container.VolumeMounts[0].MountPath = "/models"
+	container.VolumeMounts[0].SubPath = "models"
+}
+
+// loadCacheJob returns a Job that loads the model at the url into the
+// directory of a cache PVC.
+func (r *ModelReconciler) loadCacheJob(name, namespace, url string, vars map[string]string, dir, pvcName string, src modelSource) *batchv1.Job {
+	env := cacheJobEnv(vars)
+
+	env = append(env, r.cacheLoaderEnv(name)...)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: batchv1.JobSpec{
+			TTLSecondsAfterFinished: ptr.To[int32](60),
+			Parallelism:             ptr.To[int32](1),
+			Completions:             ptr.To[int32](1),
+			BackoffLimit:            r.ModelLoaders.BackoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: r.ModelLoaders.ServiceAccountName,
 					Containers: []corev1.Container{
 						{
 							Name: "loader",
 							Env:  env,
+							// The logs explain failures of the loader.
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      "model",
-									MountPath: modelCacheDir(m),
-									SubPath:   strings.TrimPrefix(modelCacheDir(m), "/"),
+									MountPath: dir,
+									SubPath:   strings.TrimPrefix(dir, "/"),
 								},
 							},
 						},
@@ -351,7 +1168,7 @@ func (r *ModelReconciler) loadCacheJobForModel(m *kubeaiv1.Model, c ModelConfig)
 							Name: "model",
 							VolumeSource: corev1.VolumeSource{
 								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: cachePVCName(m, c),
+									ClaimName: pvcName,
 								},
 							},
 						},
@@ -363,19 +1180,24 @@ func (r *ModelReconciler) loadCacheJobForModel(m *kubeaiv1.Model, c ModelConfig)
 
 	job.Spec.Template.Spec.Containers[0].Image = r.ModelLoaders.Image
 	job.Spec.Template.Spec.Containers[0].Args = []string{
-		m.Spec.URL,
-		modelCacheDir(m),
+		url,
+		dir,
 	}
-	c.Source.modelSourcePodAdditions.applyToPodSpec(&job.Spec.Template.Spec, 0)
+	src.modelSourcePodAdditions.applyToPodSpec(&job.Spec.Template.Spec, 0)
 
 	return job
 }
 
-func (r *ModelReconciler) evictCacheJobForModel(m *kubeaiv1.Model, c ModelConfig) *batchv1.Job {
+func (r *ModelReconciler) evictCacheJobForModel(m *kubeaiv1.Model, c ModelConfig, dir string) *batchv1.Job {
+	return r.evictCacheJob(evictCacheJobName(m), m.Namespace, dir, cachePVCName(m, c))
+}
+
+// evictCacheJob returns a Job that removes a directory from a cache PVC.
+func (r *ModelReconciler) evictCacheJob(name, namespace, dir, pvcName string) *batchv1.Job {
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      evictCacheJobName(m),
-			Namespace: m.Namespace,
+			Name:      name,
+			Namespace: namespace,
 		},
 		Spec: batchv1.JobSpec{
 			TTLSecondsAfterFinished: ptr.To[int32](60),
@@ -401,7 +1223,7 @@ func (r *ModelReconciler) evictCacheJobForModel(m *kubeaiv1.Model, c ModelConfig
 							Name: "model",
 							VolumeSource: corev1.VolumeSource{
 								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: cachePVCName(m, c),
+									ClaimName: pvcName,
 								},
 							},
 						},
@@ -412,16 +1234,42 @@ func (r *ModelReconciler) evictCacheJobForModel(m *kubeaiv1.Model, c ModelConfig
 	}
 
 	job.Spec.Template.Spec.Containers[0].Image = r.ModelLoaders.Image
-	job.Spec.Template.Spec.Containers[0].Command = []string{"bash", "-c", "rm -rf " + modelCacheDir(m)}
+	// Also remove the directories of refreshes (see patchCacheRefreshJob).
+	job.Spec.Template.Spec.Containers[0].Command = []string{"bash", "-c", fmt.Sprintf("rm -rf %s %s_*", dir, dir)}
 
 	return job
 }
 
+// modelCacheDir returns the directory that a Model is served from: the
+// directory of the ModelCache that it is served from, the directory of its
+// cache entry or its own directory.
 func modelCacheDir(m *kubeaiv1.Model) string {
+	switch {
+	case m.Status.Cache != nil && m.Status.Cache.ModelCache != "":
+		return modelCacheDirOfModelCache(m.Status.Cache.ModelCache)
+	case m.Status.Cache != nil && m.Status.Cache.Entry != "":
+		return cacheEntryDir(m.Status.Cache.Entry)
+	default:
+		return ownModelCacheDir(m)
+	}
+}
+
+// ownModelCacheDir returns the directory of a Model in caches without cache
+// entries, and of models that were loaded before cache entries were introduced.
+func ownModelCacheDir(m *kubeaiv1.Model) string {
 	return fmt.Sprintf("/models/%s-%s", m.Name, m.UID)
 }
 
+func sizeCacheJobName(m *kubeaiv1.Model) string {
+	return fmt.Sprintf("size-cache-%s", m.Name)
+}
+
+// loadCacheJobName returns the name of the Job that loads the model of a
+// Model. Models with the same cache entry share the load Job of the entry.
 func loadCacheJobName(m *kubeaiv1.Model) string {
+	if m.Status.Cache != nil && m.Status.Cache.Entry != "" {
+		return cacheEntryLoadJobName(m.Status.Cache.Entry)
+	}
 	return fmt.Sprintf("load-cache-%s", m.Name)
 }
 
@@ -433,22 +1281,64 @@ func patchServerCacheVolumes(podSpec *corev1.PodSpec, m *kubeaiv1.Model, c Model
 	if m.Spec.CacheProfile == "" {
 		return
 	}
+	if profile := c.CacheProfile.NodeLocal; profile != nil {
+		podSpec.Volumes = append(podSpec.Volumes, nodeCacheVolume(profile, m.Namespace))
+		mountServerCacheVolume(podSpec, corev1.VolumeMount{
+			Name:      "models",
+			MountPath: modelCacheDir(m),
+			SubPath:   path.Base(modelCacheDir(m)),
+			ReadOnly:  true,
+		})
+		return
+	}
 	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
 		Name: "models",
 		VolumeSource: corev1.VolumeSource{
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-				ClaimName: cachePVCName(m, c),
+				ClaimName: servingCachePVCName(m, c),
+				// ReadOnlyMany volumes must be attached read only.
+				ReadOnly: servingCachePVCName(m, c) != cachePVCName(m, c),
 			},
 		},
 	})
+	mountServerCacheVolume(podSpec, corev1.VolumeMount{
+		Name:      "models",
+		MountPath: modelCacheDir(m),
+		SubPath:   strings.TrimPrefix(modelCacheDir(m), "/"),
+		ReadOnly:  true,
+	})
+}
+
+// mountServerCacheVolume mounts the cache volume into the server container.
+func mountServerCacheVolume(podSpec *corev1.PodSpec, mount corev1.VolumeMount) {
 	for i := range podSpec.Containers {
-		if podSpec.Containers[i].Name == "server" {
-			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
-				Name:      "models",
-				MountPath: modelCacheDir(m),
-				SubPath:   strings.TrimPrefix(modelCacheDir(m), "/"),
-				ReadOnly:  true,
-			})
+		if podSpec.Containers[i].Name == serverContainerName {
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, mount)
 		}
 	}
 }
+
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+func newVolumeSnapshot() *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	return snapshot
+}
+
+func volumeSnapshotForPVC(pvc *corev1.PersistentVolumeClaim, volumeSnapshotClassName string) *unstructured.Unstructured {
+	snapshot := newVolumeSnapshot()
+	snapshot.SetName(pvc.Name)
+	snapshot.SetNamespace(pvc.Namespace)
+	snapshot.Object["spec"] = map[string]interface{}{
+		"volumeSnapshotClassName": volumeSnapshotClassName,
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvc.Name,
+		},
+	}
+	return snapshot
+}
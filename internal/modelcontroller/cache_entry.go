@@ -0,0 +1,204 @@
+package modelcontroller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// cacheEntryLabel is set on the load Jobs of Models to the cache entry
+	// that they load the model into.
+	cacheEntryLabel = "cache.kubeai.org/entry"
+
+	// cacheEntryWaitPeriod is the interval at which Models check whether the
+	// load Job of another Model loaded the model of a cache entry.
+	cacheEntryWaitPeriod = 10 * time.Second
+)
+
+// cacheEntryKey returns the normalized form of a model url that identifies
+// the files that are loaded from it. Parts of the url that do not change the
+// files (e.g. the query of signed "https://" urls) are dropped.
+func cacheEntryKey(u modelURL) string {
+	key := u.scheme + "://" + strings.Trim(u.ref, "/")
+	if u.modelParam != "" {
+		key += "?model=" + u.modelParam
+	}
+	if u.revision != "" {
+		key += "@" + u.revision
+	}
+	return key
+}
+
+// cacheEntryName returns the name of the cache entry of a model url in a
+// sharedFilesystem cache. Models with the same url share the entry.
+func cacheEntryName(u modelURL) string {
+	sum := sha256.Sum256([]byte(cacheEntryKey(u)))
+	return "url-" + hex.EncodeToString(sum[:])[:16]
+}
+
+func cacheEntryDir(entry string) string {
+	return "/models/" + entry
+}
+
+// cacheEntryRefs returns the PVC annotations of the Models other than the
+// given one that reference the loaded model of a cache entry, by Model name.
+func cacheEntryRefs(pvc *corev1.PersistentVolumeClaim, entry, exceptModel string) (map[string]PVCModelAnnotationValue, error) {
+	refs := map[string]PVCModelAnnotationValue{}
+	for key := range pvc.Annotations {
+		name, ok := strings.CutPrefix(key, kubeaiv1.PVCModelAnnotation(""))
+		if !ok || name == exceptModel {
+			continue
+		}
+		ann, err := parsePVCModelAnnotation(pvc, name)
+		if err != nil {
+			return nil, fmt.Errorf("parsing pvc model annotation of model %q: %w", name, err)
+		}
+		if ann.Entry == entry && !ann.Evicted {
+			refs[name] = ann
+		}
+	}
+	return refs, nil
+}
+
+// firstCacheEntryRef returns the annotation of the referencing Model with the
+// lowest name, false if the entry is not referenced.
+func firstCacheEntryRef(refs map[string]PVCModelAnnotationValue) (PVCModelAnnotationValue, bool) {
+	if len(refs) == 0 {
+		return PVCModelAnnotationValue{}, false
+	}
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return refs[names[0]], true
+}
+
+// cacheEntryLoadJobName returns the name of the Job that loads the model of
+// a cache entry. The Job is created by the first Model with the url of the
+// entry, its creation acts as a lock on the entry: other Models wait for it.
+func cacheEntryLoadJobName(entry string) string {
+	return "load-" + entry
+}
+
+// cacheEntryModels returns the names of the Models other than the given one
+// that are loaded into a cache entry or wait for it to be loaded.
+func (r *ModelReconciler) cacheEntryModels(ctx context.Context, namespace, entry, exceptModel string) ([]string, error) {
+	var models kubeaiv1.ModelList
+	if err := r.List(ctx, &models, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing models: %w", err)
+	}
+	var names []string
+	for _, m := range models.Items {
+		if m.Name == exceptModel || m.DeletionTimestamp != nil {
+			continue
+		}
+		if m.Status.Cache != nil && m.Status.Cache.Entry == entry {
+			names = append(names, m.Name)
+		}
+	}
+	return names, nil
+}
+
+// loadedCacheEntry returns the cache entry that the model of a Model was
+// loaded into according to its PVC annotation, or is being loaded into.
+// Empty for models that were loaded into a directory of their own.
+func loadedCacheEntry(m *kubeaiv1.Model, ann PVCModelAnnotationValue) string {
+	if ann.UID == string(m.UID) {
+		return ann.Entry
+	}
+	if m.Status.Cache != nil {
+		return m.Status.Cache.Entry
+	}
+	return ""
+}
+
+// loadedCacheDir returns the directory that the model of a Model was loaded
+// into, see loadedCacheEntry.
+func loadedCacheDir(m *kubeaiv1.Model, ann PVCModelAnnotationValue) string {
+	if entry := loadedCacheEntry(m, ann); entry != "" {
+		return cacheEntryDir(entry)
+	}
+	return ownModelCacheDir(m)
+}
+
+// referenceCacheEntry references the model of a cache entry in the PVC
+// annotation of a Model if it was loaded by another Model with the same url.
+// It returns false if the model of the entry was not loaded yet.
+func (r *ModelReconciler) referenceCacheEntry(ctx context.Context, model *kubeaiv1.Model, pvc *corev1.PersistentVolumeClaim, entry string) (bool, error) {
+	refs, err := cacheEntryRefs(pvc, entry, model.Name)
+	if err != nil {
+		return false, err
+	}
+	ref, ok := firstCacheEntryRef(refs)
+	if !ok {
+		return false, nil
+	}
+	if err := r.updatePVCModelAnnotation(ctx, pvc, model.Name, PVCModelAnnotationValue{
+		UID:            string(model.UID),
+		Timestamp:      time.Now(),
+		SizeBytes:      ref.SizeBytes,
+		Revision:       ref.Revision,
+		ManifestSHA256: ref.ManifestSHA256,
+		Entry:          entry,
+	}); err != nil {
+		return false, fmt.Errorf("setting pvc model annotation: %w", err)
+	}
+	return true, nil
+}
+
+// repointCacheEntryRefs points the PVC annotations of the Models that
+// reference a cache entry to a new load of its model.
+func (r *ModelReconciler) repointCacheEntryRefs(ctx context.Context, pvc *corev1.PersistentVolumeClaim, entry string, report cacheLoadReport) error {
+	refs, err := cacheEntryRefs(pvc, entry, "")
+	if err != nil {
+		return err
+	}
+	for name, ref := range refs {
+		ref.Timestamp = time.Now()
+		ref.SizeBytes = report.SizeBytes
+		ref.Revision = report.Revision
+		ref.ManifestSHA256 = report.ManifestSHA256
+		refJSON, err := json.Marshal(ref)
+		if err != nil {
+			return fmt.Errorf("marshalling pvc model status: %w", err)
+		}
+		k8sutils.SetAnnotation(pvc, kubeaiv1.PVCModelAnnotation(name), string(refJSON))
+	}
+	if err := r.Update(ctx, pvc); err != nil {
+		return fmt.Errorf("updating pvc: %w", err)
+	}
+	return nil
+}
+
+// modelsForCacheEntryJob maps the load Job of a cache entry to the Models
+// that wait for it to load the model of the entry.
+func (r *ModelReconciler) modelsForCacheEntryJob(ctx context.Context, obj client.Object) []reconcile.Request {
+	entry := obj.GetLabels()[cacheEntryLabel]
+	if entry == "" {
+		return nil
+	}
+	var models kubeaiv1.ModelList
+	if err := r.List(ctx, &models, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, m := range models.Items {
+		if m.Status.Cache != nil && m.Status.Cache.Entry == entry {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&m)})
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,185 @@
+package modelcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_cacheEntryKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		url  string
+		want string
+	}{
+		"hf": {
+			url:  "hf://org/model",
+			want: "hf://org/model",
+		},
+		"hf revision": {
+			url:  "hf://org/model@0123abcd",
+			want: "hf://org/model@0123abcd",
+		},
+		"trailing slash": {
+			url:  "s3://bucket/path/to/model/",
+			want: "s3://bucket/path/to/model",
+		},
+		"s3 version": {
+			url:  "s3://bucket/model.gguf?versionId=abc",
+			want: "s3://bucket/model.gguf@abc",
+		},
+		"signed https": {
+			url:  "https://host/path/model.gguf?sig=abc#sha256=def",
+			want: "https://host/path/model.gguf?model=model.gguf@sha256:def",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			u, err := parseModelURL(c.url)
+			require.NoError(t, err)
+			require.Equal(t, c.want, cacheEntryKey(u))
+		})
+	}
+}
+
+func Test_cacheEntryName(t *testing.T) {
+	t.Parallel()
+
+	parse := func(s string) modelURL {
+		u, err := parseModelURL(s)
+		require.NoError(t, err)
+		return u
+	}
+	name := cacheEntryName(parse("https://host/model.tar.gz?sig=abc"))
+	require.Regexp(t, "^url-[0-9a-f]{16}$", name)
+	require.Equal(t, name, cacheEntryName(parse("https://host/model.tar.gz?sig=def")))
+	require.NotEqual(t, cacheEntryName(parse("hf://org/model")), cacheEntryName(parse("hf://org/model@0123abcd")))
+}
+
+func Test_cacheEntryRefs(t *testing.T) {
+	t.Parallel()
+
+	annotation := func(v PVCModelAnnotationValue) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(b)
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				v1.PVCModelAnnotation("a"):       annotation(PVCModelAnnotationValue{UID: "a", Entry: "url-1", SizeBytes: 1}),
+				v1.PVCModelAnnotation("b"):       annotation(PVCModelAnnotationValue{UID: "b", Entry: "url-1", SizeBytes: 2}),
+				v1.PVCModelAnnotation("evicted"): annotation(PVCModelAnnotationValue{UID: "c", Entry: "url-1", Evicted: true}),
+				v1.PVCModelAnnotation("other"):   annotation(PVCModelAnnotationValue{UID: "d", Entry: "url-2"}),
+				v1.PVCModelAnnotation("legacy"):  annotation(PVCModelAnnotationValue{UID: "e"}),
+				"unrelated":                      "x",
+			},
+		},
+	}
+
+	refs, err := cacheEntryRefs(pvc, "url-1", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, slices.Sorted(maps.Keys(refs)))
+	first, ok := firstCacheEntryRef(refs)
+	require.True(t, ok)
+	require.Equal(t, int64(1), first.SizeBytes)
+
+	refs, err = cacheEntryRefs(pvc, "url-1", "a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, slices.Sorted(maps.Keys(refs)))
+
+	refs, err = cacheEntryRefs(pvc, "url-3", "")
+	require.NoError(t, err)
+	_, ok = firstCacheEntryRef(refs)
+	require.False(t, ok)
+}
+
+func Test_loadedCacheDir(t *testing.T) {
+	t.Parallel()
+
+	m := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "m", UID: "uid"}}
+	require.Equal(t, "/models/m-uid", loadedCacheDir(m, PVCModelAnnotationValue{}))
+	require.Equal(t, "/models/m-uid", loadedCacheDir(m, PVCModelAnnotationValue{UID: "uid"}))
+	require.Equal(t, "/models/url-1", loadedCacheDir(m, PVCModelAnnotationValue{UID: "uid", Entry: "url-1"}))
+
+	// Models that are being loaded into a cache entry have no annotation yet.
+	m.Status.Cache = &v1.ModelStatusCache{Entry: "url-2"}
+	require.Equal(t, "/models/url-2", loadedCacheDir(m, PVCModelAnnotationValue{}))
+	require.Equal(t, "/models/url-1", loadedCacheDir(m, PVCModelAnnotationValue{UID: "uid", Entry: "url-1"}))
+}
+
+func Test_loadCacheJobName(t *testing.T) {
+	t.Parallel()
+
+	m := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "m", UID: "uid"}}
+	require.Equal(t, "load-cache-m", loadCacheJobName(m))
+
+	// Models with the same cache entry share its load Job.
+	m.Status.Cache = &v1.ModelStatusCache{Entry: "url-1"}
+	require.Equal(t, "load-url-1", loadCacheJobName(m))
+	other := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other-uid"}}
+	other.Status.Cache = &v1.ModelStatusCache{Entry: "url-1"}
+	require.Equal(t, loadCacheJobName(m), loadCacheJobName(other))
+}
+
+func Test_repointCacheEntryRefs(t *testing.T) {
+	t.Parallel()
+
+	annotation := func(v PVCModelAnnotationValue) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(b)
+	}
+	loaded := time.Now().Add(-time.Hour)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "shared-model-cache-test",
+			Annotations: map[string]string{
+				v1.PVCModelAnnotation("a"):     annotation(PVCModelAnnotationValue{UID: "a", Entry: "url-1", Timestamp: loaded, SizeBytes: 1, ManifestSHA256: "old"}),
+				v1.PVCModelAnnotation("b"):     annotation(PVCModelAnnotationValue{UID: "b", Entry: "url-1", Timestamp: loaded, SizeBytes: 1, ManifestSHA256: "old"}),
+				v1.PVCModelAnnotation("other"): annotation(PVCModelAnnotationValue{UID: "c", Entry: "url-2", Timestamp: loaded, ManifestSHA256: "other"}),
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()}
+
+	require.NoError(t, r.repointCacheEntryRefs(context.Background(), pvc, "url-1", cacheLoadReport{
+		SizeBytes:      2,
+		Revision:       "0123abcd",
+		ManifestSHA256: "new",
+	}))
+
+	got := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(pvc), got))
+	for _, name := range []string{"a", "b"} {
+		ann, err := parsePVCModelAnnotation(got, name)
+		require.NoError(t, err)
+		require.Equal(t, name, ann.UID)
+		require.Equal(t, "url-1", ann.Entry)
+		require.Equal(t, int64(2), ann.SizeBytes)
+		require.Equal(t, "0123abcd", ann.Revision)
+		require.Equal(t, "new", ann.ManifestSHA256)
+		// Verification failures before the new load are ignored.
+		require.True(t, ann.Timestamp.After(loaded))
+	}
+	other, err := parsePVCModelAnnotation(got, "other")
+	require.NoError(t, err)
+	require.Equal(t, "other", other.ManifestSHA256)
+}
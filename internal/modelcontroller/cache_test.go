@@ -0,0 +1,302 @@
+package modelcontroller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_dedicatedVolumeSize(t *testing.T) {
+	const gib = 1 << 30
+	cases := map[int64]string{
+		0:              "1Gi",
+		100:            "1Gi",
+		gib:            "2Gi",
+		15 * gib:       "17Gi",
+		20 * gib:       "22Gi",
+		140 * gib / 10: "16Gi",
+	}
+	for downloadBytes, want := range cases {
+		size := dedicatedVolumeSize(downloadBytes)
+		require.Equal(t, want, size.String(), "download size %d", downloadBytes)
+	}
+}
+
+func Test_reconcileCacheUnsupportedProfile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns", UID: "0123456789"},
+		Spec:       v1.ModelSpec{CacheProfile: "empty"},
+	}
+
+	_, err := r.reconcileCache(context.Background(), model, ModelConfig{})
+	require.ErrorContains(t, err, `cache profile "empty" does not configure a supported cache`)
+	cond := meta.FindStatusCondition(model.Status.Conditions, v1.ModelConditionCacheLoaded)
+	require.NotNil(t, cond, "the error should be surfaced as a condition")
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, v1.ModelReasonInvalidConfiguration, cond.Reason)
+}
+
+func Test_patchServerCacheVolumes(t *testing.T) {
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: types.UID("1234567890")},
+		Spec:       v1.ModelSpec{CacheProfile: "dedicated"},
+	}
+	newPodSpec := func() *corev1.PodSpec {
+		return &corev1.PodSpec{Containers: []corev1.Container{{Name: serverContainerName}}}
+	}
+
+	cases := map[string]struct {
+		profile      config.CacheProfile
+		wantClaim    string
+		wantReadOnly bool
+	}{
+		"shared filesystem": {
+			profile:   config.CacheProfile{SharedFilesystem: &config.CacheSharedFilesystem{StorageClassName: "efs"}},
+			wantClaim: "shared-model-cache-dedicated",
+		},
+		"dedicated volume": {
+			profile:   config.CacheProfile{DedicatedVolume: &config.CacheDedicatedVolume{StorageClassName: "pd"}},
+			wantClaim: "model-cache-test-mdl-1234567",
+		},
+		"dedicated volume with read only many clone": {
+			profile: config.CacheProfile{DedicatedVolume: &config.CacheDedicatedVolume{
+				StorageClassName: "hyperdisk-ml",
+				ReadOnlyMany:     &config.CacheReadOnlyMany{},
+			}},
+			wantClaim:    "model-cache-test-mdl-1234567-ro",
+			wantReadOnly: true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			podSpec := newPodSpec()
+			patchServerCacheVolumes(podSpec, model, ModelConfig{CacheProfile: c.profile})
+			require.Len(t, podSpec.Volumes, 1)
+			require.Equal(t, c.wantClaim, podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+			require.Equal(t, c.wantReadOnly, podSpec.Volumes[0].PersistentVolumeClaim.ReadOnly)
+			require.Equal(t, []corev1.VolumeMount{{
+				Name:      "models",
+				MountPath: "/models/test-mdl-1234567890",
+				SubPath:   "models/test-mdl-1234567890",
+				ReadOnly:  true,
+			}}, podSpec.Containers[0].VolumeMounts)
+		})
+	}
+}
+
+func Test_patchServerCacheVerifier(t *testing.T) {
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: types.UID("1234567890")},
+		Spec:       v1.ModelSpec{CacheProfile: "test-profile"},
+		Status:     v1.ModelStatus{Cache: &v1.ModelStatusCache{ManifestSHA256: "abc"}},
+	}
+	shared := config.CacheProfile{SharedFilesystem: &config.CacheSharedFilesystem{StorageClassName: "efs"}}
+
+	cases := map[string]struct {
+		verify       bool
+		profile      config.CacheProfile
+		wantVerifier bool
+	}{
+		"disabled": {
+			profile: shared,
+		},
+		"shared filesystem": {
+			verify:       true,
+			profile:      shared,
+			wantVerifier: true,
+		},
+		"read only many clone": {
+			verify: true,
+			profile: config.CacheProfile{DedicatedVolume: &config.CacheDedicatedVolume{
+				StorageClassName: "hyperdisk-ml",
+				ReadOnlyMany:     &config.CacheReadOnlyMany{},
+			}},
+		},
+		"node local": {
+			verify:  true,
+			profile: config.CacheProfile{NodeLocal: &config.CacheNodeLocal{HostPath: "/mnt/models"}},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &ModelReconciler{ModelLoaders: config.ModelLoading{Image: "loader", Verify: c.verify}}
+			pod := &corev1.Pod{}
+			r.patchServerCacheVerifier(pod, model, ModelConfig{CacheProfile: c.profile})
+			if !c.wantVerifier {
+				require.Empty(t, pod.Spec.InitContainers)
+				return
+			}
+			require.Equal(t, []corev1.Container{{
+				Name:    "model-verifier",
+				Image:   "loader",
+				Command: []string{"manifest", "verify", "/models/test-mdl-1234567890", "abc"},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "models",
+					MountPath: "/models/test-mdl-1234567890",
+					SubPath:   "models/test-mdl-1234567890",
+					ReadOnly:  true,
+				}},
+			}}, pod.Spec.InitContainers)
+		})
+	}
+}
+
+func Test_parseCacheLoadProgress(t *testing.T) {
+	updated := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		logs    string
+		want    *v1.ModelCacheLoadProgress
+		wantErr bool
+	}{
+		"not reported": {},
+		"not reported yet": {
+			logs: "+ huggingface-cli download --local-dir /models/x org/model\n",
+		},
+		"estimated completion": {
+			logs: "kubeai-load-progress {\"downloadedBytes\":500,\"totalBytes\":4000,\"bytesPerSecond\":100,\"updateTime\":\"2024-10-01T11:59:55Z\"}\n" +
+				"Fetching 4 files\n" +
+				"kubeai-load-progress {\"downloadedBytes\":1000,\"totalBytes\":4000,\"bytesPerSecond\":100,\"updateTime\":\"2024-10-01T12:00:00Z\"}\n" +
+				"Fetching 4 files\n",
+			want: &v1.ModelCacheLoadProgress{
+				DownloadedBytes:         1000,
+				TotalBytes:              4000,
+				EstimatedCompletionTime: ptr.To(metav1.NewTime(updated.Add(30 * time.Second))),
+			},
+		},
+		"stalled download": {
+			logs: `kubeai-load-progress {"downloadedBytes":1000,"totalBytes":4000,"bytesPerSecond":0,"updateTime":"2024-10-01T12:00:00Z"}`,
+			want: &v1.ModelCacheLoadProgress{DownloadedBytes: 1000, TotalBytes: 4000},
+		},
+		"unknown total": {
+			logs: `kubeai-load-progress {"downloadedBytes":1000,"totalBytes":0,"bytesPerSecond":100,"updateTime":"2024-10-01T12:00:00Z"}`,
+			want: &v1.ModelCacheLoadProgress{DownloadedBytes: 1000},
+		},
+		"invalid": {
+			logs:    `kubeai-load-progress {`,
+			wantErr: true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseCacheLoadProgress("load-cache-test", c.logs)
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.want, got)
+		})
+	}
+}
+
+func Test_cacheLoadJobProgress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "load-cache-test"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "load-cache-test-abc",
+			Labels:    map[string]string{batchv1.JobNameLabel: job.Name},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "loader",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}},
+	}
+	logs := &restfake.RESTClient{
+		NegotiatedSerializer: clientgoscheme.Codecs.WithoutConversion(),
+		GroupVersion:         corev1.SchemeGroupVersion,
+		Resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`kubeai-load-progress {"downloadedBytes":250,"totalBytes":1000}` + "\n")),
+		},
+	}
+	r := &ModelReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build(),
+		PodRESTClient: logs,
+		Scheme:        scheme,
+	}
+
+	progress, msg, err := r.cacheLoadJobProgress(context.Background(), job)
+	require.NoError(t, err)
+	require.Equal(t, &v1.ModelCacheLoadProgress{DownloadedBytes: 250, TotalBytes: 1000}, progress)
+	require.Equal(t, "Loading the model into the cache with Job load-cache-test (25% downloaded)", msg)
+	require.Equal(t, "/namespaces/default/pods/load-cache-test-abc/log", logs.Req.URL.Path)
+	require.Equal(t, "loader", logs.Req.URL.Query().Get("container"))
+}
+
+func Test_cacheLoadingMessage(t *testing.T) {
+	cases := map[string]struct {
+		progress *v1.ModelCacheLoadProgress
+		failure  string
+		want     string
+	}{
+		"no progress": {
+			want: "Loading the model into the cache with Job load-cache-test",
+		},
+		"downloading": {
+			progress: &v1.ModelCacheLoadProgress{DownloadedBytes: 250, TotalBytes: 1000},
+			want:     "Loading the model into the cache with Job load-cache-test (25% downloaded)",
+		},
+		"resumed": {
+			progress: &v1.ModelCacheLoadProgress{DownloadedBytes: 500, TotalBytes: 1000, Restarts: 2},
+			failure:  "Connection reset by peer",
+			want:     "Loading the model into the cache with Job load-cache-test (50% downloaded), resumed after 2 failures, last failure: Connection reset by peer",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.want, cacheLoadingMessage("load-cache-test", c.progress, c.failure))
+		})
+	}
+}
+
+func Test_lastLogLine(t *testing.T) {
+	require.Equal(t, "", lastLogLine(""))
+	require.Equal(t, "Checksum of model.gguf does not match", lastLogLine("+ curl -fL https://host/model.gguf\nChecksum of model.gguf does not match\n+ rm -rf /models/x\n\n"))
+	require.Equal(t, "Connection reset by peer", lastLogLine("Connection reset by peer\nkubeai-load-progress {\"downloadedBytes\":1}\n"))
+}
+
+func Test_patchCacheRefreshJob(t *testing.T) {
+	t.Parallel()
+
+	r := &ModelReconciler{ModelLoaders: config.ModelLoading{Image: "loader"}}
+	const url = "hf://test-org/test-model"
+	src, err := r.parseModelSource(url)
+	require.NoError(t, err)
+	dir := modelCacheDirOfModelCache("test-cache")
+	job := r.loadCacheJob("load-modelcache-test-cache", "default", url, nil, dir, "shared-model-cache-test", src)
+	patchCacheRefreshJob(job, "1700000000")
+
+	container := job.Spec.Template.Spec.Containers[0]
+	// The loader replaces the cache directory, so it mounts its parent.
+	require.Equal(t, []string{url, dir}, container.Args)
+	require.Equal(t, "/models", container.VolumeMounts[0].MountPath)
+	require.Equal(t, "models", container.VolumeMounts[0].SubPath)
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "KUBEAI_REFRESH", Value: "1700000000"})
+
+	// Evictions remove the directories of refreshes as well.
+	evict := r.evictCacheJob("evict-modelcache-test-cache", "default", dir, "shared-model-cache-test")
+	require.Equal(t, []string{"bash", "-c", "rm -rf " + dir + " " + dir + "_*"}, evict.Spec.Template.Spec.Containers[0].Command)
+}
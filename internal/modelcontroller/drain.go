@@ -0,0 +1,71 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultDrainTimeoutSeconds = 120
+	// drainCheckInterval is the interval at which draining Pods are checked
+	// for in-flight requests.
+	drainCheckInterval = 5 * time.Second
+)
+
+// planDrain replaces the deletion of ready Pods with draining them first:
+// a draining Pod is annotated so that the load balancers stop sending new
+// requests to it and it is only deleted once the load reports show that
+// its in-flight requests are finished or the drain timeout is exceeded.
+// Pods that are draining but are no longer deleted by the plan are restored.
+func (r *ModelReconciler) planDrain(plan *podPlan, now time.Time) {
+	timeout := drainTimeout(plan.model)
+
+	var toDelete []*corev1.Pod
+	for _, pod := range plan.toDelete {
+		if timeout == 0 || r.LoadReports == nil || pod.DeletionTimestamp != nil || !k8sutils.PodIsReady(pod) {
+			toDelete = append(toDelete, pod)
+			continue
+		}
+
+		since, err := time.Parse(time.RFC3339, pod.Annotations[kubeaiv1.ModelPodDrainingAnnotation])
+		switch {
+		case !podIsDraining(pod) || err != nil:
+			plan.details = append(plan.details, fmt.Sprintf("Draining Pod %q before deleting it", pod.Name))
+			plan.patchAnnotation(*pod, kubeaiv1.ModelPodDrainingAnnotation, ptr.To(now.UTC().Format(time.RFC3339)))
+		case !now.Before(since.Add(timeout)):
+			plan.details = append(plan.details, fmt.Sprintf("Drain timeout of %s exceeded, deleting Pod %q", timeout, pod.Name))
+			toDelete = append(toDelete, pod)
+			continue
+		case r.LoadReports.EndpointDrained(plan.model.Name, strings.TrimPrefix(getPodModelServerAddr(pod), "http://")):
+			plan.details = append(plan.details, fmt.Sprintf("Pod %q is drained, deleting", pod.Name))
+			toDelete = append(toDelete, pod)
+			continue
+		}
+		if plan.requeueAfter == 0 || drainCheckInterval < plan.requeueAfter {
+			plan.requeueAfter = drainCheckInterval
+		}
+	}
+	plan.toDelete = toDelete
+
+	for _, pod := range plan.toRemain {
+		if podIsDraining(pod) {
+			plan.details = append(plan.details, fmt.Sprintf("Pod %q is no longer deleted, stopping drain", pod.Name))
+			plan.patchAnnotation(*pod, kubeaiv1.ModelPodDrainingAnnotation, nil)
+		}
+	}
+}
+
+func drainTimeout(model *kubeaiv1.Model) time.Duration {
+	return time.Duration(ptr.Deref(model.Spec.Disruption.DrainTimeoutSeconds, defaultDrainTimeoutSeconds)) * time.Second
+}
+
+func podIsDraining(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[kubeaiv1.ModelPodDrainingAnnotation]
+	return ok
+}
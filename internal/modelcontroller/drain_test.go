@@ -0,0 +1,137 @@
+package modelcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/loadreport"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func Test_planDrain(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const addr = "10.0.0.1:8000"
+
+	testPod := func(ready bool, drainingSince time.Duration) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod-1",
+				Namespace:   "test-ns",
+				Annotations: map[string]string{v1.ModelPodPortAnnotation: "8000"},
+			},
+			Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+		}
+		if ready {
+			p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		if drainingSince != 0 {
+			p.Annotations[v1.ModelPodDrainingAnnotation] = now.Add(-drainingSince).Format(time.RFC3339)
+		}
+		return p
+	}
+
+	cases := []struct {
+		name         string
+		drainTimeout *int32
+		pod          *corev1.Pod
+		remains      bool
+		load         loadreport.ModelLoad
+		noReports    bool
+		wantDeleted  bool
+		wantPatch    map[string]*string
+		wantRequeue  time.Duration
+	}{
+		{
+			name:        "unready pod is deleted immediately",
+			pod:         testPod(false, 0),
+			wantDeleted: true,
+		},
+		{
+			name:         "drain disabled",
+			drainTimeout: ptr.To[int32](0),
+			pod:          testPod(true, 0),
+			wantDeleted:  true,
+		},
+		{
+			name:        "ready pod starts draining",
+			pod:         testPod(true, 0),
+			wantPatch:   map[string]*string{v1.ModelPodDrainingAnnotation: ptr.To(now.Format(time.RFC3339))},
+			wantRequeue: drainCheckInterval,
+		},
+		{
+			name:        "draining pod with in-flight requests",
+			pod:         testPod(true, time.Minute),
+			load:        loadreport.ModelLoad{Endpoints: map[string]int64{addr: 1}, Draining: []string{addr}},
+			wantRequeue: drainCheckInterval,
+		},
+		{
+			name:        "draining pod not yet reported as draining",
+			pod:         testPod(true, time.Second),
+			load:        loadreport.ModelLoad{Responses: map[string]loadreport.EndpointResponses{addr: {Total: 1}}},
+			wantRequeue: drainCheckInterval,
+		},
+		{
+			name:        "drained pod is deleted",
+			pod:         testPod(true, time.Minute),
+			load:        loadreport.ModelLoad{Draining: []string{addr}},
+			wantDeleted: true,
+		},
+		{
+			name:        "leader without reports waits for the drain timeout",
+			pod:         testPod(true, time.Minute),
+			noReports:   true,
+			wantRequeue: drainCheckInterval,
+		},
+		{
+			name:         "drain timeout exceeded",
+			drainTimeout: ptr.To[int32](30),
+			pod:          testPod(true, time.Minute),
+			load:         loadreport.ModelLoad{Endpoints: map[string]int64{addr: 1}, Draining: []string{addr}},
+			wantDeleted:  true,
+		},
+		{
+			name:      "remaining pod stops draining",
+			pod:       testPod(true, time.Minute),
+			remains:   true,
+			wantPatch: map[string]*string{v1.ModelPodDrainingAnnotation: nil},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			model := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns"}}
+			model.Spec.Disruption.DrainTimeoutSeconds = c.drainTimeout
+
+			r := &ModelReconciler{LoadReports: loadreport.NewAggregator(time.Minute)}
+			if !c.noReports {
+				r.LoadReports.Add(loadreport.Report{Replica: "kubeai-0", Models: map[string]loadreport.ModelLoad{
+					model.Name: c.load,
+				}})
+			}
+
+			plan := &podPlan{model: model}
+			if c.remains {
+				plan.toRemain = []*corev1.Pod{c.pod}
+			} else {
+				plan.toDelete = []*corev1.Pod{c.pod}
+			}
+			r.planDrain(plan, now)
+
+			if c.wantDeleted {
+				require.Equal(t, []*corev1.Pod{c.pod}, plan.toDelete)
+			} else {
+				require.Empty(t, plan.toDelete)
+			}
+			if c.wantPatch == nil {
+				require.Empty(t, plan.toPatch)
+			} else {
+				require.Len(t, plan.toPatch, 1)
+				require.Equal(t, c.wantPatch, plan.toPatch[0].annotations)
+			}
+			require.Equal(t, c.wantRequeue, plan.requeueAfter)
+		})
+	}
+}
@@ -20,8 +20,8 @@ func (r *ModelReconciler) fasterWhisperPodForModel(m *kubeaiv1.Model, c ModelCon
 	args = append(args, m.Spec.Args...)
 
 	whisperModel := c.Source.url.ref
-	if m.Spec.CacheProfile != "" {
-		whisperModel = modelCacheDir(m)
+	if m.Spec.CacheProfile != "" || c.Source.url.scheme == "oci" {
+		whisperModel = serverModelPath(m, c.Source.url)
 	}
 
 	env := []corev1.EnvVar{
@@ -23,9 +23,9 @@ func (r *ModelReconciler) infinityPodForModel(m *kubeaiv1.Model, c ModelConfig)
 	}
 
 	infinityModelID := c.Source.url.ref
-	if m.Spec.CacheProfile != "" {
+	if m.Spec.CacheProfile != "" || c.Source.url.scheme == "oci" {
 		// TODO: Verify loading from dir works.
-		infinityModelID = modelCacheDir(m)
+		infinityModelID = serverModelPath(m, c.Source.url)
 	}
 
 	env := []corev1.EnvVar{
@@ -52,6 +52,12 @@ func (r *ModelReconciler) infinityPodForModel(m *kubeaiv1.Model, c ModelConfig)
 			Value: "8000",
 		},
 	}
+	if rev := serverModelRevision(m, c.Source.url); rev != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "INFINITY_REVISION",
+			Value: rev,
+		})
+	}
 
 	var envKeys []string
 	for key := range m.Spec.Env {
@@ -0,0 +1,163 @@
+package modelcontroller
+
+import (
+	"slices"
+	"sort"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (r *ModelReconciler) llamaCPPPodForModel(m *kubeaiv1.Model, c ModelConfig) *corev1.Pod {
+	lbs := labelsForModel(m)
+	ann := r.annotationsForModel(m)
+	if _, ok := ann[kubeaiv1.ModelPodPortAnnotation]; !ok {
+		ann[kubeaiv1.ModelPodPortAnnotation] = "8000"
+	}
+
+	args := llamaCPPModelArgs(m, c.Source.url)
+	args = append(args,
+		"--alias="+m.Name,
+		"--host=0.0.0.0",
+		"--port=8000",
+	)
+	if slices.Contains(m.Spec.Features, kubeaiv1.ModelFeatureTextEmbedding) {
+		args = append(args, "--embeddings")
+	}
+	args = append(args, m.Spec.Args...)
+
+	env := []corev1.EnvVar{}
+	var envKeys []string
+	for key := range m.Spec.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		env = append(env, corev1.EnvVar{
+			Name:  key,
+			Value: m.Spec.Env[key],
+		})
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   m.Namespace,
+			Labels:      lbs,
+			Annotations: ann,
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector:       c.NodeSelector,
+			Affinity:           c.Affinity,
+			Tolerations:        c.Tolerations,
+			SchedulerName:      c.SchedulerName,
+			RuntimeClassName:   c.RuntimeClassName,
+			PriorityClassName:  m.Spec.PriorityClassName,
+			ServiceAccountName: r.ModelServerPods.ModelServiceAccountName,
+			SecurityContext:    r.ModelServerPods.ModelPodSecurityContext,
+			ImagePullSecrets:   r.ModelServerPods.ImagePullSecrets,
+			Containers: []corev1.Container{
+				{
+					Name:  serverContainerName,
+					Image: c.Image,
+					// The entrypoint of the image is llama-server.
+					Args:            args,
+					Env:             env,
+					SecurityContext: r.ModelServerPods.ModelContainerSecurityContext,
+					Resources: corev1.ResourceRequirements{
+						Requests: c.Requests,
+						Limits:   c.Limits,
+					},
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: 8000,
+							Protocol:      corev1.ProtocolTCP,
+							Name:          "http",
+						},
+					},
+					StartupProbe: &corev1.Probe{
+						// Give the model 1 hour to start up by default.
+						FailureThreshold: 1800,
+						PeriodSeconds:    2,
+						TimeoutSeconds:   2,
+						SuccessThreshold: 1,
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Path: "/health",
+								Port: intstr.FromString("http"),
+							},
+						},
+					},
+					ReadinessProbe: &corev1.Probe{
+						FailureThreshold: 3,
+						PeriodSeconds:    10,
+						TimeoutSeconds:   2,
+						SuccessThreshold: 1,
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Path: "/health",
+								Port: intstr.FromString("http"),
+							},
+						},
+					},
+					LivenessProbe: &corev1.Probe{
+						FailureThreshold: 3,
+						PeriodSeconds:    30,
+						TimeoutSeconds:   3,
+						SuccessThreshold: 1,
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Path: "/health",
+								Port: intstr.FromString("http"),
+							},
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "dshm",
+							MountPath: "/dev/shm",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "dshm",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							Medium: corev1.StorageMediumMemory,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	patchFileVolumes(&pod.Spec, m)
+	patchServerCacheVolumes(&pod.Spec, m, c)
+	c.Source.modelSourcePodAdditions.applyToPodSpec(&pod.Spec, 0)
+
+	return pod
+}
+
+// llamaCPPModelArgs returns the flags that select the GGUF file to serve.
+// The file within a Huggingface repo, PVC or cache directory is selected with
+// the "model" query parameter of the URL (e.g. "hf://org/repo?model=model-q4_k_m.gguf").
+// Without it, llama.cpp selects a default file of a Huggingface repo and the
+// path of a PVC is expected to point to the file itself.
+func llamaCPPModelArgs(m *kubeaiv1.Model, u modelURL) []string {
+	if m.Spec.CacheProfile == "" && u.scheme == "hf" {
+		args := []string{"--hf-repo=" + u.ref}
+		if u.modelParam != "" {
+			args = append(args, "--hf-file="+u.modelParam)
+		}
+		return args
+	}
+
+	modelPath := serverModelPath(m, u)
+	if u.modelParam != "" {
+		modelPath += "/" + u.modelParam
+	}
+	return []string{"--model=" + modelPath}
+}
@@ -0,0 +1,53 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_llamaCPPModelArgs(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		url          string
+		cacheProfile string
+		want         []string
+	}{
+		"huggingface-default-file": {
+			url:  "hf://test-org/test-model-GGUF",
+			want: []string{"--hf-repo=test-org/test-model-GGUF"},
+		},
+		"huggingface-file": {
+			url:  "hf://test-org/test-model-GGUF?model=test-model-q4_k_m.gguf",
+			want: []string{"--hf-repo=test-org/test-model-GGUF", "--hf-file=test-model-q4_k_m.gguf"},
+		},
+		"pvc-file": {
+			url:  "pvc://test-pvc/models/test-model.gguf",
+			want: []string{"--model=/model"},
+		},
+		"pvc-dir": {
+			url:  "pvc://test-pvc/models?model=test-model.gguf",
+			want: []string{"--model=/model/test-model.gguf"},
+		},
+		"cache": {
+			url:          "hf://test-org/test-model-GGUF?model=test-model-q4_k_m.gguf",
+			cacheProfile: "efs",
+			want:         []string{"--model=/models/test-mdl-test-uid/test-model-q4_k_m.gguf"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			u, err := parseModelURL(c.url)
+			require.NoError(t, err)
+			m := &v1.Model{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: "test-uid"},
+				Spec:       v1.ModelSpec{URL: c.url, CacheProfile: c.cacheProfile},
+			}
+			require.Equal(t, c.want, llamaCPPModelArgs(m, u))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package modelcontroller
 
 import (
+	"encoding/json"
 	"sort"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
@@ -21,8 +22,8 @@ func (r *ModelReconciler) vLLMPodForModel(m *kubeaiv1.Model, c ModelConfig) *cor
 	useRunaiStreamer := false
 	if m.Spec.CacheProfile != "" {
 		vllmModelFlag = modelCacheDir(m)
-	} else if c.Source.url.scheme == "s3" {
-		vllmModelFlag = c.Source.url.original
+	} else if isStreamedModel(m, c.Source.url) {
+		vllmModelFlag = streamingModelURL(c.Source.url)
 		useRunaiStreamer = true
 	}
 	// The vllmModelFlag can be safely overridden because validation logic ensures
@@ -30,6 +31,9 @@ func (r *ModelReconciler) vLLMPodForModel(m *kubeaiv1.Model, c ModelConfig) *cor
 	if c.Source.url.scheme == "pvc" {
 		vllmModelFlag = "/model"
 	}
+	if c.Source.url.scheme == "oci" {
+		vllmModelFlag = serverModelPath(m, c.Source.url)
+	}
 
 	args := []string{
 		"--model=" + vllmModelFlag,
@@ -37,7 +41,9 @@ func (r *ModelReconciler) vLLMPodForModel(m *kubeaiv1.Model, c ModelConfig) *cor
 	}
 	if useRunaiStreamer {
 		args = append(args, "--load-format=runai_streamer")
+		args = append(args, vllmStreamerArgs(m.Spec.Streaming)...)
 	}
+	args = append(args, serverRevisionArgs(m, c.Source.url)...)
 	args = append(args, m.Spec.Args...)
 
 	env := []corev1.EnvVar{}
@@ -99,8 +105,9 @@ func (r *ModelReconciler) vLLMPodForModel(m *kubeaiv1.Model, c ModelConfig) *cor
 						},
 					},
 					StartupProbe: &corev1.Probe{
-						// TODO: Decrease the default and make it configurable.
-						// Give the model 3 hours to start up.
+						// Give the model 3 hours to start up by default.
+						// Configurable via the probes of the engine or Model
+						// (see startupStallTimeoutSeconds to detect stuck downloads early).
 						FailureThreshold: 5400,
 						PeriodSeconds:    2,
 						TimeoutSeconds:   2,
@@ -162,6 +169,29 @@ func (r *ModelReconciler) vLLMPodForModel(m *kubeaiv1.Model, c ModelConfig) *cor
 	r.patchServerAdapterLoader(&pod.Spec, m, r.ModelLoaders.Image)
 	patchServerCacheVolumes(&pod.Spec, m, c)
 	c.Source.modelSourcePodAdditions.applyToPodSpec(&pod.Spec, 0)
+	if useRunaiStreamer && c.Source.url.scheme == "oss" {
+		r.streamingForOSS(m).applyToPodSpec(&pod.Spec, 0)
+	}
 
 	return pod
 }
+
+// vllmStreamerArgs configures the Run:ai Model Streamer of vLLM.
+func vllmStreamerArgs(s *kubeaiv1.ModelStreaming) []string {
+	if s == nil {
+		return nil
+	}
+	extraConfig := map[string]int64{}
+	if s.Concurrency != nil {
+		extraConfig["concurrency"] = int64(*s.Concurrency)
+	}
+	if s.MemoryLimit != nil {
+		extraConfig["memory_limit"] = s.MemoryLimit.Value()
+	}
+	if len(extraConfig) == 0 {
+		return nil
+	}
+	// Marshalling a map of ints does not fail.
+	extraConfigJSON, _ := json.Marshal(extraConfig)
+	return []string{"--model-loader-extra-config=" + string(extraConfigJSON)}
+}
@@ -0,0 +1,41 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func Test_vllmStreamerArgs(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		streaming *v1.ModelStreaming
+		want      []string
+	}{
+		"unset": {},
+		"empty": {
+			streaming: &v1.ModelStreaming{},
+		},
+		"concurrency": {
+			streaming: &v1.ModelStreaming{Concurrency: ptr.To[int32](32)},
+			want:      []string{`--model-loader-extra-config={"concurrency":32}`},
+		},
+		"concurrency-and-memory-limit": {
+			streaming: &v1.ModelStreaming{
+				Concurrency: ptr.To[int32](16),
+				MemoryLimit: ptr.To(resource.MustParse("5Gi")),
+			},
+			want: []string{`--model-loader-extra-config={"concurrency":16,"memory_limit":5368709120}`},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.want, vllmStreamerArgs(c.streaming))
+		})
+	}
+}
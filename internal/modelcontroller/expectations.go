@@ -0,0 +1,172 @@
+package modelcontroller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// expectationsTimeout is the time after which unobserved Pod creations and
+// deletions are ignored. This guards against Models getting stuck when a
+// watch event is missed (same as the ReplicaSet controller).
+const expectationsTimeout = 5 * time.Minute
+
+// podExpectations tracks the server Pod creations and deletions that were
+// requested for each Model but have not been observed in the informer cache
+// yet. The Pod plan of a Model is only recalculated once all of its
+// expectations are satisfied, because the plan assumes that the cache is
+// up to date.
+type podExpectations struct {
+	mtx sync.Mutex
+	// models is keyed by <namespace>/<model-name>.
+	models map[string]*modelExpectations
+	now    func() time.Time
+}
+
+type modelExpectations struct {
+	// creations is the number of Pods that are expected to be created.
+	// Creations are counted because Pods are created with a generated name.
+	creations int
+	// deletions contains the <namespace>/<name> keys of the Pods that
+	// are expected to be deleted.
+	deletions map[string]struct{}
+	timestamp time.Time
+}
+
+func newPodExpectations() *podExpectations {
+	return &podExpectations{
+		models: map[string]*modelExpectations{},
+		now:    time.Now,
+	}
+}
+
+// expect records Pod creations and deletions that are about to be requested.
+// It must be called before the requests are sent to avoid racing with the
+// resulting watch events.
+func (e *podExpectations) expect(modelKey string, creations int, deletions []string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	exp := &modelExpectations{
+		creations: creations,
+		deletions: make(map[string]struct{}, len(deletions)),
+		timestamp: e.now(),
+	}
+	for _, key := range deletions {
+		exp.deletions[key] = struct{}{}
+	}
+	e.models[modelKey] = exp
+}
+
+// creationObserved lowers the number of expected creations for a Model.
+func (e *podExpectations) creationObserved(modelKey string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if exp, ok := e.models[modelKey]; ok && exp.creations > 0 {
+		exp.creations--
+	}
+}
+
+// deletionObserved removes the Pod from the expected deletions of a Model.
+func (e *podExpectations) deletionObserved(modelKey, podKey string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if exp, ok := e.models[modelKey]; ok {
+		delete(exp.deletions, podKey)
+	}
+}
+
+// satisfied returns true if all expected creations and deletions of a Model
+// were observed or if the expectations expired. If the expectations are not
+// satisfied, the remaining time until they expire is returned.
+func (e *podExpectations) satisfied(modelKey string) (bool, time.Duration) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	exp, ok := e.models[modelKey]
+	if !ok {
+		return true, 0
+	}
+	if exp.creations <= 0 && len(exp.deletions) == 0 {
+		delete(e.models, modelKey)
+		return true, 0
+	}
+	age := e.now().Sub(exp.timestamp)
+	if age >= expectationsTimeout {
+		delete(e.models, modelKey)
+		return true, 0
+	}
+	return false, expectationsTimeout - age
+}
+
+// forget drops all expectations of a Model.
+func (e *podExpectations) forget(modelKey string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	delete(e.models, modelKey)
+}
+
+// podExpectationsHandler lowers the expectations of the owning Model when
+// server Pod events are observed and then delegates to the wrapped handler.
+type podExpectationsHandler struct {
+	handler.EventHandler
+	expectations *podExpectations
+}
+
+func (h *podExpectationsHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if pod, ok := evt.Object.(*corev1.Pod); ok {
+		if modelKey, ok := modelKeyForPod(pod); ok {
+			if pod.DeletionTimestamp != nil {
+				// A Pod that is already terminating on startup of the
+				// informer should not count as a creation.
+				h.expectations.deletionObserved(modelKey, podKey(pod))
+			} else {
+				h.expectations.creationObserved(modelKey)
+			}
+		}
+	}
+	h.EventHandler.Create(ctx, evt, q)
+}
+
+func (h *podExpectationsHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	// Pods are gracefully deleted, the deletion timestamp is the first
+	// indication that the deletion request was processed.
+	if pod, ok := evt.ObjectNew.(*corev1.Pod); ok && pod.DeletionTimestamp != nil {
+		if modelKey, ok := modelKeyForPod(pod); ok {
+			h.expectations.deletionObserved(modelKey, podKey(pod))
+		}
+	}
+	h.EventHandler.Update(ctx, evt, q)
+}
+
+func (h *podExpectationsHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if pod, ok := evt.Object.(*corev1.Pod); ok {
+		if modelKey, ok := modelKeyForPod(pod); ok {
+			h.expectations.deletionObserved(modelKey, podKey(pod))
+		}
+	}
+	h.EventHandler.Delete(ctx, evt, q)
+}
+
+// modelKeyForPod returns the <namespace>/<model-name> key of the Model that
+// controls the given server Pod. Parked Pods of the warm pool are ignored.
+func modelKeyForPod(pod *corev1.Pod) (string, bool) {
+	if _, ok := pod.Labels[kubeaiv1.PodModelLabel]; !ok {
+		return "", false
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "Model" || owner.APIVersion != kubeaiv1.GroupVersion.String() {
+		return "", false
+	}
+	return pod.Namespace + "/" + owner.Name, true
+}
@@ -0,0 +1,92 @@
+package modelcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func Test_podExpectations(t *testing.T) {
+	now := time.Now()
+	e := newPodExpectations()
+	e.now = func() time.Time { return now }
+
+	ok, _ := e.satisfied("default/a")
+	require.True(t, ok, "Models without expectations should be satisfied")
+
+	e.expect("default/a", 2, []string{"default/pod-1"})
+	ok, remaining := e.satisfied("default/a")
+	require.False(t, ok)
+	require.Equal(t, expectationsTimeout, remaining)
+
+	e.creationObserved("default/a")
+	e.creationObserved("default/a")
+	e.creationObserved("default/b")
+	ok, _ = e.satisfied("default/a")
+	require.False(t, ok, "Deletion should still be expected")
+
+	e.deletionObserved("default/a", "default/pod-2")
+	ok, _ = e.satisfied("default/a")
+	require.False(t, ok, "Deletion of other Pods should be ignored")
+
+	e.deletionObserved("default/a", "default/pod-1")
+	ok, _ = e.satisfied("default/a")
+	require.True(t, ok)
+
+	e.expect("default/a", 1, nil)
+	now = now.Add(time.Minute)
+	ok, remaining = e.satisfied("default/a")
+	require.False(t, ok)
+	require.Equal(t, expectationsTimeout-time.Minute, remaining)
+	now = now.Add(expectationsTimeout)
+	ok, _ = e.satisfied("default/a")
+	require.True(t, ok, "Expired expectations should be satisfied")
+
+	e.expect("default/a", 1, nil)
+	e.forget("default/a")
+	ok, _ = e.satisfied("default/a")
+	require.True(t, ok, "Forgotten expectations should be satisfied")
+}
+
+func Test_podExpectationsHandler(t *testing.T) {
+	e := newPodExpectations()
+	h := &podExpectationsHandler{EventHandler: handler.Funcs{}, expectations: e}
+
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1.GroupVersion.String(),
+				Kind:       "Model",
+				Name:       "a",
+				Controller: ptr.To(true),
+			}},
+		}}
+	}
+	serverPod := pod("server", map[string]string{v1.PodModelLabel: "a"})
+	parkedPod := pod("parked", map[string]string{v1.ParkedPodModelLabel: "a"})
+	ctx := context.Background()
+
+	e.expect("default/a", 1, []string{"default/old"})
+	h.Create(ctx, event.CreateEvent{Object: parkedPod}, nil)
+	ok, _ := e.satisfied("default/a")
+	require.False(t, ok, "Parked Pods should be ignored")
+	h.Create(ctx, event.CreateEvent{Object: serverPod}, nil)
+
+	oldPod := pod("old", map[string]string{v1.PodModelLabel: "a"})
+	terminating := oldPod.DeepCopy()
+	terminating.DeletionTimestamp = ptr.To(metav1.Now())
+	h.Update(ctx, event.UpdateEvent{ObjectOld: oldPod, ObjectNew: terminating}, nil)
+	ok, _ = e.satisfied("default/a")
+	require.True(t, ok, "Terminating Pods should be observed as deleted")
+}
@@ -0,0 +1,192 @@
+package modelcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// imageVolumesAnnotation lists the volumes of a Pod (template) that are
+// mounted from an image, as a JSON map of volume name to image reference.
+//
+// The Kubernetes API types that KubeAI is built with do not have image volumes
+// yet. The Pods are built with a placeholder (emptyDir) volume that is replaced
+// by the imageVolumeClient when the Pod, Deployment or LeaderWorkerSet is
+// written to the API server.
+const imageVolumesAnnotation = "kubeai.org/image-volumes"
+
+// minImageVolumesVersion is the first version of Kubernetes with image
+// volumes (the ImageVolume feature gate).
+var minImageVolumesVersion = version.MajorMinor(1, 31)
+
+// imageVolumesSupported returns whether the API server supports image volumes.
+// The ImageVolume feature gate has to be enabled on the cluster as well.
+func imageVolumesSupported(cfg *rest.Config) (bool, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return false, fmt.Errorf("creating discovery client: %w", err)
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return false, fmt.Errorf("getting server version: %w", err)
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, fmt.Errorf("parsing server version %q: %w", info.GitVersion, err)
+	}
+	return v.AtLeast(minImageVolumesVersion), nil
+}
+
+// patchServerImageVolume mounts the artifact of an "oci://" url as an image
+// volume at "/model". The image is pulled with the image pull secrets of the
+// Pod.
+func patchServerImageVolume(pod *corev1.Pod, c ModelConfig) {
+	const volumeName = "model"
+	refs, _ := json.Marshal(map[string]string{volumeName: c.Source.url.ref})
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[imageVolumesAnnotation] = string(refs)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == serverContainerName {
+			pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: "/model",
+				ReadOnly:  true,
+			})
+		}
+	}
+}
+
+// imageVolumeClient replaces the placeholders of image volumes (see
+// imageVolumesAnnotation) in the objects that are created or updated.
+type imageVolumeClient struct {
+	client.Client
+}
+
+func (c *imageVolumeClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	u, err := withImageVolumes(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	if err := c.Client.Create(ctx, u, opts...); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+func (c *imageVolumeClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	u, err := withImageVolumes(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	if err := c.Client.Update(ctx, u, opts...); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+// withImageVolumes returns an unstructured copy of the object with image
+// volumes, or nil if the object has no image volumes.
+func withImageVolumes(obj client.Object, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	if in, ok := obj.(*unstructured.Unstructured); ok {
+		u = in.DeepCopy()
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("converting %T to unstructured: %w", obj, err)
+		}
+		u.Object = content
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		u.SetGroupVersionKind(gvk)
+	}
+	replaced, err := replaceImageVolumes(u.Object)
+	if err != nil || !replaced {
+		return nil, err
+	}
+	return u, nil
+}
+
+// replaceImageVolumes replaces the placeholders of image volumes in all Pods
+// and Pod templates that are nested in the object.
+func replaceImageVolumes(obj map[string]interface{}) (bool, error) {
+	var replaced bool
+	if refsJSON, ok, _ := unstructured.NestedString(obj, "metadata", "annotations", imageVolumesAnnotation); ok {
+		var refs map[string]string
+		if err := json.Unmarshal([]byte(refsJSON), &refs); err != nil {
+			return false, fmt.Errorf("parsing %s annotation: %w", imageVolumesAnnotation, err)
+		}
+		volumes, _, _ := unstructured.NestedSlice(obj, "spec", "volumes")
+		for i, vol := range volumes {
+			vol, ok := vol.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(vol, "name")
+			if ref, ok := refs[name]; ok {
+				volumes[i] = map[string]interface{}{
+					"name": name,
+					"image": map[string]interface{}{
+						"reference":  ref,
+						"pullPolicy": string(corev1.PullIfNotPresent),
+					},
+				}
+				replaced = true
+			}
+		}
+		if replaced {
+			if err := unstructured.SetNestedSlice(obj, volumes, "spec", "volumes"); err != nil {
+				return false, err
+			}
+		}
+	}
+	for key, field := range obj {
+		if key == "metadata" {
+			continue
+		}
+		switch field := field.(type) {
+		case map[string]interface{}:
+			r, err := replaceImageVolumes(field)
+			if err != nil {
+				return false, err
+			}
+			replaced = replaced || r
+		case []interface{}:
+			for _, item := range field {
+				if item, ok := item.(map[string]interface{}); ok {
+					r, err := replaceImageVolumes(item)
+					if err != nil {
+						return false, err
+					}
+					replaced = replaced || r
+				}
+			}
+		}
+	}
+	return replaced, nil
+}
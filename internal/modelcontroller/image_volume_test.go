@@ -0,0 +1,83 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func Test_withImageVolumes(t *testing.T) {
+	t.Parallel()
+
+	r := &ModelReconciler{
+		ModelLoaders: config.ModelLoading{Image: "loader", ImageVolumes: true},
+		imageVolumes: true,
+	}
+	const url = "oci://registry.example.com/models/llama:v1"
+	src, err := r.parseModelSource(url)
+	require.NoError(t, err)
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: serverContainerName}}}}
+	r.patchServerOCIPuller(pod, &v1.Model{Spec: v1.ModelSpec{URL: url}}, ModelConfig{Source: src})
+	require.Empty(t, pod.Spec.InitContainers)
+	require.Equal(t, []corev1.VolumeMount{
+		{Name: "model", MountPath: "/model", ReadOnly: true},
+	}, pod.Spec.Containers[0].VolumeMounts)
+
+	wantVolume := map[string]interface{}{
+		"name": "model",
+		"image": map[string]interface{}{
+			"reference":  "registry.example.com/models/llama:v1",
+			"pullPolicy": "IfNotPresent",
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	// Pods
+	u, err := withImageVolumes(pod, scheme)
+	require.NoError(t, err)
+	require.NotNil(t, u)
+	require.Equal(t, "Pod", u.GetKind())
+	volumes, _, _ := unstructured.NestedSlice(u.Object, "spec", "volumes")
+	require.Equal(t, []interface{}{wantVolume}, volumes)
+	// The placeholder of the typed Pod is not changed.
+	require.NotNil(t, pod.Spec.Volumes[0].EmptyDir)
+
+	// Deployments
+	deploy := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Annotations: pod.Annotations},
+		Spec:       pod.Spec,
+	}}}
+	u, err = withImageVolumes(deploy, scheme)
+	require.NoError(t, err)
+	require.NotNil(t, u)
+	volumes, _, _ = unstructured.NestedSlice(u.Object, "spec", "template", "spec", "volumes")
+	require.Equal(t, []interface{}{wantVolume}, volumes)
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deploy))
+
+	// LeaderWorkerSets
+	leaderTemplate, err := toUnstructuredPodTemplate(pod)
+	require.NoError(t, err)
+	lws := newLeaderWorkerSet()
+	lws.Object["spec"] = map[string]interface{}{
+		"leaderWorkerTemplate": map[string]interface{}{"leaderTemplate": leaderTemplate},
+	}
+	u, err = withImageVolumes(lws, scheme)
+	require.NoError(t, err)
+	require.NotNil(t, u)
+	volumes, _, _ = unstructured.NestedSlice(u.Object, "spec", "leaderWorkerTemplate", "leaderTemplate", "spec", "volumes")
+	require.Equal(t, []interface{}{wantVolume}, volumes)
+
+	// Objects without image volumes are not converted.
+	u, err = withImageVolumes(&corev1.Pod{}, scheme)
+	require.NoError(t, err)
+	require.Nil(t, u)
+}
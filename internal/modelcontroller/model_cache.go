@@ -0,0 +1,465 @@
+package modelcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ModelCacheReconciler loads the model of a ModelCache into the cache of a
+// sharedFilesystem cache profile, reloads it at the refresh interval and
+// evicts it when the ModelCache is deleted. Models with the same url and
+// cache profile are served from the cached model by the ModelReconciler.
+type ModelCacheReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// ModelReconciler provides the cache profiles and the model loader configuration.
+	ModelReconciler *ModelReconciler
+}
+
+func (r *ModelCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, resErr error) {
+	mc := &kubeaiv1.ModelCache{}
+	if err := r.Get(ctx, req.NamespacedName, mc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	original := mc.Status.DeepCopy()
+
+	defer func() {
+		if equality.Semantic.DeepEqual(*original, mc.Status) {
+			return
+		}
+		if err := r.Status().Update(ctx, mc); err != nil && !apierrors.IsNotFound(err) {
+			resErr = errors.Join(resErr, fmt.Errorf("updating model cache status: %w", err))
+		}
+	}()
+
+	return r.reconcileModelCache(ctx, mc)
+}
+
+func (r *ModelCacheReconciler) reconcileModelCache(ctx context.Context, mc *kubeaiv1.ModelCache) (ctrl.Result, error) {
+	cacheProfile, ok := r.ModelReconciler.CacheProfiles[mc.Spec.CacheProfile]
+	if !ok || cacheProfile.SharedFilesystem == nil {
+		if mc.DeletionTimestamp != nil {
+			// Nothing can be evicted without the cache profile.
+			return ctrl.Result{}, r.removeFinalizer(ctx, mc)
+		}
+		setModelCacheCondition(mc, metav1.ConditionFalse, kubeaiv1.ModelReasonInvalidConfiguration,
+			fmt.Sprintf("cacheProfile %q is not a sharedFilesystem cache profile", mc.Spec.CacheProfile))
+		return ctrl.Result{}, nil
+	}
+	src, err := r.ModelReconciler.parseModelSource(mc.Spec.URL)
+	if err != nil {
+		setModelCacheCondition(mc, metav1.ConditionFalse, kubeaiv1.ModelReasonInvalidConfiguration,
+			fmt.Sprintf("parsing url: %v", err))
+		return ctrl.Result{}, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvcExists := true
+	if err := r.Get(ctx, types.NamespacedName{Namespace: mc.Namespace, Name: sharedCachePVCName(mc.Spec.CacheProfile)}, pvc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting cache PVC: %w", err)
+		}
+		pvcExists = false
+	}
+
+	if mc.DeletionTimestamp == nil && controllerutil.AddFinalizer(mc, kubeaiv1.ModelCacheEvictionFinalizer) {
+		if err := r.Update(ctx, mc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("adding cache eviction finalizer: %w", err)
+		}
+	}
+
+	models, err := r.modelsServedFrom(ctx, mc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	mc.Status.Models = models
+
+	if mc.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.finalizeModelCache(ctx, mc, pvcExists && pvc.DeletionTimestamp == nil)
+	}
+
+	if !pvcExists {
+		// The size of shared filesystems is not enforced by most provisioners.
+		pvc = sharedCachePVC(mc.Namespace, mc.Spec.CacheProfile, cacheProfile.SharedFilesystem, resource.MustParse("10Gi"))
+		if err := r.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+			return ctrl.Result{}, fmt.Errorf("creating cache PVC: %w", err)
+		}
+	}
+
+	job := &batchv1.Job{}
+	jobExists := true
+	if err := r.Get(ctx, types.NamespacedName{Namespace: mc.Namespace, Name: loadModelCacheJobName(mc)}, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting cache job: %w", err)
+		}
+		jobExists = false
+	}
+
+	if !jobExists {
+		if refreshIn := modelCacheRefreshIn(mc, time.Now()); mc.Status.Loaded && refreshIn > 0 {
+			return ctrl.Result{RequeueAfter: refreshIn}, nil
+		} else if mc.Status.Loaded && mc.Spec.RefreshInterval == nil {
+			return ctrl.Result{}, nil
+		}
+		job = r.ModelReconciler.loadCacheJob(loadModelCacheJobName(mc), mc.Namespace, mc.Spec.URL, mc.Spec.Env,
+			modelCacheDirOfModelCache(mc.Name), pvc.Name, src)
+		if mc.Status.Loaded {
+			patchCacheRefreshJob(job, strconv.FormatInt(time.Now().Unix(), 10))
+		}
+		if err := ctrl.SetControllerReference(mc, job, r.Scheme); err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting controller reference on job: %w", err)
+		}
+		if err := r.Create(ctx, job); err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating job: %w", err)
+		}
+		setModelCacheLoadingCondition(mc, fmt.Sprintf("Loading the model into the cache with Job %s", job.Name))
+		return ctrl.Result{}, nil
+	}
+
+	if job.DeletionTimestamp != nil {
+		// The Job of a previous load has to be gone before the model is loaded again.
+		return ctrl.Result{}, nil
+	}
+
+	if k8sutils.IsJobFailed(job) {
+		_, failure, err := r.ModelReconciler.jobContainerFailures(ctx, job, "loader")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		msg := fmt.Sprintf("Job %s failed to load the model into the cache", job.Name)
+		if failure != "" {
+			msg += ": " + failure
+		}
+		mc.Status.Progress = nil
+		if !mc.Status.Loaded {
+			setModelCacheCondition(mc, metav1.ConditionFalse, kubeaiv1.ModelReasonLoadFailed, msg)
+			return ctrl.Result{}, nil
+		}
+		// The previously loaded model is still served, retry at the next refresh.
+		setModelCacheCondition(mc, metav1.ConditionTrue, kubeaiv1.ModelCacheReasonRefreshFailed, msg)
+		if mc.Spec.RefreshInterval == nil {
+			return ctrl.Result{}, nil
+		}
+		retryIn := time.Until(job.CreationTimestamp.Add(mc.Spec.RefreshInterval.Duration))
+		if retryIn > 0 {
+			return ctrl.Result{RequeueAfter: retryIn}, nil
+		}
+		return ctrl.Result{}, r.deleteModelCacheJob(ctx, mc.Namespace, job.Name)
+	}
+
+	if !k8sutils.IsJobCompleted(job) {
+		progress, msg, err := r.ModelReconciler.cacheLoadJobProgress(ctx, job)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		mc.Status.Progress = progress
+		setModelCacheLoadingCondition(mc, msg)
+		return ctrl.Result{RequeueAfter: cacheLoadProgressPeriod}, nil
+	}
+
+	msg, err := r.ModelReconciler.jobTerminationMessage(ctx, job, "loader")
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var report cacheLoadReport
+	if msg != "" {
+		if err := json.Unmarshal([]byte(msg), &report); err != nil {
+			return ctrl.Result{}, fmt.Errorf("parsing report of job %q: %w", job.Name, err)
+		}
+	}
+	mc.Status.Loaded = true
+	mc.Status.Revision = report.Revision
+	mc.Status.ManifestSHA256 = report.ManifestSHA256
+	mc.Status.SizeBytes = report.SizeBytes
+	mc.Status.LoadedTime = ptr.To(metav1.Now())
+	mc.Status.Progress = nil
+	setModelCacheCondition(mc, metav1.ConditionTrue, kubeaiv1.ModelReasonLoaded, "")
+
+	// Delete the Job to load the model again at the next refresh.
+	if err := r.deleteModelCacheJob(ctx, mc.Namespace, job.Name); err != nil {
+		return ctrl.Result{}, err
+	}
+	if mc.Spec.RefreshInterval != nil {
+		return ctrl.Result{RequeueAfter: mc.Spec.RefreshInterval.Duration}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// finalizeModelCache evicts the cached model once no Model is served from it.
+func (r *ModelCacheReconciler) finalizeModelCache(ctx context.Context, mc *kubeaiv1.ModelCache, pvcExists bool) error {
+	if !controllerutil.ContainsFinalizer(mc, kubeaiv1.ModelCacheEvictionFinalizer) {
+		return nil
+	}
+	if !pvcExists {
+		if err := r.deleteModelCacheJobs(ctx, mc); err != nil {
+			return err
+		}
+		return r.removeFinalizer(ctx, mc)
+	}
+	if len(mc.Status.Models) > 0 {
+		// The Models load their own copy of the model in the meantime.
+		setModelCacheCondition(mc, metav1.ConditionTrue, kubeaiv1.ModelReasonEvicting,
+			fmt.Sprintf("Waiting for Models %s to load their own copy of the model", strings.Join(mc.Status.Models, ", ")))
+		return nil
+	}
+
+	// Stop loading before the files are removed.
+	if err := r.deleteModelCacheJob(ctx, mc.Namespace, loadModelCacheJobName(mc)); err != nil {
+		return err
+	}
+
+	evictJob := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: mc.Namespace, Name: evictModelCacheJobName(mc)}, evictJob); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting cache eviction job: %w", err)
+		}
+		evictJob = r.ModelReconciler.evictCacheJob(evictModelCacheJobName(mc), mc.Namespace,
+			modelCacheDirOfModelCache(mc.Name), sharedCachePVCName(mc.Spec.CacheProfile))
+		if err := ctrl.SetControllerReference(mc, evictJob, r.Scheme); err != nil {
+			return fmt.Errorf("setting controller reference on cache eviction job: %w", err)
+		}
+		if err := r.Create(ctx, evictJob); err != nil {
+			return fmt.Errorf("creating cache eviction job: %w", err)
+		}
+		setModelCacheCondition(mc, metav1.ConditionFalse, kubeaiv1.ModelReasonEvicting,
+			fmt.Sprintf("Evicting the model from the cache with Job %s", evictJob.Name))
+		return nil
+	}
+	if !k8sutils.IsJobCompleted(evictJob) {
+		return nil
+	}
+
+	if err := r.removeFinalizer(ctx, mc); err != nil {
+		return err
+	}
+	return r.deleteModelCacheJobs(ctx, mc)
+}
+
+func (r *ModelCacheReconciler) removeFinalizer(ctx context.Context, mc *kubeaiv1.ModelCache) error {
+	if controllerutil.RemoveFinalizer(mc, kubeaiv1.ModelCacheEvictionFinalizer) {
+		if err := r.Update(ctx, mc); err != nil {
+			return fmt.Errorf("removing cache eviction finalizer: %w", err)
+		}
+	}
+	return nil
+}
+
+// modelsServedFrom returns the sorted names of the Models that are served
+// from the model of a ModelCache.
+func (r *ModelCacheReconciler) modelsServedFrom(ctx context.Context, mc *kubeaiv1.ModelCache) ([]string, error) {
+	var models kubeaiv1.ModelList
+	if err := r.List(ctx, &models, client.InNamespace(mc.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing models: %w", err)
+	}
+	var names []string
+	for _, m := range models.Items {
+		if m.Status.Cache != nil && m.Status.Cache.ModelCache == mc.Name {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (r *ModelCacheReconciler) deleteModelCacheJob(ctx context.Context, namespace, name string) error {
+	if err := r.Delete(ctx, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting job %q: %w", name, err)
+	}
+	return nil
+}
+
+// deleteModelCacheJobs deletes the Jobs of a ModelCache and their Pods.
+func (r *ModelCacheReconciler) deleteModelCacheJobs(ctx context.Context, mc *kubeaiv1.ModelCache) error {
+	for _, name := range []string{loadModelCacheJobName(mc), evictModelCacheJobName(mc)} {
+		if err := r.deleteModelCacheJob(ctx, mc.Namespace, name); err != nil {
+			return err
+		}
+		if err := r.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(mc.Namespace), client.MatchingLabels{
+			batchv1.JobNameLabel: name,
+		}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting pods for job %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// modelCacheRefreshIn returns the time until the model of a ModelCache is
+// loaded again, zero if it is due or the ModelCache is not refreshed.
+func modelCacheRefreshIn(mc *kubeaiv1.ModelCache, now time.Time) time.Duration {
+	if mc.Spec.RefreshInterval == nil || mc.Status.LoadedTime == nil {
+		return 0
+	}
+	return max(0, mc.Status.LoadedTime.Add(mc.Spec.RefreshInterval.Duration).Sub(now))
+}
+
+func setModelCacheCondition(mc *kubeaiv1.ModelCache, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mc.Status.Conditions, metav1.Condition{
+		Type:               kubeaiv1.ModelCacheConditionLoaded,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mc.Generation,
+	})
+}
+
+// setModelCacheLoadingCondition reports a running load Job, which refreshes
+// the model if it was loaded before.
+func setModelCacheLoadingCondition(mc *kubeaiv1.ModelCache, message string) {
+	if mc.Status.Loaded {
+		setModelCacheCondition(mc, metav1.ConditionTrue, kubeaiv1.ModelCacheReasonRefreshing, message)
+		return
+	}
+	setModelCacheCondition(mc, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading, message)
+}
+
+func modelCacheDirOfModelCache(name string) string {
+	return fmt.Sprintf("/models/modelcache-%s", name)
+}
+
+func loadModelCacheJobName(mc *kubeaiv1.ModelCache) string {
+	return fmt.Sprintf("load-modelcache-%s", mc.Name)
+}
+
+func evictModelCacheJobName(mc *kubeaiv1.ModelCache) string {
+	return fmt.Sprintf("evict-modelcache-%s", mc.Name)
+}
+
+// modelCacheForModel returns the ModelCache with the url and cache profile
+// of a Model, preferring the one that the Model is served from and loaded
+// ones. It returns nil if there is no such ModelCache.
+func (r *ModelReconciler) modelCacheForModel(ctx context.Context, m *kubeaiv1.Model) (*kubeaiv1.ModelCache, error) {
+	var list kubeaiv1.ModelCacheList
+	if err := r.List(ctx, &list, client.InNamespace(m.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing model caches: %w", err)
+	}
+	var candidates []*kubeaiv1.ModelCache
+	for i := range list.Items {
+		mc := &list.Items[i]
+		if mc.Spec.URL == m.Spec.URL && mc.Spec.CacheProfile == m.Spec.CacheProfile && mc.DeletionTimestamp == nil {
+			candidates = append(candidates, mc)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	rank := func(mc *kubeaiv1.ModelCache) int {
+		switch {
+		case mc.Name == m.Status.Cache.ModelCache:
+			return 0
+		case mc.Status.Loaded:
+			return 1
+		default:
+			return 2
+		}
+	}
+	slices.SortFunc(candidates, func(a, b *kubeaiv1.ModelCache) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return candidates[0], nil
+}
+
+// reconcileModelCacheOfModel serves a Model from the model of a ModelCache
+// with the same url and cache profile. It returns false if the Model loads
+// its own copy of the model instead. errReturnEarly is returned while the
+// Model waits for the ModelCache to load the model.
+func reconcileModelCacheOfModel(model *kubeaiv1.Model, mc *kubeaiv1.ModelCache) (bool, error) {
+	if mc != nil && mc.Status.Loaded {
+		model.Status.Cache.ModelCache = mc.Name
+		model.Status.Cache.Loaded = true
+		model.Status.Cache.Revision = mc.Status.Revision
+		model.Status.Cache.ManifestSHA256 = mc.Status.ManifestSHA256
+		model.Status.Cache.Files = nil
+		model.Status.Cache.Progress = nil
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionTrue, kubeaiv1.ModelReasonLoaded,
+			fmt.Sprintf("Served from ModelCache %s", mc.Name))
+		return true, nil
+	}
+
+	if model.Status.Cache.ModelCache != "" {
+		// The ModelCache is gone, the Model is loaded on its own.
+		model.Status.Cache.ModelCache = ""
+		model.Status.Cache.Loaded = false
+	}
+	if mc == nil || model.Status.Cache.Loaded {
+		return false, nil
+	}
+	if cond := meta.FindStatusCondition(mc.Status.Conditions, kubeaiv1.ModelCacheConditionLoaded); cond != nil &&
+		(cond.Reason == kubeaiv1.ModelReasonLoadFailed || cond.Reason == kubeaiv1.ModelReasonInvalidConfiguration) {
+		return false, nil
+	}
+	setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+		fmt.Sprintf("Waiting for ModelCache %s to load the model", mc.Name))
+	return true, errReturnEarly
+}
+
+// modelsForModelCache enqueues the Models that may be served from a ModelCache.
+func (r *ModelReconciler) modelsForModelCache(ctx context.Context, obj client.Object) []reconcile.Request {
+	mc, ok := obj.(*kubeaiv1.ModelCache)
+	if !ok {
+		return nil
+	}
+	var models kubeaiv1.ModelList
+	if err := r.List(ctx, &models, client.InNamespace(mc.Namespace)); err != nil {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, m := range models.Items {
+		if (m.Spec.URL == mc.Spec.URL && m.Spec.CacheProfile == mc.Spec.CacheProfile) ||
+			(m.Status.Cache != nil && m.Status.Cache.ModelCache == mc.Name) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&m)})
+		}
+	}
+	return reqs
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("modelcache").
+		For(&kubeaiv1.ModelCache{}).
+		Owns(&batchv1.Job{}).
+		Watches(&kubeaiv1.Model{}, handler.EnqueueRequestsFromMapFunc(modelCacheForServedModel)).
+		Complete(r)
+}
+
+// modelCacheForServedModel enqueues the ModelCache that a Model is served from.
+func modelCacheForServedModel(_ context.Context, obj client.Object) []reconcile.Request {
+	m, ok := obj.(*kubeaiv1.Model)
+	if !ok || m.Status.Cache == nil || m.Status.Cache.ModelCache == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Status.Cache.ModelCache}}}
+}
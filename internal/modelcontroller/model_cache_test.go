@@ -0,0 +1,114 @@
+package modelcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_modelCacheRefreshIn(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cases := map[string]struct {
+		interval   *metav1.Duration
+		loadedTime *metav1.Time
+		want       time.Duration
+	}{
+		"not refreshed": {
+			loadedTime: &metav1.Time{Time: now},
+		},
+		"not loaded": {
+			interval: &metav1.Duration{Duration: time.Hour},
+		},
+		"not due": {
+			interval:   &metav1.Duration{Duration: time.Hour},
+			loadedTime: &metav1.Time{Time: now.Add(-15 * time.Minute)},
+			want:       45 * time.Minute,
+		},
+		"due": {
+			interval:   &metav1.Duration{Duration: time.Hour},
+			loadedTime: &metav1.Time{Time: now.Add(-2 * time.Hour)},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mc := &v1.ModelCache{
+				Spec:   v1.ModelCacheSpec{RefreshInterval: c.interval},
+				Status: v1.ModelCacheStatus{LoadedTime: c.loadedTime},
+			}
+			require.Equal(t, c.want, modelCacheRefreshIn(mc, now))
+		})
+	}
+}
+
+func Test_reconcileModelCacheOfModel(t *testing.T) {
+	t.Parallel()
+
+	loaded := &v1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache"},
+		Status:     v1.ModelCacheStatus{Loaded: true, Revision: "0123abcd"},
+	}
+	loading := &v1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache"},
+	}
+	failed := &v1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache"},
+		Status: v1.ModelCacheStatus{Conditions: []metav1.Condition{{
+			Type:   v1.ModelCacheConditionLoaded,
+			Status: metav1.ConditionFalse,
+			Reason: v1.ModelReasonLoadFailed,
+		}}},
+	}
+	cases := map[string]struct {
+		mc             *v1.ModelCache
+		cache          v1.ModelStatusCache
+		wantServed     bool
+		wantErr        error
+		wantModelCache string
+		wantLoaded     bool
+	}{
+		"no model cache": {},
+		"loaded": {
+			mc:             loaded,
+			wantServed:     true,
+			wantModelCache: "cache",
+			wantLoaded:     true,
+		},
+		"loading": {
+			mc:         loading,
+			wantServed: true,
+			wantErr:    errReturnEarly,
+		},
+		"loading with own copy": {
+			mc:         loading,
+			cache:      v1.ModelStatusCache{Loaded: true},
+			wantLoaded: true,
+		},
+		"failed": {
+			mc: failed,
+		},
+		"deleted": {
+			cache: v1.ModelStatusCache{Loaded: true, ModelCache: "cache"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			m := &v1.Model{Status: v1.ModelStatus{Cache: c.cache.DeepCopy()}}
+			served, err := reconcileModelCacheOfModel(m, c.mc)
+			require.Equal(t, c.wantServed, served)
+			require.Equal(t, c.wantErr, err)
+			require.Equal(t, c.wantModelCache, m.Status.Cache.ModelCache)
+			require.Equal(t, c.wantLoaded, m.Status.Cache.Loaded)
+			if c.wantModelCache != "" {
+				require.Equal(t, "/models/modelcache-cache", modelCacheDir(m))
+				require.Equal(t, c.mc.Status.Revision, m.Status.Cache.Revision)
+			}
+		})
+	}
+}
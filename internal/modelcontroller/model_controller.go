@@ -25,19 +25,27 @@ import (
 	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/config"
 	"github.com/substratusai/kubeai/internal/k8sutils"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"github.com/substratusai/kubeai/internal/vllmclient"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -55,6 +63,7 @@ type ModelReconciler struct {
 	RESTConfig              *rest.Config
 	PodRESTClient           rest.Interface
 	Scheme                  *runtime.Scheme
+	Recorder                record.EventRecorder
 	VLLMClient              *vllmclient.Client
 	Namespace               string
 	AllowPodAddressOverride bool
@@ -65,6 +74,22 @@ type ModelReconciler struct {
 	ModelServerPods         config.ModelServerPods
 	ModelLoaders            config.ModelLoading
 	ModelRollouts           config.ModelRollouts
+	// LoadReports provides the responses of the model server Pods which are
+	// used to analyze BlueGreen and Canary rollouts. Optional.
+	LoadReports *loadreport.Aggregator
+	// MaxConcurrentReconciles is the number of Models that can be
+	// reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// expectations tracks the Pod creations and deletions that were
+	// requested but not observed yet.
+	expectations *podExpectations
+	// leaderWorkerSetInstalled is true when the LeaderWorkerSet API was
+	// available when the controller was started.
+	leaderWorkerSetInstalled bool
+	// imageVolumes is true when image volumes are enabled and supported by
+	// the API server.
+	imageVolumes bool
 }
 
 func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, resErr error) {
@@ -73,6 +98,9 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 
 	model := &kubeaiv1.Model{}
 	if err := r.Get(ctx, req.NamespacedName, model); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.expectations.forget(req.String())
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -85,6 +113,14 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 			}
 		}
 	}()
+	defer func() {
+		if model.DeletionTimestamp != nil {
+			return
+		}
+		model.Status.ObservedGeneration = model.Generation
+		model.Status.Phase = modelPhase(model)
+		r.recordConditionEvents(model, status0.Conditions)
+	}()
 
 	// Ensure ConfigMap for model files exists and is up to date
 	if err := r.ensureModelFilesConfigMap(ctx, model); err != nil {
@@ -95,7 +131,7 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 	// Apply self labels based on features so that we can easily filter models.
 	shouldUpdate := r.applySelfLabels(model)
 	// Apply replica bounds to handle cases where min/max replicas were updated but a scale event was not triggered.
-	if !model.Spec.AutoscalingDisabled {
+	if !model.Spec.AutoscalingDisabled && !model.Spec.AutoscalingDryRun {
 		shouldUpdate = r.applyAutoscalingReplicaBounds(model) || shouldUpdate
 	}
 	if shouldUpdate {
@@ -104,23 +140,30 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		}
 	}
 
-	modelConfig, err := r.getModelConfig(model)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("getting model profile: %w", err)
-	}
-
 	if model.DeletionTimestamp != nil {
 		// Get rid of all Pods for the Model.
 		// This should help avoid any issues with cache cleanup.
-		if err := r.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(model.Namespace), client.MatchingLabels{
-			kubeaiv1.PodModelLabel: model.Name,
-		}); err != nil {
-			if !apierrors.IsNotFound(err) {
-				return ctrl.Result{}, fmt.Errorf("deleting all pods: %w", err)
+		// The workload is deleted first to not have it recreate its Pods.
+		if err := r.deleteWorkloadsExcept(ctx, model, kubeaiv1.PodWorkloadKind); err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, label := range []string{kubeaiv1.PodModelLabel, kubeaiv1.ParkedPodModelLabel} {
+			if err := r.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(model.Namespace), client.MatchingLabels{
+				label: model.Name,
+			}); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return ctrl.Result{}, fmt.Errorf("deleting all pods: %w", err)
+				}
 			}
 		}
 		if model.Spec.CacheProfile != "" {
-			if err := r.finalizeCache(ctx, model, modelConfig); err != nil {
+			// The cache is finalized without resolving the ModelEngine,
+			// which might have been deleted before the Model.
+			cacheProfile, ok := r.CacheProfiles[model.Spec.CacheProfile]
+			if !ok {
+				return ctrl.Result{}, fmt.Errorf("cache profile not found: %q", model.Spec.CacheProfile)
+			}
+			if err := r.finalizeCache(ctx, model, ModelConfig{CacheProfile: cacheProfile}); err != nil {
 				if errors.Is(err, errReturnEarly) {
 					return ctrl.Result{}, nil
 				} else {
@@ -132,7 +175,15 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		return ctrl.Result{}, nil
 	}
 
-	if model.Spec.CacheProfile != "" {
+	modelConfig, err := r.getModelConfig(ctx, model)
+	if err != nil {
+		setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionTrue, kubeaiv1.ModelReasonInvalidConfiguration, err.Error())
+		return ctrl.Result{}, fmt.Errorf("getting model profile: %w", err)
+	}
+
+	if model.Spec.CacheProfile == "" {
+		meta.RemoveStatusCondition(&model.Status.Conditions, kubeaiv1.ModelConditionCacheLoaded)
+	} else {
 		cacheRes, err := r.reconcileCache(ctx, model, modelConfig)
 		if err != nil {
 			if errors.Is(err, errReturnEarly) {
@@ -145,12 +196,17 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		}
 	}
 
+	if err := r.reconcilePodDisruptionBudget(ctx, model); err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconciling pod disruption budget: %w", err)
+	}
+
 	allPods := &corev1.PodList{}
 	if err := r.List(ctx, allPods, client.InNamespace(model.Namespace), client.MatchingLabels{
 		kubeaiv1.PodModelLabel: model.Name,
 	}); err != nil {
 		return ctrl.Result{}, fmt.Errorf("listing all node pools: %w", err)
 	}
+	allPods.Items = workloadPods(model, allPods.Items)
 
 	// Summarize all pods.
 	var readyPods int32
@@ -162,50 +218,119 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 	model.Status.Replicas.All = int32(len(allPods.Items))
 	model.Status.Replicas.Ready = readyPods
 
-	scaled := false
-	defer func() {
-		if scaled {
-			// Slow things down to wait for caches to sync.
-			// This is important because the pod plan has some calculations that
-			// assume the cache is up to date.
-			// TODO: Use "epectations" instead of a wait - see the ReplicaSet controller.
-			time.Sleep(3 * time.Second)
-		}
-	}()
-
-	plan, err := r.calculatePodPlan(allPods, model, modelConfig)
+	parkedPods, err := r.reconcileWarmPool(ctx, model, modelConfig)
 	if err != nil {
-		log.Error(err, "Failed to calculate pod plan")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, fmt.Errorf("reconciling warm pool: %w", err)
 	}
 
-	if plan.containsActions() {
-		var err error
-		scaled, err = plan.execute(ctx, r.Client, r.Scheme)
+	var (
+		toRemain     []*corev1.Pod
+		requeueAfter time.Duration
+	)
+	if workloadKind(model) != kubeaiv1.PodWorkloadKind {
+		toRemain, err = r.reconcileWorkload(ctx, model, modelConfig, allPods.Items)
+		if err != nil {
+			log.Error(err, "Failed to reconcile workload")
+			setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionTrue, kubeaiv1.ModelReasonInvalidConfiguration, err.Error())
+			return ctrl.Result{}, nil
+		}
+	} else {
+		if err := r.deleteWorkloadsExcept(ctx, model, kubeaiv1.PodWorkloadKind); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// The Pod plan assumes that the cache is up to date, wait for the
+		// previously executed plan to be observed.
+		if ok, remaining := r.expectations.satisfied(req.String()); !ok {
+			log.Info("Waiting for Pod creations and deletions to be observed")
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+
+		plan, err := r.calculatePodPlan(allPods, model, modelConfig)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("executing pod plan: %w", err)
+			log.Error(err, "Failed to calculate pod plan")
+			setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionTrue, kubeaiv1.ModelReasonInvalidConfiguration, err.Error())
+			return ctrl.Result{}, nil
+		}
+		r.planDrain(plan, time.Now())
+		setPodConditions(model, allPods.Items, plan.outOfDate)
+		var cachedOnNodes []string
+		if modelConfig.CacheProfile.NodeLocal != nil && len(plan.toCreate) > 0 {
+			if cachedOnNodes, err = r.nodesWithCachedModel(ctx, model); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		for _, pod := range plan.toCreate {
+			preferNodesOfPods(pod, parkedPods)
+			preferNodes(pod, cachedOnNodes, 50)
+		}
+
+		if plan.containsActions() {
+			if err := plan.execute(ctx, r.Client, r.Scheme, r.expectations); err != nil {
+				return ctrl.Result{}, fmt.Errorf("executing pod plan: %w", err)
+			}
 		}
+		toRemain, requeueAfter = plan.toRemain, plan.requeueAfter
 	}
 
-	if err := r.reconcileAdapters(ctx, plan.toRemain, model.Spec.Adapters); err != nil {
+	if err := r.reconcileAdapters(ctx, toRemain, model, adapterProtocol(model, modelConfig)); err != nil {
 		if errors.Is(err, errReturnEarly) {
+			setCondition(model, kubeaiv1.ModelConditionAdaptersLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+				"Waiting for replicas to load adapters")
 			return ctrl.Result{}, nil
 		}
+		setCondition(model, kubeaiv1.ModelConditionAdaptersLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoadFailed, err.Error())
 		return ctrl.Result{}, fmt.Errorf("reconciling adapters: %w", err)
 	}
+	if len(model.Spec.Adapters) == 0 {
+		meta.RemoveStatusCondition(&model.Status.Conditions, kubeaiv1.ModelConditionAdaptersLoaded)
+	} else {
+		setCondition(model, kubeaiv1.ModelConditionAdaptersLoaded, metav1.ConditionTrue, kubeaiv1.ModelReasonLoaded,
+			fmt.Sprintf("%d adapters are loaded", len(model.Spec.Adapters)))
+	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// TODO: Set Model concurrency. Pod rollouts can be slow.
-	return ctrl.NewControllerManagedBy(mgr).
+	r.expectations = newPodExpectations()
+	if r.ModelLoaders.ImageVolumes {
+		supported, err := imageVolumesSupported(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("checking support of image volumes: %w", err)
+		}
+		if supported {
+			r.imageVolumes = true
+			r.Client = &imageVolumeClient{Client: r.Client}
+		} else {
+			mgr.GetLogger().Info("Image volumes are not supported by the cluster, pulling oci:// models with the model loader",
+				"minVersion", minImageVolumesVersion.String())
+		}
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&kubeaiv1.Model{}).
-		Owns(&corev1.Pod{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Watches(&corev1.Pod{}, &podExpectationsHandler{
+			EventHandler: handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+				&kubeaiv1.Model{}, handler.OnlyControllerOwner()),
+			expectations: r.expectations,
+		}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.modelForWorkloadPod)).
+		Watches(&kubeaiv1.ModelEngine{}, handler.EnqueueRequestsFromMapFunc(r.modelsForEngine)).
+		Watches(&kubeaiv1.NodeModelCache{}, handler.EnqueueRequestsFromMapFunc(modelsForNodeModelCache)).
+		Watches(&kubeaiv1.ModelCache{}, handler.EnqueueRequestsFromMapFunc(r.modelsForModelCache)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.modelsForCacheEntryJob)).
 		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Owns(&batchv1.Job{}).
-		Complete(r)
+		Owns(&appsv1.Deployment{})
+	// LeaderWorkerSet is optional, only watch it when it is installed.
+	if _, err := mgr.GetRESTMapper().RESTMapping(leaderWorkerSetGVK.GroupKind(), leaderWorkerSetGVK.Version); err == nil {
+		r.leaderWorkerSetInstalled = true
+		b = b.Owns(newLeaderWorkerSet())
+	}
+	return b.Complete(r)
 }
 
 var errReturnEarly = fmt.Errorf("return early")
@@ -252,9 +377,25 @@ type ModelConfig struct {
 	config.ResourceProfile
 	Image  string
 	Source modelSource
+	// Engine is set when the Model uses a ModelEngine instead of a built-in engine.
+	Engine *kubeaiv1.ModelEngine
+}
+
+func (r *ModelReconciler) getModelConfig(ctx context.Context, model *kubeaiv1.Model) (ModelConfig, error) {
+	var engine *kubeaiv1.ModelEngine
+	if !isBuiltinEngine(model.Spec.Engine) {
+		var err error
+		engine, err = r.getModelEngine(ctx, model)
+		if err != nil {
+			return ModelConfig{}, err
+		}
+	}
+	return r.modelConfigForEngine(model, engine)
 }
 
-func (r *ModelReconciler) getModelConfig(model *kubeaiv1.Model) (ModelConfig, error) {
+// modelConfigForEngine resolves the ModelConfig of a Model from the system config.
+// The engine is nil for the built-in engines.
+func (r *ModelReconciler) modelConfigForEngine(model *kubeaiv1.Model, engine *kubeaiv1.ModelEngine) (ModelConfig, error) {
 	var result ModelConfig
 
 	src, err := r.parseModelSource(model.Spec.URL)
@@ -262,6 +403,7 @@ func (r *ModelReconciler) getModelConfig(model *kubeaiv1.Model) (ModelConfig, er
 		return result, fmt.Errorf("parsing model source: %w", err)
 	}
 	result.Source = src
+	result.Engine = engine
 
 	if model.Spec.CacheProfile != "" {
 		cacheProfile, ok := r.CacheProfiles[model.Spec.CacheProfile]
@@ -309,7 +451,7 @@ func (r *ModelReconciler) getModelConfig(model *kubeaiv1.Model) (ModelConfig, er
 		result.Source.modelSourcePodAdditions.envFrom = model.Spec.EnvFrom
 	}
 
-	image, err := r.lookupServerImage(model, profile)
+	image, err := r.lookupServerImage(model, result.Engine, profile)
 	if err != nil {
 		return result, fmt.Errorf("looking up server image: %w", err)
 	}
@@ -318,21 +460,34 @@ func (r *ModelReconciler) getModelConfig(model *kubeaiv1.Model) (ModelConfig, er
 	return result, nil
 }
 
-func (r *ModelReconciler) lookupServerImage(model *kubeaiv1.Model, profile config.ResourceProfile) (string, error) {
-	if model.Spec.Image != "" {
-		return model.Spec.Image, nil
-	}
-
-	var serverImgs map[string]string
-	switch model.Spec.Engine {
+// modelServerConfig returns the system config of the given engine.
+func (r *ModelReconciler) modelServerConfig(engine string) config.ModelServer {
+	switch engine {
 	case kubeaiv1.OLlamaEngine:
-		serverImgs = r.ModelServers.OLlama.Images
+		return r.ModelServers.OLlama
 	case kubeaiv1.FasterWhisperEngine:
-		serverImgs = r.ModelServers.FasterWhisper.Images
+		return r.ModelServers.FasterWhisper
 	case kubeaiv1.InfinityEngine:
-		serverImgs = r.ModelServers.Infinity.Images
+		return r.ModelServers.Infinity
+	case kubeaiv1.SGLangEngine:
+		return r.ModelServers.SGLang
+	case kubeaiv1.TGIEngine:
+		return r.ModelServers.TGI
+	case kubeaiv1.LlamaCPPEngine:
+		return r.ModelServers.LlamaCPP
 	default:
-		serverImgs = r.ModelServers.VLLM.Images
+		return r.ModelServers.VLLM
+	}
+}
+
+func (r *ModelReconciler) lookupServerImage(model *kubeaiv1.Model, engine *kubeaiv1.ModelEngine, profile config.ResourceProfile) (string, error) {
+	if model.Spec.Image != "" {
+		return model.Spec.Image, nil
+	}
+
+	serverImgs := r.modelServerConfig(model.Spec.Engine).Images
+	if engine != nil {
+		serverImgs = engine.Spec.Images
 	}
 
 	// If no image name is provided for a profile, use the default image name.
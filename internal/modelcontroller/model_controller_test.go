@@ -1,6 +1,7 @@
 package modelcontroller
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -132,7 +133,7 @@ func Test_getModelConfig(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			model := c.input
-			config, err := r.getModelConfig(model)
+			config, err := r.getModelConfig(context.Background(), model)
 			require.NoError(t, err)
 			requireEqualJSON(t, c.expected, config)
 		})
@@ -0,0 +1,270 @@
+package modelcontroller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"text/template"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func isBuiltinEngine(engine string) bool {
+	switch engine {
+	case kubeaiv1.OLlamaEngine,
+		kubeaiv1.VLLMEngine,
+		kubeaiv1.FasterWhisperEngine,
+		kubeaiv1.InfinityEngine,
+		kubeaiv1.SGLangEngine,
+		kubeaiv1.TGIEngine,
+		kubeaiv1.LlamaCPPEngine:
+		return true
+	}
+	return false
+}
+
+// getModelEngine returns the ModelEngine referenced by the Model and validates
+// that the engine supports the url and features of the Model.
+func (r *ModelReconciler) getModelEngine(ctx context.Context, model *kubeaiv1.Model) (*kubeaiv1.ModelEngine, error) {
+	engine := &kubeaiv1.ModelEngine{}
+	if err := r.Get(ctx, types.NamespacedName{Name: model.Spec.Engine}, engine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("model engine not found: %q", model.Spec.Engine)
+		}
+		return nil, fmt.Errorf("getting model engine: %w", err)
+	}
+	if err := validateModelForEngine(model, engine); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+func validateModelForEngine(model *kubeaiv1.Model, engine *kubeaiv1.ModelEngine) error {
+	if model.Spec.CacheProfile == "" {
+		u, err := parseModelURL(model.Spec.URL)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(engine.Spec.URLSchemes, u.scheme) {
+			return fmt.Errorf("model engine %q does not support urls with scheme %q", engine.Name, u.scheme)
+		}
+	}
+	for _, f := range model.Spec.Features {
+		if !slices.Contains(engine.Spec.Features, f) {
+			return fmt.Errorf("model engine %q does not support feature %q", engine.Name, f)
+		}
+	}
+	if len(model.Spec.Adapters) > 0 && engine.Spec.AdapterProtocol != kubeaiv1.AdapterProtocolVLLM {
+		return fmt.Errorf("model engine %q does not support adapters", engine.Name)
+	}
+	return nil
+}
+
+// modelEngineTemplateData are the values of the placeholders in the container
+// template of a ModelEngine.
+type modelEngineTemplateData struct {
+	ModelPath       string
+	ModelRevision   string
+	ModelURL        string
+	ServedModelName string
+	Port            int32
+}
+
+func (r *ModelReconciler) modelEnginePodForModel(m *kubeaiv1.Model, c ModelConfig) (*corev1.Pod, error) {
+	engine := c.Engine
+	port := engine.Spec.Port
+	if port == 0 {
+		port = 8000
+	}
+	healthPath := engine.Spec.HealthPath
+	if healthPath == "" {
+		healthPath = "/health"
+	}
+
+	lbs := labelsForModel(m)
+	ann := r.annotationsForModel(m)
+	if _, ok := ann[kubeaiv1.ModelPodPortAnnotation]; !ok {
+		ann[kubeaiv1.ModelPodPortAnnotation] = strconv.Itoa(int(port))
+	}
+
+	data := modelEngineTemplateData{
+		ModelPath:       serverModelPath(m, c.Source.url),
+		ModelRevision:   serverModelRevision(m, c.Source.url),
+		ModelURL:        m.Spec.URL,
+		ServedModelName: m.Name,
+		Port:            port,
+	}
+	command, err := renderEngineTemplates(engine.Spec.Container.Command, data)
+	if err != nil {
+		return nil, fmt.Errorf("model engine %q: command: %w", engine.Name, err)
+	}
+	args, err := renderEngineTemplates(engine.Spec.Container.Args, data)
+	if err != nil {
+		return nil, fmt.Errorf("model engine %q: args: %w", engine.Name, err)
+	}
+	args = append(args, m.Spec.Args...)
+
+	env := []corev1.EnvVar{}
+	for _, e := range engine.Spec.Container.Env {
+		e = *e.DeepCopy()
+		if e.Value, err = renderEngineTemplate(e.Value, data); err != nil {
+			return nil, fmt.Errorf("model engine %q: env %q: %w", engine.Name, e.Name, err)
+		}
+		env = append(env, e)
+	}
+	var envKeys []string
+	for key := range m.Spec.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		env = append(env, corev1.EnvVar{
+			Name:  key,
+			Value: m.Spec.Env[key],
+		})
+	}
+
+	healthCheck := corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: healthPath,
+			Port: intstr.FromString("http"),
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   m.Namespace,
+			Labels:      lbs,
+			Annotations: ann,
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector:       c.NodeSelector,
+			Affinity:           c.Affinity,
+			Tolerations:        c.Tolerations,
+			SchedulerName:      c.SchedulerName,
+			RuntimeClassName:   c.RuntimeClassName,
+			PriorityClassName:  m.Spec.PriorityClassName,
+			ServiceAccountName: r.ModelServerPods.ModelServiceAccountName,
+			SecurityContext:    r.ModelServerPods.ModelPodSecurityContext,
+			ImagePullSecrets:   r.ModelServerPods.ImagePullSecrets,
+			Containers: []corev1.Container{
+				{
+					Name:            serverContainerName,
+					Image:           c.Image,
+					Command:         command,
+					Args:            args,
+					Env:             env,
+					SecurityContext: r.ModelServerPods.ModelContainerSecurityContext,
+					Resources: corev1.ResourceRequirements{
+						Requests: c.Requests,
+						Limits:   c.Limits,
+					},
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: port,
+							Protocol:      corev1.ProtocolTCP,
+							Name:          "http",
+						},
+					},
+					StartupProbe: &corev1.Probe{
+						// Give the model 1 hour to start up by default.
+						FailureThreshold: 1800,
+						PeriodSeconds:    2,
+						TimeoutSeconds:   2,
+						SuccessThreshold: 1,
+						ProbeHandler:     healthCheck,
+					},
+					ReadinessProbe: &corev1.Probe{
+						FailureThreshold: 3,
+						PeriodSeconds:    10,
+						TimeoutSeconds:   2,
+						SuccessThreshold: 1,
+						ProbeHandler:     healthCheck,
+					},
+					LivenessProbe: &corev1.Probe{
+						FailureThreshold: 3,
+						PeriodSeconds:    30,
+						TimeoutSeconds:   3,
+						SuccessThreshold: 1,
+						ProbeHandler:     healthCheck,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "dshm",
+							MountPath: "/dev/shm",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "dshm",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							Medium: corev1.StorageMediumMemory,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	patchFileVolumes(&pod.Spec, m)
+	if engine.Spec.AdapterProtocol == kubeaiv1.AdapterProtocolVLLM {
+		r.patchServerAdapterLoader(&pod.Spec, m, r.ModelLoaders.Image)
+	}
+	patchServerCacheVolumes(&pod.Spec, m, c)
+	c.Source.modelSourcePodAdditions.applyToPodSpec(&pod.Spec, 0)
+
+	return pod, nil
+}
+
+func renderEngineTemplates(tmpls []string, data modelEngineTemplateData) ([]string, error) {
+	var out []string
+	for _, tmpl := range tmpls {
+		s, err := renderEngineTemplate(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func renderEngineTemplate(tmpl string, data modelEngineTemplateData) (string, error) {
+	t, err := template.New("").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q: %w", tmpl, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("executing template %q: %w", tmpl, err)
+	}
+	return buf.String(), nil
+}
+
+// modelsForEngine returns a reconcile request for every Model that uses the
+// given ModelEngine.
+func (r *ModelReconciler) modelsForEngine(ctx context.Context, obj client.Object) []reconcile.Request {
+	var models kubeaiv1.ModelList
+	if err := r.List(ctx, &models, client.InNamespace(r.Namespace)); err != nil {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, m := range models.Items {
+		if m.Spec.Engine == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&m)})
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,108 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_modelEnginePodForModel(t *testing.T) {
+	engine := &v1.ModelEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-engine"},
+		Spec: v1.ModelEngineSpec{
+			Images: map[string]string{"default": "test-image"},
+			Container: v1.ModelEngineContainer{
+				Command: []string{"serve"},
+				Args:    []string{"--model={{ .ModelPath }}", "--name={{ .ServedModelName }}", "--port={{ .Port }}"},
+				Env:     []corev1.EnvVar{{Name: "SOURCE", Value: "{{ .ModelURL }}"}},
+			},
+			Port:       9000,
+			URLSchemes: []string{"hf"},
+			Features:   []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+			HealthPath: "/ping",
+		},
+	}
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns"},
+		Spec: v1.ModelSpec{
+			URL:      "hf://test-org/test-model",
+			Engine:   engine.Name,
+			Features: []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+			Args:     []string{"--extra"},
+			Env:      map[string]string{"B": "b", "A": "a"},
+		},
+	}
+
+	r := &ModelReconciler{}
+	src, err := r.parseModelSource(model.Spec.URL)
+	require.NoError(t, err)
+	pod, err := r.modelEnginePodForModel(model, ModelConfig{Image: "test-image", Source: src, Engine: engine})
+	require.NoError(t, err)
+
+	require.Equal(t, "9000", pod.Annotations[v1.ModelPodPortAnnotation])
+	require.Equal(t, "test-engine", pod.Labels[appKubernetesIOName])
+	server := pod.Spec.Containers[0]
+	require.Equal(t, []string{"serve"}, server.Command)
+	require.Equal(t, []string{"--model=test-org/test-model", "--name=test-mdl", "--port=9000", "--extra"}, server.Args)
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "SOURCE", Value: "hf://test-org/test-model"},
+		{Name: "A", Value: "a"},
+		{Name: "B", Value: "b"},
+	}, server.Env[:3])
+	require.Equal(t, int32(9000), server.Ports[0].ContainerPort)
+	require.Equal(t, "/ping", server.ReadinessProbe.HTTPGet.Path)
+
+	t.Run("unknown placeholder", func(t *testing.T) {
+		engine := engine.DeepCopy()
+		engine.Spec.Container.Args = []string{"--model={{ .Model }}"}
+		_, err := r.modelEnginePodForModel(model, ModelConfig{Source: src, Engine: engine})
+		require.ErrorContains(t, err, `model engine "test-engine": args`)
+	})
+}
+
+func Test_validateModelForEngine(t *testing.T) {
+	engine := &v1.ModelEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-engine"},
+		Spec: v1.ModelEngineSpec{
+			URLSchemes: []string{"hf", "pvc"},
+			Features:   []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+		},
+	}
+
+	cases := map[string]struct {
+		spec    v1.ModelSpec
+		wantErr string
+	}{
+		"supported": {
+			spec: v1.ModelSpec{URL: "pvc://test-pvc", Features: []v1.ModelFeature{v1.ModelFeatureTextGeneration}},
+		},
+		"unsupported scheme": {
+			spec:    v1.ModelSpec{URL: "s3://test-bucket/test-model"},
+			wantErr: `does not support urls with scheme "s3"`,
+		},
+		"unsupported scheme with cache": {
+			spec: v1.ModelSpec{URL: "s3://test-bucket/test-model", CacheProfile: "efs"},
+		},
+		"unsupported feature": {
+			spec:    v1.ModelSpec{URL: "hf://test-org/test-model", Features: []v1.ModelFeature{v1.ModelFeatureTextEmbedding}},
+			wantErr: `does not support feature "TextEmbedding"`,
+		},
+		"adapters": {
+			spec:    v1.ModelSpec{URL: "hf://test-org/test-model", Adapters: []v1.Adapter{{Name: "a", URL: "hf://a/a"}}},
+			wantErr: "does not support adapters",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateModelForEngine(&v1.Model{Spec: c.spec}, engine)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
@@ -32,10 +33,14 @@ func (r *ModelReconciler) parseModelSource(urlStr string) (modelSource, error) {
 		src.modelSourcePodAdditions = r.authForOSS()
 	case u.scheme == "s3":
 		src.modelSourcePodAdditions = r.authForS3()
+	case u.scheme == "az":
+		src.modelSourcePodAdditions = r.authForAzure()
 	case u.scheme == "hf":
 		src.modelSourcePodAdditions = r.authForHuggingfaceHub()
 	case u.scheme == "pvc":
 		src.modelSourcePodAdditions = r.pvcPodAdditions(u)
+	case u.scheme == "oci":
+		src.modelSourcePodAdditions = r.authForOCI()
 	default:
 		src.modelSourcePodAdditions = &modelSourcePodAdditions{}
 	}
@@ -56,11 +61,23 @@ func (c *modelSourcePodAdditions) append(other *modelSourcePodAdditions) {
 	c.volumeMounts = append(c.volumeMounts, other.volumeMounts...)
 }
 
+// applyToPodSpec adds the additions to a container of the Pod. Volumes that
+// were already added for another container (e.g. the credentials of the model
+// and of the adapter loader) are shared.
 func (c *modelSourcePodAdditions) applyToPodSpec(spec *corev1.PodSpec, containerIndex int) {
-	spec.Containers[containerIndex].EnvFrom = append(spec.Containers[containerIndex].EnvFrom, c.envFrom...)
-	spec.Containers[containerIndex].Env = append(spec.Containers[containerIndex].Env, c.env...)
-	spec.Volumes = append(spec.Volumes, c.volumes...)
-	spec.Containers[containerIndex].VolumeMounts = append(spec.Containers[containerIndex].VolumeMounts, c.volumeMounts...)
+	container := &spec.Containers[containerIndex]
+	container.EnvFrom = append(container.EnvFrom, c.envFrom...)
+	container.Env = append(container.Env, c.env...)
+	for _, vol := range c.volumes {
+		if !slices.ContainsFunc(spec.Volumes, func(v corev1.Volume) bool { return v.Name == vol.Name }) {
+			spec.Volumes = append(spec.Volumes, vol)
+		}
+	}
+	for _, mount := range c.volumeMounts {
+		if !slices.ContainsFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool { return m.MountPath == mount.MountPath }) {
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
 }
 
 func (r *ModelReconciler) modelAuthCredentialsForAllSources() *modelSourcePodAdditions {
@@ -69,6 +86,8 @@ func (r *ModelReconciler) modelAuthCredentialsForAllSources() *modelSourcePodAdd
 	c.append(r.authForGCS())
 	c.append(r.authForOSS())
 	c.append(r.authForS3())
+	c.append(r.authForAzure())
+	c.append(r.authForOCI())
 	return c
 }
 
@@ -110,6 +129,37 @@ func (r *ModelReconciler) authForS3() *modelSourcePodAdditions {
 	}
 }
 
+func (r *ModelReconciler) authForAzure() *modelSourcePodAdditions {
+	return &modelSourcePodAdditions{
+		env: []corev1.EnvVar{
+			{
+				Name: "AZURE_STORAGE_ACCOUNT",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: r.SecretNames.Azure,
+						},
+						Key:      "storageAccount",
+						Optional: ptr.To(true),
+					},
+				},
+			},
+			{
+				Name: "AZURE_STORAGE_SAS_TOKEN",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: r.SecretNames.Azure,
+						},
+						Key:      "sasToken",
+						Optional: ptr.To(true),
+					},
+				},
+			},
+		},
+	}
+}
+
 func (r *ModelReconciler) authForHuggingfaceHub() *modelSourcePodAdditions {
 	return &modelSourcePodAdditions{
 		env: []corev1.EnvVar{
@@ -200,6 +250,58 @@ func (r *ModelReconciler) authForOSS() *modelSourcePodAdditions {
 	}
 }
 
+// authForOCI mounts the image pull secrets of model server Pods to be used as
+// registry credentials when pulling "oci://" artifacts.
+func (r *ModelReconciler) authForOCI() *modelSourcePodAdditions {
+	const (
+		credentialsDir = "/secrets/oci-credentials"
+		volumeName     = "oci-credentials"
+	)
+	if len(r.ModelServerPods.ImagePullSecrets) == 0 {
+		return &modelSourcePodAdditions{}
+	}
+	var sources []corev1.VolumeProjection
+	for _, secret := range r.ModelServerPods.ImagePullSecrets {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: secret,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  corev1.DockerConfigJsonKey,
+						Path: secret.Name + ".json",
+					},
+				},
+				Optional: ptr.To(true),
+			},
+		})
+	}
+	return &modelSourcePodAdditions{
+		env: []corev1.EnvVar{
+			{
+				Name:  "OCI_CREDENTIALS_DIR",
+				Value: credentialsDir,
+			},
+		},
+		volumes: []corev1.Volume{
+			{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{
+						Sources: sources,
+					},
+				},
+			},
+		},
+		volumeMounts: []corev1.VolumeMount{
+			{
+				Name:      volumeName,
+				MountPath: credentialsDir,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
+
 func (r *ModelReconciler) pvcPodAdditions(url modelURL) *modelSourcePodAdditions {
 	volumeName := "model"
 	// Kubernetes does not support an subPath with a leading slash. SubPath needs to be
@@ -226,6 +328,148 @@ func (r *ModelReconciler) pvcPodAdditions(url modelURL) *modelSourcePodAdditions
 	}
 }
 
+// patchServerOCIPuller adds an init container to the model server Pod that
+// pulls the artifact of an "oci://" url into an emptyDir volume mounted at
+// "/model". Models with a cacheProfile are pulled into the cache by the load
+// Job instead. The artifact is mounted as an image volume instead when image
+// volumes are enabled and supported by the cluster.
+func (r *ModelReconciler) patchServerOCIPuller(pod *corev1.Pod, m *v1.Model, c ModelConfig) {
+	if c.Source.url.scheme != "oci" || m.Spec.CacheProfile != "" {
+		return
+	}
+	if r.imageVolumes {
+		patchServerImageVolume(pod, c)
+		return
+	}
+	const volumeName = "model"
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	puller := corev1.Container{
+		Name:  "model-puller",
+		Image: r.ModelLoaders.Image,
+		Args:  []string{c.Source.url.original, "/model"},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      volumeName,
+				MountPath: "/model",
+			},
+		},
+	}
+	auth := r.authForOCI()
+	puller.Env = append(puller.Env, auth.env...)
+	puller.VolumeMounts = append(puller.VolumeMounts, auth.volumeMounts...)
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, puller)
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: "/model",
+		ReadOnly:  true,
+	})
+}
+
+// isStreamedModel returns whether the engine streams the weights of a model
+// from object storage because it is not cached.
+func isStreamedModel(m *v1.Model, u modelURL) bool {
+	if m.Spec.CacheProfile != "" {
+		return false
+	}
+	return u.scheme == "s3" || u.scheme == "gs" || u.scheme == "oss"
+}
+
+// streamingModelURL returns the url that a model is streamed from. OSS is
+// streamed through its S3-compatible API.
+func streamingModelURL(u modelURL) string {
+	if u.scheme == "oss" {
+		return "s3://" + u.ref
+	}
+	return u.original
+}
+
+// streamingForOSS configures the S3 client of the streamer for the
+// S3-compatible API of OSS at the endpoint of the OSS_ENDPOINT env var.
+func (r *ModelReconciler) streamingForOSS(m *v1.Model) *modelSourcePodAdditions {
+	endpoint := m.Spec.Env["OSS_ENDPOINT"]
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return &modelSourcePodAdditions{
+		env: []corev1.EnvVar{
+			{
+				Name: "AWS_ACCESS_KEY_ID",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: r.SecretNames.Alibaba,
+						},
+						Key:      "accessKeyID",
+						Optional: ptr.To(true),
+					},
+				},
+			},
+			{
+				Name: "AWS_SECRET_ACCESS_KEY",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: r.SecretNames.Alibaba,
+						},
+						Key:      "accessKeySecret",
+						Optional: ptr.To(true),
+					},
+				},
+			},
+			{
+				Name:  "AWS_ENDPOINT_URL",
+				Value: endpoint,
+			},
+			{
+				// OSS only supports virtual hosted style requests.
+				Name:  "RUNAI_STREAMER_S3_USE_VIRTUAL_ADDRESSING",
+				Value: "1",
+			},
+			{
+				Name:  "AWS_EC2_METADATA_DISABLED",
+				Value: "true",
+			},
+		},
+	}
+}
+
+// serverRevisionArgs returns the flag that pins the revision of a model that
+// engines download from the Huggingface Hub.
+func serverRevisionArgs(m *v1.Model, u modelURL) []string {
+	if rev := serverModelRevision(m, u); rev != "" {
+		return []string{"--revision=" + rev}
+	}
+	return nil
+}
+
+// serverModelRevision returns the pinned revision of a model that engines
+// download from the Huggingface Hub, "" if the engine loads the model from
+// a local directory.
+func serverModelRevision(m *v1.Model, u modelURL) string {
+	if m.Spec.CacheProfile != "" || u.scheme != "hf" {
+		return ""
+	}
+	return u.revision
+}
+
+// serverModelPath returns the model reference that is passed to engines which
+// load models from the Huggingface Hub or a local directory.
+func serverModelPath(m *v1.Model, u modelURL) string {
+	switch {
+	case m.Spec.CacheProfile != "":
+		return modelCacheDir(m)
+	case u.scheme == "pvc" || u.scheme == "oci":
+		return "/model"
+	default:
+		return u.ref
+	}
+}
+
 var modelURLRegex = regexp.MustCompile(`^([a-z0-9]+):\/\/([^?]+)(\?.*)?$`)
 
 func parseModelURL(urlStr string) (modelURL, error) {
@@ -234,8 +478,34 @@ func parseModelURL(urlStr string) (modelURL, error) {
 		return modelURL{}, fmt.Errorf("invalid model URL: %s", urlStr)
 	}
 	scheme, ref := matches[1], matches[2]
+	var revision string
+	switch scheme {
+	case "hf":
+		// e.g. hf://org/model@<commit>
+		if i := strings.LastIndex(ref, "@"); i >= 0 {
+			ref, revision = ref[:i], ref[i+1:]
+		}
+	case "gs":
+		// e.g. gs://bucket/path/to/model.gguf#<generation>
+		ref, revision, _ = strings.Cut(ref, "#")
+	case "https":
+		// e.g. https://host/path/to/model.tar.gz#sha256=<checksum>
+		// The query is part of the download URL (e.g. a signed URL) and is
+		// not parsed for KubeAI parameters.
+		withoutFragment, fragment, _ := strings.Cut(urlStr, "#")
+		if checksum, ok := strings.CutPrefix(fragment, "sha256="); ok {
+			revision = "sha256:" + checksum
+		}
+		ref, _, _ = strings.Cut(strings.TrimPrefix(withoutFragment, "https://"), "?")
+		matches = matches[:3]
+	}
 	name, path, _ := strings.Cut(ref, "/")
 	var modelParam string
+	if scheme == "https" && strings.HasSuffix(path, ".gguf") {
+		// Single GGUF files are loaded into the cache directory under their
+		// file name.
+		modelParam = path[strings.LastIndex(path, "/")+1:]
+	}
 	var insecure bool
 	var pull bool = true
 
@@ -255,6 +525,9 @@ func parseModelURL(urlStr string) (modelURL, error) {
 			if strings.ToLower(pullVal) == "false" {
 				pull = false
 			}
+			if scheme == "s3" {
+				revision = urlParser.Get("versionId") // e.g. s3://bucket/path/to/model.gguf?versionId=<id>
+			}
 		}
 	}
 
@@ -267,14 +540,15 @@ func parseModelURL(urlStr string) (modelURL, error) {
 		modelParam: modelParam,
 		insecure:   insecure,
 		pull:       pull,
+		revision:   revision,
 	}, nil
 }
 
 type modelURL struct {
 	original string // e.g. "hf://username/model"
-	scheme   string // e.g. "hf", "s3", "gs", "oss", "pvc"
-	ref      string // e.g. "username/model"
-	name     string // e.g. username or bucket-name
+	scheme   string // e.g. "hf", "s3", "gs", "oss", "az", "https", "pvc", "oci"
+	ref      string // e.g. "username/model" or "registry/repo:tag"
+	name     string // e.g. username, bucket-name, container, host or registry
 	path     string // e.g. model or path/to/model
 	// e.g. "qwen2:0.5b" when ?model=qwen2:0.5b is part of the URL.
 	// This is used for Ollama where the PVC may have multiple models and we need to specify which one to load by name.
@@ -284,4 +558,10 @@ type modelURL struct {
 	insecure bool
 	// If false, the model will not be pulled and assumed to be already present.
 	pull bool
+	// e.g. the commit of "hf://username/model@<commit>", the version ID of
+	// "s3://bucket/model.gguf?versionId=<id>", the generation of
+	// "gs://bucket/model.gguf#<generation>" or the checksum of
+	// "https://host/model.gguf#sha256=<checksum>" (as "sha256:<checksum>").
+	// Empty if the URL is not pinned.
+	revision string
 }
@@ -4,6 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 func Test_parseModelURL(t *testing.T) {
@@ -135,6 +140,103 @@ func Test_parseModelURL(t *testing.T) {
 				pull:       true,
 			},
 		},
+		"valid-huggingface-with-revision": {
+			input: "hf://test-user/model-name@0123abcd",
+			want: modelURL{
+				scheme:   "hf",
+				ref:      "test-user/model-name",
+				name:     "test-user",
+				path:     "model-name",
+				pull:     true,
+				revision: "0123abcd",
+			},
+		},
+		"valid-huggingface-with-revision-and-modelname": {
+			input: "hf://test-user/model-name@v1.0?model=model-q4.gguf",
+			want: modelURL{
+				scheme:     "hf",
+				ref:        "test-user/model-name",
+				name:       "test-user",
+				path:       "model-name",
+				modelParam: "model-q4.gguf",
+				pull:       true,
+				revision:   "v1.0",
+			},
+		},
+		"valid-s3-with-version-id": {
+			input: "s3://test-bucket/path/model.gguf?versionId=3HL4kqtJlcpXroDTDmJ",
+			want: modelURL{
+				scheme:   "s3",
+				ref:      "test-bucket/path/model.gguf",
+				name:     "test-bucket",
+				path:     "path/model.gguf",
+				pull:     true,
+				revision: "3HL4kqtJlcpXroDTDmJ",
+			},
+		},
+		"valid-google-storage-with-generation": {
+			input: "gs://bucket-name/path/model.gguf#1360887697105000",
+			want: modelURL{
+				scheme:   "gs",
+				ref:      "bucket-name/path/model.gguf",
+				name:     "bucket-name",
+				path:     "path/model.gguf",
+				pull:     true,
+				revision: "1360887697105000",
+			},
+		},
+		"valid-azure-blob": {
+			input: "az://container-name/path/to/model",
+			want: modelURL{
+				scheme: "az",
+				ref:    "container-name/path/to/model",
+				name:   "container-name",
+				path:   "path/to/model",
+				pull:   true,
+			},
+		},
+		"valid-https-archive": {
+			input: "https://models.example.com/path/model.tar.gz",
+			want: modelURL{
+				scheme: "https",
+				ref:    "models.example.com/path/model.tar.gz",
+				name:   "models.example.com",
+				path:   "path/model.tar.gz",
+				pull:   true,
+			},
+		},
+		"valid-https-gguf-with-query-and-checksum": {
+			input: "https://models.example.com/path/model-q4_k_m.gguf?model=ignored&sig=abc#sha256=0123abcd",
+			want: modelURL{
+				scheme:     "https",
+				ref:        "models.example.com/path/model-q4_k_m.gguf",
+				name:       "models.example.com",
+				path:       "path/model-q4_k_m.gguf",
+				modelParam: "model-q4_k_m.gguf",
+				pull:       true,
+				revision:   "sha256:0123abcd",
+			},
+		},
+		"valid-oci-with-tag": {
+			input: "oci://registry.example.com/models/llama:v1",
+			want: modelURL{
+				scheme: "oci",
+				ref:    "registry.example.com/models/llama:v1",
+				name:   "registry.example.com",
+				path:   "models/llama:v1",
+				pull:   true,
+			},
+		},
+		"valid-oci-with-digest": {
+			input: "oci://registry.example.com/models/llama@sha256:0123abcd",
+			want: modelURL{
+				scheme: "oci",
+				ref:    "registry.example.com/models/llama@sha256:0123abcd",
+				name:   "registry.example.com",
+				path:   "models/llama@sha256:0123abcd",
+				pull:   true,
+			},
+		},
 		"valid-ollama-with-no-pull": {
 			input: "ollama://gemma2:2b?pull=false",
 			want: modelURL{
@@ -162,3 +264,236 @@ func Test_parseModelURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_serverModelPath(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		url          string
+		cacheProfile string
+		want         string
+	}{
+		"huggingface": {
+			url:  "hf://test-org/test-model",
+			want: "test-org/test-model",
+		},
+		"pvc": {
+			url:  "pvc://test-pvc/path/to/model",
+			want: "/model",
+		},
+		"oci": {
+			url:  "oci://registry.example.com/models/llama:v1",
+			want: "/model",
+		},
+		"cache": {
+			url:          "s3://test-bucket/test-model",
+			cacheProfile: "efs",
+			want:         "/models/test-mdl-test-uid",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			u, err := parseModelURL(c.url)
+			require.NoError(t, err)
+			m := &v1.Model{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: "test-uid"},
+				Spec:       v1.ModelSpec{URL: c.url, CacheProfile: c.cacheProfile},
+			}
+			require.Equal(t, c.want, serverModelPath(m, u))
+		})
+	}
+}
+
+func Test_patchServerOCIPuller(t *testing.T) {
+	t.Parallel()
+
+	r := &ModelReconciler{
+		ModelLoaders: config.ModelLoading{Image: "loader"},
+		ModelServerPods: config.ModelServerPods{
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-a"}, {Name: "registry-b"}},
+		},
+	}
+	cases := map[string]struct {
+		url          string
+		cacheProfile string
+		wantPuller   bool
+	}{
+		"oci": {
+			url:        "oci://registry.example.com/models/llama:v1",
+			wantPuller: true,
+		},
+		"oci with cache": {
+			url:          "oci://registry.example.com/models/llama:v1",
+			cacheProfile: "efs",
+		},
+		"huggingface": {
+			url: "hf://test-org/test-model",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			src, err := r.parseModelSource(c.url)
+			require.NoError(t, err)
+			m := &v1.Model{Spec: v1.ModelSpec{URL: c.url, CacheProfile: c.cacheProfile}}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "server"}}}}
+			r.patchServerOCIPuller(pod, m, ModelConfig{Source: src})
+			if !c.wantPuller {
+				require.Empty(t, pod.Spec.InitContainers)
+				require.Empty(t, pod.Spec.Volumes)
+				return
+			}
+
+			require.Equal(t, []corev1.Volume{{
+				Name:         "model",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}, pod.Spec.Volumes)
+			require.Equal(t, []corev1.Container{{
+				Name:  "model-puller",
+				Image: "loader",
+				Args:  []string{c.url, "/model"},
+				Env:   []corev1.EnvVar{{Name: "OCI_CREDENTIALS_DIR", Value: "/secrets/oci-credentials"}},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "model", MountPath: "/model"},
+					{Name: "oci-credentials", MountPath: "/secrets/oci-credentials", ReadOnly: true},
+				},
+			}}, pod.Spec.InitContainers)
+			require.Equal(t, []corev1.VolumeMount{
+				{Name: "model", MountPath: "/model", ReadOnly: true},
+			}, pod.Spec.Containers[0].VolumeMounts)
+
+			// Credentials are mounted from the image pull secrets by the source.
+			require.Len(t, src.volumes, 1)
+			projected := src.volumes[0].Projected
+			require.NotNil(t, projected)
+			require.Len(t, projected.Sources, 2)
+			require.Equal(t, &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "registry-b"},
+				Items:                []corev1.KeyToPath{{Key: ".dockerconfigjson", Path: "registry-b.json"}},
+				Optional:             ptr.To(true),
+			}, projected.Sources[1].Secret)
+		})
+	}
+}
+
+func Test_applyToPodSpecWithAdapters(t *testing.T) {
+	t.Parallel()
+
+	r := &ModelReconciler{
+		ModelLoaders: config.ModelLoading{Image: "loader"},
+		SecretNames:  config.SecretNames{GCP: "gcp"},
+		ModelServerPods: config.ModelServerPods{
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		},
+	}
+	for _, url := range []string{"oci://registry.example.com/models/llama:v1", "gs://bucket/llama"} {
+		t.Run(url, func(t *testing.T) {
+			t.Parallel()
+			src, err := r.parseModelSource(url)
+			require.NoError(t, err)
+			m := &v1.Model{Spec: v1.ModelSpec{
+				URL:      url,
+				Adapters: []v1.Adapter{{Name: "a", URL: "oci://registry.example.com/adapters/a:v1"}},
+			}}
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: serverContainerName}}}
+			src.applyToPodSpec(podSpec, 0)
+			r.patchServerAdapterLoader(podSpec, m, "loader")
+
+			// The server and the adapter loader share the credential volumes.
+			names := map[string]int{}
+			for _, vol := range podSpec.Volumes {
+				names[vol.Name]++
+			}
+			for name, n := range names {
+				require.Equal(t, 1, n, "volume %q", name)
+			}
+			require.Contains(t, names, "oci-credentials")
+			require.Contains(t, names, "gcp-credentials")
+			for _, container := range podSpec.Containers {
+				mountPaths := map[string]int{}
+				for _, mount := range container.VolumeMounts {
+					mountPaths[mount.MountPath]++
+					require.Contains(t, names, mount.Name)
+				}
+				for path, n := range mountPaths {
+					require.Equal(t, 1, n, "mount path %q of container %q", path, container.Name)
+				}
+			}
+		})
+	}
+}
+
+func Test_streamingModelURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		url          string
+		cacheProfile string
+		wantStreamed bool
+		want         string
+	}{
+		"s3": {
+			url:          "s3://test-bucket/test-model",
+			wantStreamed: true,
+			want:         "s3://test-bucket/test-model",
+		},
+		"gs": {
+			url:          "gs://test-bucket/test-model",
+			wantStreamed: true,
+			want:         "gs://test-bucket/test-model",
+		},
+		"oss": {
+			url:          "oss://test-bucket/test-model",
+			wantStreamed: true,
+			want:         "s3://test-bucket/test-model",
+		},
+		"gs with cache": {
+			url:          "gs://test-bucket/test-model",
+			cacheProfile: "efs",
+		},
+		"huggingface": {
+			url: "hf://test-org/test-model",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			u, err := parseModelURL(c.url)
+			require.NoError(t, err)
+			m := &v1.Model{Spec: v1.ModelSpec{URL: c.url, CacheProfile: c.cacheProfile}}
+			require.Equal(t, c.wantStreamed, isStreamedModel(m, u))
+			if c.wantStreamed {
+				require.Equal(t, c.want, streamingModelURL(u))
+			}
+		})
+	}
+}
+
+func Test_streamingForOSS(t *testing.T) {
+	t.Parallel()
+
+	r := &ModelReconciler{SecretNames: config.SecretNames{Alibaba: "alibaba"}}
+	cases := map[string]struct {
+		endpoint string
+		want     string
+	}{
+		"host": {
+			endpoint: "oss-cn-hangzhou.aliyuncs.com",
+			want:     "https://oss-cn-hangzhou.aliyuncs.com",
+		},
+		"url": {
+			endpoint: "http://oss-cn-hangzhou-internal.aliyuncs.com",
+			want:     "http://oss-cn-hangzhou-internal.aliyuncs.com",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			m := &v1.Model{Spec: v1.ModelSpec{Env: map[string]string{"OSS_ENDPOINT": c.endpoint}}}
+			additions := r.streamingForOSS(m)
+			require.Contains(t, additions.env, corev1.EnvVar{Name: "AWS_ENDPOINT_URL", Value: c.want})
+			require.Equal(t, "alibaba", additions.env[0].ValueFrom.SecretKeyRef.Name)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package modelcontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// builtinEngineFeatures are the features that the built-in engines support.
+var builtinEngineFeatures = map[string][]kubeaiv1.ModelFeature{
+	kubeaiv1.OLlamaEngine:        {kubeaiv1.ModelFeatureTextGeneration, kubeaiv1.ModelFeatureTextEmbedding},
+	kubeaiv1.VLLMEngine:          {kubeaiv1.ModelFeatureTextGeneration, kubeaiv1.ModelFeatureTextEmbedding},
+	kubeaiv1.FasterWhisperEngine: {kubeaiv1.ModelFeatureSpeechToText},
+	kubeaiv1.InfinityEngine:      {kubeaiv1.ModelFeatureTextEmbedding},
+	kubeaiv1.SGLangEngine:        {kubeaiv1.ModelFeatureTextGeneration, kubeaiv1.ModelFeatureTextEmbedding},
+	kubeaiv1.TGIEngine:           {kubeaiv1.ModelFeatureTextGeneration},
+	kubeaiv1.LlamaCPPEngine:      {kubeaiv1.ModelFeatureTextGeneration, kubeaiv1.ModelFeatureTextEmbedding},
+}
+
+// ModelWebhook validates Models against the system config of the
+// ModelReconciler and defaults fields that depend on it.
+// CEL validation rules of the CRD cover the checks that do not depend on the
+// system config.
+type ModelWebhook struct {
+	Reconciler *ModelReconciler
+	// DefaultImages sets the image of Models that do not specify an image
+	// to the image of their engine, pinning the image of existing Models
+	// when the images in the system config are changed.
+	DefaultImages bool
+}
+
+var (
+	_ admission.CustomValidator = &ModelWebhook{}
+	_ admission.CustomDefaulter = &ModelWebhook{}
+)
+
+// SetupWithManager registers the webhook with the webhook server of the Manager
+// (at /validate-kubeai-org-v1-model and /mutate-kubeai-org-v1-model).
+func (w *ModelWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kubeaiv1.Model{}).
+		WithValidator(w).
+		WithDefaulter(w).
+		Complete()
+}
+
+// Default sets the replicas of a Model to its minReplicas and, when enabled,
+// the image of a new Model to the image of the engine.
+func (w *ModelWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	model, ok := obj.(*kubeaiv1.Model)
+	if !ok {
+		return fmt.Errorf("expected a Model but got a %T", obj)
+	}
+
+	if model.Spec.Replicas == nil {
+		model.Spec.Replicas = ptr.To(model.Spec.MinReplicas)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if w.DefaultImages && model.Spec.Image == "" && req.Operation == admissionv1.Create {
+		engine, err := w.modelEngine(ctx, model)
+		if err != nil || (engine == nil && !isBuiltinEngine(model.Spec.Engine)) {
+			// The image of a missing engine can not be resolved.
+			return nil
+		}
+		if cfg, err := w.Reconciler.modelConfigForEngine(model, engine); err == nil {
+			model.Spec.Image = cfg.Image
+		}
+	}
+
+	return nil
+}
+
+func (w *ModelWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	model, ok := obj.(*kubeaiv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got a %T", obj)
+	}
+	return w.validate(ctx, model)
+}
+
+func (w *ModelWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	model, ok := newObj.(*kubeaiv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got a %T", newObj)
+	}
+	old, ok := oldObj.(*kubeaiv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got a %T", oldObj)
+	}
+	if model.DeletionTimestamp != nil {
+		// Do not block the removal of finalizers.
+		return nil, nil
+	}
+	// Do not block scaling and metadata changes of Models that became invalid
+	// because of a change of the system config.
+	oldSpec := old.Spec.DeepCopy()
+	oldSpec.Replicas = model.Spec.Replicas
+	if reflect.DeepEqual(*oldSpec, model.Spec) {
+		return nil, nil
+	}
+	return w.validate(ctx, model)
+}
+
+func (w *ModelWebhook) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *ModelWebhook) validate(ctx context.Context, model *kubeaiv1.Model) (admission.Warnings, error) {
+	r := w.Reconciler
+	specPath := field.NewPath("spec")
+	var (
+		warnings admission.Warnings
+		errs     field.ErrorList
+	)
+
+	if _, err := r.parseModelSource(model.Spec.URL); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("url"), model.Spec.URL, err.Error()))
+	}
+
+	engine, err := w.modelEngine(ctx, model)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	switch {
+	case engine != nil:
+		if err := validateModelForEngine(model, engine); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("engine"), model.Spec.Engine, err.Error()))
+		}
+	case isBuiltinEngine(model.Spec.Engine):
+		errs = append(errs, validateBuiltinEngineFeatures(model, specPath)...)
+	default:
+		warnings = append(warnings, fmt.Sprintf("ModelEngine %q does not exist, no Pods are created until it is created", model.Spec.Engine))
+	}
+
+	errs = append(errs, w.validateResourceProfile(model, specPath.Child("resourceProfile"))...)
+
+	if name := model.Spec.CacheProfile; name != "" {
+		if _, ok := r.CacheProfiles[name]; !ok {
+			errs = append(errs, field.NotFound(specPath.Child("cacheProfile"), fmt.Sprintf("%s (available cache profiles: %s)", name, availableNames(r.CacheProfiles))))
+		}
+	}
+
+	if workloadKind(model) == kubeaiv1.LeaderWorkerSetWorkloadKind && !r.leaderWorkerSetInstalled {
+		warnings = append(warnings, "The LeaderWorkerSet API is not installed in the cluster (or it was installed after KubeAI was started), no Pods are created until it is available")
+	}
+
+	// Build the Pod of the Model to catch configuration errors that only
+	// surface when the Pod is generated (i.e. invalid podTemplate or probes).
+	if len(errs) == 0 && (engine != nil || isBuiltinEngine(model.Spec.Engine)) {
+		cfg, err := r.modelConfigForEngine(model, engine)
+		if err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("image"), model.Spec.Image, err.Error()))
+		} else if _, err := r.podForModel(model, cfg); err != nil {
+			errs = append(errs, field.Invalid(specPath, "", fmt.Sprintf("generating the model server Pod: %v", err)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(kubeaiv1.GroupVersion.WithKind("Model").GroupKind(), model.Name, errs)
+	}
+	return warnings, nil
+}
+
+// modelEngine returns the ModelEngine of a Model, nil if the Model uses a
+// built-in engine or if the ModelEngine does not exist.
+func (w *ModelWebhook) modelEngine(ctx context.Context, model *kubeaiv1.Model) (*kubeaiv1.ModelEngine, error) {
+	if isBuiltinEngine(model.Spec.Engine) {
+		return nil, nil
+	}
+	engine := &kubeaiv1.ModelEngine{}
+	if err := w.Reconciler.Get(ctx, types.NamespacedName{Name: model.Spec.Engine}, engine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting model engine: %w", err)
+	}
+	return engine, nil
+}
+
+func validateBuiltinEngineFeatures(model *kubeaiv1.Model, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	supported := builtinEngineFeatures[model.Spec.Engine]
+	for i, f := range model.Spec.Features {
+		if !slices.Contains(supported, f) {
+			errs = append(errs, field.NotSupported(specPath.Child("features").Index(i), f, supported))
+		}
+	}
+	if len(model.Spec.Adapters) > 0 && model.Spec.Engine != kubeaiv1.VLLMEngine {
+		errs = append(errs, field.Forbidden(specPath.Child("adapters"), fmt.Sprintf("adapters are not supported by the %s engine", model.Spec.Engine)))
+	}
+	return errs
+}
+
+func (w *ModelWebhook) validateResourceProfile(model *kubeaiv1.Model, path *field.Path) field.ErrorList {
+	value := model.Spec.ResourceProfile
+	name, multiple, ok := strings.Cut(value, ":")
+	if !ok {
+		return field.ErrorList{field.Invalid(path, value, "should match <name>:<multiple>, example: nvidia-gpu-l4:2")}
+	}
+	var errs field.ErrorList
+	if n, err := strconv.Atoi(multiple); err != nil || n < 1 {
+		errs = append(errs, field.Invalid(path, value, fmt.Sprintf("multiple %q should be a positive integer", multiple)))
+	}
+	if _, ok := w.Reconciler.ResourceProfiles[name]; !ok {
+		errs = append(errs, field.NotFound(path, fmt.Sprintf("%s (available resource profiles: %s)", name, availableNames(w.Reconciler.ResourceProfiles))))
+	}
+	return errs
+}
+
+func availableNames[T any](m map[string]T) string {
+	if len(m) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
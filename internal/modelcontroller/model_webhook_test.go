@@ -0,0 +1,212 @@
+package modelcontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func testModelWebhook(t *testing.T, objs ...runtime.Object) *ModelWebhook {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(scheme))
+	return &ModelWebhook{
+		Reconciler: &ModelReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+			ResourceProfiles: map[string]config.ResourceProfile{
+				"cpu":       {},
+				"nvidia-l4": {ImageName: "nvidia"},
+			},
+			CacheProfiles: map[string]config.CacheProfile{
+				"efs": {SharedFilesystem: &config.CacheSharedFilesystem{StorageClassName: "efs"}},
+			},
+			ModelServers: config.ModelServers{
+				VLLM: config.ModelServer{Images: map[string]string{
+					"default": "vllm-cpu",
+					"nvidia":  "vllm-gpu",
+				}},
+				FasterWhisper: config.ModelServer{Images: map[string]string{"default": "faster-whisper"}},
+			},
+		},
+	}
+}
+
+func Test_ModelWebhook_validate(t *testing.T) {
+	engine := &v1.ModelEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-engine"},
+		Spec: v1.ModelEngineSpec{
+			Images:     map[string]string{"default": "test-image"},
+			URLSchemes: []string{"hf"},
+			Features:   []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+		},
+	}
+	w := testModelWebhook(t, engine)
+
+	validSpec := func() v1.ModelSpec {
+		return v1.ModelSpec{
+			URL:             "hf://test-org/test-model",
+			Engine:          v1.VLLMEngine,
+			Features:        []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+			ResourceProfile: "nvidia-l4:1",
+		}
+	}
+
+	cases := map[string]struct {
+		modify      func(*v1.ModelSpec)
+		wantErrs    []string
+		wantWarning string
+	}{
+		"valid": {
+			modify: func(*v1.ModelSpec) {},
+		},
+		"resource profile without multiple": {
+			modify:   func(s *v1.ModelSpec) { s.ResourceProfile = "nvidia-l4" },
+			wantErrs: []string{"spec.resourceProfile", "should match <name>:<multiple>"},
+		},
+		"resource profile with invalid multiple": {
+			modify:   func(s *v1.ModelSpec) { s.ResourceProfile = "nvidia-l4:x" },
+			wantErrs: []string{`multiple "x" should be a positive integer`},
+		},
+		"resource profile not found": {
+			modify:   func(s *v1.ModelSpec) { s.ResourceProfile = "nvidia-l40:1" },
+			wantErrs: []string{"spec.resourceProfile: Not found", "nvidia-l40 (available resource profiles: cpu, nvidia-l4)"},
+		},
+		"cache profile not found": {
+			modify:   func(s *v1.ModelSpec) { s.CacheProfile = "filestore" },
+			wantErrs: []string{"spec.cacheProfile: Not found", "filestore (available cache profiles: efs)"},
+		},
+		"speech to text with vllm": {
+			modify:   func(s *v1.ModelSpec) { s.Features = []v1.ModelFeature{v1.ModelFeatureSpeechToText} },
+			wantErrs: []string{"spec.features[0]: Unsupported value: \"SpeechToText\""},
+		},
+		"text generation with faster whisper": {
+			modify: func(s *v1.ModelSpec) {
+				s.Engine = v1.FasterWhisperEngine
+			},
+			wantErrs: []string{"spec.features[0]: Unsupported value: \"TextGeneration\""},
+		},
+		"adapters with faster whisper": {
+			modify: func(s *v1.ModelSpec) {
+				s.Engine = v1.FasterWhisperEngine
+				s.Features = []v1.ModelFeature{v1.ModelFeatureSpeechToText}
+				s.Adapters = []v1.Adapter{{Name: "a", URL: "hf://a/a"}}
+			},
+			wantErrs: []string{"spec.adapters: Forbidden: adapters are not supported by the FasterWhisper engine"},
+		},
+		"model engine": {
+			modify: func(s *v1.ModelSpec) { s.Engine = engine.Name },
+		},
+		"model engine with unsupported feature": {
+			modify: func(s *v1.ModelSpec) {
+				s.Engine = engine.Name
+				s.Features = []v1.ModelFeature{v1.ModelFeatureTextEmbedding}
+			},
+			wantErrs: []string{"spec.engine", `does not support feature "TextEmbedding"`},
+		},
+		"missing model engine": {
+			modify:      func(s *v1.ModelSpec) { s.Engine = "other-engine" },
+			wantWarning: `ModelEngine "other-engine" does not exist`,
+		},
+		"missing image": {
+			modify: func(s *v1.ModelSpec) {
+				s.Engine = v1.OLlamaEngine
+				s.URL = "ollama://test-model"
+			},
+			wantErrs: []string{"spec.image", "missing default server image"},
+		},
+		"invalid pod template": {
+			modify: func(s *v1.ModelSpec) {
+				s.PodTemplate = &v1.ModelPodTemplate{Spec: &runtime.RawExtension{Raw: []byte(`{"containers":[{"name":"server","ports":[{"containerPort":9000}]}]}`)}}
+			},
+			wantErrs: []string{"generating the model server Pod"},
+		},
+		"leader worker set not installed": {
+			modify:      func(s *v1.ModelSpec) { s.Workload.Kind = v1.LeaderWorkerSetWorkloadKind },
+			wantWarning: "The LeaderWorkerSet API is not installed",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			model := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns"}, Spec: validSpec()}
+			c.modify(&model.Spec)
+			warnings, err := w.ValidateCreate(context.Background(), model)
+			if c.wantWarning != "" {
+				require.Len(t, warnings, 1)
+				require.Contains(t, warnings[0], c.wantWarning)
+			} else {
+				require.Empty(t, warnings)
+			}
+			if len(c.wantErrs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got: %v", err)
+			for _, want := range c.wantErrs {
+				require.ErrorContains(t, err, want)
+			}
+		})
+	}
+
+	t.Run("update of replicas of an invalid model", func(t *testing.T) {
+		old := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl"}, Spec: validSpec()}
+		old.Spec.ResourceProfile = "removed:1"
+		updated := old.DeepCopy()
+		updated.Spec.Replicas = ptr.To[int32](2)
+		_, err := w.ValidateUpdate(context.Background(), old, updated)
+		require.NoError(t, err)
+
+		updated.Spec.Args = []string{"--new-arg"}
+		_, err = w.ValidateUpdate(context.Background(), old, updated)
+		require.ErrorContains(t, err, "spec.resourceProfile")
+	})
+}
+
+func Test_ModelWebhook_Default(t *testing.T) {
+	w := testModelWebhook(t)
+	w.DefaultImages = true
+
+	ctxFor := func(op admissionv1.Operation) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{Operation: op},
+		})
+	}
+	newModel := func() *v1.Model {
+		return &v1.Model{Spec: v1.ModelSpec{
+			URL:             "hf://test-org/test-model",
+			Engine:          v1.VLLMEngine,
+			ResourceProfile: "nvidia-l4:1",
+			MinReplicas:     2,
+		}}
+	}
+
+	model := newModel()
+	require.NoError(t, w.Default(ctxFor(admissionv1.Create), model))
+	require.Equal(t, ptr.To[int32](2), model.Spec.Replicas)
+	require.Equal(t, "vllm-gpu", model.Spec.Image)
+
+	model = newModel()
+	model.Spec.Replicas = ptr.To[int32](3)
+	model.Spec.Image = "custom"
+	require.NoError(t, w.Default(ctxFor(admissionv1.Create), model))
+	require.Equal(t, ptr.To[int32](3), model.Spec.Replicas)
+	require.Equal(t, "custom", model.Spec.Image)
+
+	// Existing Models are not pinned to an image on update.
+	model = newModel()
+	require.NoError(t, w.Default(ctxFor(admissionv1.Update), model))
+	require.Empty(t, model.Spec.Image)
+
+	w.DefaultImages = false
+	model = newModel()
+	require.NoError(t, w.Default(ctxFor(admissionv1.Create), model))
+	require.Empty(t, model.Spec.Image)
+}
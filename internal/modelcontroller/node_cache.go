@@ -0,0 +1,671 @@
+package modelcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// nodeCacheActionLabel is set on the Jobs that load models onto and
+	// evict models from a Node.
+	nodeCacheActionLabel = "node-cache.kubeai.org/action"
+	// nodeCacheModelLabel is the name of the Model of a node cache Job.
+	nodeCacheModelLabel = "node-cache.kubeai.org/model"
+	// nodeCacheModelUIDAnnotation is the UID of the Model of a node cache Job.
+	nodeCacheModelUIDAnnotation = "node-cache.kubeai.org/model-uid"
+
+	nodeCacheActionLoad  = "load"
+	nodeCacheActionEvict = "evict"
+
+	// nodeCacheLastUsedResolution limits the updates of the last used time
+	// of the cached models.
+	nodeCacheLastUsedResolution = 5 * time.Minute
+)
+
+// nodeCacheLoadScript loads a model into the node-local cache unless it was
+// loaded before. It is run by the load Jobs and by the init container of the
+// model server Pods, the lock serializes concurrent loads on a Node.
+// Interrupted loads are resumed by the loader.
+const nodeCacheLoadScript = `set -euo pipefail
+dir=%[1]s
+exec 9>"$dir.lock"
+flock 9
+if [ ! -f "$dir.loaded" ]; then
+  load "$1" "$dir"
+  touch "$dir.loaded"
+fi
+`
+
+// nodeCacheReportScript reports the size of a loaded model and the disk
+// usage of the cache in the termination message of a load Job.
+const nodeCacheReportScript = `size=$(du -sk "$dir" | cut -f1)
+df -Pk "$(dirname "$dir")" | awk -v size="$size" 'NR==2 {printf "{\"sizeBytes\":%.0f,\"diskCapacityBytes\":%.0f,\"diskUsedBytes\":%.0f}", size*1024, $2*1024, $3*1024}' > /dev/termination-log
+`
+
+const nodeCacheEvictScript = `set -euo pipefail
+dir=%[1]s
+exec 9>"$dir.lock"
+flock 9
+rm -rf "$dir" "$dir.loaded"
+`
+
+// NodeCacheReconciler loads the Models of nodeLocal cache profiles onto the
+// selected Nodes with Jobs and evicts them, tracking the state of every Node
+// in a NodeModelCache.
+type NodeCacheReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Namespace string
+	// ModelReconciler provides the model loader image and the model sources.
+	ModelReconciler *ModelReconciler
+}
+
+func (r *NodeCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	node := &corev1.Node{}
+	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
+		// NodeModelCaches are garbage collected with their Node.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	for _, name := range nodeLocalCacheProfiles(r.ModelReconciler.CacheProfiles) {
+		profile := r.ModelReconciler.CacheProfiles[name].NodeLocal
+		nmc := &kubeaiv1.NodeModelCache{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: nodeModelCacheName(name, node.Name)}, nmc)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting node model cache: %w", err)
+		}
+		exists := err == nil
+
+		if !labels.SelectorFromSet(profile.NodeSelector).Matches(labels.Set(node.Labels)) || node.DeletionTimestamp != nil {
+			if exists {
+				if err := r.Delete(ctx, nmc); err != nil && !apierrors.IsNotFound(err) {
+					return ctrl.Result{}, fmt.Errorf("deleting node model cache: %w", err)
+				}
+			}
+			continue
+		}
+
+		if !exists {
+			nmc = &kubeaiv1.NodeModelCache{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: r.Namespace,
+					Name:      nodeModelCacheName(name, node.Name),
+				},
+				Spec: kubeaiv1.NodeModelCacheSpec{
+					NodeName:     node.Name,
+					CacheProfile: name,
+				},
+			}
+			if err := ctrl.SetControllerReference(node, nmc, r.Scheme); err != nil {
+				return ctrl.Result{}, fmt.Errorf("setting controller reference on node model cache: %w", err)
+			}
+			if err := r.Create(ctx, nmc); err != nil {
+				return ctrl.Result{}, fmt.Errorf("creating node model cache: %w", err)
+			}
+		}
+
+		if err := r.reconcileNodeModelCache(ctx, nmc, profile); err != nil {
+			return ctrl.Result{}, fmt.Errorf("reconciling node model cache %q: %w", nmc.Name, err)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *NodeCacheReconciler) reconcileNodeModelCache(ctx context.Context, nmc *kubeaiv1.NodeModelCache, profile *config.CacheNodeLocal) error {
+	log := log.FromContext(ctx)
+	now := time.Now()
+	original := nmc.Status.DeepCopy()
+
+	modelList := &kubeaiv1.ModelList{}
+	if err := r.List(ctx, modelList, client.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("listing models: %w", err)
+	}
+	var models []kubeaiv1.Model
+	for _, m := range modelList.Items {
+		if m.Spec.CacheProfile == nmc.Spec.CacheProfile && m.DeletionTimestamp == nil {
+			models = append(models, m)
+		}
+	}
+
+	// Models with a server Pod on the Node are in use.
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(r.Namespace), client.HasLabels{kubeaiv1.PodModelLabel}); err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+	inUse := map[string]bool{}
+	for _, p := range podList.Items {
+		if p.Spec.NodeName == nmc.Spec.NodeName {
+			inUse[p.Labels[kubeaiv1.PodModelLabel]] = true
+		}
+	}
+
+	// Record the results of finished Jobs.
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(r.Namespace), client.HasLabels{nodeCacheActionLabel}); err != nil {
+		return fmt.Errorf("listing jobs: %w", err)
+	}
+	var finished []*batchv1.Job
+	running := map[string]bool{}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if !metav1.IsControlledBy(job, nmc) || job.DeletionTimestamp != nil {
+			continue
+		}
+		if !k8sutils.IsJobCompleted(job) && !k8sutils.IsJobFailed(job) {
+			running[job.Labels[nodeCacheModelLabel]] = true
+			continue
+		}
+		if err := r.recordJobResult(ctx, nmc, job); err != nil {
+			return err
+		}
+		finished = append(finished, job)
+	}
+	// Recreate the Jobs that were deleted before their result was recorded
+	// (creating a Job that already exists is a no-op).
+	for i := range nmc.Status.Models {
+		cached := &nmc.Status.Models[i]
+		if running[cached.Name] {
+			continue
+		}
+		switch cached.Phase {
+		case kubeaiv1.NodeCachedModelLoading:
+			idx := slices.IndexFunc(models, func(m kubeaiv1.Model) bool { return m.Name == cached.Name && m.UID == cached.UID })
+			if idx < 0 {
+				cached.Phase = kubeaiv1.NodeCachedModelFailed
+				continue
+			}
+			if err := r.createNodeCacheJob(ctx, nmc, profile, nodeCacheActionLoad, cached.Name, cached.UID, &models[idx]); err != nil {
+				return err
+			}
+		case kubeaiv1.NodeCachedModelEvicting:
+			if err := r.createNodeCacheJob(ctx, nmc, profile, nodeCacheActionEvict, cached.Name, cached.UID, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range nmc.Status.Models {
+		cached := &nmc.Status.Models[i]
+		if cached.Phase == kubeaiv1.NodeCachedModelLoaded && inUse[cached.Name] &&
+			(cached.LastUsedTime == nil || now.Sub(cached.LastUsedTime.Time) > nodeCacheLastUsedResolution) {
+			cached.LastUsedTime = ptr.To(metav1.NewTime(now))
+		}
+	}
+
+	plan := planNodeCache(nmc, profile, models, inUse)
+	for _, cached := range plan.forget {
+		nmc.Status.Models = slices.DeleteFunc(nmc.Status.Models, func(c kubeaiv1.NodeCachedModel) bool {
+			return c.Name == cached.Name && c.UID == cached.UID
+		})
+	}
+	for _, cached := range plan.evict {
+		log.Info("Evicting model from node", "model", cached.Name, "node", nmc.Spec.NodeName)
+		if err := r.createNodeCacheJob(ctx, nmc, profile, nodeCacheActionEvict, cached.Name, cached.UID, nil); err != nil {
+			return err
+		}
+		nmc.CachedModel(cached.Name, cached.UID).Phase = kubeaiv1.NodeCachedModelEvicting
+	}
+	for i := range plan.load {
+		m := &plan.load[i]
+		log.Info("Loading model onto node", "model", m.Name, "node", nmc.Spec.NodeName)
+		if err := r.createNodeCacheJob(ctx, nmc, profile, nodeCacheActionLoad, m.Name, m.UID, m); err != nil {
+			return err
+		}
+		if cached := nmc.CachedModel(m.Name, m.UID); cached != nil {
+			cached.Phase = kubeaiv1.NodeCachedModelLoading
+		} else {
+			nmc.Status.Models = append(nmc.Status.Models, kubeaiv1.NodeCachedModel{
+				Name:  m.Name,
+				UID:   m.UID,
+				Phase: kubeaiv1.NodeCachedModelLoading,
+			})
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(*original, nmc.Status) {
+		if err := r.Status().Update(ctx, nmc); err != nil {
+			return fmt.Errorf("updating node model cache status: %w", err)
+		}
+	}
+
+	// Finished Jobs are deleted once their result is recorded.
+	for _, job := range finished {
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting job: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordJobResult updates the status of a NodeModelCache with the result of
+// a finished load or evict Job.
+func (r *NodeCacheReconciler) recordJobResult(ctx context.Context, nmc *kubeaiv1.NodeModelCache, job *batchv1.Job) error {
+	name := job.Labels[nodeCacheModelLabel]
+	idx := -1
+	for i := range nmc.Status.Models {
+		if nmc.Status.Models[i].Name == name && string(nmc.Status.Models[i].UID) == job.Annotations[nodeCacheModelUIDAnnotation] {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil
+	}
+	cached := &nmc.Status.Models[idx]
+
+	switch job.Labels[nodeCacheActionLabel] {
+	case nodeCacheActionLoad:
+		if k8sutils.IsJobFailed(job) {
+			cached.Phase = kubeaiv1.NodeCachedModelFailed
+			return nil
+		}
+		msg, err := r.ModelReconciler.jobTerminationMessage(ctx, job, "loader")
+		if err != nil {
+			return err
+		}
+		var report cacheLoadReport
+		if msg != "" {
+			if err := json.Unmarshal([]byte(msg), &report); err != nil {
+				return fmt.Errorf("parsing report of job %q: %w", job.Name, err)
+			}
+			nmc.Status.DiskCapacityBytes = report.DiskCapacityBytes
+			nmc.Status.DiskUsedBytes = report.DiskUsedBytes
+		}
+		cached.Phase = kubeaiv1.NodeCachedModelLoaded
+		cached.SizeBytes = report.SizeBytes
+		cached.LastUsedTime = ptr.To(metav1.Now())
+	case nodeCacheActionEvict:
+		if k8sutils.IsJobFailed(job) {
+			// Keep the model, it is evicted again if needed.
+			cached.Phase = kubeaiv1.NodeCachedModelLoaded
+			return nil
+		}
+		nmc.Status.DiskUsedBytes = max(0, nmc.Status.DiskUsedBytes-cached.SizeBytes)
+		model := &kubeaiv1.Model{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: cached.Name}, model)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting model: %w", err)
+		}
+		if err == nil && model.UID == cached.UID && model.DeletionTimestamp == nil {
+			cached.Phase = kubeaiv1.NodeCachedModelEvicted
+			cached.SizeBytes = 0
+		} else {
+			nmc.Status.Models = append(nmc.Status.Models[:idx], nmc.Status.Models[idx+1:]...)
+		}
+	}
+	return nil
+}
+
+type nodeCachePlan struct {
+	load  []kubeaiv1.Model
+	evict []kubeaiv1.NodeCachedModel
+	// forget are the entries of deleted Models without files on the Node.
+	forget []kubeaiv1.NodeCachedModel
+}
+
+// planNodeCache determines the models to load onto and evict from a Node.
+//
+// Models that were deleted are evicted. When the disk usage is above the
+// eviction threshold, the least recently used models that are not in use
+// are evicted until the usage is below the threshold. Models are prefetched
+// one at a time while the usage is below the threshold. Models that are in
+// use are (re)loaded regardless of the disk usage to register them, their
+// files are already loaded by the init container of the model server Pod.
+func planNodeCache(nmc *kubeaiv1.NodeModelCache, profile *config.CacheNodeLocal, models []kubeaiv1.Model, inUse map[string]bool) nodeCachePlan {
+	var plan nodeCachePlan
+
+	current := map[string]types.UID{}
+	for _, m := range models {
+		current[m.Name] = m.UID
+	}
+
+	loading := false
+	for _, cached := range nmc.Status.Models {
+		switch cached.Phase {
+		case kubeaiv1.NodeCachedModelLoading:
+			loading = true
+		case kubeaiv1.NodeCachedModelEvicting:
+			continue
+		}
+		if current[cached.Name] == cached.UID {
+			continue
+		}
+		switch cached.Phase {
+		case kubeaiv1.NodeCachedModelEvicted:
+			plan.forget = append(plan.forget, cached)
+		case kubeaiv1.NodeCachedModelLoaded, kubeaiv1.NodeCachedModelFailed:
+			plan.evict = append(plan.evict, cached)
+		}
+	}
+
+	threshold := float64(profile.EvictionThresholdPercent) / 100
+	capacity := float64(nmc.Status.DiskCapacityBytes)
+	used := float64(nmc.Status.DiskUsedBytes)
+	for _, cached := range plan.evict {
+		used -= float64(cached.SizeBytes)
+	}
+	aboveThreshold := func() bool {
+		return capacity > 0 && used > capacity*threshold
+	}
+
+	if aboveThreshold() {
+		var candidates []kubeaiv1.NodeCachedModel
+		for _, cached := range nmc.Status.Models {
+			if cached.Phase == kubeaiv1.NodeCachedModelLoaded && current[cached.Name] == cached.UID && !inUse[cached.Name] {
+				candidates = append(candidates, cached)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return lastUsed(candidates[i]).Before(lastUsed(candidates[j]))
+		})
+		for _, cached := range candidates {
+			if !aboveThreshold() {
+				break
+			}
+			plan.evict = append(plan.evict, cached)
+			used -= float64(cached.SizeBytes)
+		}
+	}
+
+	for _, m := range models {
+		cached := nmc.CachedModel(m.Name, m.UID)
+		switch {
+		case cached == nil:
+			if inUse[m.Name] || (!loading && !aboveThreshold()) {
+				plan.load = append(plan.load, m)
+				loading = true
+			}
+		case (cached.Phase == kubeaiv1.NodeCachedModelEvicted || cached.Phase == kubeaiv1.NodeCachedModelFailed) && inUse[m.Name]:
+			plan.load = append(plan.load, m)
+		}
+	}
+
+	return plan
+}
+
+func lastUsed(cached kubeaiv1.NodeCachedModel) time.Time {
+	if cached.LastUsedTime == nil {
+		return time.Time{}
+	}
+	return cached.LastUsedTime.Time
+}
+
+func (r *NodeCacheReconciler) createNodeCacheJob(ctx context.Context, nmc *kubeaiv1.NodeModelCache, profile *config.CacheNodeLocal, action, modelName string, uid types.UID, model *kubeaiv1.Model) error {
+	dir := nodeCacheModelDir(modelName, uid)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeCacheJobName(action, modelName, nmc.Spec.NodeName),
+			Namespace: r.Namespace,
+			Labels: map[string]string{
+				nodeCacheActionLabel: action,
+				nodeCacheModelLabel:  modelName,
+			},
+			Annotations: map[string]string{
+				nodeCacheModelUIDAnnotation: string(uid),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			TTLSecondsAfterFinished: ptr.To[int32](60),
+			Parallelism:             ptr.To[int32](1),
+			Completions:             ptr.To[int32](1),
+			BackoffLimit:            ptr.To[int32](3),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+					// Bypass the scheduler, the Job has to run on this Node.
+					NodeName:    nmc.Spec.NodeName,
+					Tolerations: profile.Tolerations,
+					Containers: []corev1.Container{
+						{
+							Name:  "loader",
+							Image: r.ModelReconciler.ModelLoaders.Image,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "models", MountPath: "/models"},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{nodeCacheVolume(profile, r.Namespace)},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(nmc, job, r.Scheme); err != nil {
+		return fmt.Errorf("setting controller reference on job: %w", err)
+	}
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	switch action {
+	case nodeCacheActionLoad:
+		src, err := r.ModelReconciler.parseModelSource(model.Spec.URL)
+		if err != nil {
+			return fmt.Errorf("parsing model source: %w", err)
+		}
+		container.Command = []string{"bash", "-c", fmt.Sprintf(nodeCacheLoadScript, dir) + nodeCacheReportScript, "load-model"}
+		container.Args = []string{model.Spec.URL}
+		container.Env = append(cacheJobEnv(model.Spec.Env), r.ModelReconciler.cacheLoaderEnv(job.Name)...)
+		container.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+		job.Spec.Template.Spec.ServiceAccountName = r.ModelReconciler.ModelLoaders.ServiceAccountName
+		if limit := r.ModelReconciler.ModelLoaders.BackoffLimit; limit != nil {
+			job.Spec.BackoffLimit = limit
+		}
+		src.modelSourcePodAdditions.applyToPodSpec(&job.Spec.Template.Spec, 0)
+	case nodeCacheActionEvict:
+		container.Name = "evictor"
+		container.Command = []string{"bash", "-c", fmt.Sprintf(nodeCacheEvictScript, dir)}
+	}
+
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating %s job: %w", action, err)
+	}
+	return nil
+}
+
+// patchServerNodeLocalCache adds an init container to the model server Pod
+// that loads the model onto the Node if it is not cached yet and restricts
+// the Pod to the Nodes of the cache profile.
+func (r *ModelReconciler) patchServerNodeLocalCache(pod *corev1.Pod, m *kubeaiv1.Model, c ModelConfig) {
+	profile := c.CacheProfile.NodeLocal
+	if profile == nil {
+		return
+	}
+
+	loader := corev1.Container{
+		Name:    "model-loader",
+		Image:   r.ModelLoaders.Image,
+		Command: []string{"bash", "-c", fmt.Sprintf(nodeCacheLoadScript, nodeCacheModelDir(m.Name, m.UID)), "load-model"},
+		Args:    []string{m.Spec.URL},
+		Env:     cacheJobEnv(m.Spec.Env),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "models", MountPath: "/models"},
+		},
+	}
+	// The volumes of the source are added to the Pod for the server container.
+	if add := c.Source.modelSourcePodAdditions; add != nil {
+		loader.EnvFrom = append(loader.EnvFrom, add.envFrom...)
+		loader.Env = append(loader.Env, add.env...)
+		loader.VolumeMounts = append(loader.VolumeMounts, add.volumeMounts...)
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, loader)
+
+	if len(profile.NodeSelector) > 0 {
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = map[string]string{}
+		}
+		for k, v := range profile.NodeSelector {
+			pod.Spec.NodeSelector[k] = v
+		}
+	}
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, profile.Tolerations...)
+}
+
+// nodesWithCachedModel returns the Nodes that have the given Model loaded.
+func (r *ModelReconciler) nodesWithCachedModel(ctx context.Context, m *kubeaiv1.Model) ([]string, error) {
+	list := &kubeaiv1.NodeModelCacheList{}
+	if err := r.List(ctx, list, client.InNamespace(m.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing node model caches: %w", err)
+	}
+	var nodes []string
+	for _, nmc := range list.Items {
+		if nmc.Spec.CacheProfile != m.Spec.CacheProfile {
+			continue
+		}
+		if cached := nmc.CachedModel(m.Name, m.UID); cached != nil && cached.Phase == kubeaiv1.NodeCachedModelLoaded {
+			nodes = append(nodes, nmc.Spec.NodeName)
+		}
+	}
+	return nodes, nil
+}
+
+// reconcileNodeLocalCache reports the Nodes that the Model is loaded onto.
+// Model server Pods are not held back, they load the model themselves on
+// Nodes that do not have it cached yet.
+func (r *ModelReconciler) reconcileNodeLocalCache(ctx context.Context, model *kubeaiv1.Model) error {
+	nodes, err := r.nodesWithCachedModel(ctx, model)
+	if err != nil {
+		return err
+	}
+	model.Status.Cache.Loaded = len(nodes) > 0
+	if model.Status.Cache.Loaded {
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionTrue, kubeaiv1.ModelReasonLoaded,
+			fmt.Sprintf("Loaded onto %d Nodes", len(nodes)))
+	} else {
+		setCondition(model, kubeaiv1.ModelConditionCacheLoaded, metav1.ConditionFalse, kubeaiv1.ModelReasonLoading,
+			"Loading the model onto the Nodes of the cache profile")
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("nodecache").
+		For(&corev1.Node{}).
+		Watches(&kubeaiv1.NodeModelCache{}, handler.EnqueueRequestsFromMapFunc(nodeForNodeModelCache)).
+		Watches(&kubeaiv1.Model{}, handler.EnqueueRequestsFromMapFunc(r.nodesForModel)).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(nodeForModelPod)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.nodeForJob)).
+		Complete(r)
+}
+
+func nodeForNodeModelCache(_ context.Context, obj client.Object) []reconcile.Request {
+	nmc, ok := obj.(*kubeaiv1.NodeModelCache)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nmc.Spec.NodeName}}}
+}
+
+func nodeForModelPod(_ context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" || k8sutils.GetLabel(pod, kubeaiv1.PodModelLabel) == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+}
+
+func (r *NodeCacheReconciler) nodeForJob(ctx context.Context, obj client.Object) []reconcile.Request {
+	ref := metav1.GetControllerOf(obj)
+	if ref == nil || ref.Kind != "NodeModelCache" {
+		return nil
+	}
+	nmc := &kubeaiv1.NodeModelCache{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: ref.Name}, nmc); err != nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nmc.Spec.NodeName}}}
+}
+
+func (r *NodeCacheReconciler) nodesForModel(ctx context.Context, obj client.Object) []reconcile.Request {
+	model, ok := obj.(*kubeaiv1.Model)
+	if !ok || model.Spec.CacheProfile == "" {
+		return nil
+	}
+	list := &kubeaiv1.NodeModelCacheList{}
+	if err := r.List(ctx, list, client.InNamespace(model.Namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list node model caches")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, nmc := range list.Items {
+		if nmc.Spec.CacheProfile == model.Spec.CacheProfile {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: nmc.Spec.NodeName}})
+		}
+	}
+	return reqs
+}
+
+// modelsForNodeModelCache maps a NodeModelCache to the Models cached on its
+// Node to update their cache status.
+func modelsForNodeModelCache(_ context.Context, obj client.Object) []reconcile.Request {
+	nmc, ok := obj.(*kubeaiv1.NodeModelCache)
+	if !ok {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, cached := range nmc.Status.Models {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: nmc.Namespace, Name: cached.Name}})
+	}
+	return reqs
+}
+
+func nodeLocalCacheProfiles(profiles map[string]config.CacheProfile) []string {
+	var names []string
+	for name, p := range profiles {
+		if p.NodeLocal != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func nodeModelCacheName(profile, node string) string {
+	return profile + "." + node
+}
+
+func nodeCacheJobName(action, modelName, node string) string {
+	return fmt.Sprintf("%s-node-cache-%s-%s", action, modelName, k8sutils.StringHash(node))
+}
+
+// nodeCacheModelDir is the directory of a model in the node cache volume
+// mounted at /models. It matches modelCacheDir.
+func nodeCacheModelDir(name string, uid types.UID) string {
+	return fmt.Sprintf("/models/%s-%s", name, uid)
+}
+
+// nodeCacheVolume is the host directory of the cache, separate for every
+// KubeAI installation.
+func nodeCacheVolume(profile *config.CacheNodeLocal, namespace string) corev1.Volume {
+	return corev1.Volume{
+		Name: "models",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: path.Join(profile.HostPath, namespace),
+				Type: ptr.To(corev1.HostPathDirectoryOrCreate),
+			},
+		},
+	}
+}
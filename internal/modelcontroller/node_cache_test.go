@@ -0,0 +1,124 @@
+package modelcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func Test_planNodeCache(t *testing.T) {
+	now := time.Now()
+	profile := &config.CacheNodeLocal{HostPath: "/mnt/models", EvictionThresholdPercent: 80}
+	model := func(name string) v1.Model {
+		return v1.Model{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid")}}
+	}
+	cached := func(name string, phase v1.NodeCachedModelPhase, size int64, lastUsedAgo time.Duration) v1.NodeCachedModel {
+		return v1.NodeCachedModel{
+			Name:         name,
+			UID:          types.UID(name + "-uid"),
+			Phase:        phase,
+			SizeBytes:    size,
+			LastUsedTime: ptr.To(metav1.NewTime(now.Add(-lastUsedAgo))),
+		}
+	}
+	names := func(plan nodeCachePlan) (load, evict, forget []string) {
+		for _, m := range plan.load {
+			load = append(load, m.Name)
+		}
+		for _, c := range plan.evict {
+			evict = append(evict, c.Name)
+		}
+		for _, c := range plan.forget {
+			forget = append(forget, c.Name)
+		}
+		return
+	}
+
+	cases := map[string]struct {
+		status     v1.NodeModelCacheStatus
+		models     []v1.Model
+		inUse      map[string]bool
+		wantLoad   []string
+		wantEvict  []string
+		wantForget []string
+	}{
+		"prefetch one model at a time": {
+			models:   []v1.Model{model("a"), model("b")},
+			wantLoad: []string{"a"},
+		},
+		"wait for the model that is loading": {
+			status: v1.NodeModelCacheStatus{Models: []v1.NodeCachedModel{
+				cached("a", v1.NodeCachedModelLoading, 0, 0),
+			}},
+			models: []v1.Model{model("a"), model("b")},
+		},
+		"load models in use while loading": {
+			status: v1.NodeModelCacheStatus{Models: []v1.NodeCachedModel{
+				cached("a", v1.NodeCachedModelLoading, 0, 0),
+			}},
+			models:   []v1.Model{model("a"), model("b")},
+			inUse:    map[string]bool{"b": true},
+			wantLoad: []string{"b"},
+		},
+		"evict deleted models": {
+			status: v1.NodeModelCacheStatus{Models: []v1.NodeCachedModel{
+				cached("a", v1.NodeCachedModelLoaded, 10, 0),
+				cached("b", v1.NodeCachedModelEvicted, 0, 0),
+				cached("c", v1.NodeCachedModelLoading, 0, 0),
+			}},
+			wantEvict:  []string{"a"},
+			wantForget: []string{"b"},
+		},
+		"evict least recently used models above the threshold": {
+			status: v1.NodeModelCacheStatus{
+				DiskCapacityBytes: 100,
+				DiskUsedBytes:     95,
+				Models: []v1.NodeCachedModel{
+					cached("recent", v1.NodeCachedModelLoaded, 10, time.Minute),
+					cached("old", v1.NodeCachedModelLoaded, 10, time.Hour),
+					cached("older", v1.NodeCachedModelLoaded, 10, 2*time.Hour),
+					cached("oldest-in-use", v1.NodeCachedModelLoaded, 10, 3*time.Hour),
+				},
+			},
+			models:    []v1.Model{model("recent"), model("old"), model("older"), model("oldest-in-use"), model("new")},
+			inUse:     map[string]bool{"oldest-in-use": true},
+			wantEvict: []string{"older", "old"},
+			wantLoad:  []string{"new"},
+		},
+		"do not prefetch above the threshold": {
+			status: v1.NodeModelCacheStatus{
+				DiskCapacityBytes: 100,
+				DiskUsedBytes:     90,
+				Models: []v1.NodeCachedModel{
+					cached("a", v1.NodeCachedModelLoaded, 5, time.Minute),
+				},
+			},
+			models:    []v1.Model{model("a"), model("b")},
+			wantEvict: []string{"a"},
+		},
+		"reload evicted models when in use": {
+			status: v1.NodeModelCacheStatus{Models: []v1.NodeCachedModel{
+				cached("a", v1.NodeCachedModelEvicted, 0, time.Hour),
+				cached("b", v1.NodeCachedModelEvicted, 0, time.Hour),
+			}},
+			models:   []v1.Model{model("a"), model("b")},
+			inUse:    map[string]bool{"b": true},
+			wantLoad: []string{"b"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			nmc := &v1.NodeModelCache{Status: c.status}
+			load, evict, forget := names(planNodeCache(nmc, profile, c.models, c.inUse))
+			require.Equal(t, c.wantLoad, load, "load")
+			require.Equal(t, c.wantEvict, evict, "evict")
+			require.Equal(t, c.wantForget, forget, "forget")
+		})
+	}
+}
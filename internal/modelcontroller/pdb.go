@@ -0,0 +1,97 @@
+package modelcontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// getPodDisruptionBudgetName returns the name of the PodDisruptionBudget for a model
+func getPodDisruptionBudgetName(model *kubeaiv1.Model) string {
+	return fmt.Sprintf("model-%s", model.Name)
+}
+
+// podDisruptionBudgetSpec returns the spec of the PodDisruptionBudget for the
+// server Pods of a model. By default, a single Pod can be evicted at a time.
+func podDisruptionBudgetSpec(model *kubeaiv1.Model) policyv1.PodDisruptionBudgetSpec {
+	spec := policyv1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{kubeaiv1.PodModelLabel: model.Name},
+		},
+	}
+	if minAvailable := model.Spec.Disruption.MinAvailable; minAvailable != nil {
+		spec.MinAvailable = ptr.To(*minAvailable)
+	} else {
+		spec.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+	}
+	return spec
+}
+
+// reconcilePodDisruptionBudget ensures that the PodDisruptionBudget for the
+// server Pods of a model exists and is up to date, or that it is deleted
+// when it is disabled.
+func (r *ModelReconciler) reconcilePodDisruptionBudget(ctx context.Context, model *kubeaiv1.Model) error {
+	log := log.FromContext(ctx)
+	pdbName := getPodDisruptionBudgetName(model)
+
+	existing := &policyv1.PodDisruptionBudget{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: pdbName}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("getting pod disruption budget: %w", err)
+	}
+	exists := err == nil
+
+	if model.Spec.Disruption.PodDisruptionBudgetDisabled {
+		if exists {
+			if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("deleting pod disruption budget: %w", err)
+			}
+			log.Info("Deleted PodDisruptionBudget", "podDisruptionBudgetName", pdbName)
+		}
+		return nil
+	}
+
+	expectedSpec := podDisruptionBudgetSpec(model)
+	if !exists {
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pdbName,
+				Namespace: model.Namespace,
+				Labels:    labelsForModel(model),
+			},
+			Spec: expectedSpec,
+		}
+		if err := ctrl.SetControllerReference(model, pdb, r.Scheme); err != nil {
+			return fmt.Errorf("setting controller reference on pod disruption budget: %w", err)
+		}
+		if err := r.Create(ctx, pdb); err != nil {
+			return fmt.Errorf("creating pod disruption budget: %w", err)
+		}
+		log.Info("Created PodDisruptionBudget", "podDisruptionBudgetName", pdbName)
+		return nil
+	}
+
+	if !reflect.DeepEqual(existing.Spec.Selector, expectedSpec.Selector) ||
+		!reflect.DeepEqual(existing.Spec.MinAvailable, expectedSpec.MinAvailable) ||
+		!reflect.DeepEqual(existing.Spec.MaxUnavailable, expectedSpec.MaxUnavailable) {
+		existing.Spec.Selector = expectedSpec.Selector
+		existing.Spec.MinAvailable = expectedSpec.MinAvailable
+		existing.Spec.MaxUnavailable = expectedSpec.MaxUnavailable
+		if err := r.Update(ctx, existing); err != nil {
+			return fmt.Errorf("updating pod disruption budget: %w", err)
+		}
+		log.Info("Updated PodDisruptionBudget", "podDisruptionBudgetName", pdbName)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_podDisruptionBudgetSpec(t *testing.T) {
+	model := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl"}}
+
+	spec := podDisruptionBudgetSpec(model)
+	require.Equal(t, map[string]string{v1.PodModelLabel: "test-mdl"}, spec.Selector.MatchLabels)
+	require.Nil(t, spec.MinAvailable)
+	require.Equal(t, 1, spec.MaxUnavailable.IntValue())
+
+	minAvailable := intstr.FromString("50%")
+	model.Spec.Disruption.MinAvailable = &minAvailable
+	spec = podDisruptionBudgetSpec(model)
+	require.Equal(t, "50%", spec.MinAvailable.String())
+	require.Nil(t, spec.MaxUnavailable)
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/k8sutils"
@@ -13,6 +15,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,194 +23,383 @@ import (
 
 // calculatePodPlan calculates the Pod plan for the given Model.
 // It assumes the list of Pods represents an accurate snapshot of the current state.
-// It returns a Pod plan that contains Pods to create and delete.
-// If a rollout is required, it will return a Pod plan that:
-// - Adds a surge Pod
-// - Recreates any out-of-date Pod that is not Ready immediately
-// - Waits for all Pods to be Ready before recreating any out-of-date Pods that are Ready
+// It returns a Pod plan that contains Pods to create, delete and patch according
+// to the rollout strategy of the Model and updates the rollout status of the Model.
 func (r *ModelReconciler) calculatePodPlan(allPods *corev1.PodList, model *kubeaiv1.Model, modelConfig ModelConfig) (*podPlan, error) {
-	var podForModel *corev1.Pod
-
-	switch model.Spec.Engine {
-	case kubeaiv1.OLlamaEngine:
-		podForModel = r.oLlamaPodForModel(model, modelConfig)
-	case kubeaiv1.FasterWhisperEngine:
-		podForModel = r.fasterWhisperPodForModel(model, modelConfig)
-	case kubeaiv1.InfinityEngine:
-		podForModel = r.infinityPodForModel(model, modelConfig)
-	default:
-		podForModel = r.vLLMPodForModel(model, modelConfig)
-	}
-
-	if err := applyJSONPatchToPod(r.ModelServerPods.JSONPatches, podForModel); err != nil {
+	podForModel, err := r.podForModel(model, modelConfig)
+	if err != nil {
 		return nil, err
 	}
+	expectedHash := k8sutils.GetLabel(podForModel, kubeaiv1.PodHashLabel)
 
-	expectedHash := k8sutils.PodHash(podForModel.Spec)
-	podForModel.GenerateName = fmt.Sprintf("model-%s-%s-", model.Name, expectedHash)
-	k8sutils.SetLabel(podForModel, kubeaiv1.PodHashLabel, expectedHash)
+	sortPodsByDeletionOrder(allPods.Items, expectedHash)
+
+	plan := &podPlan{
+		model:     model,
+		remainder: make(map[string]*corev1.Pod),
+	}
+	for i := range allPods.Items {
+		p := &allPods.Items[i]
+		plan.remainder[podKey(p)] = p
+		if k8sutils.GetLabel(p, kubeaiv1.PodHashLabel) != expectedHash {
+			plan.outOfDate++
+		}
+	}
 
-	var (
-		readyAll  int
-		outOfDate []corev1.Pod
-		remainder = make(map[string]*corev1.Pod)
-	)
+	now := time.Now()
+	updateRolloutPhase(model, expectedHash, plan.outOfDate, now)
 
-	podKey := func(p corev1.Pod) string {
-		return p.Namespace + "/" + p.Name
+	switch model.Spec.Rollout.Strategy {
+	case kubeaiv1.RecreateRolloutStrategy:
+		r.planRecreate(plan, allPods.Items, podForModel, expectedHash)
+	case kubeaiv1.BlueGreenRolloutStrategy, kubeaiv1.CanaryRolloutStrategy:
+		r.planProgressiveRollout(plan, allPods.Items, podForModel, expectedHash, now)
+	default:
+		r.planRollingUpdate(plan, allPods.Items, podForModel, expectedHash)
 	}
 
-	sortPodsByDeletionOrder(allPods.Items, expectedHash)
+	setRolloutReplicas(model, allPods.Items)
 
-	for _, p := range allPods.Items {
-		remainder[podKey(p)] = &p
+	plan.toRemain = make([]*corev1.Pod, 0, len(plan.remainder))
+	for _, pod := range plan.remainder {
+		plan.toRemain = append(plan.toRemain, pod)
+	}
 
-		upToDate := k8sutils.GetLabel(&p, kubeaiv1.PodHashLabel) == expectedHash
+	return plan, nil
+}
 
-		if k8sutils.PodIsReady(&p) {
-			readyAll++
-		}
+// planRollingUpdate plans a rolling update. If a rollout is required, it will return a Pod plan that:
+// - Adds surge Pods
+// - Recreates any out-of-date Pod that is not Ready immediately
+// - Recreates out-of-date Pods that are Ready as long as no more than maxUnavailable Pods are unavailable
+func (r *ModelReconciler) planRollingUpdate(plan *podPlan, pods []corev1.Pod, podForModel *corev1.Pod, expectedHash string) {
+	maxSurge, maxUnavailable := r.rollingUpdateParams(plan.model)
 
-		if !upToDate {
+	var outOfDate []corev1.Pod
+	for _, p := range pods {
+		if k8sutils.GetLabel(&p, kubeaiv1.PodHashLabel) != expectedHash {
 			outOfDate = append(outOfDate, p)
 		}
 	}
 
-	var (
-		details  []string
-		toCreate []*corev1.Pod
-		toDelete []*corev1.Pod
-	)
-	appendToDelete := func(p corev1.Pod) {
-		delete(remainder, podKey(p))
-		toDelete = append(toDelete, &p)
-	}
-
-	var desiredReplicas int32
 	// NOTE: Replicas could be nil if autoscaling is disabled.
-	if model.Spec.Replicas != nil {
-		desiredReplicas = *model.Spec.Replicas
-	}
+	replicas := ptr.Deref(plan.model.Spec.Replicas, 0)
+	desiredReplicas := replicas
 	if len(outOfDate) > 0 {
-		desiredReplicas += r.ModelRollouts.Surge
+		desiredReplicas += maxSurge
 	}
-	observedReplicas := int32(len(allPods.Items))
-	replicaDiff := observedReplicas - desiredReplicas
-	replicaDiffAbs := int32(math.Abs(float64(replicaDiff)))
+	plan.scale(pods, desiredReplicas, podForModel)
 
-	switch {
-	case replicaDiff == 0:
-		// At correct scale.
-	case replicaDiff < 0:
-		// Create Pods.
-		details = append(details, fmt.Sprintf("Creating %d Pods", replicaDiffAbs))
-		for i := int32(0); i < replicaDiffAbs; i++ {
-			toCreate = append(toCreate, podForModel.DeepCopy())
-		}
-	case replicaDiff > 0:
-		// Delete Pods.
-		details = append(details, fmt.Sprintf("Deleting %d Pods", replicaDiffAbs))
-		toDeleteCount := replicaDiffAbs
-		for _, pod := range allPods.Items {
-			if toDeleteCount == 0 {
-				break
-			}
-			appendToDelete(pod)
-			toDeleteCount--
+	var readyAll int
+	for _, p := range plan.remainder {
+		if k8sutils.PodIsReady(p) {
+			readyAll++
 		}
 	}
+	// The number of Ready Pods that can be deleted without dropping below
+	// the minimum number of available replicas.
+	deletable := readyAll - int(replicas-maxUnavailable)
 
 	var recreated int
 	for _, pod := range outOfDate {
+		if !plan.remains(pod) {
+			continue
+		}
 		if !k8sutils.PodIsReady(&pod) {
-			details = append(details, fmt.Sprintf("Out-of-date Pod %q is not ready, immediately recreating", pod.Name))
-			appendToDelete(pod)
-			// Avoid recreating the surge Pod when rollout is complete.
-			if recreated < len(outOfDate)-int(r.ModelRollouts.Surge) {
-				toCreate = append(toCreate, podForModel.DeepCopy())
+			plan.details = append(plan.details, fmt.Sprintf("Out-of-date Pod %q is not ready, immediately recreating", pod.Name))
+			plan.delete(pod)
+			// Avoid recreating the surge Pods when rollout is complete.
+			if recreated < len(outOfDate)-int(maxSurge) {
+				plan.create(podForModel.DeepCopy())
 				recreated++
 			}
 			continue
 		}
-		if readyAll == int(desiredReplicas) {
-			details = append(details, fmt.Sprintf("All Pods ready, recreating out-of-date Pod %q", pod.Name))
-			appendToDelete(pod)
-			// Avoid recreating the surge Pod when rollout is complete.
-			if recreated < len(outOfDate)-int(r.ModelRollouts.Surge) {
-				toCreate = append(toCreate, podForModel.DeepCopy())
+		if deletable > 0 {
+			plan.details = append(plan.details, fmt.Sprintf("Enough Pods ready, recreating out-of-date Pod %q", pod.Name))
+			plan.delete(pod)
+			deletable--
+			// Avoid recreating the surge Pods when rollout is complete.
+			if recreated < len(outOfDate)-int(maxSurge) {
+				plan.create(podForModel.DeepCopy())
 				recreated++
 			}
-			break
 		}
 	}
+}
+
+// rollingUpdateParams returns the maxSurge and maxUnavailable of a rolling update.
+func (r *ModelReconciler) rollingUpdateParams(model *kubeaiv1.Model) (int32, int32) {
+	maxSurge := ptr.Deref(model.Spec.Rollout.RollingUpdate.MaxSurge, r.ModelRollouts.Surge)
+	maxUnavailable := ptr.Deref(model.Spec.Rollout.RollingUpdate.MaxUnavailable, 0)
+	if maxSurge == 0 && maxUnavailable == 0 {
+		// Avoid blocking the rollout.
+		maxUnavailable = 1
+	}
+	return maxSurge, maxUnavailable
+}
 
-	toRemain := make([]*corev1.Pod, 0, len(remainder))
-	for _, pod := range remainder {
-		toRemain = append(toRemain, pod)
+// planRecreate deletes all out-of-date Pods and waits for them to be gone
+// before creating up-to-date Pods.
+func (r *ModelReconciler) planRecreate(plan *podPlan, pods []corev1.Pod, podForModel *corev1.Pod, expectedHash string) {
+	if plan.outOfDate > 0 {
+		for _, pod := range pods {
+			if k8sutils.GetLabel(&pod, kubeaiv1.PodHashLabel) == expectedHash || pod.DeletionTimestamp != nil {
+				continue
+			}
+			plan.details = append(plan.details, fmt.Sprintf("Deleting out-of-date Pod %q before creating up-to-date Pods", pod.Name))
+			plan.delete(pod)
+		}
+		return
 	}
 
-	return &podPlan{
-		model:    model,
-		toCreate: toCreate,
-		toDelete: toDelete,
-		toRemain: toRemain,
-		details:  details,
-	}, nil
+	plan.scale(pods, ptr.Deref(plan.model.Spec.Replicas, 0), podForModel)
+}
+
+// podForModel returns the server Pod for the given Model, labeled with
+// the hash of its spec.
+func (r *ModelReconciler) podForModel(model *kubeaiv1.Model, modelConfig ModelConfig) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+
+	switch model.Spec.Engine {
+	case kubeaiv1.OLlamaEngine:
+		pod = r.oLlamaPodForModel(model, modelConfig)
+	case kubeaiv1.FasterWhisperEngine:
+		pod = r.fasterWhisperPodForModel(model, modelConfig)
+	case kubeaiv1.InfinityEngine:
+		pod = r.infinityPodForModel(model, modelConfig)
+	case kubeaiv1.SGLangEngine:
+		pod = r.sgLangPodForModel(model, modelConfig)
+	case kubeaiv1.TGIEngine:
+		pod = r.tgiPodForModel(model, modelConfig)
+	case kubeaiv1.LlamaCPPEngine:
+		pod = r.llamaCPPPodForModel(model, modelConfig)
+	case kubeaiv1.VLLMEngine:
+		pod = r.vLLMPodForModel(model, modelConfig)
+	default:
+		if modelConfig.Engine == nil {
+			return nil, fmt.Errorf("unknown engine: %q", model.Spec.Engine)
+		}
+		var err error
+		if pod, err = r.modelEnginePodForModel(model, modelConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	r.patchServerOCIPuller(pod, model, modelConfig)
+	r.patchServerNodeLocalCache(pod, model, modelConfig)
+	r.patchServerCacheVerifier(pod, model, modelConfig)
+	if err := r.applyProbesToPod(model, modelConfig, pod); err != nil {
+		return nil, err
+	}
+	if err := applyJSONPatchToPod(r.ModelServerPods.JSONPatches, pod); err != nil {
+		return nil, err
+	}
+	if err := applyPodTemplateToPod(model.Spec.PodTemplate, pod); err != nil {
+		return nil, err
+	}
+
+	hash := k8sutils.PodHash(pod.Spec)
+	if tmpl := model.Spec.PodTemplate; tmpl != nil && (len(tmpl.Metadata.Labels) > 0 || len(tmpl.Metadata.Annotations) > 0) {
+		// Include the template metadata so that changes to it result in a rollout.
+		// It is only included when set to keep the hash of existing Pods stable.
+		hash = k8sutils.StringHash(hash + fmt.Sprint(tmpl.Metadata.Labels, tmpl.Metadata.Annotations))
+	}
+	pod.GenerateName = fmt.Sprintf("model-%s-%s-", model.Name, hash)
+	k8sutils.SetLabel(pod, kubeaiv1.PodHashLabel, hash)
+
+	return pod, nil
 }
 
 type podPlan struct {
 	model    *kubeaiv1.Model
 	toCreate []*corev1.Pod
 	toDelete []*corev1.Pod
+	toPatch  []podPatch
 	toRemain []*corev1.Pod
 	details  []string
+	// outOfDate is the number of existing Pods that do not match the
+	// current Pod spec of the Model.
+	outOfDate int
+	// requeueAfter is set when the plan depends on the passing of time,
+	// for example during the analysis of a rollout.
+	requeueAfter time.Duration
+
+	// remainder contains the existing Pods that are not deleted.
+	remainder map[string]*corev1.Pod
+}
+
+// podPatch sets the annotations of a Pod.
+type podPatch struct {
+	pod *corev1.Pod
+	// annotations to set, nil values remove the annotation.
+	annotations map[string]*string
+}
+
+func podKey(p *corev1.Pod) string {
+	return p.Namespace + "/" + p.Name
 }
 
 func (pp *podPlan) containsActions() bool {
-	return len(pp.toCreate) > 0 || len(pp.toDelete) > 0
+	return len(pp.toCreate) > 0 || len(pp.toDelete) > 0 || len(pp.toPatch) > 0
+}
+
+func (pp *podPlan) create(pod *corev1.Pod) {
+	pp.toCreate = append(pp.toCreate, pod)
+}
+
+func (pp *podPlan) delete(pod corev1.Pod) {
+	delete(pp.remainder, podKey(&pod))
+	pp.toDelete = append(pp.toDelete, &pod)
+}
+
+// remains returns true if the given Pod is not deleted by the plan.
+func (pp *podPlan) remains(pod corev1.Pod) bool {
+	_, ok := pp.remainder[podKey(&pod)]
+	return ok
+}
+
+// scale creates or deletes Pods so that the given Pods match the desired
+// number of replicas. Pods are deleted in the given order.
+func (pp *podPlan) scale(pods []corev1.Pod, desiredReplicas int32, podToCreate *corev1.Pod) {
+	observedReplicas := int32(len(pods))
+	replicaDiff := observedReplicas - desiredReplicas
+	replicaDiffAbs := int32(math.Abs(float64(replicaDiff)))
+
+	switch {
+	case replicaDiff == 0:
+		// At correct scale.
+	case replicaDiff < 0:
+		// Create Pods.
+		pp.details = append(pp.details, fmt.Sprintf("Creating %d Pods", replicaDiffAbs))
+		for i := int32(0); i < replicaDiffAbs; i++ {
+			pp.create(podToCreate.DeepCopy())
+		}
+	case replicaDiff > 0:
+		// Delete Pods.
+		pp.details = append(pp.details, fmt.Sprintf("Deleting %d Pods", replicaDiffAbs))
+		toDeleteCount := replicaDiffAbs
+		for _, pod := range pods {
+			if toDeleteCount == 0 {
+				break
+			}
+			pp.delete(pod)
+			toDeleteCount--
+		}
+	}
+}
+
+// setTrafficPercent patches the traffic percent annotation of the given Pod
+// if it differs and the Pod is not deleted by the plan.
+func (pp *podPlan) setTrafficPercent(pod corev1.Pod, percent *int32) {
+	if !pp.remains(pod) {
+		return
+	}
+	var value *string
+	if percent != nil {
+		value = ptr.To(strconv.Itoa(int(*percent)))
+	}
+	pp.patchAnnotation(pod, kubeaiv1.ModelPodTrafficPercentAnnotation, value)
+}
+
+// patchAnnotation patches the annotation of the given Pod if it differs.
+// A nil value removes the annotation.
+func (pp *podPlan) patchAnnotation(pod corev1.Pod, key string, value *string) {
+	current, ok := pod.Annotations[key]
+	if value == nil && !ok || value != nil && ok && current == *value {
+		return
+	}
+	for i := range pp.toPatch {
+		if podKey(pp.toPatch[i].pod) == podKey(&pod) {
+			pp.toPatch[i].annotations[key] = value
+			return
+		}
+	}
+	pp.toPatch = append(pp.toPatch, podPatch{pod: &pod, annotations: map[string]*string{key: value}})
 }
 
-// execute returns true if a Pod was created or deleted.
-func (pp *podPlan) execute(ctx context.Context, client client.Client, scheme *runtime.Scheme) (bool, error) {
+// execute applies the plan. The Pod creations and deletions are recorded
+// as expectations of the Model before they are requested, requests that do
+// not result in a watch event are observed immediately.
+func (pp *podPlan) execute(ctx context.Context, c client.Client, scheme *runtime.Scheme, expectations *podExpectations) error {
 	log := log.FromContext(ctx)
 
 	detailsCSV := strings.Join(pp.details, ", ")
 	log.Info("Executing Pod plan", "modelName", pp.model.Name, "details", detailsCSV)
 
-	var changed bool
+	modelKey := pp.model.Namespace + "/" + pp.model.Name
+	deletions := make([]string, 0, len(pp.toDelete))
+	for _, pod := range pp.toDelete {
+		deletions = append(deletions, podKey(pod))
+	}
+	expectations.expect(modelKey, len(pp.toCreate), deletions)
+	// skipped lowers the expectations for requests that were not sent.
+	skipped := func(toDelete []*corev1.Pod, creations int) {
+		for _, pod := range toDelete {
+			expectations.deletionObserved(modelKey, podKey(pod))
+		}
+		for range creations {
+			expectations.creationObserved(modelKey)
+		}
+	}
 
 	// Delete before create to avoid unnecessary Node scale-ups.
-	for _, pod := range pp.toDelete {
-		if err := client.Delete(ctx, &corev1.Pod{
+	for i, pod := range pp.toDelete {
+		if err := c.Delete(ctx, &corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: pod.Namespace,
 				Name:      pod.Name,
 			},
 		}); err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Info("Pod already deleted", "podName", pod.Name)
+				expectations.deletionObserved(modelKey, podKey(pod))
+			} else {
+				skipped(pp.toDelete[i:], len(pp.toCreate))
+				return fmt.Errorf("deleting pod: %w", err)
+			}
+		}
+	}
+
+	for _, p := range pp.toPatch {
+		pod := p.pod.DeepCopy()
+		for key, value := range p.annotations {
+			if value == nil {
+				delete(pod.Annotations, key)
+				continue
+			}
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[key] = *value
+		}
+		if err := c.Patch(ctx, pod, client.MergeFrom(p.pod)); err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Info("Pod already deleted", "podName", pod.Name)
 			} else {
-				return changed, fmt.Errorf("deleting pod: %w", err)
+				skipped(nil, len(pp.toCreate))
+				return fmt.Errorf("patching pod: %w", err)
 			}
 		}
-		changed = true
 	}
 
-	for _, pod := range pp.toCreate {
+	for i, pod := range pp.toCreate {
 		if err := ctrl.SetControllerReference(pp.model, pod, scheme); err != nil {
-			return changed, fmt.Errorf("setting controller reference: %w", err)
+			skipped(nil, len(pp.toCreate)-i)
+			return fmt.Errorf("setting controller reference: %w", err)
 		}
-		if err := client.Create(ctx, pod, k8sutils.DefaultCreateOptions()); err != nil {
+		if err := c.Create(ctx, pod, k8sutils.DefaultCreateOptions()); err != nil {
 			if apierrors.IsAlreadyExists(err) {
 				log.Info("Pod already exists", "podName", pod.Name)
+				expectations.creationObserved(modelKey)
 			} else {
-				return changed, fmt.Errorf("creating pod: %w", err)
+				skipped(nil, len(pp.toCreate)-i)
+				return fmt.Errorf("creating pod: %w", err)
 			}
 		}
-		changed = true
 	}
 
-	return changed, nil
+	return nil
 }
 
 // sortPodsByDeletionOrder ensures Pods that are to be deleted/recreated
@@ -221,6 +413,13 @@ func sortPodsByDeletionOrder(pods []corev1.Pod, expectedHash string) {
 			return !iReady
 		}
 
+		// Draining Pods should be deleted first to avoid draining other Pods.
+		iDraining := podIsDraining(&pods[i])
+		jDraining := podIsDraining(&pods[j])
+		if iDraining != jDraining {
+			return iDraining
+		}
+
 		// Unscheduled Pods should be deleted first.
 		iScheduled := k8sutils.PodIsScheduled(&pods[i])
 		jScheduled := k8sutils.PodIsScheduled(&pods[j])
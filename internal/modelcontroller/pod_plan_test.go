@@ -1,6 +1,7 @@
 package modelcontroller
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/config"
 	"github.com/substratusai/kubeai/internal/k8sutils"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"golang.org/x/exp/rand"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -173,6 +175,332 @@ func Test_calculatePodPlan(t *testing.T) {
 	}
 }
 
+func Test_calculatePodPlanRolloutStrategies(t *testing.T) {
+	baseModel := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mdl",
+			Namespace: "test-ns",
+		},
+		Spec: v1.ModelSpec{
+			Engine:   v1.VLLMEngine,
+			Replicas: ptr.To[int32](2),
+			URL:      "hf://test-repo/test-model",
+		},
+	}
+
+	r := &ModelReconciler{
+		ModelRollouts: config.ModelRollouts{
+			Surge: 1,
+		},
+	}
+	src, err := r.parseModelSource(baseModel.Spec.URL)
+	require.NoError(t, err)
+	modelConfig := ModelConfig{Source: src}
+	expectedHash := k8sutils.PodHash(r.vLLMPodForModel(baseModel, modelConfig).Spec)
+
+	testPod := func(name, hash string, ready bool, traffic string) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "test-ns",
+				Labels:      map[string]string{v1.PodHashLabel: hash},
+				Annotations: map[string]string{v1.ModelPodPortAnnotation: "8000"},
+			},
+			Status: corev1.PodStatus{PodIP: "10.0.0." + name[len(name)-1:]},
+		}
+		if hash != expectedHash {
+			p.Name = "old-" + name
+		}
+		if traffic != "" {
+			p.Annotations[v1.ModelPodTrafficPercentAnnotation] = traffic
+		}
+		if ready {
+			p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return p
+	}
+	crashing := func(p corev1.Pod) corev1.Pod {
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{Name: "server", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+		}
+		return p
+	}
+	rolloutStatus := func(phase v1.ModelRolloutPhase, started time.Duration) *v1.ModelStatusRollout {
+		st := &v1.ModelStatusRollout{
+			TargetHash: expectedHash,
+			Phase:      phase,
+			StartTime:  metav1.NewTime(time.Now().Add(-started)),
+		}
+		if phase == v1.ModelRolloutPhaseAnalyzing {
+			st.AnalysisStartTime = ptr.To(st.StartTime)
+		}
+		return st
+	}
+	percent := func(p int32) *int32 { return &p }
+
+	cases := []struct {
+		name           string
+		rollout        v1.ModelRollout
+		status         *v1.ModelStatusRollout
+		responses      map[string]loadreport.EndpointResponses
+		pods           []corev1.Pod
+		wantNCreations int
+		wantCreatedPod func(t *testing.T, pod *corev1.Pod)
+		wantDeletions  []string
+		wantPatches    map[string]*int32
+		wantPhase      v1.ModelRolloutPhase
+	}{
+		{
+			name:    "recreate deletes out-of-date pods first",
+			rollout: v1.ModelRollout{Strategy: v1.RecreateRolloutStrategy},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", false, ""),
+			},
+			wantDeletions: []string{"old-2", "old-1"},
+			wantPhase:     v1.ModelRolloutPhaseProgressing,
+		},
+		{
+			name:    "recreate creates pods once out-of-date pods are gone",
+			rollout: v1.ModelRollout{Strategy: v1.RecreateRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseProgressing, time.Minute),
+			pods: []corev1.Pod{
+				testPod("1", expectedHash, false, ""),
+			},
+			wantNCreations: 1,
+			wantPhase:      v1.ModelRolloutPhaseSucceeded,
+		},
+		{
+			name: "rolling update with max unavailable",
+			rollout: v1.ModelRollout{RollingUpdate: v1.ModelRolloutRollingUpdate{
+				MaxSurge:       ptr.To[int32](0),
+				MaxUnavailable: ptr.To[int32](2),
+			}},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+			},
+			wantNCreations: 2,
+			wantDeletions:  []string{"old-1", "old-2"},
+			wantPhase:      v1.ModelRolloutPhaseProgressing,
+		},
+		{
+			name:    "blue green creates up-to-date pods without traffic",
+			rollout: v1.ModelRollout{Strategy: v1.BlueGreenRolloutStrategy},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+			},
+			wantNCreations: 2,
+			wantCreatedPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, "0", pod.Annotations[v1.ModelPodTrafficPercentAnnotation])
+			},
+			wantPhase: v1.ModelRolloutPhaseProgressing,
+		},
+		{
+			name:    "blue green switches traffic when up-to-date pods are ready",
+			rollout: v1.ModelRollout{Strategy: v1.BlueGreenRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseProgressing, time.Minute),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "0"),
+				testPod("4", expectedHash, true, "0"),
+			},
+			wantPatches: map[string]*int32{"3": percent(100), "4": percent(100)},
+			wantPhase:   v1.ModelRolloutPhaseAnalyzing,
+		},
+		{
+			name:    "blue green deletes out-of-date pods after analysis",
+			rollout: v1.ModelRollout{Strategy: v1.BlueGreenRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Hour),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "100"),
+				testPod("4", expectedHash, true, "100"),
+			},
+			wantDeletions: []string{"old-1", "old-2"},
+			wantPatches:   map[string]*int32{"3": nil, "4": nil},
+			wantPhase:     v1.ModelRolloutPhasePromoting,
+		},
+		{
+			name:    "blue green rolls back when pods do not become ready",
+			rollout: v1.ModelRollout{Strategy: v1.BlueGreenRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseProgressing, time.Hour),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "0"),
+				testPod("4", expectedHash, false, "0"),
+			},
+			wantDeletions: []string{"4", "3"},
+			wantPhase:     v1.ModelRolloutPhaseRolledBack,
+		},
+		{
+			name:    "rolled back rollout scales out-of-date pods",
+			rollout: v1.ModelRollout{Strategy: v1.BlueGreenRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseRolledBack, time.Hour),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+			},
+			wantNCreations: 1,
+			wantCreatedPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, "old-hash", pod.Labels[v1.PodHashLabel])
+			},
+			wantPhase: v1.ModelRolloutPhaseRolledBack,
+		},
+		{
+			name: "canary creates canary pods with a fraction of the traffic",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy, Canary: v1.ModelRolloutCanary{
+				Replicas:       1,
+				TrafficPercent: 20,
+			}},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+			},
+			wantNCreations: 1,
+			wantCreatedPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, "20", pod.Annotations[v1.ModelPodTrafficPercentAnnotation])
+			},
+			wantPhase: v1.ModelRolloutPhaseProgressing,
+		},
+		{
+			name:    "canary rolls back crash looping canary",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseProgressing, time.Minute),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				crashing(testPod("3", expectedHash, false, "10")),
+			},
+			wantDeletions: []string{"3"},
+			wantPhase:     v1.ModelRolloutPhaseRolledBack,
+		},
+		{
+			name:    "canary rolls back when exceeding the error rate",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy, MaxErrorRatePercent: ptr.To[int32](5)},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Minute),
+			responses: map[string]loadreport.EndpointResponses{
+				"10.0.0.1:8000": {Total: 100},
+				"10.0.0.3:8000": {Total: 20, Errors: 2},
+			},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "10"),
+			},
+			wantDeletions: []string{"3"},
+			wantPhase:     v1.ModelRolloutPhaseRolledBack,
+		},
+		{
+			name:    "canary keeps analyzing below the error rate",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy, MaxErrorRatePercent: ptr.To[int32](5)},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Minute),
+			responses: map[string]loadreport.EndpointResponses{
+				"10.0.0.3:8000": {Total: 100, Errors: 2},
+			},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "10"),
+			},
+			wantPhase: v1.ModelRolloutPhaseAnalyzing,
+		},
+		{
+			name:    "canary rolls out remaining pods after analysis",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Hour),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "10"),
+			},
+			wantNCreations: 1,
+			wantDeletions:  []string{"old-1"},
+			wantPatches:    map[string]*int32{"3": nil},
+			wantPhase:      v1.ModelRolloutPhasePromoting,
+		},
+		{
+			// E.g. a leader that did not receive the load reports.
+			name:    "canary is not promoted without reported responses",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy, MaxErrorRatePercent: ptr.To[int32](5)},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Hour),
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "10"),
+			},
+			wantPhase: v1.ModelRolloutPhaseAnalyzing,
+		},
+		{
+			name:    "canary is promoted with enough reported responses",
+			rollout: v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy, MaxErrorRatePercent: ptr.To[int32](5)},
+			status:  rolloutStatus(v1.ModelRolloutPhaseAnalyzing, time.Hour),
+			responses: map[string]loadreport.EndpointResponses{
+				"10.0.0.3:8000": {Total: 100, Errors: 2},
+			},
+			pods: []corev1.Pod{
+				testPod("1", "old-hash", true, ""),
+				testPod("2", "old-hash", true, ""),
+				testPod("3", expectedHash, true, "10"),
+			},
+			wantNCreations: 1,
+			wantDeletions:  []string{"old-1"},
+			wantPatches:    map[string]*int32{"3": nil},
+			wantPhase:      v1.ModelRolloutPhasePromoting,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			model := baseModel.DeepCopy()
+			model.Spec.Rollout = c.rollout
+			model.Status.Rollout = c.status
+			if model.Status.Rollout != nil {
+				model.Status.Rollout.Strategy = rolloutStrategy(model)
+			}
+
+			r.LoadReports = loadreport.NewAggregator(time.Minute)
+			r.LoadReports.Add(loadreport.Report{Replica: "kubeai-0", Models: map[string]loadreport.ModelLoad{
+				model.Name: {Responses: c.responses},
+			}})
+
+			plan, err := r.calculatePodPlan(&corev1.PodList{Items: c.pods}, model, modelConfig)
+			require.NoError(t, err)
+			detailsCSV := strings.Join(plan.details, ", ")
+
+			require.Lenf(t, plan.toCreate, c.wantNCreations, "Unexpected creation count, details: %v", detailsCSV)
+			if c.wantCreatedPod != nil {
+				for _, p := range plan.toCreate {
+					c.wantCreatedPod(t, p)
+				}
+			}
+			var deletionNames []string
+			for _, p := range plan.toDelete {
+				deletionNames = append(deletionNames, p.Name)
+			}
+			require.Equalf(t, c.wantDeletions, deletionNames, "Unexpected deletions, details: %v", detailsCSV)
+			patches := map[string]*int32{}
+			for _, p := range plan.toPatch {
+				var percent *int32
+				if v := p.annotations[v1.ModelPodTrafficPercentAnnotation]; v != nil {
+					n, err := strconv.Atoi(*v)
+					require.NoError(t, err)
+					percent = ptr.To(int32(n))
+				}
+				patches[p.pod.Name] = percent
+			}
+			if c.wantPatches == nil {
+				c.wantPatches = map[string]*int32{}
+			}
+			require.Equal(t, c.wantPatches, patches)
+			require.Equal(t, c.wantPhase, model.Status.Rollout.Phase, model.Status.Rollout.Message)
+		})
+	}
+}
+
 func Test_sortPodsByDeletionOrder(t *testing.T) {
 	cases := []struct {
 		name string
@@ -209,6 +537,34 @@ func Test_sortPodsByDeletionOrder(t *testing.T) {
 				"expected-hash-pod",
 			},
 		},
+		{
+			name: "draining comparison",
+			pods: []corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "serving-pod",
+						Labels: map[string]string{
+							v1.PodHashLabel: "old-hash",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "draining-pod",
+						Labels: map[string]string{
+							v1.PodHashLabel: testNewHash,
+						},
+						Annotations: map[string]string{
+							v1.ModelPodDrainingAnnotation: "2024-01-01T00:00:00Z",
+						},
+					},
+				},
+			},
+			want: []string{
+				"draining-pod",
+				"serving-pod",
+			},
+		},
 		{
 			name: "ready comparison",
 			pods: []corev1.Pod{
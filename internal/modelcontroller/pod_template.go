@@ -0,0 +1,127 @@
+package modelcontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// reservedPodLabels and reservedPodAnnotations are set by KubeAI and can not
+// be overridden by the Pod template of a Model.
+var (
+	reservedPodLabels = []string{
+		kubeaiv1.PodModelLabel,
+		kubeaiv1.ParkedPodModelLabel,
+		kubeaiv1.PodHashLabel,
+	}
+	reservedPodAnnotations = []string{
+		kubeaiv1.ModelPodIPAnnotation,
+		kubeaiv1.ModelPodPortAnnotation,
+		kubeaiv1.ModelPodTrafficPercentAnnotation,
+		kubeaiv1.ModelPodDrainingAnnotation,
+	}
+)
+
+// applyPodTemplateToPod merges the Pod template of a Model onto the given Pod.
+// The spec of the template is applied as a strategic merge patch.
+func applyPodTemplateToPod(tmpl *kubeaiv1.ModelPodTemplate, pod *corev1.Pod) error {
+	if tmpl == nil {
+		return nil
+	}
+
+	for k, v := range tmpl.Metadata.Labels {
+		if isReservedPodLabel(k) {
+			return fmt.Errorf("pod template: label %q is reserved", k)
+		}
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[k] = v
+	}
+	for k, v := range tmpl.Metadata.Annotations {
+		if isReservedPodAnnotation(k) {
+			return fmt.Errorf("pod template: annotation %q is reserved", k)
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[k] = v
+	}
+
+	if tmpl.Spec == nil || len(tmpl.Spec.Raw) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(pod.Spec)
+	if err != nil {
+		return fmt.Errorf("pod template: marshal pod spec: %w", err)
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, tmpl.Spec.Raw, corev1.PodSpec{})
+	if err != nil {
+		return fmt.Errorf("pod template: merging spec: %w", err)
+	}
+	// Decode strictly to catch misspelled fields in the template.
+	spec := corev1.PodSpec{}
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return fmt.Errorf("pod template: decoding merged spec: %w", err)
+	}
+
+	if err := validatePodTemplateSpec(pod.Spec, spec); err != nil {
+		return fmt.Errorf("pod template: %w", err)
+	}
+	pod.Spec = spec
+
+	return nil
+}
+
+// validatePodTemplateSpec ensures that the merged Pod spec still contains the
+// server container with the ports that KubeAI relies on.
+func validatePodTemplateSpec(original, merged corev1.PodSpec) error {
+	originalServer := findContainer(original.Containers, serverContainerName)
+	if originalServer == nil {
+		return nil
+	}
+	mergedServer := findContainer(merged.Containers, serverContainerName)
+	if mergedServer == nil {
+		return fmt.Errorf("the %q container can not be removed", serverContainerName)
+	}
+	if !reflect.DeepEqual(originalServer.Ports, mergedServer.Ports) {
+		return fmt.Errorf("the ports of the %q container can not be changed", serverContainerName)
+	}
+	return nil
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func isReservedPodLabel(key string) bool {
+	for _, reserved := range reservedPodLabels {
+		if key == reserved {
+			return true
+		}
+	}
+	return strings.HasPrefix(key, kubeaiv1.PodAdapterLabelPrefix)
+}
+
+func isReservedPodAnnotation(key string) bool {
+	for _, reserved := range reservedPodAnnotations {
+		if key == reserved {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_applyPodTemplateToPod(t *testing.T) {
+	basePod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{v1.PodModelLabel: "test-mdl"},
+				Annotations: map[string]string{v1.ModelPodPortAnnotation: "8000"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  serverContainerName,
+					Image: "vllm",
+					Ports: []corev1.ContainerPort{{ContainerPort: 8000, Name: "http"}},
+					ReadinessProbe: &corev1.Probe{
+						PeriodSeconds:    10,
+						FailureThreshold: 3,
+					},
+					VolumeMounts: []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}},
+				}},
+				Volumes: []corev1.Volume{{Name: "dshm"}},
+			},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		tmpl      *v1.ModelPodTemplate
+		wantErr   string
+		assertPod func(t *testing.T, pod *corev1.Pod)
+	}{
+		{
+			name: "no template",
+			assertPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, basePod(), pod)
+			},
+		},
+		{
+			name: "metadata",
+			tmpl: &v1.ModelPodTemplate{Metadata: v1.ModelPodTemplateMetadata{
+				Labels:      map[string]string{"team": "a"},
+				Annotations: map[string]string{"example.com/owner": "b"},
+			}},
+			assertPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, map[string]string{v1.PodModelLabel: "test-mdl", "team": "a"}, pod.Labels)
+				require.Equal(t, map[string]string{v1.ModelPodPortAnnotation: "8000", "example.com/owner": "b"}, pod.Annotations)
+			},
+		},
+		{
+			name: "spec is merged by name",
+			tmpl: &v1.ModelPodTemplate{Spec: &runtime.RawExtension{Raw: []byte(`{
+				"nodeSelector": {"pool": "a100"},
+				"tolerations": [{"key": "gpu", "operator": "Exists"}],
+				"volumes": [{"name": "extra", "emptyDir": {}}],
+				"initContainers": [{"name": "init", "image": "busybox"}],
+				"containers": [
+					{"name": "server", "readinessProbe": {"periodSeconds": 30}, "volumeMounts": [{"name": "extra", "mountPath": "/extra"}]},
+					{"name": "sidecar", "image": "proxy"}
+				]
+			}`)}},
+			assertPod: func(t *testing.T, pod *corev1.Pod) {
+				require.Equal(t, map[string]string{"pool": "a100"}, pod.Spec.NodeSelector)
+				require.Len(t, pod.Spec.Tolerations, 1)
+				require.ElementsMatch(t, []string{"dshm", "extra"}, []string{pod.Spec.Volumes[0].Name, pod.Spec.Volumes[1].Name})
+				require.Len(t, pod.Spec.InitContainers, 1)
+				require.Len(t, pod.Spec.Containers, 2)
+				server := findContainer(pod.Spec.Containers, serverContainerName)
+				require.Equal(t, "vllm", server.Image)
+				require.Equal(t, int32(30), server.ReadinessProbe.PeriodSeconds)
+				require.Equal(t, int32(3), server.ReadinessProbe.FailureThreshold)
+				require.Len(t, server.VolumeMounts, 2)
+			},
+		},
+		{
+			name: "reserved label",
+			tmpl: &v1.ModelPodTemplate{Metadata: v1.ModelPodTemplateMetadata{
+				Labels: map[string]string{v1.PodHashLabel: "abc"},
+			}},
+			wantErr: `label "pod-hash" is reserved`,
+		},
+		{
+			name: "reserved annotation",
+			tmpl: &v1.ModelPodTemplate{Metadata: v1.ModelPodTemplateMetadata{
+				Annotations: map[string]string{v1.ModelPodPortAnnotation: "9000"},
+			}},
+			wantErr: `annotation "model-pod-port" is reserved`,
+		},
+		{
+			name: "server container removed",
+			tmpl: &v1.ModelPodTemplate{Spec: &runtime.RawExtension{Raw: []byte(`{
+				"containers": [{"name": "server", "$patch": "delete"}]
+			}`)}},
+			wantErr: `the "server" container can not be removed`,
+		},
+		{
+			name: "server ports changed",
+			tmpl: &v1.ModelPodTemplate{Spec: &runtime.RawExtension{Raw: []byte(`{
+				"containers": [{"name": "server", "ports": [{"containerPort": 9000}]}]
+			}`)}},
+			wantErr: `the ports of the "server" container can not be changed`,
+		},
+		{
+			name: "unknown field",
+			tmpl: &v1.ModelPodTemplate{Spec: &runtime.RawExtension{Raw: []byte(`{
+				"nodeSelectors": {"pool": "a100"}
+			}`)}},
+			wantErr: `unknown field "nodeSelectors"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pod := basePod()
+			err := applyPodTemplateToPod(c.tmpl, pod)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			c.assertPod(t, pod)
+		})
+	}
+}
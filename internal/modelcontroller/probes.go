@@ -0,0 +1,182 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+// startupProgressCheckInterval is the interval at which the progress-aware
+// startup probe checks the health endpoint and the loading progress.
+const startupProgressCheckInterval = 2 * time.Second
+
+// applyProbesToPod overrides the engine default probes of the server container
+// with the probes configured for the engine (in the system config or the
+// ModelEngine) and then with the probes of the Model.
+func (r *ModelReconciler) applyProbesToPod(model *kubeaiv1.Model, c ModelConfig, pod *corev1.Pod) error {
+	server := findContainer(pod.Spec.Containers, serverContainerName)
+	if server == nil {
+		return nil
+	}
+
+	engineProbes := r.modelServerConfig(model.Spec.Engine).Probes
+	if c.Engine != nil {
+		engineProbes = c.Engine.Spec.Probes
+	}
+	probes := mergeModelProbes(engineProbes, model.Spec.Probes)
+	applyProbeSettings(server.StartupProbe, probes.Startup)
+	applyProbeSettings(server.ReadinessProbe, probes.Readiness)
+	applyProbeSettings(server.LivenessProbe, probes.Liveness)
+
+	// The progress is tracked with a bash script, images without bash
+	// (i.e. distroless images) keep the startup probe of the engine.
+	bashAvailable := r.modelServerConfig(model.Spec.Engine).BashAvailable
+	if c.Engine != nil {
+		bashAvailable = c.Engine.Spec.BashAvailable
+	}
+	if stall := ptr.Deref(probes.StartupStallTimeoutSeconds, 0); stall > 0 && bashAvailable {
+		if err := trackStartupProgress(server, stall); err != nil {
+			return fmt.Errorf("probes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeModelProbes returns the engine probes overridden by the set fields of
+// the Model probes.
+func mergeModelProbes(engine kubeaiv1.ModelProbes, model *kubeaiv1.ModelProbes) kubeaiv1.ModelProbes {
+	if model == nil {
+		return engine
+	}
+	return kubeaiv1.ModelProbes{
+		Startup:                    mergeProbeSettings(engine.Startup, model.Startup),
+		Readiness:                  mergeProbeSettings(engine.Readiness, model.Readiness),
+		Liveness:                   mergeProbeSettings(engine.Liveness, model.Liveness),
+		StartupStallTimeoutSeconds: firstSet(model.StartupStallTimeoutSeconds, engine.StartupStallTimeoutSeconds),
+	}
+}
+
+func mergeProbeSettings(base, override *kubeaiv1.ProbeSettings) *kubeaiv1.ProbeSettings {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	return &kubeaiv1.ProbeSettings{
+		InitialDelaySeconds: firstSet(override.InitialDelaySeconds, base.InitialDelaySeconds),
+		PeriodSeconds:       firstSet(override.PeriodSeconds, base.PeriodSeconds),
+		TimeoutSeconds:      firstSet(override.TimeoutSeconds, base.TimeoutSeconds),
+		FailureThreshold:    firstSet(override.FailureThreshold, base.FailureThreshold),
+		SuccessThreshold:    firstSet(override.SuccessThreshold, base.SuccessThreshold),
+	}
+}
+
+func applyProbeSettings(probe *corev1.Probe, settings *kubeaiv1.ProbeSettings) {
+	if probe == nil || settings == nil {
+		return
+	}
+	probe.InitialDelaySeconds = ptr.Deref(settings.InitialDelaySeconds, probe.InitialDelaySeconds)
+	probe.PeriodSeconds = ptr.Deref(settings.PeriodSeconds, probe.PeriodSeconds)
+	probe.TimeoutSeconds = ptr.Deref(settings.TimeoutSeconds, probe.TimeoutSeconds)
+	probe.FailureThreshold = ptr.Deref(settings.FailureThreshold, probe.FailureThreshold)
+	probe.SuccessThreshold = ptr.Deref(settings.SuccessThreshold, probe.SuccessThreshold)
+}
+
+// trackStartupProgress replaces the HTTP startup probe of the server container
+// with a single long-running exec probe that polls the health endpoint and
+// fails as soon as no loading progress was observed for the stall timeout.
+// The maximum startup time of the original probe is kept as the timeout of the
+// exec probe. The server image has to contain bash.
+func trackStartupProgress(server *corev1.Container, stallTimeoutSeconds int32) error {
+	probe := server.StartupProbe
+	if probe == nil || probe.HTTPGet == nil {
+		return fmt.Errorf("startupStallTimeoutSeconds requires an HTTP startup probe")
+	}
+	port, err := resolveContainerPort(server, probe.HTTPGet.Port)
+	if err != nil {
+		return err
+	}
+
+	maxStartupSeconds := probe.FailureThreshold * max(probe.PeriodSeconds, 1)
+	server.StartupProbe = &corev1.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      maxStartupSeconds,
+		FailureThreshold:    1,
+		SuccessThreshold:    1,
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{
+					"bash", "-c",
+					startupProgressProbeScript(port, probe.HTTPGet.Path, stallTimeoutSeconds),
+				},
+			},
+		},
+	}
+	return nil
+}
+
+func resolveContainerPort(container *corev1.Container, port intstr.IntOrString) (int32, error) {
+	if port.Type == intstr.Int {
+		return port.IntVal, nil
+	}
+	for _, p := range container.Ports {
+		if p.Name == port.StrVal {
+			return p.ContainerPort, nil
+		}
+	}
+	return 0, fmt.Errorf("port %q not found in container %q", port.StrVal, container.Name)
+}
+
+// startupProgressProbeScript returns a bash script that succeeds once the health
+// endpoint responds with 200. While the server is starting, the script sums the
+// bytes read (including from the network) by all other processes of the container
+// and fails when the sum did not change for the stall timeout, i.e. when the
+// download or loading of the model is stuck.
+// Only bash builtins are used (besides sleep) to not depend on tools like curl
+// being part of the engine image.
+func startupProgressProbeScript(port int32, path string, stallTimeoutSeconds int32) string {
+	return fmt.Sprintf(`
+last=-1
+since=$SECONDS
+while true; do
+  if exec 3<>/dev/tcp/127.0.0.1/%d; then
+    printf 'GET %s HTTP/1.0\r\nHost: localhost\r\n\r\n' >&3
+    read -r -t 5 _ code _ <&3
+    exec 3<&-
+    [ "$code" = "200" ] && exit 0
+  fi 2>/dev/null
+  progress=0
+  for f in /proc/[0-9]*/io; do
+    pid=${f#/proc/}
+    [ "${pid%%/io}" = "$$" ] && continue
+    while read -r key value; do
+      case "$key" in rchar:|read_bytes:) progress=$((progress + value)) ;; esac
+    done < "$f"
+  done 2>/dev/null
+  if [ "$progress" != "$last" ]; then
+    last=$progress
+    since=$SECONDS
+  elif [ $((SECONDS - since)) -ge %d ]; then
+    echo "No model loading progress for %d seconds"
+    exit 1
+  fi
+  sleep %d
+done
+`, port, path, stallTimeoutSeconds, stallTimeoutSeconds, int(startupProgressCheckInterval.Seconds()))
+}
+
+func firstSet[T any](vals ...*T) *T {
+	for _, v := range vals {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func Test_applyProbesToPod(t *testing.T) {
+	httpProbe := func(period, failures int32) *corev1.Probe {
+		return &corev1.Probe{
+			PeriodSeconds:    period,
+			TimeoutSeconds:   2,
+			FailureThreshold: failures,
+			SuccessThreshold: 1,
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")},
+			},
+		}
+	}
+	basePod := func() *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:           serverContainerName,
+					Ports:          []corev1.ContainerPort{{ContainerPort: 8000, Name: "http"}},
+					StartupProbe:   httpProbe(2, 5400),
+					ReadinessProbe: httpProbe(10, 3),
+					LivenessProbe:  httpProbe(30, 3),
+				}},
+			},
+		}
+	}
+
+	cases := []struct {
+		name         string
+		engineProbes v1.ModelProbes
+		modelProbes  *v1.ModelProbes
+		assertServer func(t *testing.T, server *corev1.Container)
+	}{
+		{
+			name: "defaults",
+			assertServer: func(t *testing.T, server *corev1.Container) {
+				require.Equal(t, basePod().Spec.Containers[0], *server)
+			},
+		},
+		{
+			name: "engine settings",
+			engineProbes: v1.ModelProbes{
+				Startup: &v1.ProbeSettings{FailureThreshold: ptr.To[int32](900)},
+			},
+			assertServer: func(t *testing.T, server *corev1.Container) {
+				require.Equal(t, int32(900), server.StartupProbe.FailureThreshold)
+				require.Equal(t, int32(2), server.StartupProbe.PeriodSeconds)
+			},
+		},
+		{
+			name: "model settings override engine settings",
+			engineProbes: v1.ModelProbes{
+				Startup:  &v1.ProbeSettings{FailureThreshold: ptr.To[int32](900), PeriodSeconds: ptr.To[int32](4)},
+				Liveness: &v1.ProbeSettings{TimeoutSeconds: ptr.To[int32](5)},
+			},
+			modelProbes: &v1.ModelProbes{
+				Startup:   &v1.ProbeSettings{FailureThreshold: ptr.To[int32](100)},
+				Readiness: &v1.ProbeSettings{InitialDelaySeconds: ptr.To[int32](7)},
+			},
+			assertServer: func(t *testing.T, server *corev1.Container) {
+				require.Equal(t, int32(100), server.StartupProbe.FailureThreshold)
+				require.Equal(t, int32(4), server.StartupProbe.PeriodSeconds)
+				require.Equal(t, int32(7), server.ReadinessProbe.InitialDelaySeconds)
+				require.Equal(t, int32(5), server.LivenessProbe.TimeoutSeconds)
+			},
+		},
+		{
+			name:         "startup progress tracking",
+			engineProbes: v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](600)},
+			modelProbes: &v1.ModelProbes{
+				Startup: &v1.ProbeSettings{FailureThreshold: ptr.To[int32](1800)},
+			},
+			assertServer: func(t *testing.T, server *corev1.Container) {
+				probe := server.StartupProbe
+				require.Nil(t, probe.HTTPGet)
+				require.NotNil(t, probe.Exec)
+				require.Equal(t, []string{"bash", "-c", startupProgressProbeScript(8000, "/health", 600)}, probe.Exec.Command)
+				require.Contains(t, probe.Exec.Command[2], "/dev/tcp/127.0.0.1/8000")
+				require.Equal(t, int32(3600), probe.TimeoutSeconds)
+				require.Equal(t, int32(1), probe.FailureThreshold)
+				require.Equal(t, httpProbe(10, 3), server.ReadinessProbe)
+			},
+		},
+		{
+			name:         "startup progress tracking disabled by model",
+			engineProbes: v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](600)},
+			modelProbes:  &v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](0)},
+			assertServer: func(t *testing.T, server *corev1.Container) {
+				require.Equal(t, httpProbe(2, 5400), server.StartupProbe)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &ModelReconciler{}
+			r.ModelServers = config.ModelServers{VLLM: config.ModelServer{Probes: c.engineProbes, BashAvailable: true}}
+			model := &v1.Model{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-mdl"},
+				Spec:       v1.ModelSpec{Engine: v1.VLLMEngine, Probes: c.modelProbes},
+			}
+			pod := basePod()
+			require.NoError(t, r.applyProbesToPod(model, ModelConfig{}, pod))
+			c.assertServer(t, findContainer(pod.Spec.Containers, serverContainerName))
+		})
+	}
+
+	t.Run("startup progress tracking requires bash", func(t *testing.T) {
+		r := &ModelReconciler{}
+		r.ModelServers = config.ModelServers{VLLM: config.ModelServer{
+			Probes: v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](600)},
+		}}
+		model := &v1.Model{Spec: v1.ModelSpec{Engine: v1.VLLMEngine}}
+		pod := basePod()
+		require.NoError(t, r.applyProbesToPod(model, ModelConfig{}, pod))
+		require.Equal(t, httpProbe(2, 5400), findContainer(pod.Spec.Containers, serverContainerName).StartupProbe,
+			"the startup probe of engines without bash should be kept")
+
+		engine := &v1.ModelEngine{Spec: v1.ModelEngineSpec{
+			Probes:        v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](600)},
+			BashAvailable: true,
+		}}
+		pod = basePod()
+		require.NoError(t, r.applyProbesToPod(model, ModelConfig{Engine: engine}, pod))
+		require.NotNil(t, findContainer(pod.Spec.Containers, serverContainerName).StartupProbe.Exec,
+			"ModelEngines can declare bash")
+	})
+
+	t.Run("startup progress tracking requires an http startup probe", func(t *testing.T) {
+		r := &ModelReconciler{}
+		r.ModelServers.OLlama.BashAvailable = true
+		model := &v1.Model{Spec: v1.ModelSpec{
+			Engine: v1.OLlamaEngine,
+			Probes: &v1.ModelProbes{StartupStallTimeoutSeconds: ptr.To[int32](60)},
+		}}
+		pod := basePod()
+		pod.Spec.Containers[0].StartupProbe.HTTPGet = nil
+		pod.Spec.Containers[0].StartupProbe.Exec = &corev1.ExecAction{Command: []string{"true"}}
+		require.ErrorContains(t, r.applyProbesToPod(model, ModelConfig{}, pod), "requires an HTTP startup probe")
+	})
+}
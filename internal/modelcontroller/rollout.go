@@ -0,0 +1,349 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultRolloutProgressDeadline = 600 * time.Second
+	defaultRolloutAnalysis         = 300 * time.Second
+	defaultCanaryTrafficPercent    = 10
+	// minRolloutAnalysisRequests is the number of responses that the
+	// up-to-date Pods need to serve before the error rate is evaluated.
+	minRolloutAnalysisRequests = 10
+	// rolloutAnalysisInterval is how often the error rate is evaluated
+	// during the analysis period of a rollout.
+	rolloutAnalysisInterval = 15 * time.Second
+)
+
+// updateRolloutPhase starts a new rollout in the status of the Model when
+// out-of-date Pods exist and marks the current rollout as succeeded when all
+// Pods are up to date.
+func updateRolloutPhase(model *kubeaiv1.Model, expectedHash string, outOfDate int, now time.Time) {
+	rollout := model.Status.Rollout
+	if outOfDate == 0 {
+		if rollout != nil && rollout.TargetHash == expectedHash &&
+			rollout.Phase != kubeaiv1.ModelRolloutPhaseSucceeded && rollout.Phase != kubeaiv1.ModelRolloutPhaseRolledBack {
+			rollout.Phase = kubeaiv1.ModelRolloutPhaseSucceeded
+			rollout.Message = ""
+			if isProgressiveRollout(rollout.Strategy) {
+				rollout.TrafficPercent = 100
+			}
+		}
+		return
+	}
+	if rollout == nil || rollout.TargetHash != expectedHash {
+		model.Status.Rollout = &kubeaiv1.ModelStatusRollout{
+			Strategy:   rolloutStrategy(model),
+			TargetHash: expectedHash,
+			Phase:      kubeaiv1.ModelRolloutPhaseProgressing,
+			StartTime:  metav1.NewTime(now),
+		}
+	}
+}
+
+// setRolloutReplicas counts the up-to-date Pods of the current rollout.
+func setRolloutReplicas(model *kubeaiv1.Model, pods []corev1.Pod) {
+	rollout := model.Status.Rollout
+	if rollout == nil {
+		return
+	}
+	rollout.UpdatedReplicas, rollout.ReadyUpdatedReplicas = 0, 0
+	for i := range pods {
+		if k8sutils.GetLabel(&pods[i], kubeaiv1.PodHashLabel) != rollout.TargetHash {
+			continue
+		}
+		rollout.UpdatedReplicas++
+		if k8sutils.PodIsReady(&pods[i]) {
+			rollout.ReadyUpdatedReplicas++
+		}
+	}
+}
+
+func rolloutStrategy(model *kubeaiv1.Model) kubeaiv1.ModelRolloutStrategy {
+	if model.Spec.Rollout.Strategy == "" {
+		return kubeaiv1.RollingUpdateRolloutStrategy
+	}
+	return model.Spec.Rollout.Strategy
+}
+
+func isProgressiveRollout(strategy kubeaiv1.ModelRolloutStrategy) bool {
+	return strategy == kubeaiv1.BlueGreenRolloutStrategy || strategy == kubeaiv1.CanaryRolloutStrategy
+}
+
+// planProgressiveRollout plans a BlueGreen or Canary rollout:
+// - Up-to-date Pods are created next to the out-of-date Pods and are annotated
+// with the percentage of traffic they receive.
+// - Once they are ready, they are analyzed for the analysis period.
+// - The rollout is rolled back if the up-to-date Pods fail, do not become ready
+// before the progress deadline or exceed the error rate threshold.
+// - After the analysis, a BlueGreen rollout deletes the out-of-date Pods and
+// a Canary rollout continues with a rolling update.
+func (r *ModelReconciler) planProgressiveRollout(plan *podPlan, pods []corev1.Pod, podForModel *corev1.Pod, expectedHash string, now time.Time) {
+	model := plan.model
+	replicas := ptr.Deref(model.Spec.Replicas, 0)
+
+	var oldPods, newPods []corev1.Pod
+	for _, p := range pods {
+		if k8sutils.GetLabel(&p, kubeaiv1.PodHashLabel) == expectedHash {
+			newPods = append(newPods, p)
+		} else {
+			oldPods = append(oldPods, p)
+		}
+	}
+
+	if len(oldPods) == 0 {
+		for _, p := range newPods {
+			plan.setTrafficPercent(p, nil)
+		}
+		plan.scale(pods, replicas, podForModel)
+		return
+	}
+	// Out-of-date Pods always receive the remainder of the traffic.
+	for _, p := range oldPods {
+		plan.setTrafficPercent(p, nil)
+	}
+
+	rollout := model.Status.Rollout
+	if replicas == 0 {
+		// Nothing to analyze, the next scale up creates up-to-date Pods.
+		plan.scale(pods, 0, podForModel)
+		return
+	}
+
+	switch rollout.Phase {
+	case kubeaiv1.ModelRolloutPhaseRolledBack:
+		// Hold the rollout until the Model is updated again.
+		for _, p := range newPods {
+			plan.delete(p)
+		}
+		plan.scale(oldPods, replicas, podFromExisting(&oldPods[len(oldPods)-1]))
+		return
+	case kubeaiv1.ModelRolloutPhasePromoting:
+		r.planPromotion(plan, pods, oldPods, newPods, podForModel, expectedHash)
+		return
+	}
+
+	if reason := r.rolloutFailure(model, newPods, now); reason != "" {
+		plan.details = append(plan.details, "Rolling back: "+reason)
+		rollout.Phase = kubeaiv1.ModelRolloutPhaseRolledBack
+		rollout.Message = reason
+		rollout.TrafficPercent = 0
+		for _, p := range newPods {
+			plan.delete(p)
+		}
+		plan.scale(oldPods, replicas, podFromExisting(&oldPods[len(oldPods)-1]))
+		return
+	}
+
+	// Scale down the out-of-date Pods if the Model was scaled down.
+	for i := 0; i < len(oldPods)-int(replicas); i++ {
+		plan.delete(oldPods[i])
+	}
+
+	target := replicas
+	if model.Spec.Rollout.Strategy == kubeaiv1.CanaryRolloutStrategy {
+		target = min(max(model.Spec.Rollout.Canary.Replicas, 1), replicas)
+	}
+	var readyNew int32
+	for _, p := range newPods {
+		if k8sutils.PodIsReady(&p) {
+			readyNew++
+		}
+	}
+
+	analysis := rolloutAnalysisDuration(model)
+	switch rollout.Phase {
+	case kubeaiv1.ModelRolloutPhaseProgressing:
+		if int32(len(newPods)) >= target && readyNew >= target {
+			rollout.Phase = kubeaiv1.ModelRolloutPhaseAnalyzing
+			rollout.AnalysisStartTime = ptr.To(metav1.NewTime(now))
+			rollout.Message = fmt.Sprintf("Analyzing %d up-to-date Pods for %s", readyNew, analysis)
+			plan.requeueAfter = min(analysis, rolloutAnalysisInterval)
+		} else {
+			rollout.Message = fmt.Sprintf("Waiting for %d/%d up-to-date Pods to become ready", target-readyNew, target)
+			plan.requeueAfter = rollout.StartTime.Add(rolloutProgressDeadline(model)).Sub(now)
+		}
+	case kubeaiv1.ModelRolloutPhaseAnalyzing:
+		remaining := rollout.AnalysisStartTime.Add(analysis).Sub(now)
+		if remaining > 0 {
+			plan.requeueAfter = min(remaining, rolloutAnalysisInterval)
+		} else if msg := r.inconclusiveRolloutAnalysis(model, newPods); msg != "" {
+			// Keep analyzing until enough responses were reported.
+			rollout.Message = msg
+			plan.requeueAfter = rolloutAnalysisInterval
+		} else {
+			rollout.Phase = kubeaiv1.ModelRolloutPhasePromoting
+			rollout.Message = "Up-to-date Pods passed the analysis"
+			r.planPromotion(plan, pods, oldPods, newPods, podForModel, expectedHash)
+			return
+		}
+	}
+
+	percent := rolloutTrafficPercent(model)
+	rollout.TrafficPercent = percent
+	podToCreate := podForModel.DeepCopy()
+	if podToCreate.Annotations == nil {
+		podToCreate.Annotations = map[string]string{}
+	}
+	podToCreate.Annotations[kubeaiv1.ModelPodTrafficPercentAnnotation] = fmt.Sprint(percent)
+	plan.scale(newPods, target, podToCreate)
+	for _, p := range newPods {
+		plan.setTrafficPercent(p, &percent)
+	}
+}
+
+// planPromotion deletes the out-of-date Pods of a BlueGreen rollout or
+// rolls out the remaining Pods of a Canary rollout.
+func (r *ModelReconciler) planPromotion(plan *podPlan, pods, oldPods, newPods []corev1.Pod, podForModel *corev1.Pod, expectedHash string) {
+	plan.model.Status.Rollout.TrafficPercent = 100
+	for _, p := range newPods {
+		plan.setTrafficPercent(p, nil)
+	}
+	if plan.model.Spec.Rollout.Strategy == kubeaiv1.CanaryRolloutStrategy {
+		r.planRollingUpdate(plan, pods, podForModel, expectedHash)
+		return
+	}
+	for _, p := range oldPods {
+		plan.delete(p)
+	}
+	plan.scale(newPods, ptr.Deref(plan.model.Spec.Replicas, 0), podForModel)
+}
+
+// rolloutTrafficPercent returns the percentage of traffic that the
+// up-to-date Pods receive in the current phase of the rollout.
+func rolloutTrafficPercent(model *kubeaiv1.Model) int32 {
+	if model.Spec.Rollout.Strategy == kubeaiv1.CanaryRolloutStrategy {
+		if model.Spec.Rollout.Canary.TrafficPercent == 0 {
+			return defaultCanaryTrafficPercent
+		}
+		return model.Spec.Rollout.Canary.TrafficPercent
+	}
+	// BlueGreen switches all traffic once the up-to-date Pods are ready.
+	if model.Status.Rollout.Phase == kubeaiv1.ModelRolloutPhaseAnalyzing {
+		return 100
+	}
+	return 0
+}
+
+func rolloutAnalysisDuration(model *kubeaiv1.Model) time.Duration {
+	seconds := model.Spec.Rollout.BlueGreen.AnalysisSeconds
+	if model.Spec.Rollout.Strategy == kubeaiv1.CanaryRolloutStrategy {
+		seconds = model.Spec.Rollout.Canary.AnalysisSeconds
+	}
+	if seconds == 0 {
+		return defaultRolloutAnalysis
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func rolloutProgressDeadline(model *kubeaiv1.Model) time.Duration {
+	if model.Spec.Rollout.ProgressDeadlineSeconds == 0 {
+		return defaultRolloutProgressDeadline
+	}
+	return time.Duration(model.Spec.Rollout.ProgressDeadlineSeconds) * time.Second
+}
+
+// rolloutFailure returns the reason to roll back the current rollout,
+// or an empty string if the up-to-date Pods are healthy.
+func (r *ModelReconciler) rolloutFailure(model *kubeaiv1.Model, newPods []corev1.Pod, now time.Time) string {
+	rollout := model.Status.Rollout
+	for i := range newPods {
+		pod := &newPods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if reason, message := podFailure(pod); reason != "" {
+			return fmt.Sprintf("Pod %s: %s", pod.Name, message)
+		}
+		if rollout.Phase == kubeaiv1.ModelRolloutPhaseAnalyzing && !k8sutils.PodIsReady(pod) {
+			return fmt.Sprintf("Pod %s is no longer ready", pod.Name)
+		}
+	}
+
+	switch rollout.Phase {
+	case kubeaiv1.ModelRolloutPhaseProgressing:
+		deadline := rolloutProgressDeadline(model)
+		if !now.Before(rollout.StartTime.Add(deadline)) {
+			return fmt.Sprintf("Up-to-date Pods did not become ready within %s", deadline)
+		}
+	case kubeaiv1.ModelRolloutPhaseAnalyzing:
+		maxErrorRate := model.Spec.Rollout.MaxErrorRatePercent
+		if maxErrorRate == nil {
+			return ""
+		}
+		total, errs := r.rolloutResponses(model, newPods)
+		if total >= minRolloutAnalysisRequests && errs*100 > int64(*maxErrorRate)*total {
+			return fmt.Sprintf("Error rate of up-to-date Pods is %d%% (%d/%d requests), exceeding the maximum of %d%%",
+				errs*100/total, errs, total, *maxErrorRate)
+		}
+	}
+	return ""
+}
+
+// inconclusiveRolloutAnalysis returns why the error rate of the up-to-date
+// Pods can not be analyzed yet: fewer responses than required were reported.
+// Up-to-date Pods are not promoted without the analysis, unless it is
+// disabled with a maxErrorRatePercent of 100.
+func (r *ModelReconciler) inconclusiveRolloutAnalysis(model *kubeaiv1.Model, newPods []corev1.Pod) string {
+	maxErrorRate := model.Spec.Rollout.MaxErrorRatePercent
+	if maxErrorRate == nil || *maxErrorRate >= 100 {
+		return ""
+	}
+	total, _ := r.rolloutResponses(model, newPods)
+	if total >= minRolloutAnalysisRequests {
+		return ""
+	}
+	return fmt.Sprintf("Analysis is inconclusive: %d/%d responses of up-to-date Pods were reported, waiting for more requests",
+		total, minRolloutAnalysisRequests)
+}
+
+// rolloutResponses returns the number of responses and errors of the
+// up-to-date Pods that were reported to this replica.
+func (r *ModelReconciler) rolloutResponses(model *kubeaiv1.Model, newPods []corev1.Pod) (total, errs int64) {
+	if r.LoadReports == nil {
+		return 0, 0
+	}
+	responses := r.LoadReports.ResponsesByEndpoint(model.Name)
+	for i := range newPods {
+		resp := responses[strings.TrimPrefix(getPodModelServerAddr(&newPods[i]), "http://")]
+		total += resp.Total
+		errs += resp.Errors
+	}
+	return total, errs
+}
+
+// podFromExisting returns a new Pod that is a copy of the given Pod.
+// It is used to scale the out-of-date Pods after a rollout was rolled back.
+func podFromExisting(pod *corev1.Pod) *corev1.Pod {
+	labels := maps.Clone(pod.Labels)
+	for k := range labels {
+		// Adapters are loaded into the new Pod by the controller.
+		if strings.HasPrefix(k, kubeaiv1.PodAdapterLabelPrefix) {
+			delete(labels, k)
+		}
+	}
+	annotations := maps.Clone(pod.Annotations)
+	delete(annotations, kubeaiv1.ModelPodTrafficPercentAnnotation)
+
+	newPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pod.GenerateName,
+			Namespace:    pod.Namespace,
+			Labels:       labels,
+			Annotations:  annotations,
+		},
+		Spec: *pod.Spec.DeepCopy(),
+	}
+	newPod.Spec.NodeName = ""
+	return newPod
+}
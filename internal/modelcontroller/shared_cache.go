@@ -0,0 +1,361 @@
+package modelcontroller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// sharedCacheProfileLabel is set on the Jobs that evict models from a
+	// sharedFilesystem cache to free space.
+	sharedCacheProfileLabel = "shared-cache.kubeai.org/profile"
+
+	// sharedCacheResyncPeriod is the interval at which the last request
+	// times of the Models are refreshed from the autoscaler state.
+	sharedCacheResyncPeriod = time.Minute
+)
+
+// SharedCacheReconciler tracks the usage of every sharedFilesystem cache
+// profile in a SharedModelCache and evicts the least recently served Models
+// that are scaled to zero when the configured capacity is reached.
+type SharedCacheReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Namespace string
+	// ModelReconciler provides the cache profiles and the model loader image.
+	ModelReconciler *ModelReconciler
+}
+
+func (r *SharedCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	cacheProfile, ok := r.ModelReconciler.CacheProfiles[req.Name]
+	if !ok || cacheProfile.SharedFilesystem == nil {
+		return ctrl.Result{}, nil
+	}
+	profile := cacheProfile.SharedFilesystem
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: sharedCachePVCName(req.Name)}, pvc); err != nil {
+		// The PVC is created with the first Model of the profile.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	smc := &kubeaiv1.SharedModelCache{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: req.Name}, smc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting shared model cache: %w", err)
+		}
+		smc = &kubeaiv1.SharedModelCache{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: r.Namespace,
+				Name:      req.Name,
+			},
+		}
+		// The SharedModelCache is deleted with the PVC.
+		if err := ctrl.SetControllerReference(pvc, smc, r.Scheme); err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting controller reference on shared model cache: %w", err)
+		}
+		if err := r.Create(ctx, smc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating shared model cache: %w", err)
+		}
+	}
+	original := smc.Status.DeepCopy()
+
+	modelList := &kubeaiv1.ModelList{}
+	if err := r.List(ctx, modelList, client.InNamespace(r.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing models: %w", err)
+	}
+	models := map[string]*kubeaiv1.Model{}
+	for i := range modelList.Items {
+		if m := &modelList.Items[i]; m.Spec.CacheProfile == req.Name {
+			models[m.Name] = m
+		}
+	}
+
+	stateList := &kubeaiv1.ModelAutoscalerStateList{}
+	if err := r.List(ctx, stateList, client.InNamespace(r.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing model autoscaler states: %w", err)
+	}
+	lastActive := map[string]*metav1.Time{}
+	for _, s := range stateList.Items {
+		lastActive[s.Name] = s.Status.LastActiveTime
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(r.Namespace), client.MatchingLabels{sharedCacheProfileLabel: req.Name}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing jobs: %w", err)
+	}
+	evicting := map[string]bool{}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		ref := metav1.GetControllerOf(job)
+		if ref == nil || job.DeletionTimestamp != nil {
+			continue
+		}
+		model, ok := models[ref.Name]
+		if !ok || model.UID != ref.UID || model.DeletionTimestamp != nil {
+			// Deleted Models are evicted by their finalizer.
+			continue
+		}
+		if !k8sutils.IsJobCompleted(job) && !k8sutils.IsJobFailed(job) {
+			evicting[model.Name] = true
+			continue
+		}
+		if k8sutils.IsJobCompleted(job) {
+			if err := r.markEvicted(ctx, pvc, model); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else {
+			log.Info("Failed to evict model from shared cache", "model", model.Name, "job", job.Name)
+		}
+		// The Model is reconciled (and loaded again if needed) once the Job is gone.
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("deleting job: %w", err)
+		}
+	}
+
+	smc.Status.Models = nil
+	anns := map[string]PVCModelAnnotationValue{}
+	for key, value := range pvc.Annotations {
+		name, ok := strings.CutPrefix(key, kubeaiv1.PVCModelAnnotation(""))
+		if !ok {
+			continue
+		}
+		model, ok := models[name]
+		if !ok {
+			continue
+		}
+		ann, err := parsePVCModelAnnotation(pvc, name)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("parsing pvc model annotation %q: %w", value, err)
+		}
+		if ann.UID != string(model.UID) {
+			continue
+		}
+		anns[model.Name] = ann
+		cached := kubeaiv1.SharedCachedModel{
+			Name:            model.Name,
+			UID:             model.UID,
+			Phase:           kubeaiv1.SharedCachedModelLoaded,
+			SizeBytes:       ann.SizeBytes,
+			LoadedTime:      ptr.To(metav1.NewTime(ann.Timestamp)),
+			LastRequestTime: lastActive[model.Name],
+		}
+		switch {
+		case ann.Evicted:
+			cached.Phase = kubeaiv1.SharedCachedModelEvicted
+			cached.SizeBytes = 0
+		case evicting[model.Name]:
+			cached.Phase = kubeaiv1.SharedCachedModelEvicting
+		}
+		smc.Status.Models = append(smc.Status.Models, cached)
+	}
+	sort.Slice(smc.Status.Models, func(i, j int) bool {
+		return smc.Status.Models[i].Name < smc.Status.Models[j].Name
+	})
+	smc.Status.UsedBytes = 0
+	// The model of a cache entry takes up space once for all Models that
+	// reference it.
+	countedEntries := map[string]bool{}
+	for _, cached := range smc.Status.Models {
+		if entry := anns[cached.Name].Entry; entry != "" && cached.SizeBytes > 0 {
+			if countedEntries[entry] {
+				continue
+			}
+			countedEntries[entry] = true
+		}
+		smc.Status.UsedBytes += cached.SizeBytes
+	}
+	// The models of ModelCaches take up space but are never evicted to free it.
+	modelCacheList := &kubeaiv1.ModelCacheList{}
+	if err := r.List(ctx, modelCacheList, client.InNamespace(r.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing model caches: %w", err)
+	}
+	for _, mc := range modelCacheList.Items {
+		if mc.Spec.CacheProfile == req.Name && mc.Status.Loaded {
+			smc.Status.UsedBytes += mc.Status.SizeBytes
+		}
+	}
+	smc.Status.CapacityBytes = 0
+	if profile.Capacity != nil {
+		smc.Status.CapacityBytes = profile.Capacity.Value()
+	}
+
+	idle := map[string]bool{}
+	for name, m := range models {
+		idle[name] = m.DeletionTimestamp == nil && isScaledToZero(m)
+		// The model of a cache entry is only evicted with its last reference.
+		if entry := anns[name].Entry; idle[name] && entry != "" {
+			refs, err := cacheEntryRefs(pvc, entry, name)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			idle[name] = len(refs) == 0
+		}
+	}
+	for _, cached := range planSharedCacheEviction(&smc.Status, profile, idle) {
+		model := models[cached.Name]
+		log.Info("Evicting least recently served model from shared cache", "model", model.Name, "profile", req.Name)
+		job := r.ModelReconciler.evictCacheJobForModel(model, ModelConfig{CacheProfile: cacheProfile}, loadedCacheDir(model, anns[model.Name]))
+		job.Labels = map[string]string{sharedCacheProfileLabel: req.Name}
+		if err := ctrl.SetControllerReference(model, job, r.Scheme); err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting controller reference on job: %w", err)
+		}
+		if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return ctrl.Result{}, fmt.Errorf("creating cache eviction job: %w", err)
+		}
+		idx := slices.IndexFunc(smc.Status.Models, func(c kubeaiv1.SharedCachedModel) bool { return c.Name == cached.Name })
+		smc.Status.Models[idx].Phase = kubeaiv1.SharedCachedModelEvicting
+	}
+
+	if !equality.Semantic.DeepEqual(*original, smc.Status) {
+		if err := r.Status().Update(ctx, smc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating shared model cache status: %w", err)
+		}
+	}
+
+	if profile.Capacity == nil {
+		return ctrl.Result{}, nil
+	}
+	// The last request times are not watched.
+	return ctrl.Result{RequeueAfter: sharedCacheResyncPeriod}, nil
+}
+
+// markEvicted records in the PVC annotation of a Model that its model was
+// evicted from the cache.
+func (r *SharedCacheReconciler) markEvicted(ctx context.Context, pvc *corev1.PersistentVolumeClaim, model *kubeaiv1.Model) error {
+	ann, err := parsePVCModelAnnotation(pvc, model.Name)
+	if err != nil {
+		return fmt.Errorf("parsing pvc model annotation: %w", err)
+	}
+	if ann.UID != string(model.UID) || ann.Evicted {
+		return nil
+	}
+	ann.Evicted = true
+	ann.Timestamp = time.Now()
+	if err := r.ModelReconciler.updatePVCModelAnnotation(ctx, pvc, model.Name, ann); err != nil {
+		return fmt.Errorf("setting pvc model annotation: %w", err)
+	}
+	return nil
+}
+
+// planSharedCacheEviction returns the models to evict to bring the usage of
+// a cache below the eviction threshold: the least recently served models of
+// idle Models, where models that were never served count as served when
+// they were loaded.
+func planSharedCacheEviction(status *kubeaiv1.SharedModelCacheStatus, profile *config.CacheSharedFilesystem, idle map[string]bool) []kubeaiv1.SharedCachedModel {
+	if profile.Capacity == nil {
+		return nil
+	}
+	threshold := profile.Capacity.Value() * int64(profile.EvictionThresholdPercent) / 100
+
+	used := status.UsedBytes
+	var candidates []kubeaiv1.SharedCachedModel
+	for _, cached := range status.Models {
+		switch {
+		case cached.Phase == kubeaiv1.SharedCachedModelEvicting:
+			// Space that is being freed already.
+			used -= cached.SizeBytes
+		case cached.Phase == kubeaiv1.SharedCachedModelLoaded && idle[cached.Name]:
+			candidates = append(candidates, cached)
+		}
+	}
+	if used < threshold {
+		return nil
+	}
+
+	lastServed := func(c kubeaiv1.SharedCachedModel) time.Time {
+		var t time.Time
+		if c.LoadedTime != nil {
+			t = c.LoadedTime.Time
+		}
+		if c.LastRequestTime != nil && c.LastRequestTime.After(t) {
+			t = c.LastRequestTime.Time
+		}
+		return t
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return lastServed(candidates[i]).Before(lastServed(candidates[j]))
+	})
+
+	var evict []kubeaiv1.SharedCachedModel
+	for _, cached := range candidates {
+		if used < threshold {
+			break
+		}
+		evict = append(evict, cached)
+		used -= cached.SizeBytes
+	}
+	return evict
+}
+
+// isScaledToZero returns true if the Model has no replicas and should have none.
+func isScaledToZero(m *kubeaiv1.Model) bool {
+	return ptr.Deref(m.Spec.Replicas, 0) == 0 && m.Status.Replicas.All == 0
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SharedCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sharedcache").
+		For(&kubeaiv1.SharedModelCache{}).
+		Watches(&kubeaiv1.Model{}, handler.EnqueueRequestsFromMapFunc(cacheProfileForModel)).
+		Watches(&kubeaiv1.ModelCache{}, handler.EnqueueRequestsFromMapFunc(cacheProfileForModelCache)).
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(cacheProfileForSharedPVC)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(cacheProfileForSharedCacheJob)).
+		Complete(r)
+}
+
+func cacheProfileForModel(_ context.Context, obj client.Object) []reconcile.Request {
+	model, ok := obj.(*kubeaiv1.Model)
+	if !ok || model.Spec.CacheProfile == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: model.Namespace, Name: model.Spec.CacheProfile}}}
+}
+
+func cacheProfileForModelCache(_ context.Context, obj client.Object) []reconcile.Request {
+	mc, ok := obj.(*kubeaiv1.ModelCache)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: mc.Namespace, Name: mc.Spec.CacheProfile}}}
+}
+
+func cacheProfileForSharedPVC(_ context.Context, obj client.Object) []reconcile.Request {
+	name, ok := strings.CutPrefix(obj.GetName(), sharedCachePVCName(""))
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+func cacheProfileForSharedCacheJob(_ context.Context, obj client.Object) []reconcile.Request {
+	name := k8sutils.GetLabel(obj, sharedCacheProfileLabel)
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
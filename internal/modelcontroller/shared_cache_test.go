@@ -0,0 +1,100 @@
+package modelcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func Test_planSharedCacheEviction(t *testing.T) {
+	now := time.Now()
+	profile := &config.CacheSharedFilesystem{
+		Capacity:                 resource.NewQuantity(100, resource.DecimalSI),
+		EvictionThresholdPercent: 80,
+	}
+	cached := func(name string, phase v1.SharedCachedModelPhase, size int64, loadedAgo time.Duration, requestedAgo *time.Duration) v1.SharedCachedModel {
+		c := v1.SharedCachedModel{
+			Name:       name,
+			Phase:      phase,
+			SizeBytes:  size,
+			LoadedTime: ptr.To(metav1.NewTime(now.Add(-loadedAgo))),
+		}
+		if requestedAgo != nil {
+			c.LastRequestTime = ptr.To(metav1.NewTime(now.Add(-*requestedAgo)))
+		}
+		return c
+	}
+	status := func(models ...v1.SharedCachedModel) v1.SharedModelCacheStatus {
+		s := v1.SharedModelCacheStatus{Models: models}
+		for _, m := range models {
+			s.UsedBytes += m.SizeBytes
+		}
+		return s
+	}
+
+	cases := map[string]struct {
+		status    v1.SharedModelCacheStatus
+		profile   *config.CacheSharedFilesystem
+		idle      map[string]bool
+		wantEvict []string
+	}{
+		"below the threshold": {
+			status: status(
+				cached("a", v1.SharedCachedModelLoaded, 40, time.Hour, nil),
+				cached("b", v1.SharedCachedModelLoaded, 30, time.Hour, nil),
+			),
+			idle: map[string]bool{"a": true, "b": true},
+		},
+		"no capacity configured": {
+			status: status(
+				cached("a", v1.SharedCachedModelLoaded, 100, time.Hour, nil),
+			),
+			profile: &config.CacheSharedFilesystem{EvictionThresholdPercent: 80},
+			idle:    map[string]bool{"a": true},
+		},
+		"evict least recently served idle models": {
+			status: status(
+				cached("recent", v1.SharedCachedModelLoaded, 30, 3*time.Hour, ptr.To(time.Minute)),
+				cached("old", v1.SharedCachedModelLoaded, 30, 3*time.Hour, ptr.To(time.Hour)),
+				cached("never-requested", v1.SharedCachedModelLoaded, 10, 2*time.Hour, nil),
+				cached("oldest-serving", v1.SharedCachedModelLoaded, 20, 4*time.Hour, ptr.To(4*time.Hour)),
+			),
+			idle:      map[string]bool{"recent": true, "old": true, "never-requested": true},
+			wantEvict: []string{"never-requested", "old"},
+		},
+		"account for models that are being evicted": {
+			status: status(
+				cached("a", v1.SharedCachedModelEvicting, 20, time.Hour, nil),
+				cached("b", v1.SharedCachedModelLoaded, 70, 2*time.Hour, nil),
+			),
+			idle: map[string]bool{"a": true, "b": true},
+		},
+		"ignore evicted models": {
+			status: status(
+				cached("a", v1.SharedCachedModelEvicted, 0, 3*time.Hour, nil),
+				cached("b", v1.SharedCachedModelLoaded, 90, 2*time.Hour, nil),
+			),
+			idle:      map[string]bool{"a": true, "b": true},
+			wantEvict: []string{"b"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := profile
+			if c.profile != nil {
+				p = c.profile
+			}
+			var evict []string
+			for _, m := range planSharedCacheEviction(&c.status, p, c.idle) {
+				evict = append(evict, m.Name)
+			}
+			require.Equal(t, c.wantEvict, evict)
+		})
+	}
+}
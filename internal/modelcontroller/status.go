@@ -0,0 +1,213 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"strings"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setCondition sets the given condition on the status of the Model.
+func setCondition(model *kubeaiv1.Model, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: model.Generation,
+	})
+}
+
+// setPodConditions sets the conditions of the Model that are derived
+// from the state of its server Pods.
+func setPodConditions(model *kubeaiv1.Model, pods []corev1.Pod, outOfDate int) {
+	var desired int32
+	if model.Spec.Replicas != nil {
+		desired = *model.Spec.Replicas
+	}
+	all := model.Status.Replicas.All
+	ready := model.Status.Replicas.Ready
+
+	switch {
+	case desired == 0 && all == 0:
+		setCondition(model, kubeaiv1.ModelConditionReady, metav1.ConditionFalse, kubeaiv1.ModelReasonScaledToZero,
+			"The Model is scaled to zero replicas")
+	case ready >= desired:
+		setCondition(model, kubeaiv1.ModelConditionReady, metav1.ConditionTrue, kubeaiv1.ModelReasonAllReplicasReady,
+			fmt.Sprintf("%d/%d replicas are ready", ready, desired))
+	default:
+		setCondition(model, kubeaiv1.ModelConditionReady, metav1.ConditionFalse, kubeaiv1.ModelReasonReplicasNotReady,
+			fmt.Sprintf("%d/%d replicas are ready", ready, desired))
+	}
+
+	switch {
+	case outOfDate > 0 && model.Status.Rollout != nil && model.Status.Rollout.Phase == kubeaiv1.ModelRolloutPhaseRolledBack:
+		setCondition(model, kubeaiv1.ModelConditionProgressing, metav1.ConditionFalse, kubeaiv1.ModelReasonRolledBack,
+			model.Status.Rollout.Message)
+	case outOfDate > 0:
+		setCondition(model, kubeaiv1.ModelConditionProgressing, metav1.ConditionTrue, kubeaiv1.ModelReasonRollingOut,
+			fmt.Sprintf("%d/%d replicas are out of date", outOfDate, all))
+	case all < desired || ready < desired:
+		setCondition(model, kubeaiv1.ModelConditionProgressing, metav1.ConditionTrue, kubeaiv1.ModelReasonScalingUp,
+			fmt.Sprintf("Scaling up to %d replicas", desired))
+	case all > desired:
+		setCondition(model, kubeaiv1.ModelConditionProgressing, metav1.ConditionTrue, kubeaiv1.ModelReasonScalingDown,
+			fmt.Sprintf("Scaling down to %d replicas", desired))
+	default:
+		setCondition(model, kubeaiv1.ModelConditionProgressing, metav1.ConditionFalse, kubeaiv1.ModelReasonComplete,
+			fmt.Sprintf("%d replicas are up to date", all))
+	}
+
+	degradedReason, degradedMessage := "", ""
+	schedulableReason, schedulableMessage := "", ""
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if degradedReason == "" {
+			if reason, message := podFailure(pod); reason != "" {
+				degradedReason = reason
+				degradedMessage = fmt.Sprintf("Pod %s: %s", pod.Name, message)
+			}
+		}
+		if schedulableReason == "" {
+			if reason, message := podUnschedulable(pod); reason != "" {
+				schedulableReason = reason
+				schedulableMessage = fmt.Sprintf("Pod %s: %s", pod.Name, message)
+			}
+		}
+	}
+
+	if degradedReason != "" {
+		setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionTrue, degradedReason, degradedMessage)
+	} else {
+		setCondition(model, kubeaiv1.ModelConditionDegraded, metav1.ConditionFalse, kubeaiv1.ModelReasonAsExpected, "")
+	}
+
+	if schedulableReason != "" {
+		setCondition(model, kubeaiv1.ModelConditionSchedulable, metav1.ConditionFalse, schedulableReason, schedulableMessage)
+	} else {
+		setCondition(model, kubeaiv1.ModelConditionSchedulable, metav1.ConditionTrue, kubeaiv1.ModelReasonScheduled, "")
+	}
+}
+
+// podFailure returns the reason and message of a failure of one of the
+// containers of the given Pod, or an empty reason if there is none.
+func podFailure(pod *corev1.Pod) (string, string) {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		// Report OOM kills instead of the resulting crash loop.
+		if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return kubeaiv1.ModelReasonOOMKilled, fmt.Sprintf("container %q was killed because it ran out of memory", cs.Name)
+		}
+		if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return kubeaiv1.ModelReasonOOMKilled, fmt.Sprintf("container %q was killed because it ran out of memory", cs.Name)
+		}
+		w := cs.State.Waiting
+		if w == nil {
+			continue
+		}
+		switch w.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName":
+			return kubeaiv1.ModelReasonImagePullBackOff, fmt.Sprintf("container %q: %s", cs.Name, w.Message)
+		case "CrashLoopBackOff":
+			return kubeaiv1.ModelReasonCrashLoopBackOff, fmt.Sprintf("container %q: %s", cs.Name, w.Message)
+		case "CreateContainerConfigError", "CreateContainerError":
+			return kubeaiv1.ModelReasonContainerConfigError, fmt.Sprintf("container %q: %s", cs.Name, w.Message)
+		}
+	}
+	return "", ""
+}
+
+// podUnschedulable returns the reason and message if the given Pod
+// can not be scheduled, or an empty reason if it can.
+func podUnschedulable(pod *corev1.Pod) (string, string) {
+	if k8sutils.PodIsScheduled(pod) {
+		return "", ""
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.PodScheduled || cond.Status != corev1.ConditionFalse || cond.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		msg := strings.ToLower(cond.Message)
+		if strings.Contains(msg, "insufficient") && (strings.Contains(msg, "/gpu") || strings.Contains(msg, "/tpu")) {
+			return kubeaiv1.ModelReasonInsufficientGPU, cond.Message
+		}
+		return kubeaiv1.ModelReasonUnschedulable, cond.Message
+	}
+	return "", ""
+}
+
+// modelPhase summarizes the conditions of the Model.
+func modelPhase(model *kubeaiv1.Model) kubeaiv1.ModelPhase {
+	conds := model.Status.Conditions
+	degraded := meta.FindStatusCondition(conds, kubeaiv1.ModelConditionDegraded)
+	cacheLoaded := meta.FindStatusCondition(conds, kubeaiv1.ModelConditionCacheLoaded)
+	ready := meta.FindStatusCondition(conds, kubeaiv1.ModelConditionReady)
+
+	switch {
+	case degraded != nil && degraded.Reason == kubeaiv1.ModelReasonInvalidConfiguration,
+		cacheLoaded != nil && cacheLoaded.Reason == kubeaiv1.ModelReasonLoadFailed:
+		return kubeaiv1.ModelPhaseFailed
+	case cacheLoaded != nil && cacheLoaded.Status == metav1.ConditionFalse && cacheLoaded.Reason != kubeaiv1.ModelReasonEvicted:
+		return kubeaiv1.ModelPhaseLoading
+	case meta.IsStatusConditionTrue(conds, kubeaiv1.ModelConditionDegraded):
+		return kubeaiv1.ModelPhaseDegraded
+	case meta.IsStatusConditionFalse(conds, kubeaiv1.ModelConditionSchedulable):
+		return kubeaiv1.ModelPhasePending
+	case ready != nil && ready.Reason == kubeaiv1.ModelReasonScaledToZero:
+		return kubeaiv1.ModelPhaseScaledToZero
+	case meta.IsStatusConditionTrue(conds, kubeaiv1.ModelConditionProgressing):
+		return kubeaiv1.ModelPhaseProgressing
+	case ready != nil && ready.Status == metav1.ConditionTrue:
+		return kubeaiv1.ModelPhaseReady
+	default:
+		return kubeaiv1.ModelPhasePending
+	}
+}
+
+// recordConditionEvents emits an Event for every condition that changed
+// compared to the given previous conditions. Conditions that are new
+// only result in an Event if they indicate a problem.
+func (r *ModelReconciler) recordConditionEvents(model *kubeaiv1.Model, previous []metav1.Condition) {
+	if r.Recorder == nil {
+		return
+	}
+	for _, cond := range model.Status.Conditions {
+		prev := meta.FindStatusCondition(previous, cond.Type)
+		problem := conditionIndicatesProblem(cond)
+		if prev == nil && !problem {
+			continue
+		}
+		if prev != nil && prev.Status == cond.Status && prev.Reason == cond.Reason {
+			continue
+		}
+		eventType := corev1.EventTypeNormal
+		if problem {
+			eventType = corev1.EventTypeWarning
+		}
+		msg := fmt.Sprintf("%s is %s", cond.Type, cond.Status)
+		if cond.Message != "" {
+			msg += ": " + cond.Message
+		}
+		r.Recorder.Event(model, eventType, cond.Reason, msg)
+	}
+}
+
+func conditionIndicatesProblem(cond metav1.Condition) bool {
+	switch cond.Type {
+	case kubeaiv1.ModelConditionDegraded:
+		return cond.Status == metav1.ConditionTrue
+	case kubeaiv1.ModelConditionSchedulable:
+		return cond.Status == metav1.ConditionFalse
+	case kubeaiv1.ModelConditionProgressing:
+		return cond.Reason == kubeaiv1.ModelReasonRolledBack
+	default:
+		return cond.Reason == kubeaiv1.ModelReasonLoadFailed
+	}
+}
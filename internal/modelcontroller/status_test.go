@@ -0,0 +1,195 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func Test_podFailure(t *testing.T) {
+	cases := map[string]struct {
+		status    corev1.PodStatus
+		expReason string
+	}{
+		"running": {
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "server", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}},
+		},
+		"image pull backoff": {
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "server", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			}},
+			expReason: v1.ModelReasonImagePullBackOff,
+		},
+		"init container crash loop": {
+			status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "loader", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			}},
+			expReason: v1.ModelReasonCrashLoopBackOff,
+		},
+		"crash loop after oom kill": {
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "server",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+				},
+			}},
+			expReason: v1.ModelReasonOOMKilled,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			reason, _ := podFailure(&corev1.Pod{Status: c.status})
+			require.Equal(t, c.expReason, reason)
+		})
+	}
+}
+
+func Test_podUnschedulable(t *testing.T) {
+	unschedulable := func(msg string) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: msg,
+		}}}}
+	}
+
+	reason, msg := podUnschedulable(unschedulable("0/3 nodes are available: 3 Insufficient nvidia.com/gpu."))
+	require.Equal(t, v1.ModelReasonInsufficientGPU, reason)
+	require.Equal(t, "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.", msg)
+
+	reason, _ = podUnschedulable(unschedulable("0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector."))
+	require.Equal(t, v1.ModelReasonUnschedulable, reason)
+
+	reason, _ = podUnschedulable(&corev1.Pod{Spec: corev1.PodSpec{NodeName: "node-1"}})
+	require.Empty(t, reason)
+}
+
+func Test_setPodConditions(t *testing.T) {
+	readyPod := corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+	}}}
+	crashingPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "crashing"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "server", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+		}},
+	}
+	pendingPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending"},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/1 nodes are available: 1 Insufficient google.com/tpu.",
+		}}},
+	}
+
+	cases := map[string]struct {
+		replicas  int32
+		pods      []corev1.Pod
+		outOfDate int
+		rollout   *v1.ModelStatusRollout
+		expReady  string
+		expProg   string
+		expPhase  v1.ModelPhase
+	}{
+		"scaled to zero": {
+			replicas: 0,
+			expReady: v1.ModelReasonScaledToZero,
+			expProg:  v1.ModelReasonComplete,
+			expPhase: v1.ModelPhaseScaledToZero,
+		},
+		"ready": {
+			replicas: 2,
+			pods:     []corev1.Pod{readyPod, readyPod},
+			expReady: v1.ModelReasonAllReplicasReady,
+			expProg:  v1.ModelReasonComplete,
+			expPhase: v1.ModelPhaseReady,
+		},
+		"scaling up": {
+			replicas: 2,
+			pods:     []corev1.Pod{readyPod},
+			expReady: v1.ModelReasonReplicasNotReady,
+			expProg:  v1.ModelReasonScalingUp,
+			expPhase: v1.ModelPhaseProgressing,
+		},
+		"rolling out": {
+			replicas:  1,
+			pods:      []corev1.Pod{readyPod, {}},
+			outOfDate: 1,
+			expReady:  v1.ModelReasonAllReplicasReady,
+			expProg:   v1.ModelReasonRollingOut,
+			expPhase:  v1.ModelPhaseProgressing,
+		},
+		"rolled back": {
+			replicas:  1,
+			pods:      []corev1.Pod{readyPod},
+			outOfDate: 1,
+			rollout:   &v1.ModelStatusRollout{Phase: v1.ModelRolloutPhaseRolledBack, Message: "Pod a: crash"},
+			expReady:  v1.ModelReasonAllReplicasReady,
+			expProg:   v1.ModelReasonRolledBack,
+			expPhase:  v1.ModelPhaseReady,
+		},
+		"crash looping": {
+			replicas: 2,
+			pods:     []corev1.Pod{readyPod, crashingPod},
+			expReady: v1.ModelReasonReplicasNotReady,
+			expProg:  v1.ModelReasonScalingUp,
+			expPhase: v1.ModelPhaseDegraded,
+		},
+		"waiting for gpus": {
+			replicas: 1,
+			pods:     []corev1.Pod{pendingPod},
+			expReady: v1.ModelReasonReplicasNotReady,
+			expProg:  v1.ModelReasonScalingUp,
+			expPhase: v1.ModelPhasePending,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			model := &v1.Model{Spec: v1.ModelSpec{Replicas: ptr.To(c.replicas)}}
+			model.Status.Replicas.All = int32(len(c.pods))
+			model.Status.Rollout = c.rollout
+			for i := range c.pods {
+				if c.pods[i].Status.Conditions != nil && c.pods[i].Status.Conditions[0].Type == corev1.PodReady {
+					model.Status.Replicas.Ready++
+				}
+			}
+
+			setPodConditions(model, c.pods, c.outOfDate)
+			require.Equal(t, c.expReady, meta.FindStatusCondition(model.Status.Conditions, v1.ModelConditionReady).Reason)
+			require.Equal(t, c.expProg, meta.FindStatusCondition(model.Status.Conditions, v1.ModelConditionProgressing).Reason)
+			require.Equal(t, c.expPhase, modelPhase(model))
+		})
+	}
+}
+
+func Test_recordConditionEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &ModelReconciler{Recorder: recorder}
+	model := &v1.Model{}
+
+	// New conditions that do not indicate a problem are not recorded.
+	setCondition(model, v1.ModelConditionReady, metav1.ConditionFalse, v1.ModelReasonReplicasNotReady, "0/1 replicas are ready")
+	setCondition(model, v1.ModelConditionDegraded, metav1.ConditionTrue, v1.ModelReasonOOMKilled, "Pod a: oom")
+	r.recordConditionEvents(model, nil)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, "Warning OOMKilled Degraded is True: Pod a: oom", <-recorder.Events)
+
+	previous := append([]metav1.Condition{}, model.Status.Conditions...)
+	setCondition(model, v1.ModelConditionReady, metav1.ConditionTrue, v1.ModelReasonAllReplicasReady, "1/1 replicas are ready")
+	setCondition(model, v1.ModelConditionDegraded, metav1.ConditionTrue, v1.ModelReasonOOMKilled, "Pod b: oom")
+	r.recordConditionEvents(model, previous)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, "Normal AllReplicasReady Ready is True: 1/1 replicas are ready", <-recorder.Events)
+}
@@ -0,0 +1,199 @@
+package modelcontroller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileWarmPool ensures that the number of parked Pods for the Model
+// matches the warm pool configuration. It returns the up-to-date parked Pods.
+func (r *ModelReconciler) reconcileWarmPool(ctx context.Context, model *kubeaiv1.Model, modelConfig ModelConfig) ([]corev1.Pod, error) {
+	log := log.FromContext(ctx)
+
+	var desired int
+	if model.Spec.WarmPool != nil {
+		desired = int(model.Spec.WarmPool.Replicas)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(model.Namespace), client.MatchingLabels{
+		kubeaiv1.ParkedPodModelLabel: model.Name,
+	}); err != nil {
+		return nil, fmt.Errorf("listing parked pods: %w", err)
+	}
+	if desired == 0 && len(podList.Items) == 0 {
+		model.Status.Replicas.Parked = 0
+		return nil, nil
+	}
+
+	serverPod, err := r.podForModel(model, modelConfig)
+	if err != nil {
+		return nil, err
+	}
+	parkedPod := r.parkedPodForServerPod(model, serverPod)
+	expectedHash := k8sutils.GetLabel(parkedPod, kubeaiv1.PodHashLabel)
+
+	var (
+		upToDate []corev1.Pod
+		toDelete []corev1.Pod
+	)
+	for _, p := range podList.Items {
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		if k8sutils.GetLabel(&p, kubeaiv1.PodHashLabel) != expectedHash {
+			toDelete = append(toDelete, p)
+			continue
+		}
+		upToDate = append(upToDate, p)
+	}
+	if len(upToDate) > desired {
+		// Prefer keeping Pods that are already scheduled.
+		sortPodsByDeletionOrder(upToDate, expectedHash)
+		toDelete = append(toDelete, upToDate[:len(upToDate)-desired]...)
+		upToDate = upToDate[len(upToDate)-desired:]
+	}
+
+	for _, p := range toDelete {
+		log.Info("Deleting parked Pod", "podName", p.Name)
+		if err := r.Delete(ctx, &p); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("deleting parked pod: %w", err)
+		}
+	}
+	for i := len(upToDate); i < desired; i++ {
+		pod := parkedPod.DeepCopy()
+		if err := ctrl.SetControllerReference(model, pod, r.Scheme); err != nil {
+			return nil, fmt.Errorf("setting controller reference: %w", err)
+		}
+		log.Info("Creating parked Pod")
+		if err := r.Create(ctx, pod, k8sutils.DefaultCreateOptions()); err != nil {
+			return nil, fmt.Errorf("creating parked pod: %w", err)
+		}
+	}
+
+	var ready int32
+	for _, p := range upToDate {
+		if k8sutils.PodIsReady(&p) {
+			ready++
+		}
+	}
+	model.Status.Replicas.Parked = ready
+
+	return upToDate, nil
+}
+
+// parkedPodVolumeName is the name of the volume that the pause binary of the
+// server container of parked Pods is installed into.
+const parkedPodVolumeName = "kubeai-pause"
+
+// parkedPodForServerPod returns a Pod that is scheduled like the given server Pod
+// but does not request any resources and only pauses. The init containers of
+// the server Pod are kept, so that the model is loaded while the Pod is parked.
+// The pause binary is installed from the model loader image, which does not
+// require the server image to contain any other binaries.
+func (r *ModelReconciler) parkedPodForServerPod(model *kubeaiv1.Model, serverPod *corev1.Pod) *corev1.Pod {
+	pod := serverPod.DeepCopy()
+	pod.Labels = map[string]string{
+		kubeaiv1.ParkedPodModelLabel: model.Name,
+	}
+	pod.Annotations = nil
+	// Parked Pods should never preempt server Pods.
+	pod.Spec.PriorityClassName = ""
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: parkedPodVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:    "pause-installer",
+		Image:   r.ModelLoaders.Image,
+		Command: []string{"cp", "/bin/pause", "/kubeai-pause/pause"},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: parkedPodVolumeName, MountPath: "/kubeai-pause"},
+		},
+	})
+
+	var server corev1.Container
+	for _, c := range pod.Spec.Containers {
+		if c.Name == serverContainerName {
+			server = c
+			break
+		}
+	}
+	server.Command = []string{"/kubeai-pause/pause"}
+	server.Args = nil
+	server.Resources = corev1.ResourceRequirements{}
+	server.Ports = nil
+	server.StartupProbe = nil
+	server.ReadinessProbe = nil
+	server.LivenessProbe = nil
+	server.VolumeMounts = append(server.VolumeMounts, corev1.VolumeMount{
+		Name:      parkedPodVolumeName,
+		MountPath: "/kubeai-pause",
+		ReadOnly:  true,
+	})
+	pod.Spec.Containers = []corev1.Container{server}
+
+	hash := k8sutils.PodHash(pod.Spec)
+	pod.GenerateName = fmt.Sprintf("model-%s-parked-%s-", model.Name, hash)
+	k8sutils.SetLabel(pod, kubeaiv1.PodHashLabel, hash)
+
+	return pod
+}
+
+// preferNodesOfPods adds a preferred Node affinity to the given Pod for
+// the Nodes that the given (parked) Pods are scheduled on.
+func preferNodesOfPods(pod *corev1.Pod, parked []corev1.Pod) {
+	var nodes []string
+	for _, p := range parked {
+		if p.Spec.NodeName != "" {
+			nodes = append(nodes, p.Spec.NodeName)
+		}
+	}
+	preferNodes(pod, nodes, 100)
+}
+
+// preferNodes adds a preferred Node affinity with the given weight to the
+// given Pod for the given Nodes.
+func preferNodes(pod *corev1.Pod, nodes []string, weight int32) {
+	if len(nodes) == 0 {
+		return
+	}
+	nodes = slices.Clone(nodes)
+	sort.Strings(nodes)
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	na := pod.Spec.Affinity.NodeAffinity
+	na.PreferredDuringSchedulingIgnoredDuringExecution = append(na.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.PreferredSchedulingTerm{
+			Weight: weight,
+			Preference: corev1.NodeSelectorTerm{
+				MatchFields: []corev1.NodeSelectorRequirement{
+					{
+						Key:      metav1.ObjectNameField,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   nodes,
+					},
+				},
+			},
+		},
+	)
+}
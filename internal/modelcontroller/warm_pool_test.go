@@ -0,0 +1,104 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func Test_parkedPodForServerPod(t *testing.T) {
+	r := &ModelReconciler{}
+	r.ModelLoaders.Image = "model-loader-image"
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mdl",
+			Namespace: "test-ns",
+		},
+		Spec: v1.ModelSpec{
+			Engine:            v1.VLLMEngine,
+			Replicas:          ptr.To[int32](0),
+			URL:               "oci://registry.example.com/models/llama:v1",
+			PriorityClassName: "high",
+		},
+	}
+	src, err := r.parseModelSource(model.Spec.URL)
+	require.NoError(t, err)
+	modelConfig := ModelConfig{
+		ResourceProfile: config.ResourceProfile{
+			Limits: corev1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("1"),
+			},
+			NodeSelector: map[string]string{
+				"gpu": "l4",
+			},
+			Tolerations: []corev1.Toleration{
+				{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists},
+			},
+		},
+		Image:  "vllm-image",
+		Source: src,
+	}
+
+	serverPod, err := r.podForModel(model, modelConfig)
+	require.NoError(t, err)
+	parked := r.parkedPodForServerPod(model, serverPod)
+
+	require.Equal(t, map[string]string{
+		v1.ParkedPodModelLabel: model.Name,
+		v1.PodHashLabel:        k8sutils.PodHash(parked.Spec),
+	}, parked.Labels, "parked Pods should not be selected as server Pods")
+	require.Empty(t, parked.Spec.PriorityClassName)
+	require.Equal(t, serverPod.Spec.NodeSelector, parked.Spec.NodeSelector)
+	require.Equal(t, serverPod.Spec.Tolerations, parked.Spec.Tolerations)
+	require.Equal(t, serverPod.Spec.Volumes, parked.Spec.Volumes[:len(serverPod.Spec.Volumes)])
+	require.NotEmpty(t, serverPod.Spec.InitContainers)
+	require.Equal(t, serverPod.Spec.InitContainers, parked.Spec.InitContainers[:len(serverPod.Spec.InitContainers)],
+		"parked Pods should load the model")
+	installer := parked.Spec.InitContainers[len(parked.Spec.InitContainers)-1]
+	require.Equal(t, "model-loader-image", installer.Image)
+	require.Len(t, parked.Spec.Containers, 1)
+	c := parked.Spec.Containers[0]
+	require.Equal(t, "vllm-image", c.Image)
+	require.Equal(t, []string{"/kubeai-pause/pause"}, c.Command)
+	require.Nil(t, c.Args)
+	require.Empty(t, c.Resources.Limits)
+	require.Nil(t, c.ReadinessProbe)
+
+	// The server Pod should not be modified.
+	require.Equal(t, "high", serverPod.Spec.PriorityClassName)
+	require.NotEmpty(t, serverPod.Spec.Containers[0].Resources.Limits)
+}
+
+func Test_preferNodesOfPods(t *testing.T) {
+	pod := &corev1.Pod{}
+	preferNodesOfPods(pod, []corev1.Pod{
+		{Spec: corev1.PodSpec{NodeName: "node-b"}},
+		{Spec: corev1.PodSpec{}},
+		{Spec: corev1.PodSpec{NodeName: "node-a"}},
+	})
+	require.Equal(t, []corev1.PreferredSchedulingTerm{
+		{
+			Weight: 100,
+			Preference: corev1.NodeSelectorTerm{
+				MatchFields: []corev1.NodeSelectorRequirement{
+					{
+						Key:      metav1.ObjectNameField,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"node-a", "node-b"},
+					},
+				},
+			},
+		},
+	}, pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+
+	unscheduled := &corev1.Pod{}
+	preferNodesOfPods(unscheduled, []corev1.Pod{{}})
+	require.Nil(t, unscheduled.Spec.Affinity)
+}
@@ -0,0 +1,477 @@
+package modelcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	kubeaiv1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/k8sutils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// leaderWorkerSetGVK is the kind of the LeaderWorkerSet API (sigs.k8s.io/lws).
+// LeaderWorkerSets are handled as unstructured objects to not depend on the
+// API module of a project that is optionally installed.
+var leaderWorkerSetGVK = schema.GroupVersionKind{
+	Group:   "leaderworkerset.x-k8s.io",
+	Version: "v1",
+	Kind:    "LeaderWorkerSet",
+}
+
+// rayPort is the port of the Ray head of a multi-host LeaderWorkerSet group.
+const rayPort = 6379
+
+var errLeaderWorkerSetNotInstalled = errors.New("the LeaderWorkerSet API is not installed in the cluster")
+
+func newLeaderWorkerSet() *unstructured.Unstructured {
+	lws := &unstructured.Unstructured{}
+	lws.SetGroupVersionKind(leaderWorkerSetGVK)
+	return lws
+}
+
+func workloadKind(model *kubeaiv1.Model) kubeaiv1.ModelWorkloadKind {
+	if model.Spec.Workload.Kind == "" {
+		return kubeaiv1.PodWorkloadKind
+	}
+	return model.Spec.Workload.Kind
+}
+
+// getWorkloadName returns the name of the Deployment or LeaderWorkerSet of a model.
+func getWorkloadName(model *kubeaiv1.Model) string {
+	return fmt.Sprintf("model-%s", model.Name)
+}
+
+// workloadPods returns the server Pods that are managed according to the
+// workload kind of the Model: Pods controlled by the Model itself or Pods
+// created by its Deployment or LeaderWorkerSet. Pods of the other kind are
+// left-overs of a change of the workload kind.
+func workloadPods(model *kubeaiv1.Model, pods []corev1.Pod) []corev1.Pod {
+	bare := workloadKind(model) == kubeaiv1.PodWorkloadKind
+	var filtered []corev1.Pod
+	for _, pod := range pods {
+		if metav1.IsControlledBy(&pod, model) == bare {
+			filtered = append(filtered, pod)
+		}
+	}
+	return filtered
+}
+
+// reconcileWorkload ensures that the Deployment or LeaderWorkerSet of the
+// Model is up to date, deletes Pods that were created by the Model directly
+// and updates the rollout status based on the Pods of the workload.
+// It returns the Pods of the workload that are not terminating.
+func (r *ModelReconciler) reconcileWorkload(ctx context.Context, model *kubeaiv1.Model, modelConfig ModelConfig, pods []corev1.Pod) ([]*corev1.Pod, error) {
+	pod, err := r.podForModel(model, modelConfig)
+	if err != nil {
+		return nil, err
+	}
+	expectedHash := k8sutils.GetLabel(pod, kubeaiv1.PodHashLabel)
+
+	switch workloadKind(model) {
+	case kubeaiv1.DeploymentWorkloadKind:
+		err = r.reconcileDeployment(ctx, model, pod)
+	case kubeaiv1.LeaderWorkerSetWorkloadKind:
+		err = r.reconcileLeaderWorkerSet(ctx, model, pod)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.deleteWorkloadsExcept(ctx, model, workloadKind(model)); err != nil {
+		return nil, err
+	}
+
+	// Pods controlled by the Model were created before the workload kind
+	// was changed, they are replaced by the Pods of the workload as its
+	// replicas become ready.
+	var bare corev1.PodList
+	if err := r.List(ctx, &bare, client.InNamespace(model.Namespace), client.MatchingLabels{
+		kubeaiv1.PodModelLabel: model.Name,
+	}); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	ready, err := r.workloadReadyReplicas(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range bareModelPodsToDelete(model, bare.Items, ptr.Deref(model.Spec.Replicas, 0)-ready) {
+		if err := r.Delete(ctx, p); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("deleting pod %q: %w", p.Name, err)
+		}
+	}
+
+	var outOfDate int
+	var toRemain []*corev1.Pod
+	for i := range pods {
+		p := &pods[i]
+		if k8sutils.GetLabel(p, kubeaiv1.PodHashLabel) != expectedHash {
+			outOfDate++
+		}
+		if p.DeletionTimestamp == nil {
+			toRemain = append(toRemain, p)
+		}
+	}
+	updateRolloutPhase(model, expectedHash, outOfDate, time.Now())
+	setRolloutReplicas(model, pods)
+	setPodConditions(model, pods, outOfDate)
+
+	return toRemain, nil
+}
+
+// workloadReadyReplicas returns the number of ready replicas of the
+// Deployment or LeaderWorkerSet of the Model.
+func (r *ModelReconciler) workloadReadyReplicas(ctx context.Context, model *kubeaiv1.Model) (int32, error) {
+	key := types.NamespacedName{Namespace: model.Namespace, Name: getWorkloadName(model)}
+	switch workloadKind(model) {
+	case kubeaiv1.DeploymentWorkloadKind:
+		deploy := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deploy); err != nil {
+			return 0, client.IgnoreNotFound(err)
+		}
+		return deploy.Status.ReadyReplicas, nil
+	case kubeaiv1.LeaderWorkerSetWorkloadKind:
+		lws := newLeaderWorkerSet()
+		if err := r.Get(ctx, key, lws); err != nil {
+			return 0, client.IgnoreNotFound(err)
+		}
+		ready, _, err := unstructured.NestedInt64(lws.Object, "status", "readyReplicas")
+		if err != nil {
+			return 0, fmt.Errorf("getting ready replicas of leader worker set: %w", err)
+		}
+		return int32(ready), nil
+	}
+	return 0, nil
+}
+
+// bareModelPodsToDelete returns the Pods controlled by the Model that are
+// not needed anymore: all but the given number of Pods, which serve
+// requests until the workload has enough ready replicas. Pods that are not
+// ready are deleted first.
+func bareModelPodsToDelete(model *kubeaiv1.Model, pods []corev1.Pod, keep int32) []*corev1.Pod {
+	var bare []*corev1.Pod
+	for i := range pods {
+		p := &pods[i]
+		if metav1.IsControlledBy(p, model) && p.DeletionTimestamp == nil {
+			bare = append(bare, p)
+		}
+	}
+	sort.SliceStable(bare, func(i, j int) bool {
+		return !k8sutils.PodIsReady(bare[i]) && k8sutils.PodIsReady(bare[j])
+	})
+	keep = max(keep, 0)
+	if int(keep) >= len(bare) {
+		return nil
+	}
+	return bare[:len(bare)-int(keep)]
+}
+
+// deploymentForModel returns the Deployment that creates the given server Pod.
+func (r *ModelReconciler) deploymentForModel(model *kubeaiv1.Model, pod *corev1.Pod) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getWorkloadName(model),
+			Namespace: model.Namespace,
+			Labels:    labelsForModel(model),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(ptr.Deref(model.Spec.Replicas, 0)),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{kubeaiv1.PodModelLabel: model.Name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+			Strategy: r.deploymentStrategy(model),
+		},
+	}
+}
+
+func (r *ModelReconciler) deploymentStrategy(model *kubeaiv1.Model) appsv1.DeploymentStrategy {
+	if model.Spec.Rollout.Strategy == kubeaiv1.RecreateRolloutStrategy {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	maxSurge, maxUnavailable := r.rollingUpdateParams(model)
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       ptr.To(intstr.FromInt32(maxSurge)),
+			MaxUnavailable: ptr.To(intstr.FromInt32(maxUnavailable)),
+		},
+	}
+}
+
+// reconcileDeployment ensures that the Deployment of a model exists and is up to date.
+// The Pod template is only replaced when its hash changed to not fight with the
+// defaulting of the API server.
+func (r *ModelReconciler) reconcileDeployment(ctx context.Context, model *kubeaiv1.Model, pod *corev1.Pod) error {
+	log := log.FromContext(ctx)
+	expected := r.deploymentForModel(model, pod)
+
+	existing := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(expected), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting deployment: %w", err)
+		}
+		if err := ctrl.SetControllerReference(model, expected, r.Scheme); err != nil {
+			return fmt.Errorf("setting controller reference on deployment: %w", err)
+		}
+		if err := r.Create(ctx, expected); err != nil {
+			return fmt.Errorf("creating deployment: %w", err)
+		}
+		log.Info("Created Deployment", "deploymentName", expected.Name)
+		return nil
+	}
+
+	if existing.Spec.Template.Labels[kubeaiv1.PodHashLabel] == k8sutils.GetLabel(pod, kubeaiv1.PodHashLabel) &&
+		ptr.Deref(existing.Spec.Replicas, 0) == *expected.Spec.Replicas &&
+		reflect.DeepEqual(existing.Spec.Strategy, expected.Spec.Strategy) {
+		return nil
+	}
+	existing.Spec.Replicas = expected.Spec.Replicas
+	existing.Spec.Template = expected.Spec.Template
+	existing.Spec.Strategy = expected.Spec.Strategy
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating deployment: %w", err)
+	}
+	log.Info("Updated Deployment", "deploymentName", existing.Name)
+	return nil
+}
+
+// leaderWorkerSetForModel returns the LeaderWorkerSet that creates a group of
+// Pods per replica of the Model. The given server Pod is the leader of a group.
+func (r *ModelReconciler) leaderWorkerSetForModel(model *kubeaiv1.Model, leader *corev1.Pod) (*unstructured.Unstructured, error) {
+	size := max(model.Spec.Workload.LeaderWorkerSet.Size, 1)
+	if size > 1 {
+		leader = leader.DeepCopy()
+		if err := patchVLLMRayLeader(leader); err != nil {
+			return nil, err
+		}
+	}
+	worker := workerPodForLeader(leader, size)
+
+	leaderTemplate, err := toUnstructuredPodTemplate(leader)
+	if err != nil {
+		return nil, err
+	}
+	workerTemplate, err := toUnstructuredPodTemplate(worker)
+	if err != nil {
+		return nil, err
+	}
+
+	rolloutStrategy := map[string]interface{}{"type": "RollingUpdate"}
+	if model.Spec.Rollout.Strategy == kubeaiv1.RecreateRolloutStrategy {
+		// LeaderWorkerSet has no Recreate strategy, replace all groups at once.
+		rolloutStrategy["rollingUpdateConfiguration"] = map[string]interface{}{
+			"maxUnavailable": int64(max(ptr.Deref(model.Spec.Replicas, 0), 1)),
+			"maxSurge":       int64(0),
+		}
+	} else {
+		maxSurge, maxUnavailable := r.rollingUpdateParams(model)
+		rolloutStrategy["rollingUpdateConfiguration"] = map[string]interface{}{
+			"maxUnavailable": int64(maxUnavailable),
+			"maxSurge":       int64(maxSurge),
+		}
+	}
+
+	lws := newLeaderWorkerSet()
+	lws.SetName(getWorkloadName(model))
+	lws.SetNamespace(model.Namespace)
+	lws.SetLabels(labelsForModel(model))
+	lws.Object["spec"] = map[string]interface{}{
+		"replicas":      int64(ptr.Deref(model.Spec.Replicas, 0)),
+		"startupPolicy": "LeaderCreated",
+		"leaderWorkerTemplate": map[string]interface{}{
+			"size":           int64(size),
+			"leaderTemplate": leaderTemplate,
+			"workerTemplate": workerTemplate,
+		},
+		"rolloutStrategy": rolloutStrategy,
+	}
+	return lws, nil
+}
+
+// reconcileLeaderWorkerSet ensures that the LeaderWorkerSet of a model exists and is up to date.
+func (r *ModelReconciler) reconcileLeaderWorkerSet(ctx context.Context, model *kubeaiv1.Model, pod *corev1.Pod) error {
+	log := log.FromContext(ctx)
+	expected, err := r.leaderWorkerSetForModel(model, pod)
+	if err != nil {
+		return err
+	}
+
+	existing := newLeaderWorkerSet()
+	if err := r.Get(ctx, client.ObjectKeyFromObject(expected), existing); err != nil {
+		if meta.IsNoMatchError(err) {
+			return errLeaderWorkerSetNotInstalled
+		}
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting leader worker set: %w", err)
+		}
+		if err := ctrl.SetControllerReference(model, expected, r.Scheme); err != nil {
+			return fmt.Errorf("setting controller reference on leader worker set: %w", err)
+		}
+		if err := r.Create(ctx, expected); err != nil {
+			return fmt.Errorf("creating leader worker set: %w", err)
+		}
+		log.Info("Created LeaderWorkerSet", "leaderWorkerSetName", expected.GetName())
+		return nil
+	}
+
+	if leaderWorkerSetUpToDate(existing, expected) {
+		return nil
+	}
+	existing.Object["spec"] = expected.Object["spec"]
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating leader worker set: %w", err)
+	}
+	log.Info("Updated LeaderWorkerSet", "leaderWorkerSetName", existing.GetName())
+	return nil
+}
+
+func leaderWorkerSetUpToDate(existing, expected *unstructured.Unstructured) bool {
+	for _, fields := range [][]string{
+		{"spec", "replicas"},
+		{"spec", "leaderWorkerTemplate", "size"},
+		{"spec", "leaderWorkerTemplate", "leaderTemplate", "metadata", "labels", kubeaiv1.PodHashLabel},
+		{"spec", "rolloutStrategy", "rollingUpdateConfiguration", "maxUnavailable"},
+		{"spec", "rolloutStrategy", "rollingUpdateConfiguration", "maxSurge"},
+	} {
+		a, _, _ := unstructured.NestedFieldNoCopy(existing.Object, fields...)
+		b, _, _ := unstructured.NestedFieldNoCopy(expected.Object, fields...)
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteWorkloadsExcept deletes the Deployment and LeaderWorkerSet of a model
+// unless they are of the given workload kind.
+func (r *ModelReconciler) deleteWorkloadsExcept(ctx context.Context, model *kubeaiv1.Model, kind kubeaiv1.ModelWorkloadKind) error {
+	log := log.FromContext(ctx)
+	key := client.ObjectKey{Namespace: model.Namespace, Name: getWorkloadName(model)}
+	if kind != kubeaiv1.DeploymentWorkloadKind {
+		// Deployments are cached, avoid a request to the API server for
+		// every reconcile of a Model that never used a Deployment.
+		deploy := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deploy); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("getting deployment: %w", err)
+			}
+		} else if metav1.IsControlledBy(deploy, model) {
+			if err := r.Delete(ctx, deploy); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("deleting deployment: %w", err)
+			}
+			log.Info("Deleted Deployment", "deploymentName", key.Name)
+		}
+	}
+	if kind != kubeaiv1.LeaderWorkerSetWorkloadKind && r.leaderWorkerSetInstalled {
+		lws := newLeaderWorkerSet()
+		lws.SetName(key.Name)
+		lws.SetNamespace(key.Namespace)
+		if err := r.Delete(ctx, lws); err != nil {
+			if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				return fmt.Errorf("deleting leader worker set: %w", err)
+			}
+		} else {
+			log.Info("Deleted LeaderWorkerSet", "leaderWorkerSetName", key.Name)
+		}
+	}
+	return nil
+}
+
+// workerPodForLeader returns the Pod of the workers of a LeaderWorkerSet group.
+// Workers do not serve requests and are therefore not labeled with the Model.
+// Workers of a multi-host group join the Ray cluster of the leader.
+func workerPodForLeader(leader *corev1.Pod, size int32) *corev1.Pod {
+	worker := leader.DeepCopy()
+	delete(worker.Labels, kubeaiv1.PodModelLabel)
+	delete(worker.Annotations, kubeaiv1.ModelPodPortAnnotation)
+	delete(worker.Annotations, kubeaiv1.ModelPodIPAnnotation)
+	if size == 1 {
+		return worker
+	}
+	server := findContainer(worker.Spec.Containers, serverContainerName)
+	server.Command = []string{"bash", "-c", fmt.Sprintf("exec ray start --address=$LWS_LEADER_ADDRESS:%d --block", rayPort)}
+	server.Args = nil
+	server.Ports = nil
+	server.StartupProbe = nil
+	server.ReadinessProbe = nil
+	server.LivenessProbe = nil
+	// Sidecars of the leader (i.e. the adapter loader) are not needed.
+	worker.Spec.Containers = []corev1.Container{*server}
+	return worker
+}
+
+// patchVLLMRayLeader starts a Ray head in the server container of a vLLM
+// leader Pod and starts vLLM once all workers of the group joined the Ray cluster.
+func patchVLLMRayLeader(leader *corev1.Pod) error {
+	server := findContainer(leader.Spec.Containers, serverContainerName)
+	if server == nil {
+		return fmt.Errorf("server container not found")
+	}
+	// The Pod is passed through a bash script: "$@" are the args of the container.
+	server.Command = []string{"bash", "-c", fmt.Sprintf(vllmRayLeaderScript, rayPort), "vllm"}
+	server.Args = append(server.Args, "--distributed-executor-backend=ray")
+	return nil
+}
+
+const vllmRayLeaderScript = `ray start --head --port=%d
+until [ "$(python3 -c 'import ray; ray.init(address="auto", logging_level="error"); print(sum(n["Alive"] for n in ray.nodes()))' 2>/dev/null)" = "$LWS_GROUP_SIZE" ]; do
+  echo "Waiting for $LWS_GROUP_SIZE Ray nodes"
+  sleep 5
+done
+exec python3 -m vllm.entrypoints.openai.api_server "$@"
+`
+
+func toUnstructuredPodTemplate(pod *corev1.Pod) (map[string]interface{}, error) {
+	tmpl := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      pod.Labels,
+			Annotations: pod.Annotations,
+		},
+		Spec: pod.Spec,
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("converting pod template: %w", err)
+	}
+	// Drop empty fields (i.e. "creationTimestamp: null") that the API server does not persist.
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	return u, nil
+}
+
+// modelForWorkloadPod returns a reconcile request for the Model of a Pod that
+// was created by a Deployment or LeaderWorkerSet of the Model. The Pods of the
+// workload are not owned by the Model, changes to their readiness are needed
+// for the status and adapter reconciliation.
+func (r *ModelReconciler) modelForWorkloadPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[kubeaiv1.PodModelLabel]
+	if name == "" {
+		return nil
+	}
+	if ref := metav1.GetControllerOf(obj); ref == nil || ref.Kind == "Model" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: obj.GetNamespace(), Name: name}}}
+}
@@ -0,0 +1,188 @@
+package modelcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func Test_workloadPods(t *testing.T) {
+	model := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: types.UID("test-uid")}}
+	bare := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bare", OwnerReferences: []metav1.OwnerReference{
+		{Kind: "Model", Name: model.Name, UID: model.UID, Controller: ptr.To(true)},
+	}}}
+	managed := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "managed", OwnerReferences: []metav1.OwnerReference{
+		{Kind: "ReplicaSet", Name: "model-test-mdl-abc", UID: types.UID("rs-uid"), Controller: ptr.To(true)},
+	}}}
+	pods := []corev1.Pod{bare, managed}
+
+	require.Equal(t, []corev1.Pod{bare}, workloadPods(model, pods))
+	model.Spec.Workload.Kind = v1.DeploymentWorkloadKind
+	require.Equal(t, []corev1.Pod{managed}, workloadPods(model, pods))
+}
+
+func Test_bareModelPodsToDelete(t *testing.T) {
+	model := &v1.Model{ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", UID: types.UID("test-uid")}}
+	bare := func(name string, ready bool) corev1.Pod {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Model", Name: model.Name, UID: model.UID, Controller: ptr.To(true)},
+		}}}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+	terminating := bare("terminating", true)
+	terminating.DeletionTimestamp = ptr.To(metav1.Now())
+	managed := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "managed", OwnerReferences: []metav1.OwnerReference{
+		{Kind: "ReplicaSet", Name: "model-test-mdl-abc", UID: types.UID("rs-uid"), Controller: ptr.To(true)},
+	}}}
+	pods := []corev1.Pod{bare("ready-1", true), bare("not-ready", false), bare("ready-2", true), terminating, managed}
+
+	names := func(keep int32) []string {
+		var names []string
+		for _, p := range bareModelPodsToDelete(model, pods, keep) {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	// No workload replica is ready yet.
+	require.Empty(t, names(3))
+	require.Empty(t, names(4))
+	// Pods that are not ready are deleted first.
+	require.Equal(t, []string{"not-ready"}, names(2))
+	require.Equal(t, []string{"not-ready", "ready-1"}, names(1))
+	require.Equal(t, []string{"not-ready", "ready-1", "ready-2"}, names(0))
+	require.Equal(t, []string{"not-ready", "ready-1", "ready-2"}, names(-1))
+}
+
+func Test_deploymentForModel(t *testing.T) {
+	r := &ModelReconciler{}
+	r.ModelRollouts = config.ModelRollouts{Surge: 1}
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns"},
+		Spec: v1.ModelSpec{
+			Engine:   v1.VLLMEngine,
+			Replicas: ptr.To[int32](3),
+			Workload: v1.ModelWorkload{Kind: v1.DeploymentWorkloadKind},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{v1.PodModelLabel: model.Name, v1.PodHashLabel: "test-hash"},
+			Annotations: map[string]string{v1.ModelPodPortAnnotation: "8000"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: serverContainerName}}},
+	}
+
+	deploy := r.deploymentForModel(model, pod)
+	require.Equal(t, "model-test-mdl", deploy.Name)
+	require.Equal(t, int32(3), *deploy.Spec.Replicas)
+	require.Equal(t, map[string]string{v1.PodModelLabel: model.Name}, deploy.Spec.Selector.MatchLabels)
+	require.Equal(t, pod.Labels, deploy.Spec.Template.Labels)
+	require.Equal(t, pod.Annotations, deploy.Spec.Template.Annotations)
+	require.Equal(t, pod.Spec, deploy.Spec.Template.Spec)
+	require.Equal(t, appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       ptr.To(intstr.FromInt32(1)),
+			MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+		},
+	}, deploy.Spec.Strategy)
+
+	model.Spec.Rollout.Strategy = v1.RecreateRolloutStrategy
+	require.Equal(t, appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}, r.deploymentForModel(model, pod).Spec.Strategy)
+}
+
+func Test_leaderWorkerSetForModel(t *testing.T) {
+	r := &ModelReconciler{}
+	model := &v1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mdl", Namespace: "test-ns"},
+		Spec: v1.ModelSpec{
+			Engine:   v1.VLLMEngine,
+			Replicas: ptr.To[int32](2),
+			Workload: v1.ModelWorkload{
+				Kind:            v1.LeaderWorkerSetWorkloadKind,
+				LeaderWorkerSet: v1.ModelWorkloadLeaderWorkerSet{Size: 4},
+			},
+		},
+	}
+	leader := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{v1.PodModelLabel: model.Name, v1.PodHashLabel: "test-hash"},
+			Annotations: map[string]string{v1.ModelPodPortAnnotation: "8000"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{
+				Name:           serverContainerName,
+				Args:           []string{"--model=test-org/test-model", "--tensor-parallel-size=8"},
+				Ports:          []corev1.ContainerPort{{ContainerPort: 8000, Name: "http"}},
+				ReadinessProbe: &corev1.Probe{},
+			},
+			{Name: "adapter-loader"},
+		}},
+	}
+
+	lws, err := r.leaderWorkerSetForModel(model, leader)
+	require.NoError(t, err)
+	require.Equal(t, leaderWorkerSetGVK, lws.GroupVersionKind())
+	require.Equal(t, "model-test-mdl", lws.GetName())
+
+	replicas, _, _ := unstructured.NestedInt64(lws.Object, "spec", "replicas")
+	require.Equal(t, int64(2), replicas)
+	size, _, _ := unstructured.NestedInt64(lws.Object, "spec", "leaderWorkerTemplate", "size")
+	require.Equal(t, int64(4), size)
+
+	templates, _, _ := unstructured.NestedMap(lws.Object, "spec", "leaderWorkerTemplate")
+	var leaderTmpl, workerTmpl corev1.PodTemplateSpec
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(templates["leaderTemplate"].(map[string]interface{}), &leaderTmpl))
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(templates["workerTemplate"].(map[string]interface{}), &workerTmpl))
+
+	require.Equal(t, model.Name, leaderTmpl.Labels[v1.PodModelLabel])
+	leaderServer := leaderTmpl.Spec.Containers[0]
+	require.Equal(t, "bash", leaderServer.Command[0])
+	require.Contains(t, leaderServer.Command[2], "ray start --head --port=6379")
+	require.Equal(t, []string{"--model=test-org/test-model", "--tensor-parallel-size=8", "--distributed-executor-backend=ray"}, leaderServer.Args)
+	require.Len(t, leaderTmpl.Spec.Containers, 2)
+
+	require.NotContains(t, workerTmpl.Labels, v1.PodModelLabel)
+	require.NotContains(t, workerTmpl.Annotations, v1.ModelPodPortAnnotation)
+	require.Equal(t, "test-hash", workerTmpl.Labels[v1.PodHashLabel])
+	require.Len(t, workerTmpl.Spec.Containers, 1)
+	workerServer := workerTmpl.Spec.Containers[0]
+	require.Equal(t, []string{"bash", "-c", "exec ray start --address=$LWS_LEADER_ADDRESS:6379 --block"}, workerServer.Command)
+	require.Nil(t, workerServer.Args)
+	require.Nil(t, workerServer.Ports)
+	require.Nil(t, workerServer.ReadinessProbe)
+
+	// The original server Pod is not modified.
+	require.Nil(t, leader.Spec.Containers[0].Command)
+
+	t.Run("single pod groups", func(t *testing.T) {
+		model := model.DeepCopy()
+		model.Spec.Workload.LeaderWorkerSet.Size = 1
+		lws, err := r.leaderWorkerSetForModel(model, leader)
+		require.NoError(t, err)
+		templates, _, _ := unstructured.NestedMap(lws.Object, "spec", "leaderWorkerTemplate")
+		var leaderTmpl corev1.PodTemplateSpec
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(templates["leaderTemplate"].(map[string]interface{}), &leaderTmpl))
+		require.Equal(t, leader.Spec, leaderTmpl.Spec)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		existing := lws.DeepCopy()
+		require.True(t, leaderWorkerSetUpToDate(existing, lws))
+		require.NoError(t, unstructured.SetNestedField(existing.Object, int64(1), "spec", "replicas"))
+		require.False(t, leaderWorkerSetUpToDate(existing, lws))
+	})
+}
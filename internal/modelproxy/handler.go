@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/apiutils"
@@ -17,11 +18,12 @@ import (
 
 type ModelClient interface {
 	LookupModel(ctx context.Context, model, adapter string, selectors []string) (*v1.Model, error)
-	ScaleAtLeastOneReplica(ctx context.Context, model string) error
+	ScaleAtLeastOneReplica(ctx context.Context, model string) (bool, error)
 }
 
 type LoadBalancer interface {
 	AwaitBestAddress(ctx context.Context, req *apiutils.Request) (string, func(), error)
+	RecordResponse(model, addr string, failed bool)
 }
 
 // Handler serves http requests for end-clients.
@@ -81,10 +83,15 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer metrics.InferenceRequestsActive.Add(pr.http.Context(), -1, metricAttrs)
 
 	// Ensure the backend is scaled to at least one Pod.
-	if err := h.modelClient.ScaleAtLeastOneReplica(r.Context(), pr.Model); err != nil {
+	start := time.Now()
+	coldStart, err := h.modelClient.ScaleAtLeastOneReplica(r.Context(), pr.Model)
+	if err != nil {
 		pr.sendErrorResponse(w, http.StatusInternalServerError, "unable to scale model: %v", err)
 		return
 	}
+	if coldStart {
+		pr.coldStartedAt = start
+	}
 
 	h.proxyHTTP(w, pr)
 }
@@ -113,6 +120,12 @@ func (h *Handler) proxyHTTP(w http.ResponseWriter, pr *proxyRequest) {
 	// NOTE: decrementInflight will be called after the request succeeds or fails after all retries.
 	defer decrementInflight()
 
+	if !pr.coldStartedAt.IsZero() {
+		metrics.InferenceRequestsColdStartDuration.Record(pr.http.Context(), time.Since(pr.coldStartedAt).Seconds(),
+			metric.WithAttributeSet(attribute.NewSet(metrics.AttrRequestModel.String(pr.Model))))
+		pr.coldStartedAt = time.Time{}
+	}
+
 	proxy := &httputil.ReverseProxy{
 		Rewrite: func(r *httputil.ProxyRequest) {
 			r.SetURL(&url.URL{
@@ -127,6 +140,7 @@ func (h *Handler) proxyHTTP(w http.ResponseWriter, pr *proxyRequest) {
 	proxy.ModifyResponse = func(r *http.Response) error {
 		// Record the response for metrics.
 		pr.status = r.StatusCode
+		h.loadBalancer.RecordResponse(pr.Model, addr, r.StatusCode >= http.StatusInternalServerError)
 
 		// This point is reached if a response code is received.
 		if h.isRetryCode(r.StatusCode) && pr.attempt < h.maxRetries {
@@ -141,6 +155,9 @@ func (h *Handler) proxyHTTP(w http.ResponseWriter, pr *proxyRequest) {
 		// This point could be reached if a bad response code was sent by the backend
 		// or
 		// if there was an issue with the connection and no response was ever received.
+		if err != nil && !errors.Is(err, ErrRetry) && r.Context().Err() == nil {
+			h.loadBalancer.RecordResponse(pr.Model, addr, true)
+		}
 		if err != nil && r.Context().Err() == nil && pr.attempt < h.maxRetries {
 			pr.attempt++
 
@@ -294,10 +294,12 @@ func (t *testModelInterface) LookupModel(ctx context.Context, model, adapter str
 	return nil, nil
 }
 
-func (t *testModelInterface) ScaleAtLeastOneReplica(ctx context.Context, model string) error {
-	return nil
+func (t *testModelInterface) ScaleAtLeastOneReplica(ctx context.Context, model string) (bool, error) {
+	return false, nil
 }
 
+func (t *testModelInterface) RecordResponse(model, addr string, failed bool) {}
+
 func (t *testModelInterface) AwaitBestAddress(ctx context.Context, req *apiutils.Request) (string, func(), error) {
 	t.hostRequestCount++
 	t.requestedModel = req.Model
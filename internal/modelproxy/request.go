@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/substratusai/kubeai/internal/apiutils"
 )
@@ -20,6 +21,9 @@ type proxyRequest struct {
 	http    *http.Request
 	status  int
 	attempt int
+	// coldStartedAt is set when the request arrived while the Model
+	// had no ready replicas. It is cleared once the cold start is recorded.
+	coldStartedAt time.Time
 }
 
 func (h *Handler) parseProxyRequest(r *http.Request) (*proxyRequest, error) {
@@ -38,10 +38,23 @@ func (a *Simple) Next(next float64) {
 	a.mtx.Unlock()
 }
 
+// Reset sets all measurements in the history to zero.
+func (a *Simple) Reset() {
+	a.mtx.Lock()
+	for i := range a.history {
+		a.history[i] = 0
+	}
+	a.index = 0
+	a.mtx.Unlock()
+}
+
+// History returns the measurements ordered from oldest to newest.
+// Passing the result to NewSimple restores the moving average.
 func (a *Simple) History() []float64 {
 	a.mtx.Lock()
-	result := make([]float64, len(a.history))
-	copy(result, a.history)
+	result := make([]float64, 0, len(a.history))
+	result = append(result, a.history[a.index:]...)
+	result = append(result, a.history[:a.index]...)
 	a.mtx.Unlock()
 
 	return result
@@ -57,3 +57,36 @@ func TestSimple(t *testing.T) {
 		})
 	}
 }
+
+func TestSimpleReset(t *testing.T) {
+	a := movingaverage.NewSimple([]float64{3, 3, 3})
+	a.Reset()
+	if got := a.Calculate(); got != 0 {
+		t.Errorf("got %v after reset; want 0", got)
+	}
+	a.Next(3)
+	if got := a.Calculate(); got != 1 {
+		t.Errorf("got %v; want 1", got)
+	}
+}
+
+func TestSimpleHistory(t *testing.T) {
+	a := movingaverage.NewSimple([]float64{1, 2, 3})
+	a.Next(4)
+	want := []float64{2, 3, 4}
+	got := a.History()
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	restored := movingaverage.NewSimple(got)
+	restored.Next(5)
+	if got := restored.Calculate(); got != 4 {
+		t.Errorf("got %v after restoring; want 4", got)
+	}
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestAutoscalerDryRun tests that the decisions of the autoscaler are
+// recorded but not applied for Models in dry-run mode.
+func TestAutoscalerDryRun(t *testing.T) {
+	m := modelForTest(t)
+	m.Spec.Replicas = ptr.To[int32](1)
+	m.Spec.MaxReplicas = ptr.To[int32](100)
+	m.Spec.TargetRequests = ptr.To[int32](1)
+	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](2)
+	m.Spec.AutoscalingDryRun = true
+
+	sysCfg := baseSysCfg(t)
+	sysCfg.ModelAutoscaling.TimeWindow = config.Duration{Duration: 1 * time.Second}
+	sysCfg.ModelAutoscaling.Interval = config.Duration{Duration: time.Second / 4}
+	initTest(t, sysCfg)
+
+	r := newTestLoadReporter(t, "replica-1", m.Name)
+	r.activeRequests.Store(3)
+
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	state := &v1.ModelAutoscalerState{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), state)) {
+			return
+		}
+		if assert.NotNil(t, state.Status.DryRunReplicas) {
+			assert.Equal(t, int32(3), *state.Status.DryRunReplicas)
+		}
+	}, 15*time.Second, time.Second/2, "Dry run decision should be recorded")
+
+	requireModelReplicas(t, m, 1, "Replicas should not be changed in dry-run mode", time.Second)
+
+	// Disabling dry-run applies the decisions.
+	updateModel(t, m, func() { m.Spec.AutoscalingDryRun = false }, "AutoscalingDryRun=false")
+	requireModelReplicas(t, m, 3, "Replicas should be autoscaled", 15*time.Second)
+}
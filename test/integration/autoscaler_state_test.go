@@ -1,71 +1,101 @@
 package integration
 
 import (
-	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
 	"github.com/substratusai/kubeai/internal/config"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// TestAutoscalerState tests the autoscaler's state management.
+// TestAutoscalerState tests that the autoscaler resumes from the state
+// persisted in ModelAutoscalerState objects and keeps it up to date.
 func TestAutoscalerState(t *testing.T) {
 	m := modelForTest(t)
 	m.Spec.MaxReplicas = ptr.To[int32](100)
 	m.Spec.TargetRequests = ptr.To[int32](1)
-	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](2)
+	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](60)
+
+	sysCfg := baseSysCfg(t)
+	sysCfg.ModelAutoscaling.TimeWindow = config.Duration{Duration: 10 * time.Second}
+	sysCfg.ModelAutoscaling.Interval = config.Duration{Duration: time.Second / 4}
+	windowCount := sysCfg.ModelAutoscaling.AverageWindowCount()
+
+	// Create the Model object in the Kubernetes cluster.
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	// Persist state as if a previous leader had seen 3 active requests
+	// for the whole time window.
+	state := &v1.ModelAutoscalerState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.Name,
+			Namespace: m.Namespace,
+		},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, state))
+	state.Status.ActiveRequestsHistory = make([]int64, windowCount)
+	for i := range state.Status.ActiveRequestsHistory {
+		state.Status.ActiveRequestsHistory[i] = 3
+	}
+	state.Status.Leader = "previous-leader"
+	require.NoError(t, testK8sClient.Status().Update(testCtx, state))
+
+	initTest(t, sysCfg)
 
-	ms := newTestMetricsServer(t, m.Name)
+	// No active requests are reported, the Model is only scaled up
+	// because of the persisted history.
+	newTestLoadReporter(t, "replica-1", m.Name)
+
+	requireModelReplicas(t, m, 3, "Replicas should be autoscaled from the persisted state", 15*time.Second)
+
+	// Assert that the state is updated by the new leader.
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(state), state)) {
+			return
+		}
+		assert.NotEqual(t, "previous-leader", state.Status.Leader)
+		assert.NotNil(t, state.Status.LastCalculationTime)
+		if assert.Len(t, state.Status.ActiveRequestsHistory, windowCount) {
+			assert.Equal(t, int64(0), state.Status.ActiveRequestsHistory[windowCount-1], "Newest measurement should be last")
+		}
+		assert.Positive(t, state.Status.ConsecutiveScaleDowns)
+	}, 15*time.Second, time.Second/2)
+}
+
+// TestAutoscalerStateCreated tests that a ModelAutoscalerState object
+// is created for an autoscaled Model.
+func TestAutoscalerStateCreated(t *testing.T) {
+	m := modelForTest(t)
+	m.Spec.MaxReplicas = ptr.To[int32](100)
+	m.Spec.TargetRequests = ptr.To[int32](1)
+	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](2)
 
 	sysCfg := baseSysCfg(t)
 	sysCfg.ModelAutoscaling.TimeWindow = config.Duration{Duration: 1 * time.Second}
 	sysCfg.ModelAutoscaling.Interval = config.Duration{Duration: time.Second / 4}
-	sysCfg.FixedSelfMetricAddrs = []string{
-		ms.addr(),
-	}
-	t.Logf("FixedSelfMetricAddrs: %v", sysCfg.FixedSelfMetricAddrs)
 	initTest(t, sysCfg)
 
-	ms.activeRequests.Store(2)
+	r := newTestLoadReporter(t, "replica-1", m.Name)
+	r.activeRequests.Store(2)
 
-	// Create the Model object in the Kubernetes cluster.
 	require.NoError(t, testK8sClient.Create(testCtx, m))
 
 	requireModelReplicas(t, m, 2, "Replicas should be autoscaled", 15*time.Second)
 
-	// Assert that state was saved.
-	cm := &corev1.ConfigMap{}
+	state := &v1.ModelAutoscalerState{}
 	require.EventuallyWithT(t, func(t *assert.CollectT) {
-		err := testK8sClient.Get(testCtx, types.NamespacedName{Name: sysCfg.ModelAutoscaling.StateConfigMapName, Namespace: testNS}, cm)
-		if !assert.NoError(t, err) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), state)) {
 			return
 		}
-		if !assert.NotNil(t, cm.Data) {
-			return
-		}
-		data, ok := cm.Data["models"]
-		if !assert.True(t, ok) {
-			return
-		}
-		type modelState struct {
-			AverageActiveRequests float64 `json:"averageActiveRequests"`
-		}
-		var state struct {
-			Models map[string]modelState `json:"models"`
-		}
-		if !assert.NoError(t, json.Unmarshal([]byte(data), &state)) {
-			return
-		}
-		if !assert.NotNil(t, state.Models) {
-			return
+		assert.Equal(t, []int64{2, 2, 2, 2}, state.Status.ActiveRequestsHistory)
+		if assert.Len(t, state.OwnerReferences, 1) {
+			assert.Equal(t, m.Name, state.OwnerReferences[0].Name)
+			assert.Equal(t, "Model", state.OwnerReferences[0].Kind)
 		}
-		assert.Equal(t, state.Models[m.Name], modelState{
-			AverageActiveRequests: 2.0,
-		})
-	}, 15*time.Second, 1*time.Second)
+	}, 15*time.Second, time.Second/2)
 }
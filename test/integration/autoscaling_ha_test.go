@@ -1,44 +1,39 @@
 package integration
 
 import (
-	"fmt"
+	"bytes"
+	"encoding/json"
 	"net/http"
-	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/substratusai/kubeai/internal/config"
+	"github.com/substratusai/kubeai/internal/loadreport"
 	"k8s.io/utils/ptr"
 )
 
 // TestAutoscalingHA tests autoscaling when there are multiple KubeAI instances.
-// Metrics are mocked using test servers.
+// Other instances are mocked using test reporters that push load reports to the leader.
 func TestAutoscalingHA(t *testing.T) {
 	m := modelForTest(t)
 	m.Spec.MaxReplicas = ptr.To[int32](100)
 	m.Spec.TargetRequests = ptr.To[int32](1)
 	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](2)
 
-	s1 := newTestMetricsServer(t, m.Name)
-	s2 := newTestMetricsServer(t, m.Name)
-	s3 := newTestMetricsServer(t, m.Name)
-
 	sysCfg := baseSysCfg(t)
 	sysCfg.ModelAutoscaling.TimeWindow = config.Duration{Duration: 1 * time.Second}
 	sysCfg.ModelAutoscaling.Interval = config.Duration{Duration: time.Second / 4}
-	sysCfg.FixedSelfMetricAddrs = []string{
-		s1.addr(),
-		s2.addr(),
-		s3.addr(),
-	}
-	t.Logf("FixedSelfMetricAddrs: %v", sysCfg.FixedSelfMetricAddrs)
 	initTest(t, sysCfg)
 
-	s1.activeRequests.Store(1)
-	s2.activeRequests.Store(2)
-	s3.activeRequests.Store(3)
+	r1 := newTestLoadReporter(t, "replica-1", m.Name)
+	r2 := newTestLoadReporter(t, "replica-2", m.Name)
+	r3 := newTestLoadReporter(t, "replica-3", m.Name)
+
+	r1.activeRequests.Store(1)
+	r2.activeRequests.Store(2)
+	r3.activeRequests.Store(3)
 
 	// Create the Model object in the Kubernetes cluster.
 	require.NoError(t, testK8sClient.Create(testCtx, m))
@@ -46,45 +41,81 @@ func TestAutoscalingHA(t *testing.T) {
 	// 1 + 2 + 3 = 6
 	requireModelReplicas(t, m, 6, "Replicas should be autoscaled", 15*time.Second)
 
-	s1.activeRequests.Store(0)
-	s2.activeRequests.Store(0)
-	s3.activeRequests.Store(0)
+	// Replica churn: a replica that stops reporting should no longer count.
+	r3.stop()
+	requireModelReplicas(t, m, 3, "Replicas should be autoscaled after a replica stopped reporting", 15*time.Second)
+
+	r1.activeRequests.Store(0)
+	r2.activeRequests.Store(0)
 
 	requireModelReplicas(t, m, 0, "Replicas should be autoscaled to zero", 15*time.Second)
 }
 
-func newTestMetricsServer(t *testing.T, model string) *testMetricsServer {
-	s := &testMetricsServer{
+// newTestLoadReporter starts pushing load reports to the KubeAI instance under test
+// as if they were sent by another KubeAI replica.
+func newTestLoadReporter(t *testing.T, replica, model string) *testLoadReporter {
+	r := &testLoadReporter{
 		t:              t,
+		replica:        replica,
 		model:          model,
 		activeRequests: &atomic.Int64{},
+		done:           make(chan struct{}),
+		exited:         make(chan struct{}),
 	}
-	s.Server = httptest.NewServer(s)
-	t.Cleanup(s.Close)
-	return s
+	go r.run()
+	t.Cleanup(r.stop)
+	return r
 }
 
-type testMetricsServer struct {
-	t *testing.T
-	*httptest.Server
+type testLoadReporter struct {
+	t              *testing.T
+	replica        string
 	model          string
 	activeRequests *atomic.Int64
+	done           chan struct{}
+	exited         chan struct{}
+	stopped        atomic.Bool
 }
 
-func (s *testMetricsServer) addr() string {
-	return s.Server.Listener.Addr().String()
+func (r *testLoadReporter) stop() {
+	if r.stopped.CompareAndSwap(false, true) {
+		close(r.done)
+	}
+	// The test must not be failed after it completed.
+	<-r.exited
 }
 
-func (s *testMetricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	require.Equal(s.t, "GET", r.Method)
-	require.Equal(s.t, "/metrics", r.URL.Path)
-
-	const format = `
-# HELP kubeai_inference_requests_active The number of active requests by model
-# TYPE kubeai_inference_requests_active gauge
-kubeai_inference_requests_active{otel_scope_name="kubeai.org",otel_scope_version="",request_model="%s",request_type="http"} %d
-`
-	promMetrics := fmt.Sprintf(format, s.model, s.activeRequests.Load())
-	_, err := w.Write([]byte(promMetrics))
-	require.NoError(s.t, err)
+func (r *testLoadReporter) run() {
+	defer close(r.exited)
+	ticker := time.NewTicker(time.Second / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+		body, err := json.Marshal(loadreport.Report{
+			Replica:   r.replica,
+			Timestamp: time.Now(),
+			Models: map[string]loadreport.ModelLoad{
+				r.model: {Active: r.activeRequests.Load()},
+			},
+		})
+		if err != nil {
+			r.t.Errorf("Marshalling load report: %v", err)
+			return
+		}
+		// The manager under test serves load reports on the metrics address.
+		resp, err := http.Post("http://127.0.0.1:8080"+loadreport.Path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			// The manager might not be listening yet.
+			r.t.Logf("Sending load report: %v", err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			r.t.Errorf("Load report of replica %q was rejected: %s", r.replica, resp.Status)
+		}
+	}
 }
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestCacheDedicatedVolume tests that a Model with a dedicatedVolume cache
+// profile is loaded into its own PVC sized after the download size of the
+// model and that the PVC is cloned into a ReadOnlyMany PVC for serving.
+func TestCacheDedicatedVolume(t *testing.T) {
+	const cacheProfileName = "my-dedicated-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			DedicatedVolume: &config.CacheDedicatedVolume{
+				StorageClassName: "my-storage-class",
+				ReadOnlyMany: &config.CacheReadOnlyMany{
+					StorageClassName: "my-rox-storage-class",
+				},
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.CacheProfile = cacheProfileName
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	// Assert that the size Job is created.
+	sizeJob := &batchv1.Job{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: m.Namespace,
+			Name:      fmt.Sprintf("size-cache-%s", m.Name),
+		}, sizeJob))
+	}, 5*time.Second, time.Second/10, "Size Job should be created")
+	require.Equal(t, []string{"/bin/size"}, sizeJob.Spec.Template.Spec.Containers[0].Command)
+	require.Equal(t, []string{m.Spec.URL}, sizeJob.Spec.Template.Spec.Containers[0].Args)
+
+	// There is no Job controller in the test environment,
+	// create the Pod of the Job reporting the download size.
+	sizePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sizeJob.Name + "-abc",
+			Namespace: testNS,
+			Labels:    map[string]string{batchv1.JobNameLabel: sizeJob.Name},
+		},
+		Spec: sizeJob.Spec.Template.Spec,
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, sizePod))
+	sizePod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: "sizer",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 0,
+			// 20 GiB
+			Message: "21474836480",
+		}},
+	}}
+	require.NoError(t, testK8sClient.Status().Update(testCtx, sizePod))
+	requireUpdateJobAsCompleted(t, sizeJob)
+
+	// Assert that the dedicated PVC is created with the measured size.
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: m.Namespace,
+			Name:      fmt.Sprintf("model-cache-%s-%s", m.Name, m.UID[0:7]),
+		}, pvc))
+	}, 5*time.Second, time.Second/10, "PVC should be created")
+	require.Equal(t, ptr.To("my-storage-class"), pvc.Spec.StorageClassName)
+	require.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, pvc.Spec.AccessModes)
+	require.Equal(t, resource.MustParse("22Gi"), pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+	require.True(t, metav1.IsControlledBy(pvc, m), "PVC should be controlled by the Model")
+
+	// Assert that the model loader Job is created and complete it.
+	loaderJob := &batchv1.Job{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: m.Namespace,
+			Name:      fmt.Sprintf("load-cache-%s", m.Name),
+		}, loaderJob))
+	}, 5*time.Second, time.Second/10, "Loader Job should be created")
+	requireUpdateJobAsCompleted(t, loaderJob)
+
+	// Assert that the ReadOnlyMany clone is created.
+	roxPVC := &corev1.PersistentVolumeClaim{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: m.Namespace,
+			Name:      pvc.Name + "-ro",
+		}, roxPVC))
+	}, 5*time.Second, time.Second/10, "ReadOnlyMany PVC should be created")
+	require.Equal(t, ptr.To("my-rox-storage-class"), roxPVC.Spec.StorageClassName)
+	require.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}, roxPVC.Spec.AccessModes)
+	require.Equal(t, &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: pvc.Name}, roxPVC.Spec.DataSource)
+	require.Equal(t, pvc.Spec.Resources.Requests, roxPVC.Spec.Resources.Requests)
+	require.True(t, metav1.IsControlledBy(roxPVC, m), "ReadOnlyMany PVC should be controlled by the Model")
+
+	// Assert that the Model is loaded without a cache eviction finalizer.
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if !assert.NotNil(t, m.Status.Cache) {
+			return
+		}
+		assert.True(t, m.Status.Cache.Loaded)
+		assert.NotContains(t, m.Finalizers, v1.ModelCacheEvictionFinalizer)
+	}, 10*time.Second, time.Second/10, "Model status should be updated")
+
+	// Assert that the model server Pod mounts the ReadOnlyMany PVC.
+	podList := &corev1.PodList{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name})) {
+			return
+		}
+		assert.Len(t, podList.Items, 1)
+	}, 15*time.Second, time.Second/10, "Model Pods should be created")
+	var vol *corev1.PersistentVolumeClaimVolumeSource
+	for _, v := range podList.Items[0].Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			vol = v.PersistentVolumeClaim
+		}
+	}
+	require.Equal(t, &corev1.PersistentVolumeClaimVolumeSource{ClaimName: roxPVC.Name, ReadOnly: true}, vol)
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestCacheLoadProgress tests that the failures of the loader are reported
+// on the Model.
+func TestCacheLoadProgress(t *testing.T) {
+	const cacheProfileName = "my-progress-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			SharedFilesystem: &config.CacheSharedFilesystem{
+				StorageClassName: "my-storage-class",
+			},
+		},
+	}
+	sysCfg.ModelLoading.Parallelism = 8
+	sysCfg.ModelLoading.BackoffLimit = ptr.To[int32](2)
+	sysCfg.ModelLoading.ServiceAccountName = "model-loader"
+	initTest(t, sysCfg)
+
+	m := modelForTest(t)
+	m.Spec.CacheProfile = cacheProfileName
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	job := requireCacheEntryLoadJob(t, m)
+	require.Equal(t, ptr.To[int32](2), job.Spec.BackoffLimit)
+	require.Equal(t, "model-loader", job.Spec.Template.Spec.ServiceAccountName)
+	loader := job.Spec.Template.Spec.Containers[0]
+	require.Equal(t, corev1.TerminationMessageFallbackToLogsOnError, loader.TerminationMessagePolicy)
+	require.Contains(t, loader.Env, corev1.EnvVar{Name: "LOAD_PARALLELISM", Value: "8"})
+	require.Contains(t, loader.Env, corev1.EnvVar{Name: "KUBEAI_JOB_NAME", Value: job.Name})
+
+	// The progress is read from the logs of the loader, which are not
+	// available in the test environment (see Test_cacheLoadJobProgress).
+
+	// Failures of the loader are reported while the download is resumed.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abc",
+			Namespace: job.Namespace,
+			Labels:    map[string]string{batchv1.JobNameLabel: job.Name},
+		},
+		Spec: job.Spec.Template.Spec,
+	}
+	// The service account does not exist in the test environment.
+	pod.Spec.ServiceAccountName = ""
+	require.NoError(t, testK8sClient.Create(testCtx, pod))
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:         "loader",
+		RestartCount: 1,
+		State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 1,
+			Message:  "+ huggingface-cli download\nConnection reset by peer\n",
+		}},
+	}}
+	require.NoError(t, testK8sClient.Status().Update(testCtx, pod))
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if assert.NotNil(t, m.Status.Cache) && assert.NotNil(t, m.Status.Cache.Progress) {
+			assert.Equal(t, int32(1), m.Status.Cache.Progress.Restarts)
+		}
+		cond := meta.FindStatusCondition(m.Status.Conditions, v1.ModelConditionCacheLoaded)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, v1.ModelReasonLoading, cond.Reason)
+			assert.Contains(t, cond.Message, "resumed after 1 failures, last failure: Connection reset by peer")
+		}
+	}, 5*time.Second, time.Second/10, "Model should report failures of the loader")
+
+	// The failure reason is propagated when the Job fails.
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(job), job)) {
+			return
+		}
+		job.Status.Failed = 3
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:   batchv1.JobFailed,
+			Status: corev1.ConditionTrue,
+			Reason: "BackoffLimitExceeded",
+		})
+		assert.NoError(t, testK8sClient.Status().Update(testCtx, job))
+	}, 2*time.Second, time.Second/10)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		cond := meta.FindStatusCondition(m.Status.Conditions, v1.ModelConditionCacheLoaded)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, v1.ModelReasonLoadFailed, cond.Reason)
+			assert.Contains(t, cond.Message, "failed to load the model into the cache: Connection reset by peer")
+		}
+		assert.Nil(t, m.Status.Cache.Progress)
+	}, 5*time.Second, time.Second/10, "Model should report the failure of the load Job")
+}
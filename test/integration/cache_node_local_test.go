@@ -0,0 +1,194 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestCacheNodeLocal tests that the Models of a nodeLocal cache profile are
+// loaded onto the selected Nodes, that model server Pods prefer the Nodes
+// that have the model cached and that deleted Models are evicted.
+func TestCacheNodeLocal(t *testing.T) {
+	const cacheProfileName = "my-node-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			NodeLocal: &config.CacheNodeLocal{
+				HostPath:     "/mnt/models",
+				NodeSelector: map[string]string{"test-cache-node": "true"},
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	selected := createNodeForTest(t, "node-cache-selected", map[string]string{"test-cache-node": "true"})
+	createNodeForTest(t, "node-cache-other", nil)
+
+	nmc := &v1.NodeModelCache{}
+	nmcKey := types.NamespacedName{Namespace: testNS, Name: cacheProfileName + "." + selected.Name}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, nmcKey, nmc)) {
+			return
+		}
+		assert.Equal(t, selected.Name, nmc.Spec.NodeName)
+		assert.Equal(t, cacheProfileName, nmc.Spec.CacheProfile)
+		assert.True(t, metav1.IsControlledBy(nmc, selected))
+	}, 5*time.Second, time.Second/10, "NodeModelCache should be created for the selected Node")
+	err := testK8sClient.Get(testCtx, types.NamespacedName{Namespace: testNS, Name: cacheProfileName + ".node-cache-other"}, &v1.NodeModelCache{})
+	require.True(t, apierrors.IsNotFound(err), "NodeModelCache should not be created for other Nodes: %v", err)
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.Replicas = ptr.To[int32](1)
+	m.Spec.MaxReplicas = ptr.To[int32](2)
+	m.Spec.CacheProfile = cacheProfileName
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	// Model server Pods are not held back while the model is prefetched.
+	podList := &corev1.PodList{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{v1.PodModelLabel: m.Name})) {
+			return
+		}
+		assert.Len(t, podList.Items, 1)
+	}, 5*time.Second, time.Second/10, "Model Pod should be created")
+	pod := podList.Items[0]
+	require.Equal(t, map[string]string{"test-cache-node": "true"}, pod.Spec.NodeSelector)
+	require.Len(t, pod.Spec.InitContainers, 1)
+	require.Equal(t, "model-loader", pod.Spec.InitContainers[0].Name)
+	require.Equal(t, []string{m.Spec.URL}, pod.Spec.InitContainers[0].Args)
+	var hostPath string
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == "models" && v.HostPath != nil {
+			hostPath = v.HostPath.Path
+		}
+	}
+	require.Equal(t, "/mnt/models/"+testNS, hostPath)
+
+	// Assert that the model is prefetched onto the selected Node.
+	loadJob := requireNodeCacheJob(t, "load", m.Name)
+	require.Equal(t, selected.Name, loadJob.Spec.Template.Spec.NodeName)
+	completeCacheLoadJob(t, loadJob, `{"sizeBytes":1000,"diskCapacityBytes":100000,"diskUsedBytes":5000}`)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, nmcKey, nmc)) {
+			return
+		}
+		cached := nmc.CachedModel(m.Name, m.UID)
+		if !assert.NotNil(t, cached) {
+			return
+		}
+		assert.Equal(t, v1.NodeCachedModelLoaded, cached.Phase)
+		assert.Equal(t, int64(1000), cached.SizeBytes)
+		assert.Equal(t, int64(100000), nmc.Status.DiskCapacityBytes)
+		assert.Equal(t, int64(5000), nmc.Status.DiskUsedBytes)
+	}, 5*time.Second, time.Second/10, "Model should be loaded onto the Node")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if assert.NotNil(t, m.Status.Cache) {
+			assert.True(t, m.Status.Cache.Loaded)
+		}
+	}, 5*time.Second, time.Second/10, "Model cache status should be loaded")
+
+	// New Pods prefer the Node that has the model cached.
+	updateModel(t, m, func() { m.Spec.Replicas = ptr.To[int32](2) }, "Scaling up")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{v1.PodModelLabel: m.Name})) {
+			return
+		}
+		if !assert.Len(t, podList.Items, 2) {
+			return
+		}
+		var preferred bool
+		for _, p := range podList.Items {
+			if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil {
+				continue
+			}
+			for _, term := range p.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				for _, req := range term.Preference.MatchFields {
+					if req.Key == metav1.ObjectNameField && assert.ObjectsAreEqual([]string{selected.Name}, req.Values) {
+						preferred = true
+					}
+				}
+			}
+		}
+		assert.True(t, preferred, "new Pod should prefer the Node with the cached model")
+	}, 5*time.Second, time.Second/10, "New Pod should be created")
+
+	// Deleted Models are evicted from the Node.
+	require.NoError(t, testK8sClient.Delete(testCtx, m))
+	evictJob := requireNodeCacheJob(t, "evict", m.Name)
+	require.Equal(t, selected.Name, evictJob.Spec.Template.Spec.NodeName)
+	requireUpdateJobAsCompleted(t, evictJob)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, nmcKey, nmc)) {
+			return
+		}
+		assert.Empty(t, nmc.Status.Models)
+		assert.Equal(t, int64(4000), nmc.Status.DiskUsedBytes)
+	}, 5*time.Second, time.Second/10, "Model should be evicted from the Node")
+}
+
+func createNodeForTest(t *testing.T, name string, labels map[string]string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	require.NoError(t, testK8sClient.Create(testCtx, node))
+	t.Cleanup(func() {
+		if err := testK8sClient.Delete(testCtx, node); err != nil {
+			t.Logf("Cleanup: deleting Node: %v", err)
+		}
+	})
+	return node
+}
+
+func requireNodeCacheJob(t *testing.T, action, modelName string) *batchv1.Job {
+	jobList := &batchv1.JobList{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.List(testCtx, jobList, client.InNamespace(testNS), client.MatchingLabels{
+			"node-cache.kubeai.org/action": action,
+			"node-cache.kubeai.org/model":  modelName,
+		})) {
+			return
+		}
+		assert.Len(t, jobList.Items, 1)
+	}, 5*time.Second, time.Second/10, "Node cache %s Job should be created", action)
+	return &jobList.Items[0]
+}
+
+// completeCacheLoadJob creates the Pod of a load Job with the given
+// termination message (there is no Job controller in the test environment)
+// and marks the Job as completed.
+func completeCacheLoadJob(t *testing.T, job *batchv1.Job, report string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abc",
+			Namespace: job.Namespace,
+			Labels:    map[string]string{batchv1.JobNameLabel: job.Name},
+		},
+		Spec: job.Spec.Template.Spec,
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, pod))
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: "loader",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 0,
+			Message:  report,
+		}},
+	}}
+	require.NoError(t, testK8sClient.Status().Update(testCtx, pod))
+	requireUpdateJobAsCompleted(t, job)
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestCacheSharedFilesystemDedup tests that Models with the same url share a
+// single copy of the model in a sharedFilesystem cache that is only evicted
+// with the last Model.
+func TestCacheSharedFilesystemDedup(t *testing.T) {
+	const cacheProfileName = "dedup-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			SharedFilesystem: &config.CacheSharedFilesystem{
+				StorageClassName: "my-storage-class",
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	createModel := func(name string) *v1.Model {
+		m := modelForTest(t)
+		m.Name = name
+		m.Spec.MinReplicas = 1
+		m.Spec.CacheProfile = cacheProfileName
+		require.NoError(t, testK8sClient.Create(testCtx, m))
+		return m
+	}
+	requireEntry := func(m *v1.Model) string {
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			if assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) && assert.NotNil(t, m.Status.Cache) {
+				assert.True(t, strings.HasPrefix(m.Status.Cache.Entry, "url-"))
+			}
+		}, 5*time.Second, time.Second/10, "Model should have a cache entry")
+		return m.Status.Cache.Entry
+	}
+	requireMountedDir := func(m *v1.Model, dir string) {
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			podList := &corev1.PodList{}
+			if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name})) {
+				return
+			}
+			if !assert.Len(t, podList.Items, 1) {
+				return
+			}
+			assert.Contains(t, podList.Items[0].Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+				Name:      "models",
+				MountPath: dir,
+				SubPath:   strings.TrimPrefix(dir, "/"),
+				ReadOnly:  true,
+			})
+		}, 5*time.Second, time.Second/10, "Model Pod should mount the cache entry")
+	}
+
+	first := createModel("first-model")
+	entry := requireEntry(first)
+	entryDir := "/models/" + entry
+
+	loadJob := requireSharedCacheJob(t, "load-"+entry)
+	require.Equal(t, []string{first.Spec.URL, entryDir}, loadJob.Spec.Template.Spec.Containers[0].Args)
+	require.Equal(t, entry, loadJob.Labels["cache.kubeai.org/entry"])
+
+	// A Model with the same url follows the running load Job of the entry.
+	second := createModel("second-model")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(second), second)) {
+			assert.Equal(t, entry, second.Status.Cache.Entry)
+		}
+	}, 5*time.Second, time.Second/10, "Model should share the cache entry")
+	requireCacheLoadedCondition(t, second, v1.ModelReasonLoading)
+	require.Contains(t, meta.FindStatusCondition(second.Status.Conditions, v1.ModelConditionCacheLoaded).Message, loadJob.Name)
+
+	completeCacheLoadJob(t, loadJob, `{"sizeBytes":100,"revision":"0123abcd","manifestSHA256":"abc"}`)
+	requireCacheLoadedCondition(t, first, v1.ModelReasonLoaded)
+	requireCacheLoadedCondition(t, second, v1.ModelReasonLoaded)
+	require.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(second), second))
+	require.Equal(t, "0123abcd", second.Status.Cache.Revision)
+	requireMountedDir(first, entryDir)
+	requireMountedDir(second, entryDir)
+
+	smc := &v1.SharedModelCache{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{Namespace: testNS, Name: cacheProfileName}, smc)) {
+			assert.Len(t, smc.Status.Models, 2)
+			assert.Equal(t, int64(100), smc.Status.UsedBytes)
+		}
+	}, 5*time.Second, time.Second/10, "The shared model should take up space once")
+
+	// Deleting a Model that is not the last reference keeps the cached model.
+	require.NoError(t, testK8sClient.Delete(testCtx, first))
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, client.ObjectKeyFromObject(first), first)
+		assert.True(t, apierrors.IsNotFound(err))
+	}, 5*time.Second, time.Second/10, "Model should be finalized")
+	requireNoJob(t, "evict-cache-"+first.Name)
+
+	// Deleting the last Model evicts the cached model.
+	require.NoError(t, testK8sClient.Delete(testCtx, second))
+	evictJob := requireSharedCacheJob(t, "evict-cache-"+second.Name)
+	require.Equal(t, []string{"bash", "-c", "rm -rf " + entryDir + " " + entryDir + "_*"}, evictJob.Spec.Template.Spec.Containers[0].Command)
+	requireUpdateJobAsCompleted(t, evictJob)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, client.ObjectKeyFromObject(second), second)
+		assert.True(t, apierrors.IsNotFound(err))
+	}, 5*time.Second, time.Second/10, "Model should be finalized")
+}
@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestCacheSharedFilesystemEviction tests that the usage of a sharedFilesystem
+// cache profile is tracked and that the least recently served Model that is
+// scaled to zero is evicted when the capacity threshold is reached.
+func TestCacheSharedFilesystemEviction(t *testing.T) {
+	const cacheProfileName = "my-capped-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			SharedFilesystem: &config.CacheSharedFilesystem{
+				StorageClassName:         "my-storage-class",
+				Capacity:                 resource.NewQuantity(1000, resource.DecimalSI),
+				EvictionThresholdPercent: 80,
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	createModel := func(name string) *v1.Model {
+		m := modelForTest(t)
+		m.Name = name
+		// Models with the same url would share their cache entry.
+		m.Spec.URL = "hf://test-org/" + name
+		m.Spec.MinReplicas = 0
+		m.Spec.Replicas = ptr.To[int32](0)
+		m.Spec.CacheProfile = cacheProfileName
+		require.NoError(t, testK8sClient.Create(testCtx, m))
+		return m
+	}
+	loadModel := func(m *v1.Model, sizeBytes int) {
+		job := requireCacheEntryLoadJob(t, m)
+		completeCacheLoadJob(t, job, fmt.Sprintf(`{"sizeBytes":%d}`, sizeBytes))
+		requireCacheLoadedCondition(t, m, v1.ModelReasonLoaded)
+	}
+
+	older := createModel("older-model")
+	loadModel(older, 500)
+
+	smc := &v1.SharedModelCache{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{Namespace: testNS, Name: cacheProfileName}, smc)) {
+			return
+		}
+		assert.Equal(t, int64(1000), smc.Status.CapacityBytes)
+		assert.Equal(t, int64(500), smc.Status.UsedBytes)
+		if assert.Len(t, smc.Status.Models, 1) {
+			assert.Equal(t, older.Name, smc.Status.Models[0].Name)
+			assert.Equal(t, v1.SharedCachedModelLoaded, smc.Status.Models[0].Phase)
+			assert.Equal(t, int64(500), smc.Status.Models[0].SizeBytes)
+		}
+	}, 5*time.Second, time.Second/10, "SharedModelCache should track the loaded model")
+
+	// Reaching the threshold evicts the least recently served Model.
+	newer := createModel("newer-model")
+	loadModel(newer, 400)
+
+	evictJob := requireSharedCacheJob(t, "evict-cache-"+older.Name)
+	require.Equal(t, cacheProfileName, evictJob.Labels["shared-cache.kubeai.org/profile"])
+	requireCacheLoadedCondition(t, older, v1.ModelReasonEvicting)
+	requireUpdateJobAsCompleted(t, evictJob)
+
+	requireCacheLoadedCondition(t, older, v1.ModelReasonEvicted)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(smc), smc)) {
+			return
+		}
+		assert.Equal(t, int64(400), smc.Status.UsedBytes)
+		phases := map[string]v1.SharedCachedModelPhase{}
+		for _, cached := range smc.Status.Models {
+			phases[cached.Name] = cached.Phase
+		}
+		assert.Equal(t, map[string]v1.SharedCachedModelPhase{
+			older.Name: v1.SharedCachedModelEvicted,
+			newer.Name: v1.SharedCachedModelLoaded,
+		}, phases)
+	}, 5*time.Second, time.Second/10, "SharedModelCache should track the evicted model")
+
+	// Evicted models are loaded again when the Model is scaled up.
+	updateModel(t, older, func() { older.Spec.Replicas = ptr.To[int32](1) }, "Scaling up")
+	requireCacheLoadedCondition(t, older, v1.ModelReasonLoading)
+}
+
+func requireSharedCacheJob(t *testing.T, name string) *batchv1.Job {
+	job := &batchv1.Job{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{Namespace: testNS, Name: name}, job)) {
+			return
+		}
+		assert.Nil(t, job.DeletionTimestamp)
+	}, 5*time.Second, time.Second/10, "Job %s should be created", name)
+	return job
+}
+
+// requireCacheEntryLoadJob returns the load Job of the cache entry of a Model.
+func requireCacheEntryLoadJob(t *testing.T, m *v1.Model) *batchv1.Job {
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) && assert.NotNil(t, m.Status.Cache) {
+			assert.NotEmpty(t, m.Status.Cache.Entry)
+		}
+	}, 5*time.Second, time.Second/10, "Model should have a cache entry")
+	return requireSharedCacheJob(t, "load-"+m.Status.Cache.Entry)
+}
+
+func requireCacheLoadedCondition(t *testing.T, m *v1.Model, reason string) {
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		cond := meta.FindStatusCondition(m.Status.Conditions, v1.ModelConditionCacheLoaded)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, reason, cond.Reason)
+		}
+	}, 5*time.Second, time.Second/10, "Model %s should have the CacheLoaded reason %s", m.Name, reason)
+}
@@ -51,16 +51,10 @@ func TestCacheSharedFilesystem(t *testing.T) {
 	require.Equal(t, "my-pv", pvc.Spec.VolumeName)
 
 	// Assert that the model loader Job is created
-	loaderJob := &batchv1.Job{}
-	require.EventuallyWithT(t, func(t *assert.CollectT) {
-		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
-			Namespace: m.Namespace,
-			Name:      fmt.Sprintf("load-cache-%s", m.Name),
-		}, loaderJob))
-	}, 5*time.Second, time.Second/10, "Loader Job should be created")
+	loaderJob := requireCacheEntryLoadJob(t, m)
 
-	// Update the Job to have a completed status
-	requireUpdateJobAsCompleted(t, loaderJob)
+	// Complete the Job with a report of the loaded files
+	completeCacheLoadJob(t, loaderJob, `{"sizeBytes":3,"revision":"0123abcd","manifestSHA256":"abc","files":[{"path":"config.json","sha256":"def"}]}`)
 
 	// Assert that the PVC was updated with an annotation for the downloaded model
 	require.EventuallyWithT(t, func(t *assert.CollectT) {
@@ -92,6 +86,9 @@ func TestCacheSharedFilesystem(t *testing.T) {
 			return
 		}
 		assert.True(t, m.Status.Cache.Loaded)
+		assert.Equal(t, "0123abcd", m.Status.Cache.Revision)
+		assert.Equal(t, "abc", m.Status.Cache.ManifestSHA256)
+		assert.Equal(t, []v1.ModelCachedFile{{Path: "config.json", SHA256: "def"}}, m.Status.Cache.Files)
 		assert.Contains(t, m.Finalizers, v1.ModelCacheEvictionFinalizer)
 	}, 10*time.Second, time.Second/10, "Model status & finalizers should be updated")
 
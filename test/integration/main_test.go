@@ -168,6 +168,7 @@ func baseSysCfg(t *testing.T) config.System {
 			AWS:         "aws",
 			GCP:         "gcp",
 			Alibaba:     "alibaba",
+			Azure:       "azure",
 		},
 		ModelServers: config.ModelServers{
 			VLLM: config.ModelServer{
@@ -256,8 +257,5 @@ func baseSysCfg(t *testing.T) config.System {
 			RetryPeriod:   config.Duration{Duration: time.Second / 10},
 		},
 		AllowPodAddressOverride: true,
-		// FixedSelfIPs is used to tell the autoscaler to scrape metrics from the local host
-		// (because KubeAI is not running in a Pod).
-		FixedSelfMetricAddrs: []string{"127.0.0.1:8080"},
 	}
 }
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestModelCache(t *testing.T) {
+	const cacheProfileName = "model-cache-test"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			SharedFilesystem: &config.CacheSharedFilesystem{
+				StorageClassName: "my-storage-class",
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	// Pre-warm the cache before any Model is created.
+	mc := &v1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.ToLower(t.Name()),
+			Namespace: testNS,
+		},
+		Spec: v1.ModelCacheSpec{
+			URL:          "hf://test-org/test-model",
+			CacheProfile: cacheProfileName,
+			Env:          map[string]string{"TEST_ENV": "test"},
+		},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, mc))
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: testNS,
+			Name:      "shared-model-cache-" + cacheProfileName,
+		}, pvc))
+	}, 5*time.Second, time.Second/10, "PVC should be created")
+
+	loadJob := requireSharedCacheJob(t, "load-modelcache-"+mc.Name)
+	cacheDir := "/models/modelcache-" + mc.Name
+	require.Equal(t, []string{mc.Spec.URL, cacheDir}, loadJob.Spec.Template.Spec.Containers[0].Args)
+	require.Equal(t, pvc.Name, loadJob.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	require.Contains(t, loadJob.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "TEST_ENV", Value: "test"})
+
+	completeCacheLoadJob(t, loadJob, `{"sizeBytes":123,"revision":"0123abcd","manifestSHA256":"abc"}`)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(mc), mc)) {
+			return
+		}
+		assert.True(t, mc.Status.Loaded)
+		assert.Equal(t, int64(123), mc.Status.SizeBytes)
+		assert.Equal(t, "0123abcd", mc.Status.Revision)
+		assert.NotNil(t, mc.Status.LoadedTime)
+		assert.Contains(t, mc.Finalizers, v1.ModelCacheEvictionFinalizer)
+		assert.True(t, meta.IsStatusConditionTrue(mc.Status.Conditions, v1.ModelCacheConditionLoaded))
+	}, 5*time.Second, time.Second/10, "ModelCache should be loaded")
+
+	// A Model with the same url is served from the cached model.
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.CacheProfile = cacheProfileName
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if !assert.NotNil(t, m.Status.Cache) {
+			return
+		}
+		assert.True(t, m.Status.Cache.Loaded)
+		assert.Equal(t, mc.Name, m.Status.Cache.ModelCache)
+		assert.Equal(t, "0123abcd", m.Status.Cache.Revision)
+	}, 5*time.Second, time.Second/10, "Model should be served from the ModelCache")
+
+	podList := &corev1.PodList{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name})) {
+			return
+		}
+		assert.Len(t, podList.Items, 1)
+	}, 5*time.Second, time.Second/10, "Model Pods should be created")
+	require.Contains(t, podList.Items[0].Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "models",
+		MountPath: cacheDir,
+		SubPath:   strings.TrimPrefix(cacheDir, "/"),
+		ReadOnly:  true,
+	})
+	require.Contains(t, podList.Items[0].Spec.Containers[0].Args, "--model="+cacheDir)
+	requireNoJob(t, "load-cache-"+m.Name)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(mc), mc)) {
+			assert.Equal(t, []string{m.Name}, mc.Status.Models)
+		}
+	}, 5*time.Second, time.Second/10, "ModelCache should list the Model")
+
+	// Deleting the ModelCache makes the Model load its own copy before the
+	// cached model is evicted.
+	require.NoError(t, testK8sClient.Delete(testCtx, mc))
+	requireCacheEntryLoadJob(t, m)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			assert.Empty(t, m.Status.Cache.ModelCache)
+		}
+	}, 5*time.Second, time.Second/10, "Model should not be served from the deleted ModelCache")
+
+	evictJob := requireSharedCacheJob(t, "evict-modelcache-"+mc.Name)
+	require.Equal(t, []string{"bash", "-c", "rm -rf " + cacheDir + " " + cacheDir + "_*"}, evictJob.Spec.Template.Spec.Containers[0].Command)
+	requireUpdateJobAsCompleted(t, evictJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, client.ObjectKeyFromObject(mc), mc)
+		assert.True(t, apierrors.IsNotFound(err))
+	}, 5*time.Second, time.Second/10, "ModelCache should be finalized")
+}
+
+func TestModelCacheInvalidProfile(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	mc := &v1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.ToLower(t.Name()),
+			Namespace: testNS,
+		},
+		Spec: v1.ModelCacheSpec{
+			URL:          "hf://test-org/test-model",
+			CacheProfile: "does-not-exist",
+		},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, mc))
+	t.Cleanup(func() {
+		require.NoError(t, client.IgnoreNotFound(testK8sClient.Delete(testCtx, mc)))
+	})
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(mc), mc)) {
+			return
+		}
+		cond := meta.FindStatusCondition(mc.Status.Conditions, v1.ModelCacheConditionLoaded)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, v1.ModelReasonInvalidConfiguration, cond.Reason)
+		}
+	}, 5*time.Second, time.Second/10, "ModelCache should report the invalid cache profile")
+}
+
+func requireNoJob(t *testing.T, name string) {
+	err := testK8sClient.Get(testCtx, types.NamespacedName{Namespace: testNS, Name: name}, &batchv1.Job{})
+	require.True(t, apierrors.IsNotFound(err), fmt.Sprintf("Job %s should not exist: %v", name, err))
+}
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelPodDisruptionBudget tests that a PodDisruptionBudget is managed for
+// the Pods of a Model.
+func TestModelPodDisruptionBudget(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdbKey := client.ObjectKey{Namespace: testNS, Name: "model-" + m.Name}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, pdbKey, pdb)) {
+			return
+		}
+		assert.Equal(t, map[string]string{v1.PodModelLabel: m.Name}, pdb.Spec.Selector.MatchLabels)
+		assert.Equal(t, ptr.To(intstr.FromInt32(1)), pdb.Spec.MaxUnavailable)
+		assert.Nil(t, pdb.Spec.MinAvailable)
+	}, 5*time.Second, time.Second/10, "PodDisruptionBudget should be created")
+
+	updateModel(t, m, func() {
+		m.Spec.Disruption.MinAvailable = ptr.To(intstr.FromString("50%"))
+	}, "Setting minAvailable")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, pdbKey, pdb)) {
+			return
+		}
+		assert.Equal(t, ptr.To(intstr.FromString("50%")), pdb.Spec.MinAvailable)
+		assert.Nil(t, pdb.Spec.MaxUnavailable)
+	}, 5*time.Second, time.Second/10, "PodDisruptionBudget should be updated")
+
+	updateModel(t, m, func() {
+		m.Spec.Disruption.PodDisruptionBudgetDisabled = true
+	}, "Disabling the PodDisruptionBudget")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, pdbKey, pdb)
+		assert.True(t, apierrors.IsNotFound(err), "unexpected error: %v", err)
+	}, 5*time.Second, time.Second/10, "PodDisruptionBudget should be deleted")
+}
@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelEngine tests that Models can use a ModelEngine that is rendered
+// into the server Pods and that the Model recovers once a missing ModelEngine
+// is created.
+func TestModelEngine(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	testModelBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(testModelBackend.Close)
+	u, err := url.Parse(testModelBackend.URL)
+	require.NoError(t, err)
+
+	engine := &v1.ModelEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-model-engine"},
+		Spec: v1.ModelEngineSpec{
+			Images: map[string]string{"default": "test-engine-image"},
+			Container: v1.ModelEngineContainer{
+				Args: []string{"serve", "{{ .ModelPath }}", "--name={{ .ServedModelName }}", "--port={{ .Port }}"},
+			},
+			URLSchemes: []string{"hf"},
+			Features:   []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+		},
+	}
+
+	m := modelForTest(t)
+	m.Spec.Engine = engine.Name
+	m.Spec.MinReplicas = 1
+	m.Spec.MaxReplicas = ptr.To[int32](1)
+	m.Annotations[v1.ModelPodIPAnnotation] = u.Hostname()
+	m.Annotations[v1.ModelPodPortAnnotation] = u.Port()
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireModelStatus(t, m, v1.ModelPhaseFailed, v1.ModelConditionDegraded, v1.ModelReasonInvalidConfiguration)
+	requireModelPods(t, m, 0, "No Pods should be created without the ModelEngine", time.Second)
+
+	require.NoError(t, testK8sClient.Create(testCtx, engine))
+	t.Cleanup(func() {
+		if err := testK8sClient.Delete(testCtx, engine); err != nil {
+			t.Logf("Cleanup: deleting ModelEngine: %v", err)
+		}
+	})
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 1) {
+			return
+		}
+		server := mustFindPodContainerByName(t, &pods[0], "server")
+		assert.Equal(t, "test-engine-image", server.Image)
+		assert.Equal(t, []string{"serve", "test-org/test-model", "--name=testmodelengine", "--port=8000", "--test-arg"}, server.Args)
+		assert.Equal(t, []corev1.ContainerPort{{Name: "http", ContainerPort: 8000, Protocol: corev1.ProtocolTCP}}, server.Ports)
+		assert.Equal(t, "/health", server.ReadinessProbe.HTTPGet.Path)
+	}, 5*time.Second, time.Second/10, "Pod should be created from the ModelEngine")
+	markAllModelPodsReady(t, m)
+
+	var wg sync.WaitGroup
+	sendRequests(t, &wg, m.Name, nil, 1, http.StatusOK, "", "request to the stand-in backend")
+	wg.Wait()
+}
+
+// TestModelEngineDeletedBeforeModel tests that the cache of a Model is
+// finalized when its ModelEngine was deleted before the Model.
+func TestModelEngineDeletedBeforeModel(t *testing.T) {
+	const cacheProfileName = "my-test-cache"
+	sysCfg := baseSysCfg(t)
+	sysCfg.CacheProfiles = map[string]config.CacheProfile{
+		cacheProfileName: {
+			SharedFilesystem: &config.CacheSharedFilesystem{
+				StorageClassName: "my-storage-class",
+			},
+		},
+	}
+	initTest(t, sysCfg)
+
+	engine := &v1.ModelEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deleted-model-engine"},
+		Spec: v1.ModelEngineSpec{
+			Images: map[string]string{"default": "test-engine-image"},
+			Container: v1.ModelEngineContainer{
+				Args: []string{"serve", "{{ .ModelPath }}"},
+			},
+			URLSchemes: []string{"hf"},
+			Features:   []v1.ModelFeature{v1.ModelFeatureTextGeneration},
+		},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, engine))
+
+	m := modelForTest(t)
+	m.Spec.Engine = engine.Name
+	m.Spec.CacheProfile = cacheProfileName
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	loaderJob := requireCacheEntryLoadJob(t, m)
+	completeCacheLoadJob(t, loaderJob, `{"sizeBytes":3,"revision":"0123abcd","manifestSHA256":"abc","files":[{"path":"config.json","sha256":"def"}]}`)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		assert.Contains(t, m.Finalizers, v1.ModelCacheEvictionFinalizer)
+	}, 10*time.Second, time.Second/10, "Model should have the cache eviction finalizer")
+
+	require.NoError(t, testK8sClient.Delete(testCtx, engine))
+	requireModelStatus(t, m, v1.ModelPhaseFailed, v1.ModelConditionDegraded, v1.ModelReasonInvalidConfiguration)
+
+	require.NoError(t, testK8sClient.Delete(testCtx, m))
+
+	evictJob := &batchv1.Job{}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, types.NamespacedName{
+			Namespace: m.Namespace,
+			Name:      "evict-cache-" + m.Name,
+		}, evictJob))
+	}, 5*time.Second, time.Second/10, "Eviction Job should be created without the ModelEngine")
+	requireUpdateJobAsCompleted(t, evictJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)
+		assert.True(t, apierrors.IsNotFound(err))
+	}, 5*time.Second, time.Second/10, "Model should be finalized")
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestModelEngineSGLang(t *testing.T) {
+	testModelEngine(t, v1.SGLangEngine, "hf://test-org/test-model", []string{
+		"--model-path=test-org/test-model",
+		"--served-model-name=testmodelenginesglang",
+		"--host=0.0.0.0",
+		"--port=8000",
+		"--test-arg",
+	})
+}
+
+func TestModelEngineTGI(t *testing.T) {
+	testModelEngine(t, v1.TGIEngine, "hf://test-org/test-model", []string{
+		"--model-id=test-org/test-model",
+		"--served-model-name=testmodelenginetgi",
+		"--hostname=0.0.0.0",
+		"--port=8000",
+		"--test-arg",
+	})
+}
+
+func TestModelEngineLlamaCPP(t *testing.T) {
+	testModelEngine(t, v1.LlamaCPPEngine, "hf://test-org/test-model-GGUF?model=test-model-q4_k_m.gguf", []string{
+		"--hf-repo=test-org/test-model-GGUF",
+		"--hf-file=test-model-q4_k_m.gguf",
+		"--alias=testmodelenginellamacpp",
+		"--host=0.0.0.0",
+		"--port=8000",
+		"--test-arg",
+	})
+}
+
+// testModelEngine tests that the Pod of a Model is built for the given engine
+// and that requests are proxied to it (a stand-in backend serves them).
+func testModelEngine(t *testing.T, engine, url string, expArgs []string) {
+	const testImage = "test-engine-image"
+	sysCfg := baseSysCfg(t)
+	engineCfg := config.ModelServer{Images: map[string]string{"default": testImage}}
+	switch engine {
+	case v1.SGLangEngine:
+		sysCfg.ModelServers.SGLang = engineCfg
+	case v1.TGIEngine:
+		sysCfg.ModelServers.TGI = engineCfg
+	case v1.LlamaCPPEngine:
+		sysCfg.ModelServers.LlamaCPP = engineCfg
+	}
+	initTest(t, sysCfg)
+
+	testModelBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(testModelBackend.Close)
+
+	m := modelForTest(t)
+	m.Spec.Engine = engine
+	m.Spec.URL = url
+	m.Spec.MinReplicas = 1
+	m.Spec.MaxReplicas = ptr.To[int32](1)
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 1) {
+			return
+		}
+		pod := pods[0]
+		assert.Equal(t, "8000", pod.Annotations[v1.ModelPodPortAnnotation])
+		server := mustFindPodContainerByName(t, &pod, "server")
+		assert.Equal(t, testImage, server.Image)
+		assert.Equal(t, expArgs, server.Args)
+		if assert.NotNil(t, server.StartupProbe) && assert.NotNil(t, server.StartupProbe.HTTPGet) {
+			assert.Equal(t, "/health", server.StartupProbe.HTTPGet.Path)
+		}
+	}, 5*time.Second, time.Second/10, "Pod should be created for the engine")
+
+	// Recreate the Pod with the address of the stand-in backend.
+	updateModelWithBackend(t, m, testModelBackend)
+	require.NoError(t, testK8sClient.DeleteAllOf(testCtx, &corev1.Pod{}, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name}))
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if assert.Len(t, pods, 1) {
+			assert.NotEqual(t, "8000", pods[0].Annotations[v1.ModelPodPortAnnotation])
+		}
+	}, 5*time.Second, time.Second/10, "Pod should be recreated with the backend address")
+	markAllModelPodsReady(t, m)
+
+	var wg sync.WaitGroup
+	sendRequests(t, &wg, m.Name, nil, 1, http.StatusOK, "", "request to the stand-in backend")
+	wg.Wait()
+}
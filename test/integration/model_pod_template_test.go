@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+// TestModelPodTemplate tests that the Pod template of a Model is merged onto
+// the Pods of the Model and that changes to it result in a rollout.
+func TestModelPodTemplate(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.MaxReplicas = ptr.To[int32](1)
+	m.Spec.PodTemplate = &v1.ModelPodTemplate{
+		Metadata: v1.ModelPodTemplateMetadata{
+			Labels: map[string]string{"team": "a"},
+		},
+		Spec: &runtime.RawExtension{Raw: []byte(`{
+			"tolerations": [{"key": "dedicated", "operator": "Exists"}],
+			"containers": [{"name": "sidecar", "image": "proxy"}]
+		}`)},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 1) {
+			return
+		}
+		pod := pods[0]
+		assert.Equal(t, "a", pod.Labels["team"])
+		assert.Contains(t, pod.Spec.Tolerations, corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists})
+		var names []string
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
+		}
+		assert.ElementsMatch(t, []string{"server", "sidecar"}, names)
+	}, 5*time.Second, time.Second/10, "Pod should be created from the template")
+	markAllModelPodsReady(t, m)
+
+	updateModel(t, m, func() {
+		m.Spec.PodTemplate.Metadata.Labels["team"] = "b"
+	}, "Updating the template labels")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		var updated bool
+		for _, pod := range pods {
+			if pod.Labels["team"] == "b" {
+				updated = true
+			}
+		}
+		assert.True(t, updated, "A Pod with the updated labels should be created")
+	}, 5*time.Second, time.Second/10, "Template changes should result in a rollout")
+}
@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"k8s.io/utils/ptr"
+)
+
+// TestModelProbes tests that the probes configured for the engine and the
+// Model are applied to the server container and that changes result in a rollout.
+func TestModelProbes(t *testing.T) {
+	sysCfg := baseSysCfg(t)
+	sysCfg.ModelServers.VLLM.Probes = v1.ModelProbes{
+		Startup:  &v1.ProbeSettings{FailureThreshold: ptr.To[int32](900)},
+		Liveness: &v1.ProbeSettings{TimeoutSeconds: ptr.To[int32](10)},
+	}
+	sysCfg.ModelServers.VLLM.BashAvailable = true
+	initTest(t, sysCfg)
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.MaxReplicas = ptr.To[int32](1)
+	m.Spec.Probes = &v1.ModelProbes{
+		Liveness: &v1.ProbeSettings{TimeoutSeconds: ptr.To[int32](20)},
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 1) {
+			return
+		}
+		server := pods[0].Spec.Containers[0]
+		assert.Equal(t, int32(900), server.StartupProbe.FailureThreshold)
+		assert.Equal(t, int32(20), server.LivenessProbe.TimeoutSeconds)
+	}, 5*time.Second, time.Second/10, "Pod should be created with the configured probes")
+	markAllModelPodsReady(t, m)
+
+	updateModel(t, m, func() {
+		m.Spec.Probes.StartupStallTimeoutSeconds = ptr.To[int32](600)
+	}, "Enabling startup progress tracking")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		var updated bool
+		for _, pod := range listModelPods(t, m) {
+			if probe := pod.Spec.Containers[0].StartupProbe; probe.Exec != nil {
+				updated = true
+				assert.Equal(t, int32(1800), probe.TimeoutSeconds)
+			}
+		}
+		assert.True(t, updated, "A Pod with the progress-aware startup probe should be created")
+	}, 5*time.Second, time.Second/10, "Probe changes should result in a rollout")
+}
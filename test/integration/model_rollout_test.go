@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelRolloutBlueGreen tests that a BlueGreen rollout creates a full set of
+// up-to-date Pods without traffic and only deletes the out-of-date Pods after
+// the up-to-date Pods passed the analysis.
+func TestModelRolloutBlueGreen(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 2
+	m.Spec.MaxReplicas = ptr.To[int32](2)
+	m.Spec.Rollout = v1.ModelRollout{
+		Strategy:  v1.BlueGreenRolloutStrategy,
+		BlueGreen: v1.ModelRolloutBlueGreen{AnalysisSeconds: 1},
+		// The Pods do not serve requests in the test.
+		MaxErrorRatePercent: ptr.To[int32](100),
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireModelPods(t, m, 2, "Pods should be created", 5*time.Second)
+	markAllModelPodsReady(t, m)
+
+	const newArg = "--my-new-arg-added-in-testcase"
+	updateModel(t, m, func() { m.Spec.Args = []string{newArg} }, "Adding a new arg to the Model")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 4) {
+			return
+		}
+		var upToDate int
+		for _, pod := range pods {
+			if hasArg(pod, newArg) {
+				upToDate++
+				assert.Equal(t, "0", pod.Annotations[v1.ModelPodTrafficPercentAnnotation], "Up-to-date Pods should not receive traffic")
+			}
+		}
+		assert.Equal(t, 2, upToDate)
+	}, 10*time.Second, time.Second/10, "Up-to-date Pods should be created next to the out-of-date Pods")
+
+	markAllModelPodsReady(t, m)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 2) {
+			return
+		}
+		for _, pod := range pods {
+			assert.True(t, hasArg(pod, newArg), "Out-of-date Pods should be deleted")
+			assert.NotContains(t, pod.Annotations, v1.ModelPodTrafficPercentAnnotation)
+		}
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if assert.NotNil(t, m.Status.Rollout) {
+			assert.Equal(t, v1.ModelRolloutPhaseSucceeded, m.Status.Rollout.Phase)
+			assert.Equal(t, int32(2), m.Status.Rollout.UpdatedReplicas)
+		}
+	}, 15*time.Second, time.Second/10, "Rollout should succeed after the analysis")
+}
+
+// TestModelRolloutCanaryRollback tests that a Canary rollout is rolled back
+// when the canary Pod fails.
+func TestModelRolloutCanaryRollback(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 2
+	m.Spec.MaxReplicas = ptr.To[int32](2)
+	m.Spec.Rollout = v1.ModelRollout{
+		Strategy: v1.CanaryRolloutStrategy,
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireModelPods(t, m, 2, "Pods should be created", 5*time.Second)
+	markAllModelPodsReady(t, m)
+
+	const newArg = "--my-new-arg-added-in-testcase"
+	updateModel(t, m, func() { m.Spec.Args = []string{newArg} }, "Adding a new arg to the Model")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 3) {
+			return
+		}
+		for _, pod := range pods {
+			if hasArg(pod, newArg) {
+				assert.Equal(t, "10", pod.Annotations[v1.ModelPodTrafficPercentAnnotation], "Canary Pod should receive a fraction of the traffic")
+			}
+		}
+	}, 10*time.Second, time.Second/10, "Canary Pod should be created")
+
+	updateAllModelPodStatuses(t, m, func(pod *corev1.Pod) {
+		if !hasArg(*pod, newArg) {
+			return
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "server",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "CrashLoopBackOff",
+			}},
+		}}
+	})
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		pods := listModelPods(t, m)
+		if !assert.Len(t, pods, 2) {
+			return
+		}
+		for _, pod := range pods {
+			assert.False(t, hasArg(pod, newArg), "Canary Pod should be deleted")
+		}
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		if assert.NotNil(t, m.Status.Rollout) {
+			assert.Equal(t, v1.ModelRolloutPhaseRolledBack, m.Status.Rollout.Phase)
+		}
+	}, 10*time.Second, time.Second/10, "Rollout should be rolled back")
+	requireModelStatus(t, m, v1.ModelPhaseReady, v1.ModelConditionProgressing, v1.ModelReasonRolledBack)
+}
+
+func listModelPods(t assert.TestingT, m *v1.Model) []corev1.Pod {
+	podList := &corev1.PodList{}
+	if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name})) {
+		return nil
+	}
+	return podList.Items
+}
+
+func hasArg(pod corev1.Pod, arg string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == "server" && slices.Contains(c.Args, arg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelStatus tests that the phase and conditions of a Model
+// reflect the state of its Pods.
+func TestModelStatus(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 1
+	m.Spec.MaxReplicas = ptr.To[int32](1)
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireModelPods(t, m, 1, "Pod should be created", 5*time.Second)
+	requireModelStatus(t, m, v1.ModelPhaseProgressing, v1.ModelConditionReady, v1.ModelReasonReplicasNotReady)
+
+	// Crash looping server containers degrade the Model.
+	updateAllModelPodStatuses(t, m, func(pod *corev1.Pod) {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "server",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "CrashLoopBackOff",
+			}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "OOMKilled",
+			}},
+		}}
+	})
+	requireModelStatus(t, m, v1.ModelPhaseDegraded, v1.ModelConditionDegraded, v1.ModelReasonOOMKilled)
+
+	updateAllModelPodStatuses(t, m, func(pod *corev1.Pod) {
+		pod.Status.ContainerStatuses = nil
+	})
+	markAllModelPodsReady(t, m)
+	requireModelStatus(t, m, v1.ModelPhaseReady, v1.ModelConditionReady, v1.ModelReasonAllReplicasReady)
+
+	// Updating the Model results in a rollout.
+	updateModel(t, m, func() { m.Spec.Args = []string{"--new-arg"} }, "Adding a new arg to the Model")
+	requireModelStatus(t, m, v1.ModelPhaseProgressing, v1.ModelConditionProgressing, v1.ModelReasonRollingOut)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		assert.Equal(t, m.Generation, m.Status.ObservedGeneration)
+	}, 5*time.Second, time.Second/10, "ObservedGeneration should be updated")
+}
+
+func requireModelStatus(t *testing.T, m *v1.Model, expPhase v1.ModelPhase, condType, expReason string) {
+	t.Helper()
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		assert.Equal(t, expPhase, m.Status.Phase)
+		cond := meta.FindStatusCondition(m.Status.Conditions, condType)
+		if assert.NotNil(t, cond, "Condition %q should be set", condType) {
+			assert.Equal(t, expReason, cond.Reason)
+		}
+	}, 5*time.Second, time.Second/10, "Model should be %s with %s condition reason %s", expPhase, condType, expReason)
+}
+
+func updateAllModelPodStatuses(t *testing.T, m *v1.Model, modify func(*corev1.Pod)) {
+	t.Helper()
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		podList := &corev1.PodList{}
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{"model": m.Name})) {
+			return
+		}
+		for i := range podList.Items {
+			modify(&podList.Items[i])
+			if !assert.NoError(t, testK8sClient.Status().Update(testCtx, &podList.Items[i])) {
+				return
+			}
+		}
+	}, 2*time.Second, time.Second/10, "Updating the status of all model Pods should succeed")
+}
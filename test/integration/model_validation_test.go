@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
@@ -159,6 +160,61 @@ func TestModelValidation(t *testing.T) {
 			},
 			expErrContain: "NotAValidFeature",
 		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("sglang-embedding-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "SGLang",
+					Features: []v1.ModelFeature{"TextEmbedding"},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("tgi-embedding-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "TGI",
+					Features: []v1.ModelFeature{"TextEmbedding"},
+				},
+			},
+			expErrContain: "TGI engine only supports the TextGeneration feature",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("llamacpp-speech-to-text-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "LlamaCPP",
+					Features: []v1.ModelFeature{"SpeechToText"},
+				},
+			},
+			expErrContain: "SGLang and LlamaCPP engines only support TextGeneration and TextEmbedding features",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("llamacpp-pvc-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "pvc://test-pvc/models?model=test-model.gguf",
+					Engine:   "LlamaCPP",
+					Features: []v1.ModelFeature{"TextGeneration"},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("tgi-s3-without-cache-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "s3://test-bucket/test-model",
+					Engine:   "TGI",
+					Features: []v1.ModelFeature{"TextGeneration"},
+				},
+			},
+			expErrContain: "SGLang, TGI and LlamaCPP engines only support urls",
+		},
 		{
 			model: v1.Model{
 				ObjectMeta: metadata("replicas-0-1-2-valid"),
@@ -214,6 +270,31 @@ func TestModelValidation(t *testing.T) {
 			},
 			expErrContain: "minReplicas should be less than or equal to maxReplicas",
 		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("idle-timeout-with-min-replicas-invalid"),
+				Spec: v1.ModelSpec{
+					URL:                "hf://test-repo/test-model",
+					Engine:             "VLLM",
+					Features:           []v1.ModelFeature{},
+					MinReplicas:        1,
+					IdleTimeoutSeconds: ptr.To[int64](60),
+				},
+			},
+			expErrContain: "idleTimeoutSeconds requires minReplicas to be 0",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("idle-timeout-valid"),
+				Spec: v1.ModelSpec{
+					URL:                "hf://test-repo/test-model",
+					Engine:             "VLLM",
+					Features:           []v1.ModelFeature{},
+					IdleTimeoutSeconds: ptr.To[int64](60),
+				},
+			},
+			expValid: true,
+		},
 		{
 			model: v1.Model{
 				ObjectMeta: metadata("cache-profile-with-hf-url-valid"),
@@ -249,6 +330,230 @@ func TestModelValidation(t *testing.T) {
 			},
 			expValid: true,
 		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("hf-url-with-revision-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model@0123abcd",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("hf-url-with-revision-llamacpp-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model@0123abcd?model=model.gguf",
+					Engine:   "LlamaCPP",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "revisions of \"hf://\" urls are not supported",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("s3-url-with-version-id-without-cache-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "s3://test-bucket/test-model.gguf?versionId=abc",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "only supported when using a cacheProfile",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("gs-url-streamed-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "gs://test-bucket/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Streaming: &v1.ModelStreaming{
+						Concurrency: ptr.To[int32](32),
+						MemoryLimit: ptr.To(resource.MustParse("4Gi")),
+					},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("gs-url-with-generation-without-cache-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "gs://test-bucket/test-model#1700000000000000",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "urls of format \"gs://...#<generation>\" only supported when using a cacheProfile",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("s3-url-streamed-faster-whisper-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "s3://test-bucket/test-model",
+					Engine:   "FasterWhisper",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "are streamed without a cacheProfile, which is only supported by the VLLM engine",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oss-url-streamed-without-endpoint-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "oss://test-bucket/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "requires the OSS_ENDPOINT env var",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oss-url-streamed-with-endpoint-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "oss://test-bucket/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Env:      map[string]string{"OSS_ENDPOINT": "oss-cn-hangzhou.aliyuncs.com"},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("streaming-with-cache-profile-invalid"),
+				Spec: v1.ModelSpec{
+					URL:          "s3://test-bucket/test-model",
+					Engine:       "VLLM",
+					Features:     []v1.ModelFeature{},
+					CacheProfile: "some-cache-profile",
+					Streaming:    &v1.ModelStreaming{Concurrency: ptr.To[int32](8)},
+				},
+			},
+			expErrContain: "streaming is only supported for urls",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("streaming-zero-concurrency-invalid"),
+				Spec: v1.ModelSpec{
+					URL:       "s3://test-bucket/test-model",
+					Engine:    "VLLM",
+					Features:  []v1.ModelFeature{},
+					Streaming: &v1.ModelStreaming{Concurrency: ptr.To[int32](0)},
+				},
+			},
+			expErrContain: "spec.streaming.concurrency",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("streaming-model-engine-invalid"),
+				Spec: v1.ModelSpec{
+					URL:       "s3://test-bucket/test-model",
+					Engine:    "my-engine",
+					Features:  []v1.ModelFeature{},
+					Streaming: &v1.ModelStreaming{MemoryLimit: ptr.To(resource.MustParse("4Gi"))},
+				},
+			},
+			expErrContain: "streaming is only supported by the VLLM engine",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("az-url-without-cache-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "az://test-container/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "urls of format \"az://...\" only supported when using a cacheProfile",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("az-url-with-cache-profile-valid"),
+				Spec: v1.ModelSpec{
+					URL:          "az://test-container/test-model",
+					Engine:       "VLLM",
+					Features:     []v1.ModelFeature{},
+					CacheProfile: "some-cache-profile",
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("https-url-without-cache-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "https://models.example.com/test-model.gguf",
+					Engine:   "LlamaCPP",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "urls of format \"https://...\" only supported when using a cacheProfile",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("https-url-with-cache-profile-valid"),
+				Spec: v1.ModelSpec{
+					URL:          "https://models.example.com/test-model.gguf#sha256=0123abcd",
+					Engine:       "LlamaCPP",
+					Features:     []v1.ModelFeature{},
+					CacheProfile: "some-cache-profile",
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oci-url-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "oci://registry.example.com/models/llama:v1",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oci-url-with-cache-profile-valid"),
+				Spec: v1.ModelSpec{
+					URL:          "oci://registry.example.com/models/llama@sha256:0123abcd",
+					Engine:       "VLLM",
+					Features:     []v1.ModelFeature{},
+					CacheProfile: "some-cache-profile",
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oci-url-ollama-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "oci://registry.example.com/models/llama:v1",
+					Engine:   "OLlama",
+					Features: []v1.ModelFeature{},
+				},
+			},
+			expErrContain: "urls of format \"oci://...\" are not supported by the OLlama engine",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("oci-adapter-url-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Adapters: []v1.Adapter{
+						{Name: "adapter1", URL: "oci://registry.example.com/adapters/lora:v1"},
+					},
+				},
+			},
+			expValid: true,
+		},
 		{
 			model: v1.Model{
 				ObjectMeta: metadata("cache-profile-with-non-hf-url-invalid"),
@@ -464,6 +769,122 @@ func TestModelValidation(t *testing.T) {
 			},
 			expErrContain: "may not be longer than 100000",
 		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("pod-template-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					PodTemplate: &v1.ModelPodTemplate{
+						Metadata: v1.ModelPodTemplateMetadata{
+							Labels:      map[string]string{"team": "a"},
+							Annotations: map[string]string{"example.com/owner": "b"},
+						},
+					},
+				},
+			},
+			expValid: true,
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("pod-template-reserved-label-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					PodTemplate: &v1.ModelPodTemplate{
+						Metadata: v1.ModelPodTemplateMetadata{
+							Labels: map[string]string{v1.PodModelLabel: "other"},
+						},
+					},
+				},
+			},
+			expErrContain: "labels used by KubeAI can not be overridden",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("pod-template-reserved-annotation-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					PodTemplate: &v1.ModelPodTemplate{
+						Metadata: v1.ModelPodTemplateMetadata{
+							Annotations: map[string]string{v1.ModelPodPortAnnotation: "9000"},
+						},
+					},
+				},
+			},
+			expErrContain: "annotations used by KubeAI can not be overridden",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("deployment-workload-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "OLlama",
+					Features: []v1.ModelFeature{},
+					Workload: v1.ModelWorkload{Kind: v1.DeploymentWorkloadKind},
+					Rollout:  v1.ModelRollout{Strategy: v1.RecreateRolloutStrategy},
+				},
+			},
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("deployment-workload-canary-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Workload: v1.ModelWorkload{Kind: v1.DeploymentWorkloadKind},
+					Rollout:  v1.ModelRollout{Strategy: v1.CanaryRolloutStrategy},
+				},
+			},
+			expErrContain: "Deployment and LeaderWorkerSet workloads only support the RollingUpdate and Recreate rollout strategies",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("deployment-workload-warm-pool-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Workload: v1.ModelWorkload{Kind: v1.DeploymentWorkloadKind},
+					WarmPool: &v1.WarmPool{Replicas: 1},
+				},
+			},
+			expErrContain: "warmPool is only supported with the Pod workload",
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("leader-worker-set-multi-host-valid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "VLLM",
+					Features: []v1.ModelFeature{},
+					Workload: v1.ModelWorkload{
+						Kind:            v1.LeaderWorkerSetWorkloadKind,
+						LeaderWorkerSet: v1.ModelWorkloadLeaderWorkerSet{Size: 2},
+					},
+				},
+			},
+		},
+		{
+			model: v1.Model{
+				ObjectMeta: metadata("leader-worker-set-multi-host-invalid"),
+				Spec: v1.ModelSpec{
+					URL:      "hf://test-repo/test-model",
+					Engine:   "SGLang",
+					Features: []v1.ModelFeature{},
+					Workload: v1.ModelWorkload{
+						Kind:            v1.LeaderWorkerSetWorkloadKind,
+						LeaderWorkerSet: v1.ModelWorkloadLeaderWorkerSet{Size: 2},
+					},
+				},
+			},
+			expErrContain: "LeaderWorkerSet groups with more than one Pod are only supported with the VLLM engine",
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.model.Name, func(t *testing.T) {
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	"github.com/substratusai/kubeai/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelWarmPool tests that parked Pods are maintained for a Model
+// independently of its server Pods.
+func TestModelWarmPool(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 0
+	m.Spec.MaxReplicas = ptr.To[int32](3)
+	m.Spec.WarmPool = &v1.WarmPool{Replicas: 2}
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireParkedPods(t, m, 2, "Parked Pods should be created", 5*time.Second)
+	requireModelPods(t, m, 0, "Parked Pods should not be counted as server Pods", 2*time.Second)
+
+	updateModel(t, m, func() { m.Spec.WarmPool.Replicas = 1 }, "WarmPool.Replicas=1")
+	requireParkedPods(t, m, 1, "Parked Pods should be scaled down", 5*time.Second)
+
+	updateModel(t, m, func() { m.Spec.WarmPool = nil }, "WarmPool=nil")
+	requireParkedPods(t, m, 0, "Parked Pods should be removed", 5*time.Second)
+}
+
+// TestModelIdleTimeout tests that an idle Model is scaled to zero after
+// the idle timeout, even when the moving average would keep it scaled up.
+func TestModelIdleTimeout(t *testing.T) {
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 0
+	m.Spec.MaxReplicas = ptr.To[int32](3)
+	m.Spec.TargetRequests = ptr.To[int32](1)
+	m.Spec.ScaleDownDelaySeconds = ptr.To[int64](999999)
+	m.Spec.IdleTimeoutSeconds = ptr.To[int64](2)
+
+	sysCfg := baseSysCfg(t)
+	sysCfg.ModelAutoscaling.TimeWindow = config.Duration{Duration: time.Hour}
+	sysCfg.ModelAutoscaling.Interval = config.Duration{Duration: time.Second / 4}
+	initTest(t, sysCfg)
+
+	r := newTestLoadReporter(t, "replica-1", m.Name)
+	r.activeRequests.Store(1)
+
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+	requireModelReplicas(t, m, 1, "Replicas should be autoscaled", 15*time.Second)
+
+	r.activeRequests.Store(0)
+	requireModelReplicas(t, m, 0, "Replicas should be scaled to zero after the idle timeout", 15*time.Second)
+}
+
+func requireParkedPods(t *testing.T, m *v1.Model, expectedPods int, msg string, after time.Duration) {
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		podList := &corev1.PodList{}
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{v1.ParkedPodModelLabel: m.Name})) {
+			return
+		}
+		var active int
+		for _, p := range podList.Items {
+			if p.DeletionTimestamp == nil {
+				active++
+			}
+		}
+		assert.Equal(t, expectedPods, active)
+	}, after, time.Second/10, "Parked Pods should match: "+msg)
+}
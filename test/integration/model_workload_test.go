@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "github.com/substratusai/kubeai/api/k8s/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestModelWorkloadDeployment tests that a Model with a Deployment workload is
+// reconciled into a Deployment and that the Pods of the Deployment are used
+// for the status of the Model.
+func TestModelWorkloadDeployment(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 2
+	m.Spec.Replicas = ptr.To[int32](2)
+	m.Spec.Workload.Kind = v1.DeploymentWorkloadKind
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	deploy := &appsv1.Deployment{}
+	deployKey := client.ObjectKey{Namespace: testNS, Name: "model-" + m.Name}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, deployKey, deploy)) {
+			return
+		}
+		assert.Equal(t, int32(2), ptr.Deref(deploy.Spec.Replicas, 0))
+		assert.Equal(t, map[string]string{v1.PodModelLabel: m.Name}, deploy.Spec.Selector.MatchLabels)
+		assert.Equal(t, m.Name, deploy.Spec.Template.Labels[v1.PodModelLabel])
+		assert.NotEmpty(t, deploy.Spec.Template.Labels[v1.PodHashLabel])
+		assert.Equal(t, "vllm", deploy.Spec.Template.Labels["app.kubernetes.io/name"])
+		assert.Contains(t, deploy.Spec.Template.Spec.Containers[0].Args, "--test-arg")
+		assert.True(t, metav1.IsControlledBy(deploy, m))
+	}, 5*time.Second, time.Second/10, "Deployment should be created")
+	requireModelPods(t, m, 0, "No bare Pods should be created", time.Second)
+
+	// There is no Deployment controller in the test environment,
+	// create a Pod the way the Deployment would.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        m.Name + "-deploy-pod",
+			Namespace:   testNS,
+			Labels:      deploy.Spec.Template.Labels,
+			Annotations: deploy.Spec.Template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       deploy.Name + "-abc",
+				UID:        "test-replicaset-uid",
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec: deploy.Spec.Template.Spec,
+	}
+	require.NoError(t, testK8sClient.Create(testCtx, pod))
+	markAllModelPodsReady(t, m)
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, client.ObjectKeyFromObject(m), m)) {
+			return
+		}
+		assert.Equal(t, int32(1), m.Status.Replicas.All)
+		assert.Equal(t, int32(1), m.Status.Replicas.Ready)
+	}, 5*time.Second, time.Second/10, "Model status should count the Pods of the Deployment")
+
+	updateModel(t, m, func() {
+		m.Spec.Replicas = ptr.To[int32](3)
+		m.Spec.MaxReplicas = ptr.To[int32](3)
+		m.Spec.Args = []string{"--updated-arg"}
+	}, "Scaling and changing the args")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		if !assert.NoError(t, testK8sClient.Get(testCtx, deployKey, deploy)) {
+			return
+		}
+		assert.Equal(t, int32(3), ptr.Deref(deploy.Spec.Replicas, 0))
+		assert.Contains(t, deploy.Spec.Template.Spec.Containers[0].Args, "--updated-arg")
+		assert.NotEqual(t, pod.Labels[v1.PodHashLabel], deploy.Spec.Template.Labels[v1.PodHashLabel])
+	}, 5*time.Second, time.Second/10, "Deployment should be updated")
+
+	updateModel(t, m, func() {
+		m.Spec.Workload.Kind = v1.PodWorkloadKind
+	}, "Switching to bare Pods")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := testK8sClient.Get(testCtx, deployKey, deploy)
+		assert.True(t, apierrors.IsNotFound(err), "unexpected error: %v", err)
+	}, 5*time.Second, time.Second/10, "Deployment should be deleted")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		podList := &corev1.PodList{}
+		if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{v1.PodModelLabel: m.Name})) {
+			return
+		}
+		var bare int
+		for _, p := range podList.Items {
+			if metav1.IsControlledBy(&p, m) {
+				bare++
+			}
+		}
+		assert.Equal(t, 3, bare)
+	}, 5*time.Second, time.Second/10, "Bare Pods should be created")
+}
+
+// TestModelWorkloadLeaderWorkerSetNotInstalled tests that a Model with a
+// LeaderWorkerSet workload fails when the LeaderWorkerSet API is not installed.
+func TestModelWorkloadLeaderWorkerSetNotInstalled(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.Workload.Kind = v1.LeaderWorkerSetWorkloadKind
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+
+	requireModelStatus(t, m, v1.ModelPhaseFailed, v1.ModelConditionDegraded, v1.ModelReasonInvalidConfiguration)
+	require.Contains(t, meta.FindStatusCondition(m.Status.Conditions, v1.ModelConditionDegraded).Message, "LeaderWorkerSet API is not installed")
+}
+
+// TestModelWorkloadSwitchToDeployment tests that the bare Pods of a Model are
+// only deleted as the replicas of its new Deployment become ready.
+func TestModelWorkloadSwitchToDeployment(t *testing.T) {
+	initTest(t, baseSysCfg(t))
+
+	m := modelForTest(t)
+	m.Spec.MinReplicas = 2
+	m.Spec.Replicas = ptr.To[int32](2)
+	require.NoError(t, testK8sClient.Create(testCtx, m))
+	requireModelPods(t, m, 2, "Bare Pods should be created", 5*time.Second)
+	markAllModelPodsReady(t, m)
+
+	requireBarePods := func(n int, msg string) {
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			podList := &corev1.PodList{}
+			if !assert.NoError(t, testK8sClient.List(testCtx, podList, client.InNamespace(testNS), client.MatchingLabels{v1.PodModelLabel: m.Name})) {
+				return
+			}
+			var bare int
+			for _, p := range podList.Items {
+				if metav1.IsControlledBy(&p, m) && p.DeletionTimestamp == nil {
+					bare++
+				}
+			}
+			assert.Equal(t, n, bare)
+		}, 5*time.Second, time.Second/10, msg)
+	}
+
+	updateModel(t, m, func() {
+		m.Spec.Workload.Kind = v1.DeploymentWorkloadKind
+	}, "Switching to a Deployment")
+	deploy := &appsv1.Deployment{}
+	deployKey := client.ObjectKey{Namespace: testNS, Name: "model-" + m.Name}
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NoError(t, testK8sClient.Get(testCtx, deployKey, deploy))
+	}, 5*time.Second, time.Second/10, "Deployment should be created")
+	time.Sleep(time.Second)
+	requireBarePods(2, "Bare Pods should serve until the Deployment is ready")
+
+	// There is no Deployment controller in the test environment.
+	setReadyReplicas := func(n int32) {
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			if !assert.NoError(t, testK8sClient.Get(testCtx, deployKey, deploy)) {
+				return
+			}
+			deploy.Status.Replicas = n
+			deploy.Status.ReadyReplicas = n
+			assert.NoError(t, testK8sClient.Status().Update(testCtx, deploy))
+		}, 5*time.Second, time.Second/10, "Deployment status should be updated")
+	}
+	setReadyReplicas(1)
+	requireBarePods(1, "A bare Pod should be deleted per ready replica")
+	setReadyReplicas(2)
+	requireBarePods(0, "All bare Pods should be deleted")
+}